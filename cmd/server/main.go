@@ -12,6 +12,7 @@ import (
 	"github.com/nekogravitycat/court-booking-backend/internal/app"
 	"github.com/nekogravitycat/court-booking-backend/internal/config"
 	"github.com/nekogravitycat/court-booking-backend/internal/db"
+	"github.com/nekogravitycat/court-booking-backend/internal/notification"
 )
 
 const SERVER_SHUTDOWN_TIMEOUT = 5 * time.Second
@@ -48,14 +49,35 @@ func main() {
 
 	// Initialize App Container
 	appContainer := app.NewContainer(app.Config{
-		IsProduction: cfg.IsProduction,
-		ProdOrigins:  cfg.ProdOrigins,
-		DBPool:       pool,
-		JWTSecret:    cfg.JWTSecret,
-		JWTTTL:       cfg.JWTAccessTokenTTL,
-		BcryptCost:   cfg.BcryptCost,
+		IsProduction:            cfg.IsProduction,
+		ProdOrigins:             cfg.ProdOrigins,
+		DBPool:                  pool,
+		JWTSecret:               cfg.JWTSecret,
+		JWTTTL:                  cfg.JWTAccessTokenTTL,
+		BcryptCost:              cfg.BcryptCost,
+		PickupTemplateInterval:  cfg.PickupTemplateInterval,
+		PickupLifecycleInterval: cfg.PickupLifecycleInterval,
+		PickupAutoCancelCutoff:  cfg.PickupAutoCancelCutoff,
+		NotificationInterval:    cfg.NotificationInterval,
+		SMTP: notification.SMTPConfig{
+			Host:     cfg.SMTPHost,
+			Port:     cfg.SMTPPort,
+			Username: cfg.SMTPUsername,
+			Password: cfg.SMTPPassword,
+			From:     cfg.SMTPFrom,
+		},
+		NotificationWebhookURL:    cfg.NotificationWebhookURL,
+		NotificationWebhookSecret: cfg.NotificationWebhookSecret,
+		ReminderOffsets:           cfg.ReminderOffsets,
+		ReminderInterval:          cfg.ReminderInterval,
+		WebhookInterval:           cfg.WebhookInterval,
 	})
 
+	// Start background workers; they stop when ctx is cancelled on shutdown.
+	for _, w := range appContainer.Workers {
+		go w.Start(ctx)
+	}
+
 	// Use http.Server for graceful shutdown
 	server := &http.Server{
 		Addr:    cfg.HTTPAddr,
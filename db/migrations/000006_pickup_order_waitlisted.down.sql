@@ -0,0 +1,20 @@
+-- Revert 000006: remove the 'waitlisted' pickup order status.
+--
+-- PostgreSQL cannot drop a value from an enum, so the type is rebuilt without
+-- 'waitlisted'. Any queued orders are folded into 'cancelled' (they never held a
+-- seat) before the value disappears.
+UPDATE public.pickup_orders SET status = 'cancelled' WHERE status = 'waitlisted';
+
+ALTER TABLE public.pickup_orders ALTER COLUMN status DROP DEFAULT;
+
+ALTER TYPE pickup_order_status RENAME TO pickup_order_status_old;
+
+CREATE TYPE pickup_order_status AS ENUM ('pending', 'confirmed', 'cancelled', 'cancel_request', 'rejected');
+
+ALTER TABLE public.pickup_orders
+  ALTER COLUMN status TYPE pickup_order_status
+  USING status::text::pickup_order_status;
+
+ALTER TABLE public.pickup_orders ALTER COLUMN status SET DEFAULT 'pending';
+
+DROP TYPE pickup_order_status_old;
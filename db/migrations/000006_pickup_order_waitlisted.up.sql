@@ -0,0 +1,12 @@
+-- Migration 000006: add a 'waitlisted' pickup order status.
+--
+-- Rationale:
+--   * Users may queue on a full pickup group instead of being turned away. A
+--     waitlisted order does not occupy a seat (it is excluded from the enrolled
+--     count) and is promoted to 'pending' in FIFO order as seats free up.
+--   * The queue position is tracked by waitlisted_at, added in 000007.
+--
+-- ALTER TYPE ... ADD VALUE must stand alone in this migration: it cannot share a
+-- transaction with statements that use the new value, so no other statement is
+-- added here.
+ALTER TYPE pickup_order_status ADD VALUE IF NOT EXISTS 'waitlisted';
@@ -0,0 +1,5 @@
+-- Revert 000007: drop waitlist queue ordering.
+DROP INDEX IF EXISTS public.idx_pickup_orders_waitlist;
+
+ALTER TABLE public.pickup_orders
+  DROP COLUMN IF EXISTS waitlisted_at;
@@ -0,0 +1,15 @@
+-- Migration 000007: waitlist queue ordering for pickup orders.
+--
+-- Rationale:
+--   * waitlisted_at is set when an order joins the waitlist and cleared when it
+--     is promoted. The queue is ordered by (waitlisted_at, id); the position
+--     shown to users is derived live from that order rather than stored, so
+--     leaving the queue never requires renumbering other rows.
+--   * The partial index serves both the position lookup and the FIFO promotion
+--     scan, which only ever touch waitlisted rows of a single group.
+ALTER TABLE public.pickup_orders
+  ADD COLUMN IF NOT EXISTS waitlisted_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_pickup_orders_waitlist
+  ON public.pickup_orders (pickup_group_id, waitlisted_at, id)
+  WHERE status = 'waitlisted';
@@ -0,0 +1,12 @@
+-- Revert 000008: drop recurring pickup group templates. Materialised groups are
+-- kept as ordinary one-off groups.
+DROP INDEX IF EXISTS public.uq_pickup_groups_template_occurrence;
+
+ALTER TABLE public.pickup_groups
+  DROP CONSTRAINT IF EXISTS pickup_groups_template_id_fkey;
+
+ALTER TABLE public.pickup_groups
+  DROP COLUMN IF EXISTS occurrence_date,
+  DROP COLUMN IF EXISTS template_id;
+
+DROP TABLE IF EXISTS public.pickup_group_templates;
@@ -0,0 +1,95 @@
+-- Migration 000008: recurring pickup group templates.
+--
+-- Rationale:
+--   * Hosts run the same session every week. A template stores the group
+--     fields (title, fee, capacity, location, sport, skill level) plus a weekly
+--     recurrence rule, and concrete pickup_groups rows are materialised from it
+--     up to advance_days ahead.
+--   * The rule is expressed in the host's wall-clock time: start_local is a
+--     local TIME interpreted in the template's IANA timezone, so a session stays
+--     at 19:00 across DST changes.
+--   * Each materialised group records (template_id, occurrence_date). The
+--     partial unique index makes materialisation idempotent (ON CONFLICT DO
+--     NOTHING), so a worker and an API call racing cannot create duplicates.
+--   * materialized_through is a watermark: dates up to it are never generated
+--     again. An occurrence the host edited, cancelled, or an admin deleted is
+--     therefore left alone, which lets single occurrences diverge from the
+--     template independently.
+--   * template_id is ON DELETE SET NULL: deleting a template stops future
+--     materialisation but keeps already-created groups (and their orders).
+CREATE TABLE IF NOT EXISTS public.pickup_group_templates (
+  -- Identity
+  id                   UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  host_id              UUID NOT NULL,                           -- FK to users, the host owning the template
+
+  -- Fields copied onto every materialised group
+  title                TEXT NOT NULL,
+  fee                  INTEGER NOT NULL DEFAULT 0,
+  capacity             INTEGER NOT NULL,
+  location_id          UUID NOT NULL,
+  sport_id             UUID NOT NULL,
+  skill_level_id       UUID NOT NULL,
+
+  -- Recurrence rule
+  weekdays             INTEGER[] NOT NULL,                      -- Days of week, 0 = Sunday ... 6 = Saturday
+  start_local          TIME WITHOUT TIME ZONE NOT NULL,         -- Local start time in `timezone`
+  duration_minutes     INTEGER NOT NULL,                        -- Length of each occurrence
+  timezone             TEXT NOT NULL DEFAULT 'UTC',             -- IANA timezone of start_local
+  advance_days         INTEGER NOT NULL DEFAULT 14,             -- How far ahead to materialise
+  starts_on            DATE NOT NULL,                           -- First date eligible for an occurrence
+  ends_on              DATE,                                    -- Last eligible date (NULL = open-ended)
+  is_active            BOOLEAN NOT NULL DEFAULT true,           -- Paused templates materialise nothing
+
+  -- Materialisation watermark (local date in `timezone`)
+  materialized_through DATE,
+
+  -- Meta / Audit
+  created_at           TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at           TIMESTAMPTZ NOT NULL DEFAULT now(),
+
+  CONSTRAINT pickup_group_templates_host_id_fkey
+    FOREIGN KEY (host_id) REFERENCES public.users(id) ON DELETE CASCADE,
+
+  CONSTRAINT pickup_group_templates_location_id_fkey
+    FOREIGN KEY (location_id) REFERENCES public.locations(id) ON DELETE RESTRICT,
+
+  CONSTRAINT pickup_group_templates_sport_id_fkey
+    FOREIGN KEY (sport_id) REFERENCES public.sports(id) ON DELETE RESTRICT,
+
+  CONSTRAINT pickup_group_templates_skill_level_id_fkey
+    FOREIGN KEY (skill_level_id) REFERENCES public.skill_levels(id) ON DELETE RESTRICT,
+
+  CONSTRAINT pickup_group_templates_capacity_positive
+    CHECK (capacity > 0),
+
+  CONSTRAINT pickup_group_templates_fee_non_negative
+    CHECK (fee >= 0),
+
+  CONSTRAINT pickup_group_templates_weekdays_valid
+    CHECK (cardinality(weekdays) > 0 AND weekdays <@ ARRAY[0, 1, 2, 3, 4, 5, 6]),
+
+  CONSTRAINT pickup_group_templates_duration_valid
+    CHECK (duration_minutes > 0 AND duration_minutes <= 1440),
+
+  CONSTRAINT pickup_group_templates_advance_days_valid
+    CHECK (advance_days BETWEEN 1 AND 90),
+
+  CONSTRAINT pickup_group_templates_date_range_valid
+    CHECK (ends_on IS NULL OR ends_on >= starts_on)
+);
+
+CREATE INDEX IF NOT EXISTS idx_pickup_group_templates_host_id
+  ON public.pickup_group_templates (host_id);
+
+-- pickup_groups: link materialised occurrences back to their template.
+ALTER TABLE public.pickup_groups
+  ADD COLUMN IF NOT EXISTS template_id UUID,
+  ADD COLUMN IF NOT EXISTS occurrence_date DATE;
+
+ALTER TABLE public.pickup_groups
+  ADD CONSTRAINT pickup_groups_template_id_fkey
+    FOREIGN KEY (template_id) REFERENCES public.pickup_group_templates(id) ON DELETE SET NULL;
+
+CREATE UNIQUE INDEX IF NOT EXISTS uq_pickup_groups_template_occurrence
+  ON public.pickup_groups (template_id, occurrence_date)
+  WHERE template_id IS NOT NULL;
@@ -0,0 +1,11 @@
+-- Revert 000009: unlink pickup groups from resources and bookings. The
+-- bookings themselves are kept as ordinary bookings of the host.
+DROP INDEX IF EXISTS public.uq_pickup_groups_booking_id;
+
+ALTER TABLE public.pickup_groups
+  DROP CONSTRAINT IF EXISTS pickup_groups_booking_id_fkey,
+  DROP CONSTRAINT IF EXISTS pickup_groups_resource_id_fkey;
+
+ALTER TABLE public.pickup_groups
+  DROP COLUMN IF EXISTS booking_id,
+  DROP COLUMN IF EXISTS resource_id;
@@ -0,0 +1,32 @@
+-- Migration 000009: link pickup groups to the court they are played on.
+--
+-- Rationale:
+--   * A pickup group only knew its location, so nothing reserved the actual
+--     court and hosts double-booked or found the court taken. resource_id
+--     names the court and booking_id points at the bookings row that reserves
+--     it for the group's time.
+--   * The booking is created (or an existing one of the host's is linked) in
+--     the same transaction as the group, so the bookings_no_overlap exclusion
+--     constraint from 000002 guards pickup groups exactly like regular
+--     bookings.
+--   * The booking's lifecycle follows the group: cancelling the group cancels
+--     the booking, re-activating it re-requests the booking, and moving the
+--     group moves the booking.
+--   * uq_pickup_groups_booking_id keeps a booking from being shared by two
+--     groups. booking_id is ON DELETE SET NULL so a system admin removing the
+--     booking leaves the group in place without a reserved court.
+ALTER TABLE public.pickup_groups
+  ADD COLUMN IF NOT EXISTS resource_id UUID,
+  ADD COLUMN IF NOT EXISTS booking_id UUID;
+
+ALTER TABLE public.pickup_groups
+  ADD CONSTRAINT pickup_groups_resource_id_fkey
+    FOREIGN KEY (resource_id) REFERENCES public.resources(id) ON DELETE RESTRICT;
+
+ALTER TABLE public.pickup_groups
+  ADD CONSTRAINT pickup_groups_booking_id_fkey
+    FOREIGN KEY (booking_id) REFERENCES public.bookings(id) ON DELETE SET NULL;
+
+CREATE UNIQUE INDEX IF NOT EXISTS uq_pickup_groups_booking_id
+  ON public.pickup_groups (booking_id)
+  WHERE booking_id IS NOT NULL;
@@ -0,0 +1,20 @@
+-- Revert 000010: remove the 'completed' pickup order status.
+--
+-- PostgreSQL cannot drop a value from an enum, so the type is rebuilt without
+-- 'completed'. Completed orders fold back into 'confirmed', the status they held
+-- before their group ended.
+UPDATE public.pickup_orders SET status = 'confirmed' WHERE status = 'completed';
+
+ALTER TABLE public.pickup_orders ALTER COLUMN status DROP DEFAULT;
+
+ALTER TYPE pickup_order_status RENAME TO pickup_order_status_old;
+
+CREATE TYPE pickup_order_status AS ENUM ('pending', 'confirmed', 'cancelled', 'cancel_request', 'rejected', 'waitlisted');
+
+ALTER TABLE public.pickup_orders
+  ALTER COLUMN status TYPE pickup_order_status
+  USING status::text::pickup_order_status;
+
+ALTER TABLE public.pickup_orders ALTER COLUMN status SET DEFAULT 'pending';
+
+DROP TYPE pickup_order_status_old;
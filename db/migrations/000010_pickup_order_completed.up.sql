@@ -0,0 +1,12 @@
+-- Migration 000010: add a 'completed' pickup order status.
+--
+-- Rationale:
+--   * The lifecycle worker completes pickup groups once they have ended. Their
+--     confirmed orders move to 'completed', a terminal status that records the
+--     participant played, while orders that never got a seat or were never
+--     confirmed are cancelled.
+--
+-- ALTER TYPE ... ADD VALUE must stand alone in this migration: it cannot share a
+-- transaction with statements that use the new value, so no other statement is
+-- added here.
+ALTER TYPE pickup_order_status ADD VALUE IF NOT EXISTS 'completed';
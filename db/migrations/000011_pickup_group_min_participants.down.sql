@@ -0,0 +1,8 @@
+-- Revert 000011: drop the pickup group minimum headcount.
+DROP INDEX IF EXISTS public.idx_pickup_groups_active_start;
+
+ALTER TABLE public.pickup_groups
+  DROP CONSTRAINT IF EXISTS pickup_groups_min_participants_valid;
+
+ALTER TABLE public.pickup_groups
+  DROP COLUMN IF EXISTS min_participants;
@@ -0,0 +1,19 @@
+-- Migration 000011: minimum headcount for pickup groups.
+--
+-- Rationale:
+--   * A host may require a minimum number of participants. When a group still
+--     has fewer seat-holding orders than min_participants at the auto-cancel
+--     cutoff before start_time, the lifecycle worker cancels it (and its orders
+--     and court booking). 0 disables the check.
+--   * idx_pickup_groups_active_start lets the worker find active groups due for
+--     completion or the headcount check without scanning finished groups.
+ALTER TABLE public.pickup_groups
+  ADD COLUMN IF NOT EXISTS min_participants INTEGER NOT NULL DEFAULT 0;
+
+ALTER TABLE public.pickup_groups
+  ADD CONSTRAINT pickup_groups_min_participants_valid
+    CHECK (min_participants >= 0 AND min_participants <= capacity);
+
+CREATE INDEX IF NOT EXISTS idx_pickup_groups_active_start
+  ON public.pickup_groups (start_time)
+  WHERE status = 'active';
@@ -0,0 +1,2 @@
+-- Revert 000012: drop pickup host reviews.
+DROP TABLE IF EXISTS public.pickup_host_reviews;
@@ -0,0 +1,53 @@
+-- Migration 000012: participant reviews of pickup hosts.
+--
+-- Rationale:
+--   * Users choose hosts (favorite_hosts) without any signal about host
+--     quality. A participant whose order was confirmed in a group that has
+--     completed may rate the host 1-5 with an optional comment.
+--   * One review per order: order_id is unique, so a participant reviews each
+--     session they attended at most once, but may review the same host again
+--     after another session.
+--   * host_id and reviewer_id are copied from the group / order at insert time
+--     so the per-host aggregate (average, count) is a single indexed scan and
+--     does not depend on the group row staying around.
+--   * The aggregate is derived live (AVG / COUNT over idx_pickup_host_reviews_host_id)
+--     instead of being denormalised onto pickup_hosts, so it stays correct when
+--     the host role is revoked and re-granted or a review row is removed.
+--   * order_id cascades: hard-deleting an order (system admin only) removes
+--     its review with it.
+CREATE TABLE IF NOT EXISTS public.pickup_host_reviews (
+  -- Identity
+  id              UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+
+  -- Relationships
+  order_id        UUID NOT NULL,                              -- The completed order being reviewed
+  pickup_group_id UUID NOT NULL,                              -- The group the order belonged to
+  host_id         UUID NOT NULL,                              -- The host being reviewed
+  reviewer_id     UUID NOT NULL,                              -- The participant writing the review
+
+  -- Content
+  rating          SMALLINT NOT NULL,                          -- 1 (worst) .. 5 (best)
+  comment         TEXT,                                       -- Optional free text
+
+  -- Meta / Audit
+  created_at      TIMESTAMPTZ NOT NULL DEFAULT now(),
+
+  CONSTRAINT pickup_host_reviews_rating_check CHECK (rating BETWEEN 1 AND 5),
+
+  CONSTRAINT pickup_host_reviews_order_id_key UNIQUE (order_id),
+
+  CONSTRAINT pickup_host_reviews_order_id_fkey
+    FOREIGN KEY (order_id) REFERENCES public.pickup_orders(id) ON DELETE CASCADE,
+
+  CONSTRAINT pickup_host_reviews_pickup_group_id_fkey
+    FOREIGN KEY (pickup_group_id) REFERENCES public.pickup_groups(id) ON DELETE CASCADE,
+
+  CONSTRAINT pickup_host_reviews_host_id_fkey
+    FOREIGN KEY (host_id) REFERENCES public.users(id) ON DELETE CASCADE,
+
+  CONSTRAINT pickup_host_reviews_reviewer_id_fkey
+    FOREIGN KEY (reviewer_id) REFERENCES public.users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_pickup_host_reviews_host_id
+  ON public.pickup_host_reviews (host_id, created_at DESC);
@@ -0,0 +1,14 @@
+-- Revert 000013: drop attendance marking and the reliability requirement.
+ALTER TABLE public.pickup_groups
+  DROP CONSTRAINT IF EXISTS pickup_groups_min_reliability_valid;
+
+ALTER TABLE public.pickup_groups
+  DROP COLUMN IF EXISTS min_reliability;
+
+DROP INDEX IF EXISTS public.idx_pickup_orders_user_attendance;
+
+ALTER TABLE public.pickup_orders
+  DROP COLUMN IF EXISTS attendance_marked_at,
+  DROP COLUMN IF EXISTS attendance;
+
+DROP TYPE IF EXISTS pickup_attendance;
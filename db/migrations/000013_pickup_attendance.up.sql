@@ -0,0 +1,30 @@
+-- Migration 000013: attendance marking and participant reliability.
+--
+-- Rationale:
+--   * Hosts need to record who actually showed up. Once a group has started,
+--     the host marks each confirmed (or completed) order as attended or
+--     no_show; NULL means not marked yet. The mark may be corrected later.
+--   * A participant's reliability score is derived live from their marked
+--     orders across all groups: attended / (attended + no_show), as a
+--     percentage. Users with no marked history have no score and are never
+--     blocked by it. idx_pickup_orders_user_attendance keeps the per-user
+--     aggregate an index scan.
+--   * min_reliability (0-100, 0 = off) lets a host require a minimum score to
+--     enroll. It is checked at enrollment time only; existing orders are not
+--     affected when it is raised.
+CREATE TYPE pickup_attendance AS ENUM ('attended', 'no_show');
+
+ALTER TABLE public.pickup_orders
+  ADD COLUMN IF NOT EXISTS attendance            pickup_attendance,
+  ADD COLUMN IF NOT EXISTS attendance_marked_at  TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_pickup_orders_user_attendance
+  ON public.pickup_orders (user_id)
+  WHERE attendance IS NOT NULL;
+
+ALTER TABLE public.pickup_groups
+  ADD COLUMN IF NOT EXISTS min_reliability INTEGER NOT NULL DEFAULT 0;
+
+ALTER TABLE public.pickup_groups
+  ADD CONSTRAINT pickup_groups_min_reliability_valid
+    CHECK (min_reliability BETWEEN 0 AND 100);
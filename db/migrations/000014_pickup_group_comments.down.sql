@@ -0,0 +1,2 @@
+-- Revert 000014: drop pickup group comment threads.
+DROP TABLE IF EXISTS public.pickup_group_comments;
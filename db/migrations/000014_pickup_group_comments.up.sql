@@ -0,0 +1,41 @@
+-- Migration 000014: comment thread on pickup groups.
+--
+-- Rationale:
+--   * Participants coordinate details ("who brings shuttlecocks?") per group.
+--     Each group has a flat, paginated thread readable and writable by the host
+--     and users holding a pending / confirmed (or, after the group ended,
+--     completed) order. Membership is checked in the service at request time,
+--     so a user who cancels loses access without any row changes here.
+--   * Hosts moderate: they can delete any comment and pin comments. Pinned
+--     comments are listed first (most recently pinned first), followed by the
+--     rest in chronological order. pinned_at records the pin order and is NULL
+--     while unpinned.
+--   * Comments are hard-deleted; the thread is ephemeral coordination, not an
+--     audit trail. Deleting the group or the author's account removes them.
+CREATE TABLE IF NOT EXISTS public.pickup_group_comments (
+  -- Identity
+  id              UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+
+  -- Relationships
+  pickup_group_id UUID NOT NULL,                              -- The group the thread belongs to
+  author_id       UUID NOT NULL,                              -- The user who wrote the comment
+
+  -- Content
+  body            TEXT NOT NULL,                              -- 1-2000 characters
+  pinned_at       TIMESTAMPTZ,                                -- When the host pinned it (NULL = not pinned)
+
+  -- Meta / Audit
+  created_at      TIMESTAMPTZ NOT NULL DEFAULT now(),
+
+  CONSTRAINT pickup_group_comments_body_length
+    CHECK (char_length(body) BETWEEN 1 AND 2000),
+
+  CONSTRAINT pickup_group_comments_pickup_group_id_fkey
+    FOREIGN KEY (pickup_group_id) REFERENCES public.pickup_groups(id) ON DELETE CASCADE,
+
+  CONSTRAINT pickup_group_comments_author_id_fkey
+    FOREIGN KEY (author_id) REFERENCES public.users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_pickup_group_comments_group
+  ON public.pickup_group_comments (pickup_group_id, created_at);
@@ -0,0 +1,8 @@
+-- Revert 000015: drop pickup group visibility; every group becomes public again.
+DROP INDEX IF EXISTS public.uq_pickup_groups_invite_code;
+
+ALTER TABLE public.pickup_groups
+  DROP COLUMN IF EXISTS invite_code,
+  DROP COLUMN IF EXISTS visibility;
+
+DROP TYPE IF EXISTS pickup_group_visibility;
@@ -0,0 +1,24 @@
+-- Migration 000015: pickup group visibility and invite codes.
+--
+-- Rationale:
+--   * Hosts running closed club sessions need groups that do not appear in the
+--     public listing. visibility controls this:
+--       - public:   listed, viewable and joinable by anyone (the old behaviour,
+--                   and the default for existing rows);
+--       - unlisted: not listed, but anyone with the link (group id or invite
+--                   code) can view and join;
+--       - private:  not listed; viewing and joining require the invite code
+--                   (the host, system admins and users already holding an order
+--                   are exempt).
+--   * invite_code is generated for unlisted and private groups and can be
+--     rotated by the host, which invalidates previously shared links. It is
+--     NULL for public groups. The unique index also serves look-ups by code.
+CREATE TYPE pickup_group_visibility AS ENUM ('public', 'unlisted', 'private');
+
+ALTER TABLE public.pickup_groups
+  ADD COLUMN IF NOT EXISTS visibility  pickup_group_visibility NOT NULL DEFAULT 'public',
+  ADD COLUMN IF NOT EXISTS invite_code TEXT;
+
+CREATE UNIQUE INDEX IF NOT EXISTS uq_pickup_groups_invite_code
+  ON public.pickup_groups (invite_code)
+  WHERE invite_code IS NOT NULL;
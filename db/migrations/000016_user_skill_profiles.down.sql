@@ -0,0 +1,10 @@
+-- Revert 000016: drop user skill profiles and group skill ranges.
+ALTER TABLE public.pickup_groups
+  DROP CONSTRAINT IF EXISTS pickup_groups_max_skill_level_id_fkey,
+  DROP CONSTRAINT IF EXISTS pickup_groups_min_skill_level_id_fkey;
+
+ALTER TABLE public.pickup_groups
+  DROP COLUMN IF EXISTS max_skill_level_id,
+  DROP COLUMN IF EXISTS min_skill_level_id;
+
+DROP TABLE IF EXISTS public.user_skill_profiles;
@@ -0,0 +1,52 @@
+-- Migration 000016: per-sport user skill profiles and group skill ranges.
+--
+-- Rationale:
+--   * Skill levels only classified groups, so nothing stopped a beginner from
+--     enrolling in an A-level group. Each user now holds at most one skill
+--     level per sport. It is self-declared and may later be verified by the
+--     host of a game the user played in; verified_by / verified_at record the
+--     most recent verification and are cleared when the user re-declares.
+--   * A group may restrict enrollment to a skill-level range of its sport.
+--     min_skill_level_id / max_skill_level_id are the inclusive bounds,
+--     compared by skill_levels.sort_order; either bound may be NULL for an
+--     open-ended range. When any bound is set, a user must have a profile for
+--     the group's sport whose level falls inside the range to enroll. Like
+--     min_reliability, the range is checked at enrollment time only.
+CREATE TABLE IF NOT EXISTS public.user_skill_profiles (
+  -- Identity
+  user_id         UUID NOT NULL,                              -- The profile owner
+  sport_id        UUID NOT NULL,                              -- One profile per sport
+
+  -- Level
+  skill_level_id  UUID NOT NULL,                              -- Must belong to sport_id (checked by the service)
+  verified_by     UUID,                                       -- Host who last verified the level (NULL = self-declared)
+  verified_at     TIMESTAMPTZ,
+
+  -- Meta / Audit
+  created_at      TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at      TIMESTAMPTZ NOT NULL DEFAULT now(),
+
+  PRIMARY KEY (user_id, sport_id),
+
+  CONSTRAINT user_skill_profiles_user_id_fkey
+    FOREIGN KEY (user_id) REFERENCES public.users(id) ON DELETE CASCADE,
+
+  CONSTRAINT user_skill_profiles_sport_id_fkey
+    FOREIGN KEY (sport_id) REFERENCES public.sports(id) ON DELETE RESTRICT,
+
+  CONSTRAINT user_skill_profiles_skill_level_id_fkey
+    FOREIGN KEY (skill_level_id) REFERENCES public.skill_levels(id) ON DELETE RESTRICT,
+
+  CONSTRAINT user_skill_profiles_verified_by_fkey
+    FOREIGN KEY (verified_by) REFERENCES public.users(id) ON DELETE SET NULL
+);
+
+ALTER TABLE public.pickup_groups
+  ADD COLUMN IF NOT EXISTS min_skill_level_id UUID,
+  ADD COLUMN IF NOT EXISTS max_skill_level_id UUID;
+
+ALTER TABLE public.pickup_groups
+  ADD CONSTRAINT pickup_groups_min_skill_level_id_fkey
+    FOREIGN KEY (min_skill_level_id) REFERENCES public.skill_levels(id) ON DELETE RESTRICT,
+  ADD CONSTRAINT pickup_groups_max_skill_level_id_fkey
+    FOREIGN KEY (max_skill_level_id) REFERENCES public.skill_levels(id) ON DELETE RESTRICT;
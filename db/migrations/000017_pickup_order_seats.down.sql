@@ -0,0 +1,14 @@
+-- Revert 000017: back to one seat per pickup order.
+ALTER TABLE public.pickup_groups
+  DROP CONSTRAINT IF EXISTS pickup_groups_max_guests_per_order_valid;
+
+ALTER TABLE public.pickup_groups
+  DROP COLUMN IF EXISTS max_guests_per_order;
+
+ALTER TABLE public.pickup_orders
+  DROP CONSTRAINT IF EXISTS pickup_orders_guest_names_fit,
+  DROP CONSTRAINT IF EXISTS pickup_orders_seats_positive;
+
+ALTER TABLE public.pickup_orders
+  DROP COLUMN IF EXISTS guest_names,
+  DROP COLUMN IF EXISTS seats;
@@ -0,0 +1,31 @@
+-- Migration 000017: multi-seat pickup orders for guests without accounts.
+--
+-- Rationale:
+--   * An order used to hold exactly one seat, so a player bringing friends
+--     without accounts could not enroll them. seats is the number of places
+--     the order takes (the booker plus guests); guest_names optionally lists
+--     the guests, at most seats - 1 of them.
+--   * Capacity, current_enrolled, free-seat search, waitlist promotion and
+--     the min_participants auto-cancel now sum seats instead of counting
+--     rows. Existing orders default to one seat, so nothing changes for them.
+--     Waitlist promotion stays strictly first-come: the queue head is promoted
+--     only while it fits, and a large party at the head is not skipped.
+--   * max_guests_per_order lets the host cap guests per order; NULL means no
+--     cap beyond the group capacity and 0 disallows guests. It is checked at
+--     enrollment time only.
+ALTER TABLE public.pickup_orders
+  ADD COLUMN IF NOT EXISTS seats       INTEGER NOT NULL DEFAULT 1,
+  ADD COLUMN IF NOT EXISTS guest_names TEXT[]  NOT NULL DEFAULT '{}';
+
+ALTER TABLE public.pickup_orders
+  ADD CONSTRAINT pickup_orders_seats_positive
+    CHECK (seats >= 1),
+  ADD CONSTRAINT pickup_orders_guest_names_fit
+    CHECK (cardinality(guest_names) <= seats - 1);
+
+ALTER TABLE public.pickup_groups
+  ADD COLUMN IF NOT EXISTS max_guests_per_order INTEGER;
+
+ALTER TABLE public.pickup_groups
+  ADD CONSTRAINT pickup_groups_max_guests_per_order_valid
+    CHECK (max_guests_per_order IS NULL OR max_guests_per_order >= 0);
@@ -0,0 +1,4 @@
+-- Revert 000018: drop the pickup payment ledger.
+DROP TABLE IF EXISTS public.pickup_payments;
+
+DROP TYPE IF EXISTS pickup_payment_method;
@@ -0,0 +1,70 @@
+-- Migration 000018: ledger of pickup fee payments.
+--
+-- Rationale:
+--   * pickup_orders.payment_status only says whether an order is paid, not how
+--     much, how, or when, so hosts had no record of what they collected. Each
+--     row is one payment the host (or a system admin) recorded against an
+--     order; an order may have several, e.g. a deposit and the balance.
+--   * Recording a payment marks the order's payment_status as done. Refunds
+--     are recorded on the payment itself (refunded_at / refunded_by) for its
+--     full amount; the row is never deleted, so earnings can be reconciled.
+--   * pickup_group_id, host_id and payer_user_id are copied from the order and
+--     group at insert time. The ledger outlives its order and group: deleting
+--     either only clears the link, while host_id keeps the earnings attributed.
+--   * Period summaries book a payment at paid_at and its refund at
+--     refunded_at, so a refund lowers the earnings of the period it happened in.
+CREATE TYPE pickup_payment_method AS ENUM ('cash', 'bank_transfer', 'line_pay', 'other');
+
+CREATE TABLE IF NOT EXISTS public.pickup_payments (
+  -- Identity
+  id              UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+
+  -- Relationships
+  order_id        UUID,                                       -- The order paid for (NULL once the order is deleted)
+  pickup_group_id UUID,                                       -- The order's group (NULL once the group is deleted)
+  host_id         UUID NOT NULL,                              -- The host the money was paid to
+  payer_user_id   UUID,                                       -- The order's booker
+
+  -- Payment
+  amount          INTEGER NOT NULL,                           -- Amount collected, in the group's fee unit
+  method          pickup_payment_method NOT NULL,
+  paid_at         TIMESTAMPTZ NOT NULL DEFAULT now(),
+  note            TEXT,
+  recorded_by     UUID,                                       -- Host or admin who recorded the payment
+
+  -- Refund
+  refunded_at     TIMESTAMPTZ,                                -- NULL while the payment stands
+  refunded_by     UUID,
+
+  -- Meta / Audit
+  created_at      TIMESTAMPTZ NOT NULL DEFAULT now(),
+
+  CONSTRAINT pickup_payments_amount_check CHECK (amount > 0),
+
+  CONSTRAINT pickup_payments_order_id_fkey
+    FOREIGN KEY (order_id) REFERENCES public.pickup_orders(id) ON DELETE SET NULL,
+
+  CONSTRAINT pickup_payments_pickup_group_id_fkey
+    FOREIGN KEY (pickup_group_id) REFERENCES public.pickup_groups(id) ON DELETE SET NULL,
+
+  CONSTRAINT pickup_payments_host_id_fkey
+    FOREIGN KEY (host_id) REFERENCES public.users(id) ON DELETE CASCADE,
+
+  CONSTRAINT pickup_payments_payer_user_id_fkey
+    FOREIGN KEY (payer_user_id) REFERENCES public.users(id) ON DELETE SET NULL,
+
+  CONSTRAINT pickup_payments_recorded_by_fkey
+    FOREIGN KEY (recorded_by) REFERENCES public.users(id) ON DELETE SET NULL,
+
+  CONSTRAINT pickup_payments_refunded_by_fkey
+    FOREIGN KEY (refunded_by) REFERENCES public.users(id) ON DELETE SET NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_pickup_payments_order_id
+  ON public.pickup_payments (order_id);
+
+CREATE INDEX IF NOT EXISTS idx_pickup_payments_pickup_group_id
+  ON public.pickup_payments (pickup_group_id);
+
+CREATE INDEX IF NOT EXISTS idx_pickup_payments_host_id_paid_at
+  ON public.pickup_payments (host_id, paid_at);
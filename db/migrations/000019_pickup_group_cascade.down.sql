@@ -0,0 +1,8 @@
+-- Revert 000019: drop the pickup order refund and reconfirmation flags.
+DROP INDEX IF EXISTS public.idx_pickup_orders_refund_pending;
+
+ALTER TABLE public.pickup_orders
+  DROP COLUMN IF EXISTS reconfirm_required,
+  DROP COLUMN IF EXISTS refund_status;
+
+DROP TYPE IF EXISTS pickup_refund_status;
@@ -0,0 +1,23 @@
+-- Migration 000019: cascade pickup group cancellations and material changes
+-- onto their orders.
+--
+-- Rationale:
+--   * Cancelling a group used to leave its orders untouched, so participants
+--     still appeared enrolled and nobody tracked the fees owed back. A host
+--     cancellation now cancels every open order, as the min_participants
+--     auto-cancel already did, and flags paid orders with refund_status =
+--     'pending'.
+--   * refund_status is NULL while no refund is owed. It becomes 'done' once
+--     every payment recorded for the order in the ledger is refunded.
+--   * Moving a group's time or location, or raising its fee, sets
+--     reconfirm_required on its open orders. The participant either
+--     reconfirms, which clears the flag, or withdraws; a paid order withdrawn
+--     while the flag is set is owed a full refund like a cancelled group.
+CREATE TYPE pickup_refund_status AS ENUM ('pending', 'done');
+
+ALTER TABLE public.pickup_orders
+  ADD COLUMN IF NOT EXISTS refund_status      pickup_refund_status,          -- NULL: no refund owed
+  ADD COLUMN IF NOT EXISTS reconfirm_required BOOLEAN NOT NULL DEFAULT false; -- Set by a material group change
+
+CREATE INDEX IF NOT EXISTS idx_pickup_orders_refund_pending
+  ON public.pickup_orders (pickup_group_id) WHERE refund_status = 'pending';
@@ -0,0 +1,4 @@
+-- Revert 000020: drop pickup group and template co-hosts.
+DROP TABLE IF EXISTS public.pickup_template_cohosts;
+
+DROP TABLE IF EXISTS public.pickup_group_cohosts;
@@ -0,0 +1,44 @@
+-- Migration 000020: co-hosts for pickup groups and recurring templates.
+--
+-- Rationale:
+--   * A group had a single host_id, so clubs sharing hosting duties had to
+--     share one account. A co-host may review enrollments, edit the group,
+--     mark attendance and moderate its comment thread. The primary host keeps
+--     ownership: only they (or a system admin) delete the group, manage its
+--     co-hosts, and collect its fees in the payment ledger.
+--   * Co-hosts need not hold the pickup host role; that role only gates who
+--     may own groups.
+--   * Template co-hosts are copied onto each group materialised from the
+--     template. Later edits to either list do not affect the other.
+CREATE TABLE IF NOT EXISTS public.pickup_group_cohosts (
+  pickup_group_id UUID NOT NULL,
+  user_id         UUID NOT NULL,
+  added_by        UUID,                                       -- The user who added the co-host (NULL once deleted)
+  created_at      TIMESTAMPTZ NOT NULL DEFAULT now(),
+
+  PRIMARY KEY (pickup_group_id, user_id),
+  CONSTRAINT fk_pickup_group_cohosts_group
+    FOREIGN KEY (pickup_group_id) REFERENCES public.pickup_groups(id) ON DELETE CASCADE,
+  CONSTRAINT fk_pickup_group_cohosts_user
+    FOREIGN KEY (user_id) REFERENCES public.users(id) ON DELETE CASCADE,
+  CONSTRAINT fk_pickup_group_cohosts_added_by
+    FOREIGN KEY (added_by) REFERENCES public.users(id) ON DELETE SET NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_pickup_group_cohosts_user
+  ON public.pickup_group_cohosts (user_id);
+
+CREATE TABLE IF NOT EXISTS public.pickup_template_cohosts (
+  template_id UUID NOT NULL,
+  user_id     UUID NOT NULL,
+  added_by    UUID,                                           -- The user who added the co-host (NULL once deleted)
+  created_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+
+  PRIMARY KEY (template_id, user_id),
+  CONSTRAINT fk_pickup_template_cohosts_template
+    FOREIGN KEY (template_id) REFERENCES public.pickup_group_templates(id) ON DELETE CASCADE,
+  CONSTRAINT fk_pickup_template_cohosts_user
+    FOREIGN KEY (user_id) REFERENCES public.users(id) ON DELETE CASCADE,
+  CONSTRAINT fk_pickup_template_cohosts_added_by
+    FOREIGN KEY (added_by) REFERENCES public.users(id) ON DELETE SET NULL
+);
@@ -0,0 +1,8 @@
+-- Revert 000021: drop the pickup group enrollment and cancellation deadlines.
+ALTER TABLE public.pickup_groups
+  DROP CONSTRAINT IF EXISTS pickup_groups_cancel_deadline_valid,
+  DROP CONSTRAINT IF EXISTS pickup_groups_enrollment_deadline_valid;
+
+ALTER TABLE public.pickup_groups
+  DROP COLUMN IF EXISTS cancel_deadline,
+  DROP COLUMN IF EXISTS enrollment_deadline;
@@ -0,0 +1,23 @@
+-- Migration 000021: enrollment and free-cancellation deadlines on pickup
+-- groups.
+--
+-- Rationale:
+--   * Participants could enroll or walk away until the group started, leaving
+--     hosts no time to plan around the final headcount.
+--   * enrollment_deadline closes enrollment, waitlist included; orders placed
+--     before it are unaffected and waitlist promotion continues.
+--   * cancel_deadline ends free cancellation. After it, an owner's
+--     cancellation of a seat-holding order is recorded as cancel_request and
+--     needs the host's approval. Orders awaiting reconfirmation of a material
+--     change may still withdraw freely.
+--   * Both are optional (NULL disables them) and must not fall after
+--     start_time.
+ALTER TABLE public.pickup_groups
+  ADD COLUMN IF NOT EXISTS enrollment_deadline TIMESTAMPTZ, -- NULL: enrollment open until the group ends
+  ADD COLUMN IF NOT EXISTS cancel_deadline     TIMESTAMPTZ; -- NULL: owners may always cancel freely
+
+ALTER TABLE public.pickup_groups
+  ADD CONSTRAINT pickup_groups_enrollment_deadline_valid
+    CHECK (enrollment_deadline IS NULL OR enrollment_deadline <= start_time),
+  ADD CONSTRAINT pickup_groups_cancel_deadline_valid
+    CHECK (cancel_deadline IS NULL OR cancel_deadline <= start_time);
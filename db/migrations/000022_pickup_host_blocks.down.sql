@@ -0,0 +1,2 @@
+-- Revert 000022: drop the pickup host blocklist.
+DROP TABLE IF EXISTS public.pickup_host_blocks;
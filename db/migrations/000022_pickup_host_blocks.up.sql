@@ -0,0 +1,29 @@
+-- Migration 000022: per-host blocklist for pickup enrollment.
+--
+-- Rationale:
+--   * Rejecting an order only keeps the user out of that one group, so a
+--     disruptive participant could enroll in every other group of the host.
+--     A block stops the user from enrolling (waitlist included) in any group
+--     of the host until it is lifted.
+--   * Blocks belong to the primary host of a group; co-hosts do not carry
+--     their own list into groups they help run. Orders placed before the
+--     block are left alone, and the host rejects them as usual.
+--   * The optional reason is a private note for the host and system admins;
+--     it is never shown to the blocked user.
+CREATE TABLE IF NOT EXISTS public.pickup_host_blocks (
+  host_id    UUID NOT NULL,
+  user_id    UUID NOT NULL,
+  reason     TEXT,                                       -- Private note for the host
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+
+  PRIMARY KEY (host_id, user_id),
+  CONSTRAINT pickup_host_blocks_not_self
+    CHECK (host_id <> user_id),
+  CONSTRAINT fk_pickup_host_blocks_host
+    FOREIGN KEY (host_id) REFERENCES public.users(id) ON DELETE CASCADE,
+  CONSTRAINT fk_pickup_host_blocks_user
+    FOREIGN KEY (user_id) REFERENCES public.users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_pickup_host_blocks_user
+  ON public.pickup_host_blocks (user_id);
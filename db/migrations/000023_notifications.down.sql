@@ -0,0 +1,6 @@
+-- Revert 000023: drop the notification inbox, deliveries and outbox.
+DROP TABLE IF EXISTS public.notifications;
+DROP TABLE IF EXISTS public.notification_deliveries;
+DROP TABLE IF EXISTS public.notification_outbox;
+
+DROP TYPE IF EXISTS notification_delivery_status;
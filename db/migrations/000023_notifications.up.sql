@@ -0,0 +1,68 @@
+-- Migration 000023: notification outbox, deliveries and in-app inbox.
+--
+-- Rationale:
+--   * Services publish events for users in the transaction that makes their
+--     change. Each event is written to notification_outbox together with one
+--     delivery row per configured channel (email, webhook, in_app), so an
+--     event is stored exactly when its change commits and is never lost even
+--     if a channel is down.
+--   * A dispatcher claims due deliveries with FOR UPDATE SKIP LOCKED and
+--     pushes next_attempt_at forward as a lease, so several replicas can run
+--     it side by side and a crashed attempt is retried once the lease lapses.
+--   * Failed attempts back off exponentially; after the attempt limit the
+--     delivery is marked failed and last_error keeps the final cause.
+--   * The in-app channel writes to notifications, the user's inbox. outbox_id
+--     is unique so a retried in-app delivery does not duplicate the item, and
+--     is cleared rather than cascaded if old outbox rows are pruned.
+CREATE TYPE notification_delivery_status AS ENUM ('pending', 'sent', 'failed');
+
+CREATE TABLE IF NOT EXISTS public.notification_outbox (
+  id          UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  user_id     UUID NOT NULL,                              -- Recipient
+  type        TEXT NOT NULL,                              -- Event type, e.g. booking.confirmed
+  data        JSONB NOT NULL DEFAULT '{}'::jsonb,         -- Event payload
+  occurred_at TIMESTAMPTZ NOT NULL,
+  created_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+
+  CONSTRAINT fk_notification_outbox_user
+    FOREIGN KEY (user_id) REFERENCES public.users(id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS public.notification_deliveries (
+  id              UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  outbox_id       UUID NOT NULL,
+  channel         TEXT NOT NULL,                          -- email, webhook or in_app
+  status          notification_delivery_status NOT NULL DEFAULT 'pending',
+  attempts        INT NOT NULL DEFAULT 0,
+  next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT now(),     -- Due time, or lease end while in flight
+  last_error      TEXT,
+  sent_at         TIMESTAMPTZ,
+  created_at      TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at      TIMESTAMPTZ NOT NULL DEFAULT now(),
+
+  CONSTRAINT notification_deliveries_unique UNIQUE (outbox_id, channel),
+  CONSTRAINT fk_notification_deliveries_outbox
+    FOREIGN KEY (outbox_id) REFERENCES public.notification_outbox(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_notification_deliveries_due
+  ON public.notification_deliveries (next_attempt_at)
+  WHERE status = 'pending';
+
+CREATE TABLE IF NOT EXISTS public.notifications (
+  id         UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  user_id    UUID NOT NULL,
+  outbox_id  UUID UNIQUE,                                 -- Source event; NULL once pruned
+  type       TEXT NOT NULL,
+  data       JSONB NOT NULL DEFAULT '{}'::jsonb,
+  created_at TIMESTAMPTZ NOT NULL,                        -- When the event occurred
+  read_at    TIMESTAMPTZ,                                 -- NULL while unread
+
+  CONSTRAINT fk_notifications_user
+    FOREIGN KEY (user_id) REFERENCES public.users(id) ON DELETE CASCADE,
+  CONSTRAINT fk_notifications_outbox
+    FOREIGN KEY (outbox_id) REFERENCES public.notification_outbox(id) ON DELETE SET NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_notifications_user_created
+  ON public.notifications (user_id, created_at DESC);
@@ -0,0 +1,3 @@
+-- Revert 000024: drop notification preferences and the unread index.
+DROP INDEX IF EXISTS public.idx_notifications_user_unread;
+DROP TABLE IF EXISTS public.notification_preferences;
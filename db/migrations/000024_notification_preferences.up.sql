@@ -0,0 +1,22 @@
+-- Migration 000024: per-type notification preferences and unread lookups.
+--
+-- Rationale:
+--   * Users choose, per event type, whether it adds an item to their in-app
+--     inbox. Only explicit choices are stored; a type without a row is
+--     enabled, so new event types reach users until they opt out.
+--   * Unread counts and mark-all-read only touch a user's unread items, so a
+--     partial index on them keeps both cheap however long the inbox grows.
+CREATE TABLE IF NOT EXISTS public.notification_preferences (
+  user_id    UUID NOT NULL,
+  type       TEXT NOT NULL,                               -- Event type, e.g. pickup.order_rejected
+  in_app     BOOLEAN NOT NULL,                            -- Whether the type adds inbox items
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+
+  PRIMARY KEY (user_id, type),
+  CONSTRAINT fk_notification_preferences_user
+    FOREIGN KEY (user_id) REFERENCES public.users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_notifications_user_unread
+  ON public.notifications (user_id, type)
+  WHERE read_at IS NULL;
@@ -0,0 +1,4 @@
+-- Revert 000025: drop the reminder ledgers.
+DROP INDEX IF EXISTS public.idx_bookings_confirmed_start;
+DROP TABLE IF EXISTS public.pickup_order_reminders;
+DROP TABLE IF EXISTS public.booking_reminders;
@@ -0,0 +1,40 @@
+-- Migration 000025: reminders before bookings and pickup games.
+--
+-- Rationale:
+--   * A scheduler reminds users of confirmed bookings and confirmed pickup
+--     orders at configured offsets before the start (e.g. 24h and 2h). Every
+--     reminder sent is recorded here in the transaction that publishes it; the
+--     primary key makes the record the claim, so when several replicas run the
+--     scheduler at once exactly one of them inserts the row and sends the
+--     reminder.
+--   * The key includes the start time the reminder was for. A booking or
+--     group moved to another time is reminded afresh for its new time, and a
+--     row for the old time never matches again.
+--   * Cancelled, rejected and unconfirmed items are never selected, so they
+--     are not reminded; rows cascade away with their booking or order.
+CREATE TABLE IF NOT EXISTS public.booking_reminders (
+  booking_id     UUID NOT NULL,
+  before_seconds INT NOT NULL,                            -- Offset before start_time the reminder was for
+  start_time     TIMESTAMPTZ NOT NULL,                    -- Booking start time when reminded
+  sent_at        TIMESTAMPTZ NOT NULL DEFAULT now(),
+
+  PRIMARY KEY (booking_id, before_seconds, start_time),
+  CONSTRAINT fk_booking_reminders_booking
+    FOREIGN KEY (booking_id) REFERENCES public.bookings(id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS public.pickup_order_reminders (
+  order_id       UUID NOT NULL,
+  before_seconds INT NOT NULL,                            -- Offset before start_time the reminder was for
+  start_time     TIMESTAMPTZ NOT NULL,                    -- Group start time when reminded
+  sent_at        TIMESTAMPTZ NOT NULL DEFAULT now(),
+
+  PRIMARY KEY (order_id, before_seconds, start_time),
+  CONSTRAINT fk_pickup_order_reminders_order
+    FOREIGN KEY (order_id) REFERENCES public.pickup_orders(id) ON DELETE CASCADE
+);
+
+-- Index: the scheduler scans confirmed bookings by start time.
+CREATE INDEX IF NOT EXISTS idx_bookings_confirmed_start
+  ON public.bookings (start_time)
+  WHERE status = 'confirmed';
@@ -0,0 +1,5 @@
+-- Revert 000026: drop organization webhooks and their delivery log.
+DROP TABLE IF EXISTS public.webhook_deliveries;
+DROP TABLE IF EXISTS public.organization_webhooks;
+
+DROP TYPE IF EXISTS webhook_delivery_status;
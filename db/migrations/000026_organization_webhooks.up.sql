@@ -0,0 +1,70 @@
+-- Migration 000026: organization webhooks and their delivery log.
+--
+-- Rationale:
+--   * Organization owners register endpoints of their own systems (POS, door
+--     access) that receive booking changes. event_types lists the event
+--     types an endpoint subscribes to; secret signs every request body with
+--     HMAC-SHA256 so the receiver can verify it came from us.
+--   * Each matching event is written to webhook_deliveries in the transaction
+--     making the booking change, with a copy of the payload as it was sent, so the
+--     table doubles as the delivery log owners browse.
+--   * Deliveries are dispatched like notification deliveries: claimed with
+--     FOR UPDATE SKIP LOCKED, next_attempt_at pushed forward as a lease, and
+--     retried with exponential backoff until the attempt limit. The outcome of
+--     the last attempt (HTTP status, start of the response body, error) is
+--     kept for troubleshooting.
+--   * A manual redelivery inserts a new delivery of the same event
+--     (event_id is unchanged, redelivery_of points at the original), so the
+--     log keeps every attempt and receivers can still drop duplicates.
+--   * Deliveries of a disabled webhook stay pending until it is enabled
+--     again; deleting a webhook or its organization drops its log.
+CREATE TYPE webhook_delivery_status AS ENUM ('pending', 'delivered', 'failed');
+
+CREATE TABLE IF NOT EXISTS public.organization_webhooks (
+  id              UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  organization_id UUID NOT NULL,
+  url             TEXT NOT NULL,
+  description     TEXT,
+  secret          TEXT NOT NULL,                          -- HMAC-SHA256 signing key
+  event_types     TEXT[] NOT NULL,                        -- Subscribed event types, e.g. booking.created
+  is_active       BOOLEAN NOT NULL DEFAULT true,
+  created_at      TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at      TIMESTAMPTZ NOT NULL DEFAULT now(),
+
+  CONSTRAINT organization_webhooks_event_types_not_empty CHECK (cardinality(event_types) > 0),
+  CONSTRAINT fk_organization_webhooks_organization
+    FOREIGN KEY (organization_id) REFERENCES public.organizations(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_organization_webhooks_organization
+  ON public.organization_webhooks (organization_id);
+
+CREATE TABLE IF NOT EXISTS public.webhook_deliveries (
+  id              UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  webhook_id      UUID NOT NULL,
+  event_id        UUID NOT NULL,                          -- Same for every delivery of one event
+  event_type      TEXT NOT NULL,
+  payload         JSONB NOT NULL,                         -- Request body as sent
+  status          webhook_delivery_status NOT NULL DEFAULT 'pending',
+  attempts        INT NOT NULL DEFAULT 0,
+  next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT now(),     -- Due time, or lease end while in flight
+  response_status INT,                                    -- HTTP status of the last attempt
+  response_body   TEXT,                                   -- Start of the last response body
+  last_error      TEXT,
+  delivered_at    TIMESTAMPTZ,
+  redelivery_of   UUID,                                   -- Delivery this one manually repeats
+  created_at      TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at      TIMESTAMPTZ NOT NULL DEFAULT now(),
+
+  CONSTRAINT fk_webhook_deliveries_webhook
+    FOREIGN KEY (webhook_id) REFERENCES public.organization_webhooks(id) ON DELETE CASCADE,
+  CONSTRAINT fk_webhook_deliveries_redelivery_of
+    FOREIGN KEY (redelivery_of) REFERENCES public.webhook_deliveries(id) ON DELETE SET NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_due
+  ON public.webhook_deliveries (next_attempt_at)
+  WHERE status = 'pending';
+
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_webhook_created
+  ON public.webhook_deliveries (webhook_id, created_at DESC);
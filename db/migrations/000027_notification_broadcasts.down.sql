@@ -0,0 +1,2 @@
+-- Revert 000027: drop pending broadcasts.
+DROP TABLE IF EXISTS public.notification_broadcasts;
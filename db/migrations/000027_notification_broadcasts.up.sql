@@ -0,0 +1,27 @@
+-- Migration 000027: broadcasts fanned out to inboxes in the background.
+--
+-- Rationale:
+--   * An announcement used to insert one inbox item per active user in the
+--     request that posted it, so the request grew with the user base. It now
+--     stores the broadcast once and the notification dispatcher copies it to
+--     the inboxes in batches.
+--   * Users are walked in id order and cursor_user_id records the last one
+--     handled, so each batch commits with its cursor and a crashed dispatcher
+--     resumes where it stopped. The batch and claim run in one transaction
+--     locked with FOR UPDATE SKIP LOCKED, so replicas never copy the same
+--     users twice.
+--   * Only users registered when the broadcast was posted receive it, as
+--     before; completed_at marks a broadcast every such user has been given.
+CREATE TABLE IF NOT EXISTS public.notification_broadcasts (
+  id             UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  type           TEXT NOT NULL,                           -- Event type, e.g. announcement.published
+  data           JSONB NOT NULL DEFAULT '{}'::jsonb,      -- Event payload
+  occurred_at    TIMESTAMPTZ NOT NULL,
+  cursor_user_id UUID,                                    -- Last user handled; NULL before the first batch
+  completed_at   TIMESTAMPTZ,                             -- NULL while users remain
+  created_at     TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_notification_broadcasts_pending
+  ON public.notification_broadcasts (created_at)
+  WHERE completed_at IS NULL;
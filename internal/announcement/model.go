@@ -13,6 +13,10 @@ var (
 	ErrContentRequired = apperror.New(http.StatusBadRequest, "content is required")
 )
 
+// EventPublished is broadcast to every user's inbox when an announcement is
+// created.
+const EventPublished = "announcement.published"
+
 // Announcement represents a system-wide news or update.
 type Announcement struct {
 	ID        string
@@ -2,7 +2,11 @@ package announcement
 
 import (
 	"context"
+	"log"
 	"strings"
+	"time"
+
+	"github.com/nekogravitycat/court-booking-backend/internal/pkg/event"
 )
 
 type CreateRequest struct {
@@ -23,12 +27,19 @@ type Service interface {
 	Delete(ctx context.Context, id string) error
 }
 
+// Broadcaster delivers an event to every user. It is implemented by the
+// notification service and injected to keep this module decoupled from it.
+type Broadcaster interface {
+	Broadcast(ctx context.Context, e event.Event) error
+}
+
 type service struct {
-	repo Repository
+	repo        Repository
+	broadcaster Broadcaster
 }
 
-func NewService(repo Repository) Service {
-	return &service{repo: repo}
+func NewService(repo Repository, broadcaster Broadcaster) Service {
+	return &service{repo: repo, broadcaster: broadcaster}
 }
 
 func (s *service) Create(ctx context.Context, req CreateRequest) (*Announcement, error) {
@@ -47,6 +58,20 @@ func (s *service) Create(ctx context.Context, req CreateRequest) (*Announcement,
 	if err := s.repo.Create(ctx, a); err != nil {
 		return nil, err
 	}
+
+	// The announcement is already saved, so a failed broadcast is logged
+	// rather than returned.
+	e := event.Event{
+		Type: EventPublished,
+		Data: map[string]any{
+			"announcement_id": a.ID,
+			"title":           a.Title,
+		},
+		OccurredAt: time.Now(),
+	}
+	if err := s.broadcaster.Broadcast(ctx, e); err != nil {
+		log.Printf("announcement: broadcast %s failed: %v", a.ID, err)
+	}
 	return a, nil
 }
 
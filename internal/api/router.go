@@ -18,36 +18,51 @@ import (
 	fileHttp "github.com/nekogravitycat/court-booking-backend/internal/file/http"
 	"github.com/nekogravitycat/court-booking-backend/internal/location"
 	locHttp "github.com/nekogravitycat/court-booking-backend/internal/location/http"
+	"github.com/nekogravitycat/court-booking-backend/internal/notification"
+	notificationHttp "github.com/nekogravitycat/court-booking-backend/internal/notification/http"
 	"github.com/nekogravitycat/court-booking-backend/internal/organization"
 	orgHttp "github.com/nekogravitycat/court-booking-backend/internal/organization/http"
 	"github.com/nekogravitycat/court-booking-backend/internal/pickup"
 	pickupHttp "github.com/nekogravitycat/court-booking-backend/internal/pickup/http"
+	"github.com/nekogravitycat/court-booking-backend/internal/pickuppayment"
+	pickupPaymentHttp "github.com/nekogravitycat/court-booking-backend/internal/pickuppayment/http"
+	"github.com/nekogravitycat/court-booking-backend/internal/pickuptemplate"
+	pickupTemplateHttp "github.com/nekogravitycat/court-booking-backend/internal/pickuptemplate/http"
 	"github.com/nekogravitycat/court-booking-backend/internal/resource"
 	resHttp "github.com/nekogravitycat/court-booking-backend/internal/resource/http"
 	"github.com/nekogravitycat/court-booking-backend/internal/skilllevel"
 	skillHttp "github.com/nekogravitycat/court-booking-backend/internal/skilllevel/http"
+	"github.com/nekogravitycat/court-booking-backend/internal/skillprofile"
+	skillProfileHttp "github.com/nekogravitycat/court-booking-backend/internal/skillprofile/http"
 	"github.com/nekogravitycat/court-booking-backend/internal/sports"
 	sportsHttp "github.com/nekogravitycat/court-booking-backend/internal/sports/http"
 	"github.com/nekogravitycat/court-booking-backend/internal/user"
 	userHttp "github.com/nekogravitycat/court-booking-backend/internal/user/http"
+	"github.com/nekogravitycat/court-booking-backend/internal/webhook"
+	webhookHttp "github.com/nekogravitycat/court-booking-backend/internal/webhook/http"
 )
 
 // Config holds all dependencies required to initialize the router.
 type Config struct {
-	IsProduction      bool
-	ProdOrigins       string
-	UserService       user.Service
-	OrgService        organization.Service
-	LocService        location.Service
-	ResService        resource.Service
-	BookingService    booking.Service
-	AnnService        announcement.Service
-	SportsService     sports.Service
-	SkillLevelService skilllevel.Service
-	PickupService     pickup.Service
-	FavoriteService   favorite.Service
-	FileService       file.Service
-	JWTManager        *auth.JWTManager
+	IsProduction          bool
+	ProdOrigins           string
+	UserService           user.Service
+	OrgService            organization.Service
+	LocService            location.Service
+	ResService            resource.Service
+	BookingService        booking.Service
+	AnnService            announcement.Service
+	SportsService         sports.Service
+	SkillLevelService     skilllevel.Service
+	SkillProfileService   skillprofile.Service
+	PickupService         pickup.Service
+	PickupTemplateService pickuptemplate.Service
+	PickupPaymentService  pickuppayment.Service
+	FavoriteService       favorite.Service
+	NotificationService   notification.Service
+	WebhookService        webhook.Service
+	FileService           file.Service
+	JWTManager            *auth.JWTManager
 }
 
 // NewRouter initializes the HTTP router engine using the provided config.
@@ -109,8 +124,13 @@ func NewRouter(cfg Config) *gin.Engine {
 	annHandler := annHttp.NewHandler(cfg.AnnService)
 	sportsHandler := sportsHttp.NewHandler(cfg.SportsService)
 	skillHandler := skillHttp.NewHandler(cfg.SkillLevelService)
+	skillProfileHandler := skillProfileHttp.NewHandler(cfg.SkillProfileService)
 	pickupHandler := pickupHttp.NewHandler(cfg.PickupService, cfg.UserService)
+	pickupTemplateHandler := pickupTemplateHttp.NewHandler(cfg.PickupTemplateService, cfg.UserService)
+	pickupPaymentHandler := pickupPaymentHttp.NewHandler(cfg.PickupPaymentService, cfg.UserService)
 	favoriteHandler := favoriteHttp.NewHandler(cfg.FavoriteService)
+	notificationHandler := notificationHttp.NewHandler(cfg.NotificationService)
+	webhookHandler := webhookHttp.NewHandler(cfg.WebhookService)
 
 	// Register Routes
 	v1 := r.Group("/v1")
@@ -124,8 +144,13 @@ func NewRouter(cfg Config) *gin.Engine {
 		annHttp.RegisterRoutes(v1, annHandler, authMiddleware, sysAdminMiddleware)
 		sportsHttp.RegisterRoutes(v1, sportsHandler, authMiddleware, sysAdminMiddleware)
 		skillHttp.RegisterRoutes(v1, skillHandler, authMiddleware, sysAdminMiddleware)
+		skillProfileHttp.RegisterRoutes(v1, skillProfileHandler, authMiddleware)
 		pickupHttp.RegisterRoutes(v1, pickupHandler, authMiddleware, optionalAuthMiddleware)
+		pickupTemplateHttp.RegisterRoutes(v1, pickupTemplateHandler, authMiddleware)
+		pickupPaymentHttp.RegisterRoutes(v1, pickupPaymentHandler, authMiddleware, sysAdminMiddleware)
 		favoriteHttp.RegisterRoutes(v1, favoriteHandler, authMiddleware)
+		notificationHttp.RegisterRoutes(v1, notificationHandler, authMiddleware)
+		webhookHttp.RegisterRoutes(v1, webhookHandler, authMiddleware)
 	}
 
 	return r
@@ -1,6 +1,9 @@
 package app
 
 import (
+	"context"
+	"errors"
+	"log"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -12,13 +15,19 @@ import (
 	"github.com/nekogravitycat/court-booking-backend/internal/favorite"
 	"github.com/nekogravitycat/court-booking-backend/internal/file"
 	"github.com/nekogravitycat/court-booking-backend/internal/location"
+	"github.com/nekogravitycat/court-booking-backend/internal/notification"
 	"github.com/nekogravitycat/court-booking-backend/internal/organization"
 	"github.com/nekogravitycat/court-booking-backend/internal/pickup"
+	"github.com/nekogravitycat/court-booking-backend/internal/pickuppayment"
+	"github.com/nekogravitycat/court-booking-backend/internal/pickuptemplate"
 	"github.com/nekogravitycat/court-booking-backend/internal/pkg/storage"
+	"github.com/nekogravitycat/court-booking-backend/internal/pkg/worker"
 	"github.com/nekogravitycat/court-booking-backend/internal/resource"
 	"github.com/nekogravitycat/court-booking-backend/internal/skilllevel"
+	"github.com/nekogravitycat/court-booking-backend/internal/skillprofile"
 	"github.com/nekogravitycat/court-booking-backend/internal/sports"
 	"github.com/nekogravitycat/court-booking-backend/internal/user"
+	"github.com/nekogravitycat/court-booking-backend/internal/webhook"
 )
 
 // Config holds the dependencies and settings required to start the application.
@@ -29,12 +38,63 @@ type Config struct {
 	JWTSecret    string
 	JWTTTL       time.Duration
 	BcryptCost   int
+	// PickupTemplateInterval is how often recurring pickup templates are
+	// materialised in the background. Zero uses defaultPickupTemplateInterval.
+	PickupTemplateInterval time.Duration
+	// PickupLifecycleInterval is how often pickup groups are completed or
+	// auto-cancelled. Zero uses defaultPickupLifecycleInterval.
+	PickupLifecycleInterval time.Duration
+	// PickupAutoCancelCutoff is how long before start_time a group below its
+	// min_participants is cancelled.
+	PickupAutoCancelCutoff time.Duration
+	// NotificationInterval is how often due notification deliveries are sent.
+	// Zero uses defaultNotificationInterval.
+	NotificationInterval time.Duration
+	// SMTP configures the email notification channel; it is disabled while
+	// SMTP.Host is empty.
+	SMTP notification.SMTPConfig
+	// NotificationWebhookURL enables the webhook notification channel, signed
+	// with NotificationWebhookSecret when set.
+	NotificationWebhookURL    string
+	NotificationWebhookSecret string
+	// ReminderOffsets are how long before a confirmed booking or pickup order
+	// starts its participant is reminded; empty disables reminders.
+	ReminderOffsets []time.Duration
+	// ReminderInterval is how often due reminders are looked for. Zero uses
+	// defaultReminderInterval.
+	ReminderInterval time.Duration
+	// WebhookInterval is how often due organization webhook deliveries are
+	// sent. Zero uses defaultWebhookInterval.
+	WebhookInterval time.Duration
+	// WebhookAllowPrivateNetworks lets organization webhooks reach loopback
+	// and private addresses. Only tests with a local receiver set it.
+	WebhookAllowPrivateNetworks bool
 }
 
+// Names of the background workers in Container.Workers.
+const (
+	PickupTemplateWorker  = "pickup-template-materializer"
+	PickupLifecycleWorker = "pickup-lifecycle"
+	NotificationWorker    = "notification-dispatcher"
+	ReminderWorker        = "reminder-scheduler"
+	WebhookWorker         = "webhook-dispatcher"
+)
+
+const (
+	defaultPickupTemplateInterval  = time.Hour
+	defaultPickupLifecycleInterval = 5 * time.Minute
+	defaultNotificationInterval    = 30 * time.Second
+	defaultReminderInterval        = 5 * time.Minute
+	defaultWebhookInterval         = 30 * time.Second
+)
+
 // Container holds the initialized components that are needed externally.
 type Container struct {
 	Router     *gin.Engine
 	JWTManager *auth.JWTManager
+	// Workers are the background jobs the caller should start alongside the
+	// HTTP server.
+	Workers []worker.Periodic
 }
 
 // NewContainer initializes all modules and returns the container.
@@ -43,6 +103,17 @@ func NewContainer(cfg Config) *Container {
 	passwordHasher := auth.NewBcryptPasswordHasherWithCost(cfg.BcryptCost)
 	jwtManager := auth.NewJWTManager(cfg.JWTSecret, cfg.JWTTTL)
 
+	// Notification Module (the outbox every service publishes its events to)
+	notificationRepo := notification.NewPgxRepository(cfg.DBPool)
+	channels := []notification.Channel{notification.NewInAppChannel(notificationRepo)}
+	if cfg.SMTP.Host != "" {
+		channels = append(channels, notification.NewEmailChannel(cfg.SMTP))
+	}
+	if cfg.NotificationWebhookURL != "" {
+		channels = append(channels, notification.NewWebhookChannel(cfg.NotificationWebhookURL, cfg.NotificationWebhookSecret))
+	}
+	notificationService := notification.NewService(notificationRepo, channels...)
+
 	// File Module
 	store, err := storage.NewLocalStorage("storage")
 	if err != nil {
@@ -57,7 +128,7 @@ func NewContainer(cfg Config) *Container {
 
 	// User Module
 	userRepo := user.NewPgxRepository(cfg.DBPool)
-	userService := user.NewService(userRepo, passwordHasher, fileService, favoriteRepo)
+	userService := user.NewService(userRepo, passwordHasher, fileService, favoriteRepo, notificationService)
 
 	// Favorite Service (depends on user service to validate pickup hosts)
 	favoriteService := favorite.NewService(favoriteRepo, userService)
@@ -65,20 +136,27 @@ func NewContainer(cfg Config) *Container {
 	// Organization & Location Module
 	orgRepo := organization.NewPgxRepository(cfg.DBPool)
 	locRepo := location.NewPgxRepository(cfg.DBPool)
-	orgService := organization.NewService(orgRepo, userService, locRepo, fileService)
+	orgService := organization.NewService(orgRepo, userService, locRepo, fileService, notificationService)
 	locService := location.NewService(locRepo, orgService, userService, fileService)
 
+	// Webhook Module (organization endpoints subscribed to booking changes)
+	webhookRepo := webhook.NewPgxRepository(cfg.DBPool)
+	webhookService := webhook.NewService(webhookRepo, orgService, webhook.Config{
+		RequireHTTPS:         cfg.IsProduction,
+		AllowPrivateNetworks: cfg.WebhookAllowPrivateNetworks,
+	})
+
 	// Resource Module
 	resRepo := resource.NewPgxRepository(cfg.DBPool)
 	resService := resource.NewService(resRepo, locService, fileService)
 
 	// Booking Module
 	bookingRepo := booking.NewPgxRepository(cfg.DBPool)
-	bookingService := booking.NewService(bookingRepo, resService, locService, orgService)
+	bookingService := booking.NewService(bookingRepo, resService, locService, orgService, notificationService, webhookService)
 
 	// Announcement Module
 	annRepo := announcement.NewPgxRepository(cfg.DBPool)
-	annService := announcement.NewService(annRepo)
+	annService := announcement.NewService(annRepo, notificationService)
 
 	// Sports & Skill-Level lookup modules
 	sportsRepo := sports.NewPgxRepository(cfg.DBPool)
@@ -86,33 +164,114 @@ func NewContainer(cfg Config) *Container {
 	skillLevelRepo := skilllevel.NewPgxRepository(cfg.DBPool)
 	skillLevelService := skilllevel.NewService(skillLevelRepo)
 
+	// Skill Profile Module (per-sport user skill levels)
+	skillProfileRepo := skillprofile.NewPgxRepository(cfg.DBPool)
+	skillProfileService := skillprofile.NewService(skillProfileRepo, sportsService, skillLevelService)
+
 	// Pickup Module
 	pickupRepo := pickup.NewPgxRepository(cfg.DBPool)
-	pickupService := pickup.NewService(pickupRepo, userService, sportsService, skillLevelService)
+	pickupService := pickup.NewService(pickupRepo, userService, sportsService, skillLevelService, resService, bookingService, skillProfileService, notificationService)
+
+	// Pickup Template Module (recurring groups materialised into pickup groups)
+	pickupTemplateRepo := pickuptemplate.NewPgxRepository(cfg.DBPool)
+	pickupTemplateService := pickuptemplate.NewService(pickupTemplateRepo, pickupService)
+
+	// Pickup Payment Module (host earnings ledger)
+	pickupPaymentRepo := pickuppayment.NewPgxRepository(cfg.DBPool)
+	pickupPaymentService := pickuppayment.NewService(pickupPaymentRepo, pickupService)
 
 	// API Router Config
 	routerParams := api.Config{
-		IsProduction:      cfg.IsProduction,
-		ProdOrigins:       cfg.ProdOrigins,
-		UserService:       userService,
-		OrgService:        orgService,
-		LocService:        locService,
-		ResService:        resService,
-		BookingService:    bookingService,
-		AnnService:        annService,
-		SportsService:     sportsService,
-		SkillLevelService: skillLevelService,
-		PickupService:     pickupService,
-		FavoriteService:   favoriteService,
-		FileService:       fileService,
-		JWTManager:        jwtManager,
+		IsProduction:          cfg.IsProduction,
+		ProdOrigins:           cfg.ProdOrigins,
+		UserService:           userService,
+		OrgService:            orgService,
+		LocService:            locService,
+		ResService:            resService,
+		BookingService:        bookingService,
+		AnnService:            annService,
+		SportsService:         sportsService,
+		SkillLevelService:     skillLevelService,
+		SkillProfileService:   skillProfileService,
+		PickupService:         pickupService,
+		PickupTemplateService: pickupTemplateService,
+		PickupPaymentService:  pickupPaymentService,
+		FavoriteService:       favoriteService,
+		NotificationService:   notificationService,
+		WebhookService:        webhookService,
+		FileService:           fileService,
+		JWTManager:            jwtManager,
 	}
 
 	// Router
 	router := api.NewRouter(routerParams)
 
+	// Background Workers
+	templateInterval := cfg.PickupTemplateInterval
+	if templateInterval <= 0 {
+		templateInterval = defaultPickupTemplateInterval
+	}
+	lifecycleInterval := cfg.PickupLifecycleInterval
+	if lifecycleInterval <= 0 {
+		lifecycleInterval = defaultPickupLifecycleInterval
+	}
+	notificationInterval := cfg.NotificationInterval
+	if notificationInterval <= 0 {
+		notificationInterval = defaultNotificationInterval
+	}
+	reminderInterval := cfg.ReminderInterval
+	if reminderInterval <= 0 {
+		reminderInterval = defaultReminderInterval
+	}
+	webhookInterval := cfg.WebhookInterval
+	if webhookInterval <= 0 {
+		webhookInterval = defaultWebhookInterval
+	}
+	workers := []worker.Periodic{
+		{
+			Name:     PickupTemplateWorker,
+			Interval: templateInterval,
+			Run:      pickupTemplateService.MaterializeAll,
+		},
+		{
+			Name:     PickupLifecycleWorker,
+			Interval: lifecycleInterval,
+			Run: func(ctx context.Context) error {
+				result, err := pickupService.AdvanceLifecycle(ctx, time.Now(), cfg.PickupAutoCancelCutoff)
+				if result.Completed > 0 || result.Cancelled > 0 {
+					log.Printf("pickup lifecycle: completed %d, auto-cancelled %d groups", result.Completed, result.Cancelled)
+				}
+				return err
+			},
+		},
+		{
+			Name:     NotificationWorker,
+			Interval: notificationInterval,
+			Run:      notificationService.DispatchDue,
+		},
+		{
+			Name:     ReminderWorker,
+			Interval: reminderInterval,
+			Run: func(ctx context.Context) error {
+				now := time.Now()
+				bookings, bookingErr := bookingService.SendReminders(ctx, now, cfg.ReminderOffsets)
+				orders, orderErr := pickupService.SendReminders(ctx, now, cfg.ReminderOffsets)
+				if bookings > 0 || orders > 0 {
+					log.Printf("reminders: sent %d booking and %d pickup order reminders", bookings, orders)
+				}
+				return errors.Join(bookingErr, orderErr)
+			},
+		},
+		{
+			Name:     WebhookWorker,
+			Interval: webhookInterval,
+			Run:      webhookService.DispatchDue,
+		},
+	}
+
 	return &Container{
 		Router:     router,
 		JWTManager: jwtManager,
+		Workers:    workers,
 	}
 }
@@ -16,11 +16,26 @@ type ListBookingsRequest struct {
 	request.ListParams
 	ResourceID     string     `form:"resource_id" binding:"omitempty,uuid"`
 	OrganizationID string     `form:"organization_id" binding:"omitempty,uuid"`
+	LocationID     string     `form:"location_id" binding:"omitempty,uuid"`
+	ResourceType   string     `form:"resource_type"`
 	Status         string     `form:"status" binding:"omitempty,oneof=pending confirmed cancelled cancel_request"`
+	PaymentStatus  string     `form:"payment_status" binding:"omitempty,oneof=done pending failed"`
 	UserID         string     `form:"user_id" binding:"omitempty,uuid"`
+	Q              string     `form:"q"`
 	StartTimeFrom  *time.Time `form:"start_time_from" time_format:"2006-01-02T15:04:05Z07:00"`
 	StartTimeTo    *time.Time `form:"start_time_to" time_format:"2006-01-02T15:04:05Z07:00"`
+	CreatedAtFrom  *time.Time `form:"created_at_from" time_format:"2006-01-02T15:04:05Z07:00"`
+	CreatedAtTo    *time.Time `form:"created_at_to" time_format:"2006-01-02T15:04:05Z07:00"`
 	SortBy         string     `form:"sort_by" binding:"omitempty,oneof=start_time end_time created_at status"`
+	// Pagination selects offset (page / page_size, default) or cursor
+	// (cursor / page_size) pagination.
+	Pagination string `form:"pagination" binding:"omitempty,oneof=offset cursor"`
+	Cursor     string `form:"cursor"`
+}
+
+// UseCursor reports whether the request asks for cursor pagination.
+func (r *ListBookingsRequest) UseCursor() bool {
+	return r.Pagination == "cursor" || r.Cursor != ""
 }
 
 // Validate performs custom validation for ListBookingsRequest.
@@ -30,6 +45,11 @@ func (r *ListBookingsRequest) Validate() error {
 			return booking.ErrInvalidTimeRange
 		}
 	}
+	if r.CreatedAtFrom != nil && r.CreatedAtTo != nil {
+		if r.CreatedAtFrom.After(*r.CreatedAtTo) {
+			return booking.ErrInvalidTimeRange
+		}
+	}
 	return nil
 }
 
@@ -43,8 +63,10 @@ type BookingResponse struct {
 	EndTime       time.Time               `json:"end_time"`
 	Status        string                  `json:"status"`
 	PaymentStatus string                  `json:"payment_status"`
-	CreatedAt     time.Time               `json:"created_at"`
-	UpdatedAt     time.Time               `json:"updated_at"`
+	// PickupGroupID is set when the booking reserves the court for a pickup group.
+	PickupGroupID *string   `json:"pickup_group_id"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
 }
 
 func NewBookingResponse(b *booking.Booking) BookingResponse {
@@ -58,6 +80,7 @@ func NewBookingResponse(b *booking.Booking) BookingResponse {
 		EndTime:       b.EndTime.UTC(),
 		Status:        string(b.Status),
 		PaymentStatus: string(b.PaymentStatus),
+		PickupGroupID: b.PickupGroupID,
 		CreatedAt:     b.CreatedAt.UTC(),
 		UpdatedAt:     b.UpdatedAt.UTC(),
 	}
@@ -96,3 +119,53 @@ func (r *UpdateBookingRequest) Validate() error {
 	}
 	return nil
 }
+
+// OccupancyRequest defines query parameters for the occupancy heatmap.
+// From / To are inclusive calendar dates in each location's local timezone.
+type OccupancyRequest struct {
+	OrganizationID string    `form:"organization_id" binding:"omitempty,uuid"`
+	LocationID     string    `form:"location_id" binding:"omitempty,uuid"`
+	ResourceType   string    `form:"resource_type"`
+	From           time.Time `form:"from" binding:"required" time_format:"2006-01-02"`
+	To             time.Time `form:"to" binding:"required" time_format:"2006-01-02"`
+}
+
+// Validate performs custom validation for OccupancyRequest.
+func (r *OccupancyRequest) Validate() error {
+	if r.To.Before(r.From) {
+		return booking.ErrInvalidTimeRange
+	}
+	return nil
+}
+
+// OccupancyHeatmapResponse is one location's weekday x hour matrix.
+// Cells[weekday][hour]: weekday 0 = Sunday ... 6 = Saturday, hour 0-23 local.
+type OccupancyHeatmapResponse struct {
+	Location locHttp.LocationTag `json:"location"`
+	Timezone string              `json:"timezone"`
+	Cells    [7][24]int          `json:"cells"`
+	Total    int                 `json:"total"`
+}
+
+type OccupancyResponse struct {
+	From  string                     `json:"from"`
+	To    string                     `json:"to"`
+	Items []OccupancyHeatmapResponse `json:"items"`
+}
+
+func NewOccupancyResponse(from, to time.Time, heatmaps []*booking.OccupancyHeatmap) OccupancyResponse {
+	items := make([]OccupancyHeatmapResponse, len(heatmaps))
+	for i, hm := range heatmaps {
+		items[i] = OccupancyHeatmapResponse{
+			Location: locHttp.LocationTag{ID: hm.LocationID, Name: hm.LocationName},
+			Timezone: hm.Timezone,
+			Cells:    hm.Cells,
+			Total:    hm.Total,
+		}
+	}
+	return OccupancyResponse{
+		From:  from.Format("2006-01-02"),
+		To:    to.Format("2006-01-02"),
+		Items: items,
+	}
+}
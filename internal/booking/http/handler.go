@@ -79,28 +79,40 @@ func (h *Handler) List(c *gin.Context) {
 	}
 
 	// Access Control Logic
+	ctx := c.Request.Context()
 	currentUserID := auth.GetUserID(c)
-	isSysAdmin := h.checkIsSysAdmin(c, currentUserID)
 
-	filterUserID := currentUserID
+	canViewScope, err := h.canViewListScope(c, req, currentUserID)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
 
-	// If Admin, they can see all or filter by specific user
-	if isSysAdmin {
+	// Admins and managers of the requested scope see every booking in it and
+	// may narrow by user; everyone else is forced to see only their own.
+	filterUserID := currentUserID
+	if canViewScope {
 		filterUserID = req.UserID // can be empty to show all
 	}
-	// If Normal User, forced to see only their own
 
 	filter := booking.Filter{
 		UserID:         filterUserID,
 		ResourceID:     req.ResourceID,
 		OrganizationID: req.OrganizationID,
+		LocationID:     req.LocationID,
+		ResourceType:   req.ResourceType,
 		Status:         req.Status,
+		PaymentStatus:  req.PaymentStatus,
+		Search:         strings.TrimSpace(req.Q),
 		StartTime:      req.StartTimeFrom,
 		EndTime:        req.StartTimeTo,
+		CreatedAtFrom:  req.CreatedAtFrom,
+		CreatedAtTo:    req.CreatedAtTo,
 		Page:           req.Page,
 		PageSize:       req.PageSize,
 		SortBy:         req.SortBy,
 		SortOrder:      req.SortOrder,
+		Cursor:         req.Cursor,
 	}
 
 	if filter.SortBy == "" {
@@ -112,19 +124,50 @@ func (h *Handler) List(c *gin.Context) {
 		filter.SortOrder = strings.ToUpper(filter.SortOrder)
 	}
 
-	bookings, total, err := h.service.List(c.Request.Context(), filter)
+	if req.UseCursor() {
+		bookings, nextCursor, err := h.service.ListByCursor(ctx, filter)
+		if err != nil {
+			response.Error(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, response.NewCursorPageResponse(newBookingResponses(bookings), req.PageSize, nextCursor))
+		return
+	}
+
+	bookings, total, err := h.service.List(ctx, filter)
 	if err != nil {
 		response.Error(c, err)
 		return
 	}
 
+	resp := response.NewPageResponse(newBookingResponses(bookings), req.Page, req.PageSize, total)
+	c.JSON(http.StatusOK, resp)
+}
+
+// canViewListScope reports whether the user may list other users' bookings
+// within the requested scope: System Admins anywhere, Location Managers (or
+// above) for location_id, Organization Managers (or above) for organization_id.
+func (h *Handler) canViewListScope(c *gin.Context, req ListBookingsRequest, userID string) (bool, error) {
+	if h.checkIsSysAdmin(c, userID) {
+		return true, nil
+	}
+
+	ctx := c.Request.Context()
+	switch {
+	case req.LocationID != "":
+		return h.locService.IsLocationManagerOrAbove(ctx, req.LocationID, userID)
+	case req.OrganizationID != "":
+		return h.orgService.IsManagerOrAbove(ctx, req.OrganizationID, userID)
+	}
+	return false, nil
+}
+
+func newBookingResponses(bookings []*booking.Booking) []BookingResponse {
 	items := make([]BookingResponse, len(bookings))
 	for i, b := range bookings {
 		items[i] = NewBookingResponse(b)
 	}
-
-	resp := response.NewPageResponse(items, req.Page, req.PageSize, total)
-	c.JSON(http.StatusOK, resp)
+	return items
 }
 
 func (h *Handler) Create(c *gin.Context) {
@@ -246,3 +289,64 @@ func (h *Handler) Delete(c *gin.Context) {
 
 	c.Status(http.StatusNoContent)
 }
+
+// Occupancy returns weekday x hour heatmaps of confirmed bookings per location,
+// bucketed in each location's timezone.
+//
+// Access Control:
+//   - System Admin: any scope, including no scope (all locations with bookings).
+//   - Location Manager or above: scoped by location_id.
+//   - Organization Manager or above: scoped by organization_id.
+func (h *Handler) Occupancy(c *gin.Context) {
+	var req OccupancyRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid query parameters", "details": err.Error()})
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx := c.Request.Context()
+	userID := auth.GetUserID(c)
+
+	if !h.checkIsSysAdmin(c, userID) {
+		var allowed bool
+		var err error
+		switch {
+		case req.LocationID != "":
+			allowed, err = h.locService.IsLocationManagerOrAbove(ctx, req.LocationID, userID)
+		case req.OrganizationID != "":
+			allowed, err = h.orgService.IsManagerOrAbove(ctx, req.OrganizationID, userID)
+		default:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "organization_id or location_id is required"})
+			return
+		}
+		if err != nil {
+			response.Error(c, err)
+			return
+		}
+		if !allowed {
+			c.JSON(http.StatusForbidden, gin.H{"error": "permission denied"})
+			return
+		}
+	}
+
+	filter := booking.OccupancyFilter{
+		OrganizationID: req.OrganizationID,
+		LocationID:     req.LocationID,
+		ResourceType:   req.ResourceType,
+		From:           req.From,
+		To:             req.To,
+	}
+
+	heatmaps, err := h.service.GetOccupancyHeatmap(ctx, filter)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, NewOccupancyResponse(req.From, req.To, heatmaps))
+}
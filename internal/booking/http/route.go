@@ -11,6 +11,7 @@ func RegisterRoutes(g *gin.RouterGroup, h *Handler, authMiddleware gin.HandlerFu
 	group.Use(authMiddleware)
 	{
 		group.GET("", h.List)
+		group.GET("/occupancy", h.Occupancy)
 		group.GET("/:id", h.Get)
 		group.POST("", h.Create)
 		group.PATCH("/:id", h.Update)
@@ -1,6 +1,8 @@
 package booking
 
 import (
+	"encoding/base64"
+	"encoding/json"
 	"net/http"
 	"time"
 
@@ -21,6 +23,12 @@ var (
 	ErrOutsideOpeningHours = apperror.New(http.StatusBadRequest, "booking must fall within the location's opening hours")
 	ErrBookingTooLong      = apperror.New(http.StatusBadRequest, "booking duration exceeds the maximum allowed")
 	ErrInvalidTimezone     = apperror.New(http.StatusInternalServerError, "location has an invalid timezone")
+
+	ErrOccupancyRangeTooLong = apperror.New(http.StatusBadRequest, "occupancy date range exceeds the maximum allowed")
+	ErrInvalidCursor         = apperror.New(http.StatusBadRequest, "invalid cursor")
+	ErrCursorSortUnsupported = apperror.New(http.StatusBadRequest, "cursor pagination requires sort_by start_time, end_time or created_at")
+
+	ErrManagedByPickupGroup = apperror.New(http.StatusConflict, "booking is managed by its pickup group; change the pickup group instead")
 )
 
 // MaxBookingDuration is a defensive upper bound on the length of a single
@@ -29,10 +37,46 @@ var (
 // rules require.
 const MaxBookingDuration = 24 * time.Hour
 
+// MaxOccupancyRangeDays bounds the date range of an occupancy heatmap query so
+// a single request cannot expand years of bookings into hourly buckets.
+const MaxOccupancyRangeDays = 366
+
 // availabilityPageSize is the batch size used when paging through a day's
 // bookings to compute availability, ensuring no bookings are silently dropped.
 const availabilityPageSize = 1000
 
+// Events published to the booker when someone else decides on their booking.
+const (
+	EventBookingConfirmed = "booking.confirmed"
+	EventBookingCancelled = "booking.cancelled"
+)
+
+// EventBookingReminder reminds the booker of a confirmed booking shortly
+// before it starts (see Service.SendReminders).
+const EventBookingReminder = "booking.reminder"
+
+// Events published to the organization owning the booked resource, for its
+// webhooks (see OrgPublisher). A deleted booking is reported as cancelled.
+const (
+	OrgEventBookingCreated       = "booking.created"
+	OrgEventBookingUpdated       = "booking.updated"
+	OrgEventBookingCancelled     = "booking.cancelled"
+	OrgEventPaymentStatusChanged = "booking.payment_status_changed"
+)
+
+// Reminder is a reminder claimed for a confirmed booking.
+type Reminder struct {
+	BookingID    string
+	UserID       string
+	ResourceID   string
+	ResourceName string
+	LocationName string
+	StartTime    time.Time
+	EndTime      time.Time
+	// Before is the configured offset before StartTime it was sent for.
+	Before time.Duration
+}
+
 type Status string
 
 const (
@@ -84,17 +128,101 @@ type Booking struct {
 	PaymentStatus    PaymentStatus
 	CreatedAt        time.Time
 	UpdatedAt        time.Time
+	// PickupGroupID is set when the booking reserves the court for a pickup
+	// group; its time and lifecycle then follow the group.
+	PickupGroupID *string
+}
+
+// Change is a change another module made to a booking in its own
+// transaction, such as the pickup module reserving or releasing a group's
+// court. Previous is the booking's state before the change, nil for a booking
+// the change created. See Service.PublishChanges.
+type Change struct {
+	BookingID string
+	Previous  *Snapshot
+}
+
+// Snapshot is the state of a booking a change is compared against.
+type Snapshot struct {
+	StartTime     time.Time
+	EndTime       time.Time
+	Status        Status
+	PaymentStatus PaymentStatus
 }
 
 type Filter struct {
 	UserID         string
 	ResourceID     string
 	OrganizationID string
+	LocationID     string
+	ResourceType   string
 	Status         string
+	PaymentStatus  string
+	Search         string     // Keyword search in booker display name / username / email
 	StartTime      *time.Time // Filter bookings starting after this time
 	EndTime        *time.Time // Filter bookings ending before this time
+	CreatedAtFrom  *time.Time
+	CreatedAtTo    *time.Time
 	Page           int
 	PageSize       int
 	SortBy         string
 	SortOrder      string
+
+	// Cursor is the opaque keyset cursor returned by a previous ListByCursor
+	// call. It is ignored by offset (Page-based) listing.
+	Cursor string
+}
+
+// cursorSortColumns are the sort keys that support keyset pagination. They are
+// immutable or monotonic timestamps, so paired with the id tie-breaker they give
+// a stable total order that new inserts cannot shift.
+var cursorSortColumns = []string{"start_time", "end_time", "created_at"}
+
+// Cursor is the decoded keyset position: the sort column and direction it was
+// issued for, that column's value on the last returned row, and the row's id.
+type Cursor struct {
+	SortBy string    `json:"s"`
+	Order  string    `json:"o"`
+	Value  time.Time `json:"v"`
+	ID     string    `json:"id"`
+}
+
+// Encode returns the opaque, URL-safe form of the cursor.
+func (c Cursor) Encode() string {
+	raw, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// DecodeCursor parses an opaque cursor produced by Cursor.Encode.
+func DecodeCursor(s string) (*Cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var c Cursor
+	if err := json.Unmarshal(raw, &c); err != nil || c.ID == "" {
+		return nil, ErrInvalidCursor
+	}
+	return &c, nil
+}
+
+// OccupancyFilter defines the scope of an occupancy heatmap query. From and To
+// are calendar dates (inclusive) interpreted in each location's own timezone.
+type OccupancyFilter struct {
+	OrganizationID string
+	LocationID     string
+	ResourceType   string
+	From           time.Time
+	To             time.Time
+}
+
+// OccupancyHeatmap is the weekday x hour demand matrix of a single location.
+// Cells[weekday][hour] counts the confirmed bookings occupying that local hour,
+// with weekday following time.Weekday (0 = Sunday) and hour in 0-23 local time.
+type OccupancyHeatmap struct {
+	LocationID   string
+	LocationName string
+	Timezone     string
+	Cells        [7][24]int
+	Total        int
 }
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/Masterminds/squirrel"
@@ -11,18 +12,39 @@ import (
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/nekogravitycat/court-booking-backend/internal/pkg/event"
 )
 
 type Repository interface {
-	Create(ctx context.Context, booking *Booking) error
+	// Create inserts the booking, loads its joined details into it and runs
+	// publish, all in one transaction.
+	Create(ctx context.Context, booking *Booking, publish event.Hook) error
 	GetByID(ctx context.Context, id string) (*Booking, error)
+	// GetByIDTx loads the booking within tx, seeing the changes tx made.
+	GetByIDTx(ctx context.Context, tx pgx.Tx, id string) (*Booking, error)
 	List(ctx context.Context, filter Filter) ([]*Booking, int, error)
-	Update(ctx context.Context, booking *Booking) error
-	Delete(ctx context.Context, id string) error
+	// ListAfter is the keyset-paginated variant of List (see Filter.Cursor).
+	ListAfter(ctx context.Context, filter Filter, after *Cursor, limit int) ([]*Booking, error)
+	// Update and Delete run publish in the transaction making the change.
+	Update(ctx context.Context, booking *Booking, publish event.Hook) error
+	Delete(ctx context.Context, id string, publish event.Hook) error
 
 	// HasOverlap checks if there is any conflicting booking for the resource in the given time range.
 	// excludeBookingID is used during updates to ignore the booking itself.
 	HasOverlap(ctx context.Context, resourceID string, start, end time.Time, excludeBookingID string) (bool, error)
+
+	// OccupancyHeatmap buckets confirmed bookings into local weekday/hour cells,
+	// one heatmap per location in scope.
+	OccupancyHeatmap(ctx context.Context, filter OccupancyFilter) ([]*OccupancyHeatmap, error)
+
+	// ClaimReminders records the reminders due at now for confirmed bookings,
+	// at most one per booking: that of the smallest offset already reached.
+	// Bookings made after that point are skipped. A reminder recorded before,
+	// by this or another replica, is not claimed again. The claimed reminders
+	// are handed to send in the claiming transaction, so a failed send leaves
+	// them to the next run. It returns how many were sent.
+	ClaimReminders(ctx context.Context, now time.Time, offsets []time.Duration, send func(tx pgx.Tx, reminders []*Reminder) error) (int, error)
 }
 
 type pgxRepository struct {
@@ -33,7 +55,7 @@ func NewPgxRepository(pool *pgxpool.Pool) Repository {
 	return &pgxRepository{pool: pool}
 }
 
-func (r *pgxRepository) Create(ctx context.Context, b *Booking) error {
+func (r *pgxRepository) Create(ctx context.Context, b *Booking, publish event.Hook) error {
 	psql := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
 	query, args, err := psql.Insert("public.bookings").
 		Columns("resource_id", "user_id", "start_time", "end_time", "status").
@@ -44,11 +66,27 @@ func (r *pgxRepository) Create(ctx context.Context, b *Booking) error {
 		return fmt.Errorf("build create booking query failed: %w", err)
 	}
 
-	if err := r.pool.QueryRow(ctx, query, args...).
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin transaction failed: %w", err)
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck
+
+	if err := tx.QueryRow(ctx, query, args...).
 		Scan(&b.ID, &b.CreatedAt, &b.UpdatedAt); err != nil {
 		return mapOverlapError(err)
 	}
-	return nil
+
+	created, err := getByID(ctx, tx, b.ID)
+	if err != nil {
+		return err
+	}
+	*b = *created
+
+	if err := publish.Run(tx); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
 }
 
 // mapOverlapError translates the database-level overlap exclusion violation
@@ -64,31 +102,29 @@ func mapOverlapError(err error) error {
 }
 
 func (r *pgxRepository) GetByID(ctx context.Context, id string) (*Booking, error) {
-	psql := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
-	query, args, err := psql.Select(
-		"b.id", "b.resource_id", "r.name", "b.user_id", "u.display_name",
-		"l.id", "l.name", "o.id", "o.name",
-		"b.start_time", "b.end_time", "b.status", "b.payment_status", "b.created_at", "b.updated_at",
-	).
-		From("public.bookings b").
-		Join("public.resources r ON b.resource_id = r.id").
-		Join("public.users u ON b.user_id = u.id").
-		Join("public.locations l ON r.location_id = l.id").
-		Join("public.organizations o ON l.organization_id = o.id").
+	return getByID(ctx, r.pool, id)
+}
+
+func (r *pgxRepository) GetByIDTx(ctx context.Context, tx pgx.Tx, id string) (*Booking, error) {
+	return getByID(ctx, tx, id)
+}
+
+// rowQueryer is satisfied by both *pgxpool.Pool and pgx.Tx.
+type rowQueryer interface {
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// getByID loads the booking with its joined details through q.
+func getByID(ctx context.Context, q rowQueryer, id string) (*Booking, error) {
+	query, args, err := listQuery(Filter{}, bookingSelectColumns...).
 		Where(squirrel.Eq{"b.id": id}).
 		ToSql()
 	if err != nil {
 		return nil, fmt.Errorf("build get booking query failed: %w", err)
 	}
 
-	row := r.pool.QueryRow(ctx, query, args...)
-
 	var b Booking
-	if err := row.Scan(
-		&b.ID, &b.ResourceID, &b.ResourceName, &b.UserID, &b.UserName,
-		&b.LocationID, &b.LocationName, &b.OrganizationID, &b.OrganizationName,
-		&b.StartTime, &b.EndTime, &b.Status, &b.PaymentStatus, &b.CreatedAt, &b.UpdatedAt,
-	); err != nil {
+	if err := q.QueryRow(ctx, query, args...).Scan(scanBooking(&b)...); err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, ErrNotFound
 		}
@@ -97,14 +133,24 @@ func (r *pgxRepository) GetByID(ctx context.Context, id string) (*Booking, error
 	return &b, nil
 }
 
-func (r *pgxRepository) List(ctx context.Context, filter Filter) ([]*Booking, int, error) {
+// bookingSelectColumns are the columns returned by the booking list queries,
+// in the order scanBooking expects.
+var bookingSelectColumns = []string{
+	"b.id", "b.resource_id", "r.name", "b.user_id", "u.display_name",
+	"l.id", "l.name", "o.id", "o.name",
+	"b.start_time", "b.end_time", "b.status", "b.payment_status", "b.created_at", "b.updated_at",
+	"(SELECT pg.id FROM public.pickup_groups pg WHERE pg.booking_id = b.id) AS pickup_group_id",
+}
+
+// likeEscaper escapes LIKE wildcards (and the escape character itself, which
+// is a backslash by default in PostgreSQL) so search text matches literally.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// listQuery builds the joined booking selection with every Filter condition
+// applied. Sorting and pagination are left to the caller.
+func listQuery(filter Filter, columns ...string) squirrel.SelectBuilder {
 	psql := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
-	query := psql.Select(
-		"b.id", "b.resource_id", "r.name", "b.user_id", "u.display_name",
-		"l.id", "l.name", "o.id", "o.name",
-		"b.start_time", "b.end_time", "b.status", "b.payment_status", "b.created_at", "b.updated_at",
-		"count(*) OVER() as total_count",
-	).
+	query := psql.Select(columns...).
 		From("public.bookings b").
 		Join("public.resources r ON b.resource_id = r.id").
 		Join("public.users u ON b.user_id = u.id").
@@ -120,9 +166,26 @@ func (r *pgxRepository) List(ctx context.Context, filter Filter) ([]*Booking, in
 	if filter.OrganizationID != "" {
 		query = query.Where(squirrel.Eq{"o.id": filter.OrganizationID})
 	}
+	if filter.LocationID != "" {
+		query = query.Where(squirrel.Eq{"l.id": filter.LocationID})
+	}
+	if filter.ResourceType != "" {
+		query = query.Where(squirrel.Eq{"r.resource_type": filter.ResourceType})
+	}
 	if filter.Status != "" {
 		query = query.Where(squirrel.Eq{"b.status": filter.Status})
 	}
+	if filter.PaymentStatus != "" {
+		query = query.Where(squirrel.Eq{"b.payment_status": filter.PaymentStatus})
+	}
+	if filter.Search != "" {
+		pattern := "%" + likeEscaper.Replace(filter.Search) + "%"
+		query = query.Where(squirrel.Or{
+			squirrel.ILike{"u.display_name": pattern},
+			squirrel.ILike{"u.username": pattern},
+			squirrel.ILike{"u.email": pattern},
+		})
+	}
 	// Date range filtering (intersection logic)
 	if filter.StartTime != nil {
 		query = query.Where(squirrel.GtOrEq{"b.end_time": filter.StartTime})
@@ -130,6 +193,30 @@ func (r *pgxRepository) List(ctx context.Context, filter Filter) ([]*Booking, in
 	if filter.EndTime != nil {
 		query = query.Where(squirrel.LtOrEq{"b.start_time": filter.EndTime})
 	}
+	if filter.CreatedAtFrom != nil {
+		query = query.Where(squirrel.GtOrEq{"b.created_at": filter.CreatedAtFrom})
+	}
+	if filter.CreatedAtTo != nil {
+		query = query.Where(squirrel.LtOrEq{"b.created_at": filter.CreatedAtTo})
+	}
+
+	return query
+}
+
+// scanBooking returns scan targets in the bookingSelectColumns order. Extra
+// trailing targets (e.g. total_count) are appended by callers.
+func scanBooking(b *Booking, extra ...any) []any {
+	targets := []any{
+		&b.ID, &b.ResourceID, &b.ResourceName, &b.UserID, &b.UserName,
+		&b.LocationID, &b.LocationName, &b.OrganizationID, &b.OrganizationName,
+		&b.StartTime, &b.EndTime, &b.Status, &b.PaymentStatus, &b.CreatedAt, &b.UpdatedAt,
+		&b.PickupGroupID,
+	}
+	return append(targets, extra...)
+}
+
+func (r *pgxRepository) List(ctx context.Context, filter Filter) ([]*Booking, int, error) {
+	query := listQuery(filter, append(bookingSelectColumns, "count(*) OVER() as total_count")...)
 
 	// Sorting
 	orderBy := "b.start_time"
@@ -171,20 +258,69 @@ func (r *pgxRepository) List(ctx context.Context, filter Filter) ([]*Booking, in
 
 	for rows.Next() {
 		var b Booking
-		if err := rows.Scan(
-			&b.ID, &b.ResourceID, &b.ResourceName, &b.UserID, &b.UserName,
-			&b.LocationID, &b.LocationName, &b.OrganizationID, &b.OrganizationName,
-			&b.StartTime, &b.EndTime, &b.Status, &b.PaymentStatus, &b.CreatedAt, &b.UpdatedAt, &total,
-		); err != nil {
+		if err := rows.Scan(scanBooking(&b, &total)...); err != nil {
 			return nil, 0, fmt.Errorf("scan booking failed: %w", err)
 		}
 		bookings = append(bookings, &b)
 	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("iterate bookings failed: %w", err)
+	}
 
 	return bookings, total, nil
 }
 
-func (r *pgxRepository) Update(ctx context.Context, b *Booking) error {
+// ListAfter returns up to limit bookings strictly after the keyset position
+// `after` (or from the beginning when nil), ordered by (sort column, id). The
+// id tie-breaker makes the order total, so rows sharing a timestamp are never
+// skipped or repeated across pages.
+func (r *pgxRepository) ListAfter(ctx context.Context, filter Filter, after *Cursor, limit int) ([]*Booking, error) {
+	query := listQuery(filter, bookingSelectColumns...)
+
+	column := "b." + filter.SortBy
+	desc := filter.SortOrder == "DESC"
+
+	if after != nil {
+		op := ">"
+		if desc {
+			op = "<"
+		}
+		query = query.Where(fmt.Sprintf("(%s, b.id) %s (?, ?)", column, op), after.Value, after.ID)
+	}
+
+	dir := "ASC"
+	if desc {
+		dir = "DESC"
+	}
+	query = query.OrderBy(column+" "+dir, "b.id "+dir).Limit(uint64(limit))
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build list bookings after cursor query failed: %w", err)
+	}
+
+	rows, err := r.pool.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("list bookings after cursor failed: %w", err)
+	}
+	defer rows.Close()
+
+	var bookings []*Booking
+	for rows.Next() {
+		var b Booking
+		if err := rows.Scan(scanBooking(&b)...); err != nil {
+			return nil, fmt.Errorf("scan booking failed: %w", err)
+		}
+		bookings = append(bookings, &b)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate bookings failed: %w", err)
+	}
+
+	return bookings, nil
+}
+
+func (r *pgxRepository) Update(ctx context.Context, b *Booking, publish event.Hook) error {
 	psql := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
 	query, args, err := psql.Update("public.bookings").
 		Set("start_time", b.StartTime).
@@ -198,17 +334,27 @@ func (r *pgxRepository) Update(ctx context.Context, b *Booking) error {
 		return fmt.Errorf("build update booking query failed: %w", err)
 	}
 
-	ct, err := r.pool.Exec(ctx, query, args...)
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin transaction failed: %w", err)
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck
+
+	ct, err := tx.Exec(ctx, query, args...)
 	if err != nil {
 		return mapOverlapError(fmt.Errorf("update booking failed: %w", err))
 	}
 	if ct.RowsAffected() == 0 {
 		return ErrNotFound
 	}
-	return nil
+
+	if err := publish.Run(tx); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
 }
 
-func (r *pgxRepository) Delete(ctx context.Context, id string) error {
+func (r *pgxRepository) Delete(ctx context.Context, id string, publish event.Hook) error {
 	psql := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
 	query, args, err := psql.Delete("public.bookings").
 		Where(squirrel.Eq{"id": id}).
@@ -217,14 +363,24 @@ func (r *pgxRepository) Delete(ctx context.Context, id string) error {
 		return fmt.Errorf("build delete booking query failed: %w", err)
 	}
 
-	ct, err := r.pool.Exec(ctx, query, args...)
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin transaction failed: %w", err)
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck
+
+	ct, err := tx.Exec(ctx, query, args...)
 	if err != nil {
 		return fmt.Errorf("delete booking failed: %w", err)
 	}
 	if ct.RowsAffected() == 0 {
 		return ErrNotFound
 	}
-	return nil
+
+	if err := publish.Run(tx); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
 }
 
 func (r *pgxRepository) HasOverlap(ctx context.Context, resourceID string, start, end time.Time, excludeBookingID string) (bool, error) {
@@ -260,3 +416,203 @@ func (r *pgxRepository) HasOverlap(ctx context.Context, resourceID string, start
 	}
 	return exists, nil
 }
+
+func (r *pgxRepository) OccupancyHeatmap(ctx context.Context, filter OccupancyFilter) ([]*OccupancyHeatmap, error) {
+	// Each confirmed booking is expanded into the local wall-clock hours it
+	// touches (generate_series over the booking converted to the location's
+	// timezone), then counted per weekday/hour. A booking ending exactly on an
+	// hour boundary does not spill into the next hour. Slots are clipped to the
+	// requested local date range, so a booking straddling the range edge only
+	// contributes its in-range hours. Bookings are first narrowed on their UTC
+	// bounds, padded by the widest timezone offsets (±14h), so the expansion
+	// only runs over bookings that can reach the range.
+	from := filter.From.Format("2006-01-02")
+	to := filter.To.Format("2006-01-02")
+	psql := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
+	query := psql.Select(
+		"l.id", "l.name", "l.timezone",
+		"EXTRACT(DOW FROM h.slot)::int AS weekday",
+		"EXTRACT(HOUR FROM h.slot)::int AS hour",
+		"COUNT(*) AS bookings",
+	).
+		From("public.bookings b").
+		Join("public.resources r ON b.resource_id = r.id").
+		Join("public.locations l ON r.location_id = l.id").
+		JoinClause("CROSS JOIN LATERAL generate_series(" +
+			"date_trunc('hour', b.start_time AT TIME ZONE l.timezone), " +
+			"(b.end_time AT TIME ZONE l.timezone) - interval '1 microsecond', " +
+			"interval '1 hour') AS h(slot)").
+		Where(squirrel.Eq{"b.status": string(StatusConfirmed)}).
+		Where("b.end_time > (?::date::timestamp AT TIME ZONE 'UTC') - interval '14 hours'", from).
+		Where("b.start_time < ((?::date + 1)::timestamp AT TIME ZONE 'UTC') + interval '14 hours'", to).
+		Where("h.slot >= ?::date", from).
+		Where("h.slot < ?::date + 1", to)
+
+	if filter.OrganizationID != "" {
+		query = query.Where(squirrel.Eq{"l.organization_id": filter.OrganizationID})
+	}
+	if filter.LocationID != "" {
+		query = query.Where(squirrel.Eq{"l.id": filter.LocationID})
+	}
+	if filter.ResourceType != "" {
+		query = query.Where(squirrel.Eq{"r.resource_type": filter.ResourceType})
+	}
+
+	sql, args, err := query.
+		GroupBy("l.id", "l.name", "l.timezone", "weekday", "hour").
+		OrderBy("l.name ASC", "l.id ASC").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build occupancy heatmap query failed: %w", err)
+	}
+
+	heatmaps, byLocation, err := r.occupancyScope(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.pool.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("occupancy heatmap failed: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var locID, locName, tz string
+		var weekday, hour, count int
+		if err := rows.Scan(&locID, &locName, &tz, &weekday, &hour, &count); err != nil {
+			return nil, fmt.Errorf("scan occupancy cell failed: %w", err)
+		}
+		hm, ok := byLocation[locID]
+		if !ok {
+			hm = &OccupancyHeatmap{LocationID: locID, LocationName: locName, Timezone: tz}
+			byLocation[locID] = hm
+			heatmaps = append(heatmaps, hm)
+		}
+		hm.Cells[weekday][hour] = count
+		hm.Total += count
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate occupancy cells failed: %w", err)
+	}
+	return heatmaps, nil
+}
+
+// occupancyScope pre-seeds an empty heatmap for every location in an explicit
+// organization / location scope, so venues without any confirmed bookings still
+// show up (as all-zero matrices) when managers compare demand. Without a scope
+// (system admin overview) only locations that have bookings are reported.
+func (r *pgxRepository) occupancyScope(ctx context.Context, filter OccupancyFilter) ([]*OccupancyHeatmap, map[string]*OccupancyHeatmap, error) {
+	byLocation := make(map[string]*OccupancyHeatmap)
+	if filter.OrganizationID == "" && filter.LocationID == "" {
+		return nil, byLocation, nil
+	}
+
+	psql := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
+	query := psql.Select("l.id", "l.name", "l.timezone").From("public.locations l")
+	if filter.OrganizationID != "" {
+		query = query.Where(squirrel.Eq{"l.organization_id": filter.OrganizationID})
+	}
+	if filter.LocationID != "" {
+		query = query.Where(squirrel.Eq{"l.id": filter.LocationID})
+	}
+
+	sql, args, err := query.OrderBy("l.name ASC", "l.id ASC").ToSql()
+	if err != nil {
+		return nil, nil, fmt.Errorf("build occupancy scope query failed: %w", err)
+	}
+
+	rows, err := r.pool.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("list occupancy scope failed: %w", err)
+	}
+	defer rows.Close()
+
+	var heatmaps []*OccupancyHeatmap
+	for rows.Next() {
+		hm := &OccupancyHeatmap{}
+		if err := rows.Scan(&hm.LocationID, &hm.LocationName, &hm.Timezone); err != nil {
+			return nil, nil, fmt.Errorf("scan occupancy scope failed: %w", err)
+		}
+		byLocation[hm.LocationID] = hm
+		heatmaps = append(heatmaps, hm)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("iterate occupancy scope failed: %w", err)
+	}
+	return heatmaps, byLocation, nil
+}
+
+func (r *pgxRepository) ClaimReminders(ctx context.Context, now time.Time, offsets []time.Duration, send func(tx pgx.Tx, reminders []*Reminder) error) (int, error) {
+	secs := make([]int32, len(offsets))
+	var maxSecs int32
+	for i, o := range offsets {
+		secs[i] = int32(o.Seconds())
+		maxSecs = max(maxSecs, secs[i])
+	}
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("begin transaction failed: %w", err)
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck
+
+	rows, err := tx.Query(ctx, `
+		WITH due AS (
+			SELECT b.id, b.start_time, d.secs
+			FROM public.bookings b
+			CROSS JOIN LATERAL (
+				SELECT min(s) AS secs
+				FROM unnest($2::int[]) AS s
+				WHERE b.start_time - make_interval(secs => s) <= $1
+			) d
+			WHERE b.status = 'confirmed'
+			  AND b.start_time > $1
+			  AND b.start_time <= $1 + make_interval(secs => $3)
+			  AND d.secs IS NOT NULL
+			  AND b.created_at < b.start_time - make_interval(secs => d.secs)
+		), claimed AS (
+			INSERT INTO public.booking_reminders (booking_id, before_seconds, start_time)
+			SELECT id, secs, start_time FROM due
+			ON CONFLICT DO NOTHING
+			RETURNING booking_id, before_seconds
+		)
+		SELECT b.id, b.user_id, b.resource_id, r.name, l.name, b.start_time, b.end_time, c.before_seconds
+		FROM claimed c
+		JOIN public.bookings b ON b.id = c.booking_id
+		JOIN public.resources r ON r.id = b.resource_id
+		JOIN public.locations l ON l.id = r.location_id
+		ORDER BY b.start_time`,
+		now, secs, maxSecs,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("claim booking reminders failed: %w", err)
+	}
+	defer rows.Close()
+
+	var reminders []*Reminder
+	for rows.Next() {
+		var rem Reminder
+		var before int32
+		if err := rows.Scan(&rem.BookingID, &rem.UserID, &rem.ResourceID, &rem.ResourceName, &rem.LocationName,
+			&rem.StartTime, &rem.EndTime, &before); err != nil {
+			return 0, fmt.Errorf("scan booking reminder failed: %w", err)
+		}
+		rem.Before = time.Duration(before) * time.Second
+		reminders = append(reminders, &rem)
+	}
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("iterate booking reminders failed: %w", err)
+	}
+	if len(reminders) == 0 {
+		return 0, nil
+	}
+
+	if err := send(tx, reminders); err != nil {
+		return 0, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return 0, err
+	}
+	return len(reminders), nil
+}
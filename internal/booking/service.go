@@ -3,11 +3,15 @@ package booking
 import (
 	"context"
 	"errors"
+	"slices"
 	"sort"
 	"time"
 
+	"github.com/jackc/pgx/v5"
+
 	"github.com/nekogravitycat/court-booking-backend/internal/location"
 	"github.com/nekogravitycat/court-booking-backend/internal/organization"
+	"github.com/nekogravitycat/court-booking-backend/internal/pkg/event"
 	"github.com/nekogravitycat/court-booking-backend/internal/resource"
 )
 
@@ -35,24 +39,60 @@ type Service interface {
 	Create(ctx context.Context, req CreateRequest) (*Booking, error)
 	GetByID(ctx context.Context, id string) (*Booking, error)
 	List(ctx context.Context, filter Filter) ([]*Booking, int, error)
+	// ListByCursor returns one keyset page and the cursor for the next page
+	// (empty when there are no more rows).
+	ListByCursor(ctx context.Context, filter Filter) ([]*Booking, string, error)
 	Update(ctx context.Context, id string, req UpdateRequest, updaterUserID string, isSysAdmin bool) (*Booking, error)
 	Delete(ctx context.Context, id string, deleterUserID string, isSysAdmin bool) error
 	GetAvailability(ctx context.Context, resourceID string, date time.Time) ([]TimeSlot, error)
+	GetOccupancyHeatmap(ctx context.Context, filter OccupancyFilter) ([]*OccupancyHeatmap, error)
+
+	// CheckSlot runs the same validation Create applies (time range, not in the
+	// past, resource exists, location open and within opening hours, no overlap)
+	// without creating anything. excludeBookingID ignores one existing booking
+	// in the overlap check; pass "" to check against all bookings. Callers that
+	// insert the booking themselves still rely on the database exclusion
+	// constraint as the final guard.
+	CheckSlot(ctx context.Context, resourceID string, start, end time.Time, excludeBookingID string) error
+
+	// SendReminders publishes the reminders due at now for confirmed bookings,
+	// offsets being how long before the start to remind, and returns how many
+	// were sent. It is run periodically by a background worker and is safe to
+	// run on several replicas at once.
+	SendReminders(ctx context.Context, now time.Time, offsets []time.Duration) (int, error)
+
+	// PublishChanges reports bookings another module created or changed in
+	// tx, the way Create, Update and Delete report their own: to the
+	// organization as created, updated or cancelled events. With notifyBooker
+	// the booker is also told of a confirmation or cancellation, for changes
+	// they did not make themselves.
+	PublishChanges(ctx context.Context, tx pgx.Tx, changes []Change, notifyBooker bool) error
+}
+
+// OrgPublisher hands booking changes to the organization owning the booked
+// resource. It is implemented by the webhook service and injected to keep
+// this module decoupled from it.
+type OrgPublisher interface {
+	PublishOrgEvent(ctx context.Context, tx pgx.Tx, orgID string, e event.Event) error
 }
 
 type service struct {
-	repo       Repository
-	resService resource.Service
-	locService location.Service
-	orgService organization.Service
+	repo         Repository
+	resService   resource.Service
+	locService   location.Service
+	orgService   organization.Service
+	publisher    event.Publisher
+	orgPublisher OrgPublisher
 }
 
-func NewService(repo Repository, resService resource.Service, locService location.Service, orgService organization.Service) Service {
+func NewService(repo Repository, resService resource.Service, locService location.Service, orgService organization.Service, publisher event.Publisher, orgPublisher OrgPublisher) Service {
 	return &service{
-		repo:       repo,
-		resService: resService,
-		locService: locService,
-		orgService: orgService,
+		repo:         repo,
+		resService:   resService,
+		locService:   locService,
+		orgService:   orgService,
+		publisher:    publisher,
+		orgPublisher: orgPublisher,
 	}
 }
 
@@ -73,23 +113,47 @@ func (s *service) isOrgManager(ctx context.Context, resourceID string, userID st
 }
 
 func (s *service) Create(ctx context.Context, req CreateRequest) (*Booking, error) {
+	if err := s.CheckSlot(ctx, req.ResourceID, req.StartTime, req.EndTime, ""); err != nil {
+		return nil, err
+	}
+
+	booking := &Booking{
+		ResourceID: req.ResourceID,
+		UserID:     req.UserID,
+		StartTime:  req.StartTime,
+		EndTime:    req.EndTime,
+		Status:     StatusPending, // Default status
+	}
+
+	// The repository loads the full booking details (joins) for the event and
+	// the response.
+	err := s.repo.Create(ctx, booking, func(tx pgx.Tx) error {
+		return s.publishOrgEvent(ctx, tx, OrgEventBookingCreated, booking, nil)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return booking, nil
+}
+
+func (s *service) CheckSlot(ctx context.Context, resourceID string, start, end time.Time, excludeBookingID string) error {
 	// 1. Validate Time Range
-	if req.EndTime.Before(req.StartTime) || req.EndTime.Equal(req.StartTime) {
-		return nil, ErrInvalidTimeRange
+	if end.Before(start) || end.Equal(start) {
+		return ErrInvalidTimeRange
 	}
 	// Strict check: StartTime cannot be in the past
-	if req.StartTime.Before(time.Now().UTC()) {
-		return nil, ErrStartTimePast
+	if start.Before(time.Now().UTC()) {
+		return ErrStartTimePast
 	}
 
 	// 2. Validate Resource Exists
-	res, err := s.resService.GetByID(ctx, req.ResourceID)
+	res, err := s.resService.GetByID(ctx, resourceID)
 	if err != nil {
 		switch {
 		case errors.Is(err, resource.ErrNotFound):
-			return nil, ErrResourceNotFound
+			return ErrResourceNotFound
 		default:
-			return nil, err
+			return err
 		}
 	}
 
@@ -97,35 +161,21 @@ func (s *service) Create(ctx context.Context, req CreateRequest) (*Booking, erro
 	// (open flag, opening hours in the location timezone, max duration).
 	loc, err := s.locService.GetByID(ctx, res.LocationID)
 	if err != nil {
-		return nil, err
+		return err
 	}
-	if err := validateBookingWindow(loc, req.StartTime, req.EndTime); err != nil {
-		return nil, err
+	if err := validateBookingWindow(loc, start, end); err != nil {
+		return err
 	}
 
 	// 3. Check for Overlaps
-	hasOverlap, err := s.repo.HasOverlap(ctx, req.ResourceID, req.StartTime, req.EndTime, "")
+	hasOverlap, err := s.repo.HasOverlap(ctx, resourceID, start, end, excludeBookingID)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	if hasOverlap {
-		return nil, ErrTimeConflict
+		return ErrTimeConflict
 	}
-	// 4. Create Booking
-	booking := &Booking{
-		ResourceID: req.ResourceID,
-		UserID:     req.UserID,
-		StartTime:  req.StartTime,
-		EndTime:    req.EndTime,
-		Status:     StatusPending, // Default status
-	}
-
-	if err := s.repo.Create(ctx, booking); err != nil {
-		return nil, err
-	}
-
-	// 5. Fetch full booking details (joins) for response
-	return s.repo.GetByID(ctx, booking.ID)
+	return nil
 }
 
 func (s *service) GetByID(ctx context.Context, id string) (*Booking, error) {
@@ -136,6 +186,54 @@ func (s *service) List(ctx context.Context, filter Filter) ([]*Booking, int, err
 	return s.repo.List(ctx, filter)
 }
 
+func (s *service) ListByCursor(ctx context.Context, filter Filter) ([]*Booking, string, error) {
+	if !slices.Contains(cursorSortColumns, filter.SortBy) {
+		return nil, "", ErrCursorSortUnsupported
+	}
+	if filter.PageSize < 1 {
+		filter.PageSize = 20
+	}
+
+	var after *Cursor
+	if filter.Cursor != "" {
+		c, err := DecodeCursor(filter.Cursor)
+		if err != nil {
+			return nil, "", err
+		}
+		// A cursor is only meaningful for the ordering it was issued under.
+		if c.SortBy != filter.SortBy || c.Order != filter.SortOrder {
+			return nil, "", ErrInvalidCursor
+		}
+		after = c
+	}
+
+	// Fetch one extra row to learn whether another page exists.
+	bookings, err := s.repo.ListAfter(ctx, filter, after, filter.PageSize+1)
+	if err != nil {
+		return nil, "", err
+	}
+	if len(bookings) <= filter.PageSize {
+		return bookings, "", nil
+	}
+
+	bookings = bookings[:filter.PageSize]
+	last := bookings[len(bookings)-1]
+	next := Cursor{SortBy: filter.SortBy, Order: filter.SortOrder, Value: sortValue(last, filter.SortBy), ID: last.ID}
+	return bookings, next.Encode(), nil
+}
+
+// sortValue returns the booking's value for one of the cursorSortColumns.
+func sortValue(b *Booking, sortBy string) time.Time {
+	switch sortBy {
+	case "end_time":
+		return b.EndTime
+	case "created_at":
+		return b.CreatedAt
+	default:
+		return b.StartTime
+	}
+}
+
 func (s *service) Update(ctx context.Context, id string, req UpdateRequest, updaterUserID string, isSysAdmin bool) (*Booking, error) {
 	b, err := s.repo.GetByID(ctx, id)
 	if err != nil {
@@ -163,6 +261,9 @@ func (s *service) Update(ctx context.Context, id string, req UpdateRequest, upda
 		return nil, ErrPermissionDenied
 	}
 
+	before := *b
+	oldStatus := b.Status
+
 	// Prepare new values
 	newStart := b.StartTime
 	newEnd := b.EndTime
@@ -177,6 +278,12 @@ func (s *service) Update(ctx context.Context, id string, req UpdateRequest, upda
 		timeChanged = true
 	}
 
+	// A booking reserved for a pickup group moves and ends with the group, so
+	// its time cannot be edited directly.
+	if timeChanged && b.PickupGroupID != nil {
+		return nil, ErrManagedByPickupGroup
+	}
+
 	if timeChanged {
 		if newEnd.Before(newStart) || newEnd.Equal(newStart) {
 			return nil, ErrInvalidTimeRange
@@ -225,6 +332,10 @@ func (s *service) Update(ctx context.Context, id string, req UpdateRequest, upda
 			if st != StatusCancelled && st != StatusCancelRequest {
 				return nil, ErrPermissionDenied
 			}
+			// The host releases a pickup group's court by cancelling the group.
+			if b.PickupGroupID != nil {
+				return nil, ErrManagedByPickupGroup
+			}
 		}
 		b.Status = st
 	}
@@ -241,13 +352,175 @@ func (s *service) Update(ctx context.Context, id string, req UpdateRequest, upda
 		b.PaymentStatus = ps
 	}
 
-	if err := s.repo.Update(ctx, b); err != nil {
+	err = s.repo.Update(ctx, b, func(tx pgx.Tx) error {
+		if b.Status != oldStatus && !isBookingOwner {
+			if err := s.publishStatusChange(ctx, tx, b); err != nil {
+				return err
+			}
+		}
+		return s.publishOrgChanges(ctx, tx, &before, b)
+	})
+	if err != nil {
 		return nil, err
 	}
 
 	return b, nil
 }
 
+// orgEventData is the booking as reported to its organization, with the
+// previous values of the fields that changed when there are any.
+func orgEventData(b *Booking, previous map[string]any) map[string]any {
+	data := map[string]any{
+		"booking_id":      b.ID,
+		"resource_id":     b.ResourceID,
+		"resource_name":   b.ResourceName,
+		"location_id":     b.LocationID,
+		"location_name":   b.LocationName,
+		"user_id":         b.UserID,
+		"start_time":      b.StartTime,
+		"end_time":        b.EndTime,
+		"status":          b.Status,
+		"payment_status":  b.PaymentStatus,
+		"pickup_group_id": b.PickupGroupID,
+	}
+	if len(previous) > 0 {
+		data["previous"] = previous
+	}
+	return data
+}
+
+// publishOrgEvent reports a booking change to the booking's organization.
+func (s *service) publishOrgEvent(ctx context.Context, tx pgx.Tx, eventType string, b *Booking, previous map[string]any) error {
+	e := event.Event{
+		Type:       eventType,
+		UserID:     b.UserID,
+		Data:       orgEventData(b, previous),
+		OccurredAt: time.Now(),
+	}
+	return s.orgPublisher.PublishOrgEvent(ctx, tx, b.OrganizationID, e)
+}
+
+// publishOrgChanges reports an update to the booking's organization: a
+// cancellation, or otherwise a change of time or status, and separately a
+// change of payment status.
+func (s *service) publishOrgChanges(ctx context.Context, tx pgx.Tx, before, after *Booking) error {
+	previous := map[string]any{}
+	if !after.StartTime.Equal(before.StartTime) {
+		previous["start_time"] = before.StartTime
+	}
+	if !after.EndTime.Equal(before.EndTime) {
+		previous["end_time"] = before.EndTime
+	}
+	if after.Status != before.Status {
+		previous["status"] = before.Status
+	}
+	var err error
+	switch {
+	case after.Status == StatusCancelled && before.Status != StatusCancelled:
+		err = s.publishOrgEvent(ctx, tx, OrgEventBookingCancelled, after, previous)
+	case len(previous) > 0:
+		err = s.publishOrgEvent(ctx, tx, OrgEventBookingUpdated, after, previous)
+	}
+	if err != nil {
+		return err
+	}
+
+	if after.PaymentStatus != before.PaymentStatus {
+		return s.publishOrgEvent(ctx, tx, OrgEventPaymentStatusChanged, after,
+			map[string]any{"payment_status": before.PaymentStatus})
+	}
+	return nil
+}
+
+func (s *service) PublishChanges(ctx context.Context, tx pgx.Tx, changes []Change, notifyBooker bool) error {
+	for _, c := range changes {
+		b, err := s.repo.GetByIDTx(ctx, tx, c.BookingID)
+		if err != nil {
+			return err
+		}
+		if c.Previous == nil {
+			if err := s.publishOrgEvent(ctx, tx, OrgEventBookingCreated, b, nil); err != nil {
+				return err
+			}
+			continue
+		}
+
+		before := *b
+		before.StartTime = c.Previous.StartTime
+		before.EndTime = c.Previous.EndTime
+		before.Status = c.Previous.Status
+		before.PaymentStatus = c.Previous.PaymentStatus
+		if notifyBooker && b.Status != before.Status {
+			if err := s.publishStatusChange(ctx, tx, b); err != nil {
+				return err
+			}
+		}
+		if err := s.publishOrgChanges(ctx, tx, &before, b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// publishStatusChange tells the booker that a manager confirmed or cancelled
+// their booking.
+func (s *service) publishStatusChange(ctx context.Context, tx pgx.Tx, b *Booking) error {
+	var eventType string
+	switch b.Status {
+	case StatusConfirmed:
+		eventType = EventBookingConfirmed
+	case StatusCancelled:
+		eventType = EventBookingCancelled
+	default:
+		return nil
+	}
+	e := event.Event{
+		Type:   eventType,
+		UserID: b.UserID,
+		Data: map[string]any{
+			"booking_id":    b.ID,
+			"resource_id":   b.ResourceID,
+			"resource_name": b.ResourceName,
+			"location_name": b.LocationName,
+			"start_time":    b.StartTime,
+			"end_time":      b.EndTime,
+		},
+		OccurredAt: time.Now(),
+	}
+	return s.publisher.Publish(ctx, tx, e)
+}
+
+func (s *service) SendReminders(ctx context.Context, now time.Time, offsets []time.Duration) (int, error) {
+	if len(offsets) == 0 {
+		return 0, nil
+	}
+	return s.repo.ClaimReminders(ctx, now, offsets, func(tx pgx.Tx, reminders []*Reminder) error {
+		return s.publisher.Publish(ctx, tx, reminderEvents(reminders, now)...)
+	})
+}
+
+// reminderEvents tells each booker their booking starts soon.
+func reminderEvents(reminders []*Reminder, now time.Time) []event.Event {
+	events := make([]event.Event, len(reminders))
+	for i, r := range reminders {
+		events[i] = event.Event{
+			Type:   EventBookingReminder,
+			UserID: r.UserID,
+			Data: map[string]any{
+				"booking_id":            r.BookingID,
+				"resource_id":           r.ResourceID,
+				"resource_name":         r.ResourceName,
+				"location_name":         r.LocationName,
+				"start_time":            r.StartTime,
+				"end_time":              r.EndTime,
+				"remind_before_minutes": int(r.Before.Minutes()),
+			},
+			OccurredAt: now,
+		}
+	}
+	return events
+}
+
 func (s *service) Delete(ctx context.Context, id string, deleterUserID string, isSysAdmin bool) error {
 	b, err := s.repo.GetByID(ctx, id)
 	if err != nil {
@@ -270,7 +543,22 @@ func (s *service) Delete(ctx context.Context, id string, deleterUserID string, i
 		return ErrPermissionDenied
 	}
 
-	return s.repo.Delete(ctx, id)
+	// Only a system admin may remove a pickup group's booking outright; the
+	// group keeps running without a linked court in that case.
+	if b.PickupGroupID != nil && !isSysAdmin {
+		return ErrManagedByPickupGroup
+	}
+
+	return s.repo.Delete(ctx, id, func(tx pgx.Tx) error {
+		// To the organization a deleted booking is a cancelled one, unless it
+		// had been cancelled already.
+		if b.Status == StatusCancelled {
+			return nil
+		}
+		cancelled := *b
+		cancelled.Status = StatusCancelled
+		return s.publishOrgEvent(ctx, tx, OrgEventBookingCancelled, &cancelled, map[string]any{"status": b.Status})
+	})
 }
 
 func (s *service) GetAvailability(ctx context.Context, resourceID string, date time.Time) ([]TimeSlot, error) {
@@ -328,6 +616,28 @@ func (s *service) GetAvailability(ctx context.Context, resourceID string, date t
 	return CalculateAvailability(date, tz, loc.OpeningHoursStart, loc.OpeningHoursEnd, bookings)
 }
 
+// GetOccupancyHeatmap returns per-location weekday x hour demand matrices of
+// confirmed bookings over an inclusive local date range. Callers are expected
+// to have scoped the filter to what the requester may see.
+func (s *service) GetOccupancyHeatmap(ctx context.Context, filter OccupancyFilter) ([]*OccupancyHeatmap, error) {
+	if filter.To.Before(filter.From) {
+		return nil, ErrInvalidTimeRange
+	}
+	if filter.To.Sub(filter.From) >= MaxOccupancyRangeDays*24*time.Hour {
+		return nil, ErrOccupancyRangeTooLong
+	}
+	if filter.ResourceType != "" && !slices.Contains(resource.ValidResourceTypes, filter.ResourceType) {
+		return nil, ErrInvalidInput
+	}
+	if filter.LocationID != "" {
+		if _, err := s.locService.GetByID(ctx, filter.LocationID); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.repo.OccupancyHeatmap(ctx, filter)
+}
+
 // loadLocationTZ resolves an IANA timezone name to a *time.Location. An empty
 // name falls back to UTC. Location timezones are validated at create/update
 // time, so a failure here indicates corrupt data and surfaces as an error.
@@ -3,8 +3,10 @@ package config
 import (
 	"fmt"
 	"log"
+	"net/mail"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/joho/godotenv"
@@ -25,6 +27,36 @@ type Config struct {
 	JWTSecret         string
 	JWTAccessTokenTTL time.Duration
 	BcryptCost        int
+	// PickupTemplateInterval is how often recurring pickup templates are
+	// materialised into pickup groups.
+	PickupTemplateInterval time.Duration
+	// PickupLifecycleInterval is how often pickup groups are completed or
+	// auto-cancelled.
+	PickupLifecycleInterval time.Duration
+	// PickupAutoCancelCutoff is how long before start_time a group below its
+	// min_participants is cancelled.
+	PickupAutoCancelCutoff time.Duration
+	// NotificationInterval is how often due notification deliveries are sent.
+	NotificationInterval time.Duration
+	// SMTP settings of the email notification channel; email is disabled
+	// while SMTPHost is empty.
+	SMTPHost     string
+	SMTPPort     int
+	SMTPUsername string
+	SMTPPassword string
+	SMTPFrom     string
+	// NotificationWebhookURL enables the webhook notification channel; the
+	// optional secret signs its requests.
+	NotificationWebhookURL    string
+	NotificationWebhookSecret string
+	// ReminderOffsets are how long before a confirmed booking or pickup order
+	// starts its participant is reminded; empty disables reminders.
+	ReminderOffsets []time.Duration
+	// ReminderInterval is how often due reminders are looked for.
+	ReminderInterval time.Duration
+	// WebhookInterval is how often due organization webhook deliveries are
+	// sent.
+	WebhookInterval time.Duration
 }
 
 // Load loads configuration from .env (optional) and environment variables.
@@ -77,6 +109,107 @@ func Load() (*Config, error) {
 		return nil, fmt.Errorf("invalid BCRYPT_COST: %w", err)
 	}
 
+	// Pickup template materialisation interval (default: 1h)
+	intervalStr := getEnv("PICKUP_TEMPLATE_MATERIALIZE_INTERVAL", "1h")
+	interval, err := time.ParseDuration(intervalStr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid PICKUP_TEMPLATE_MATERIALIZE_INTERVAL: %w", err)
+	}
+	if interval <= 0 {
+		return nil, fmt.Errorf("PICKUP_TEMPLATE_MATERIALIZE_INTERVAL must be positive")
+	}
+	cfg.PickupTemplateInterval = interval
+
+	// Pickup lifecycle worker interval (default: 5m)
+	lifecycleStr := getEnv("PICKUP_LIFECYCLE_INTERVAL", "5m")
+	lifecycle, err := time.ParseDuration(lifecycleStr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid PICKUP_LIFECYCLE_INTERVAL: %w", err)
+	}
+	if lifecycle <= 0 {
+		return nil, fmt.Errorf("PICKUP_LIFECYCLE_INTERVAL must be positive")
+	}
+	cfg.PickupLifecycleInterval = lifecycle
+
+	// Auto-cancel cutoff before start_time for underfilled groups (default: 2h)
+	cutoffStr := getEnv("PICKUP_AUTO_CANCEL_CUTOFF", "2h")
+	cutoff, err := time.ParseDuration(cutoffStr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid PICKUP_AUTO_CANCEL_CUTOFF: %w", err)
+	}
+	if cutoff < 0 {
+		return nil, fmt.Errorf("PICKUP_AUTO_CANCEL_CUTOFF must not be negative")
+	}
+	cfg.PickupAutoCancelCutoff = cutoff
+
+	// Notification dispatch interval (default: 30s)
+	notifyStr := getEnv("NOTIFICATION_DISPATCH_INTERVAL", "30s")
+	notify, err := time.ParseDuration(notifyStr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid NOTIFICATION_DISPATCH_INTERVAL: %w", err)
+	}
+	if notify <= 0 {
+		return nil, fmt.Errorf("NOTIFICATION_DISPATCH_INTERVAL must be positive")
+	}
+	cfg.NotificationInterval = notify
+
+	// SMTP relay for email notifications (optional)
+	cfg.SMTPHost = getEnv("SMTP_HOST", "")
+	cfg.SMTPPort, err = getEnvAsInt("SMTP_PORT", 587)
+	if err != nil {
+		return nil, fmt.Errorf("invalid SMTP_PORT: %w", err)
+	}
+	cfg.SMTPUsername = getEnv("SMTP_USERNAME", "")
+	cfg.SMTPPassword = getEnv("SMTP_PASSWORD", "")
+	cfg.SMTPFrom = getEnv("SMTP_FROM", "")
+	if cfg.SMTPHost != "" {
+		if _, err := mail.ParseAddress(cfg.SMTPFrom); err != nil {
+			return nil, fmt.Errorf("SMTP_FROM must be a valid address when SMTP_HOST is set: %w", err)
+		}
+	}
+
+	// Webhook endpoint for notifications (optional)
+	cfg.NotificationWebhookURL = getEnv("NOTIFICATION_WEBHOOK_URL", "")
+	cfg.NotificationWebhookSecret = getEnv("NOTIFICATION_WEBHOOK_SECRET", "")
+
+	// Reminder offsets before start, comma separated (default: 24h,2h)
+	for _, s := range strings.Split(getEnv("REMINDER_OFFSETS", "24h,2h"), ",") {
+		s = strings.TrimSpace(s)
+		if s == "" {
+			continue
+		}
+		offset, err := time.ParseDuration(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid REMINDER_OFFSETS: %w", err)
+		}
+		if offset < time.Second {
+			return nil, fmt.Errorf("REMINDER_OFFSETS must be at least 1s, got %s", s)
+		}
+		cfg.ReminderOffsets = append(cfg.ReminderOffsets, offset)
+	}
+
+	// Reminder scheduler interval (default: 5m)
+	reminderStr := getEnv("REMINDER_INTERVAL", "5m")
+	reminder, err := time.ParseDuration(reminderStr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid REMINDER_INTERVAL: %w", err)
+	}
+	if reminder <= 0 {
+		return nil, fmt.Errorf("REMINDER_INTERVAL must be positive")
+	}
+	cfg.ReminderInterval = reminder
+
+	// Organization webhook dispatch interval (default: 30s)
+	webhookStr := getEnv("WEBHOOK_DISPATCH_INTERVAL", "30s")
+	webhookInterval, err := time.ParseDuration(webhookStr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid WEBHOOK_DISPATCH_INTERVAL: %w", err)
+	}
+	if webhookInterval <= 0 {
+		return nil, fmt.Errorf("WEBHOOK_DISPATCH_INTERVAL must be positive")
+	}
+	cfg.WebhookInterval = webhookInterval
+
 	return cfg, nil
 }
 
@@ -0,0 +1,211 @@
+package notification
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Channel delivers messages over one medium. Send returns an error for the
+// dispatcher to retry the delivery later; it should be safe to send the same
+// message more than once.
+type Channel interface {
+	Name() string
+	Send(ctx context.Context, m *Message) error
+}
+
+// inAppChannel adds messages to the user's in-app inbox.
+type inAppChannel struct {
+	repo Repository
+}
+
+// NewInAppChannel returns the channel that fills the in-app inbox.
+func NewInAppChannel(repo Repository) Channel {
+	return &inAppChannel{repo: repo}
+}
+
+func (c *inAppChannel) Name() string { return ChannelInApp }
+
+func (c *inAppChannel) Send(ctx context.Context, m *Message) error {
+	return c.repo.InsertInboxItem(ctx, m)
+}
+
+// SMTPConfig configures the email channel. From may carry a display name
+// ("Court Booking <no-reply@example.com>"). Username may be empty for relays
+// that need no authentication, such as a local development mail sink.
+type SMTPConfig struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+}
+
+// smtpTimeout bounds a whole SMTP conversation.
+const smtpTimeout = 30 * time.Second
+
+type emailChannel struct {
+	cfg SMTPConfig
+}
+
+// NewEmailChannel returns the channel that emails messages through an SMTP
+// relay. STARTTLS is used whenever the relay offers it.
+func NewEmailChannel(cfg SMTPConfig) Channel {
+	return &emailChannel{cfg: cfg}
+}
+
+func (c *emailChannel) Name() string { return ChannelEmail }
+
+func (c *emailChannel) Send(ctx context.Context, m *Message) error {
+	if m.Email == "" {
+		return nil
+	}
+	from, err := mail.ParseAddress(c.cfg.From)
+	if err != nil {
+		return fmt.Errorf("invalid sender address %q: %w", c.cfg.From, err)
+	}
+	subject, body := render(m)
+
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", from.String())
+	fmt.Fprintf(&msg, "To: %s\r\n", m.Email)
+	fmt.Fprintf(&msg, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(&msg, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	fmt.Fprintf(&msg, "Message-ID: <%s.%s@%s>\r\n", m.EventID, m.Channel, c.cfg.Host)
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	msg.WriteString("Content-Transfer-Encoding: 8bit\r\n\r\n")
+	msg.WriteString(strings.ReplaceAll(body, "\n", "\r\n"))
+
+	return c.sendMail(ctx, from.Address, m.Email, msg.Bytes())
+}
+
+// sendMail is smtp.SendMail with a dial context and an overall deadline, so a
+// stalled relay cannot hold up the dispatcher.
+func (c *emailChannel) sendMail(ctx context.Context, from, to string, msg []byte) error {
+	addr := net.JoinHostPort(c.cfg.Host, strconv.Itoa(c.cfg.Port))
+	conn, err := (&net.Dialer{Timeout: smtpTimeout}).DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return fmt.Errorf("dial smtp %s failed: %w", addr, err)
+	}
+	conn.SetDeadline(time.Now().Add(smtpTimeout))
+
+	client, err := smtp.NewClient(conn, c.cfg.Host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("smtp handshake failed: %w", err)
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: c.cfg.Host}); err != nil {
+			return fmt.Errorf("smtp starttls failed: %w", err)
+		}
+	}
+	if c.cfg.Username != "" {
+		auth := smtp.PlainAuth("", c.cfg.Username, c.cfg.Password, c.cfg.Host)
+		if err := client.Auth(auth); err != nil {
+			return fmt.Errorf("smtp auth failed: %w", err)
+		}
+	}
+	if err := client.Mail(from); err != nil {
+		return fmt.Errorf("smtp mail from failed: %w", err)
+	}
+	if err := client.Rcpt(to); err != nil {
+		return fmt.Errorf("smtp rcpt to failed: %w", err)
+	}
+	w, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("smtp data failed: %w", err)
+	}
+	if _, err := w.Write(msg); err != nil {
+		return fmt.Errorf("smtp write message failed: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("smtp message rejected: %w", err)
+	}
+	return client.Quit()
+}
+
+// webhookTimeout bounds one webhook request.
+const webhookTimeout = 10 * time.Second
+
+type webhookChannel struct {
+	url    string
+	secret string
+	client *http.Client
+}
+
+// NewWebhookChannel returns the channel that POSTs each message as JSON to
+// url. With a secret, the body is signed with HMAC-SHA256 in the
+// X-Notification-Signature header ("sha256=<hex>").
+func NewWebhookChannel(url, secret string) Channel {
+	return &webhookChannel{
+		url:    url,
+		secret: secret,
+		client: &http.Client{Timeout: webhookTimeout},
+	}
+}
+
+func (c *webhookChannel) Name() string { return ChannelWebhook }
+
+// webhookPayload is the JSON body of a webhook delivery.
+type webhookPayload struct {
+	ID         string         `json:"id"`
+	Type       string         `json:"type"`
+	UserID     string         `json:"user_id"`
+	Data       map[string]any `json:"data"`
+	OccurredAt time.Time      `json:"occurred_at"`
+}
+
+func (c *webhookChannel) Send(ctx context.Context, m *Message) error {
+	body, err := json.Marshal(webhookPayload{
+		ID:         m.EventID,
+		Type:       m.Type,
+		UserID:     m.UserID,
+		Data:       m.Data,
+		OccurredAt: m.OccurredAt,
+	})
+	if err != nil {
+		return fmt.Errorf("marshal webhook payload failed: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("build webhook request failed: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Notification-ID", m.EventID)
+	req.Header.Set("X-Notification-Type", m.Type)
+	if c.secret != "" {
+		mac := hmac.New(sha256.New, []byte(c.secret))
+		mac.Write(body)
+		req.Header.Set("X-Notification-Signature", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("webhook request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook responded %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package http
+
+import (
+	"time"
+
+	"github.com/nekogravitycat/court-booking-backend/internal/notification"
+	"github.com/nekogravitycat/court-booking-backend/internal/pkg/request"
+)
+
+// ListNotificationsRequest defines query parameters for listing the current
+// user's notifications, newest first.
+type ListNotificationsRequest struct {
+	request.ListParams
+	Unread bool   `form:"unread"`
+	Type   string `form:"type"`
+}
+
+type NotificationResponse struct {
+	ID        string         `json:"id"`
+	Type      string         `json:"type"`
+	Title     string         `json:"title"`
+	Data      map[string]any `json:"data"`
+	CreatedAt time.Time      `json:"created_at"`
+	ReadAt    *time.Time     `json:"read_at"`
+}
+
+func NewNotificationResponse(n *notification.Notification) NotificationResponse {
+	resp := NotificationResponse{
+		ID:        n.ID,
+		Type:      n.Type,
+		Title:     notification.Subject(n.Type),
+		Data:      n.Data,
+		CreatedAt: n.CreatedAt.UTC(),
+	}
+	if resp.Data == nil {
+		resp.Data = map[string]any{}
+	}
+	if n.ReadAt != nil {
+		t := n.ReadAt.UTC()
+		resp.ReadAt = &t
+	}
+	return resp
+}
+
+// UnreadCountResponse is the number of unread notifications, in total and by
+// type. Types without unread notifications are omitted from ByType.
+type UnreadCountResponse struct {
+	UnreadCount int            `json:"unread_count"`
+	ByType      map[string]int `json:"by_type"`
+}
+
+type MarkAllReadResponse struct {
+	Updated int64 `json:"updated"`
+}
+
+// TypeURI is the event type path parameter of the preference endpoints.
+type TypeURI struct {
+	Type string `uri:"type" binding:"required"`
+}
+
+type PreferenceResponse struct {
+	Type  string `json:"type"`
+	Title string `json:"title"`
+	InApp bool   `json:"in_app"`
+}
+
+func NewPreferenceResponse(p *notification.Preference) PreferenceResponse {
+	return PreferenceResponse{
+		Type:  p.Type,
+		Title: notification.Subject(p.Type),
+		InApp: p.InApp,
+	}
+}
+
+// SetPreferenceBody sets whether a type adds items to the inbox.
+type SetPreferenceBody struct {
+	InApp *bool `json:"in_app" binding:"required"`
+}
@@ -0,0 +1,171 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nekogravitycat/court-booking-backend/internal/auth"
+	"github.com/nekogravitycat/court-booking-backend/internal/notification"
+	"github.com/nekogravitycat/court-booking-backend/internal/pkg/request"
+	"github.com/nekogravitycat/court-booking-backend/internal/pkg/response"
+)
+
+type Handler struct {
+	service notification.Service
+}
+
+func NewHandler(service notification.Service) *Handler {
+	return &Handler{service: service}
+}
+
+// List returns the caller's notifications, newest first, optionally only the
+// unread ones or those of one type.
+// Access Control: any authenticated user, for their own inbox.
+func (h *Handler) List(c *gin.Context) {
+	userID := auth.GetUserID(c)
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var req ListNotificationsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid query parameters", "details": err.Error()})
+		return
+	}
+
+	filter := notification.Filter{
+		UserID:     userID,
+		UnreadOnly: req.Unread,
+		Type:       req.Type,
+		Page:       req.Page,
+		PageSize:   req.PageSize,
+	}
+
+	list, total, err := h.service.List(c.Request.Context(), filter)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	items := make([]NotificationResponse, len(list))
+	for i, n := range list {
+		items[i] = NewNotificationResponse(n)
+	}
+
+	c.JSON(http.StatusOK, response.NewPageResponse(items, req.Page, req.PageSize, total))
+}
+
+// UnreadCount returns how many of the caller's notifications are unread.
+// Access Control: any authenticated user, for their own inbox.
+func (h *Handler) UnreadCount(c *gin.Context) {
+	userID := auth.GetUserID(c)
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	count, err := h.service.CountUnread(c.Request.Context(), userID)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, UnreadCountResponse{UnreadCount: count.Total, ByType: count.ByType})
+}
+
+// MarkRead marks one of the caller's notifications read.
+// Access Control: the notification's recipient.
+func (h *Handler) MarkRead(c *gin.Context) {
+	var uri request.ByIDRequest
+	if err := c.ShouldBindUri(&uri); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request", "details": err.Error()})
+		return
+	}
+
+	userID := auth.GetUserID(c)
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	n, err := h.service.MarkRead(c.Request.Context(), userID, uri.ID)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, NewNotificationResponse(n))
+}
+
+// MarkAllRead marks every unread notification of the caller read.
+// Access Control: any authenticated user, for their own inbox.
+func (h *Handler) MarkAllRead(c *gin.Context) {
+	userID := auth.GetUserID(c)
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	updated, err := h.service.MarkAllRead(c.Request.Context(), userID)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, MarkAllReadResponse{Updated: updated})
+}
+
+// ListPreferences returns the caller's preference for every notification type.
+// Access Control: any authenticated user, for their own preferences.
+func (h *Handler) ListPreferences(c *gin.Context) {
+	userID := auth.GetUserID(c)
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	prefs, err := h.service.ListPreferences(c.Request.Context(), userID)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	items := make([]PreferenceResponse, len(prefs))
+	for i, p := range prefs {
+		items[i] = NewPreferenceResponse(p)
+	}
+
+	c.JSON(http.StatusOK, items)
+}
+
+// SetPreference sets whether a notification type adds items to the caller's
+// inbox. Items already in the inbox are kept.
+// Access Control: any authenticated user, for their own preferences.
+func (h *Handler) SetPreference(c *gin.Context) {
+	var uri TypeURI
+	if err := c.ShouldBindUri(&uri); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request", "details": err.Error()})
+		return
+	}
+
+	userID := auth.GetUserID(c)
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var body SetPreferenceBody
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body", "details": err.Error()})
+		return
+	}
+
+	p := &notification.Preference{Type: uri.Type, InApp: *body.InApp}
+	if err := h.service.SetPreference(c.Request.Context(), userID, p); err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, NewPreferenceResponse(p))
+}
@@ -0,0 +1,25 @@
+package http
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterRoutes(g *gin.RouterGroup, h *Handler, authMiddleware gin.HandlerFunc) {
+	// Current user's in-app inbox
+	inboxGroup := g.Group("/me/notifications")
+	inboxGroup.Use(authMiddleware)
+	{
+		inboxGroup.GET("", h.List)
+		inboxGroup.GET("/unread-count", h.UnreadCount)
+		inboxGroup.POST("/read-all", h.MarkAllRead)
+		inboxGroup.POST("/:id/read", h.MarkRead)
+	}
+
+	// Current user's per-type preferences
+	prefsGroup := g.Group("/me/notification-preferences")
+	prefsGroup.Use(authMiddleware)
+	{
+		prefsGroup.GET("", h.ListPreferences)
+		prefsGroup.PUT("/:type", h.SetPreference)
+	}
+}
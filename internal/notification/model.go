@@ -0,0 +1,113 @@
+// Package notification delivers the events services raise for users. Events
+// are stored in an outbox with one delivery per configured channel, and a
+// background dispatcher sends the due deliveries, retrying failures with
+// exponential backoff.
+package notification
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/nekogravitycat/court-booking-backend/internal/pkg/apperror"
+)
+
+var (
+	ErrNotFound    = apperror.New(http.StatusNotFound, "notification not found")
+	ErrUnknownType = apperror.New(http.StatusBadRequest, "unknown notification type")
+)
+
+// Channel names stored on deliveries.
+const (
+	ChannelEmail   = "email"
+	ChannelWebhook = "webhook"
+	ChannelInApp   = "in_app"
+)
+
+type DeliveryStatus string
+
+const (
+	DeliveryStatusPending DeliveryStatus = "pending"
+	DeliveryStatusSent    DeliveryStatus = "sent"
+	DeliveryStatusFailed  DeliveryStatus = "failed"
+)
+
+// Retry policy of the dispatcher. The nth failed attempt is retried after
+// RetryBaseDelay * 2^(n-1), capped at RetryMaxDelay; a delivery is given up
+// after MaxAttempts attempts.
+const (
+	MaxAttempts    = 8
+	RetryBaseDelay = 30 * time.Second
+	RetryMaxDelay  = time.Hour
+)
+
+// claimLease is how long a claimed delivery stays hidden from other
+// dispatchers; an attempt that has not finished by then is tried again.
+const claimLease = 5 * time.Minute
+
+// dispatchBatchSize is the number of deliveries claimed, or of users a
+// broadcast is fanned out to, at a time.
+const dispatchBatchSize = 100
+
+// Message is one delivery of an event over one channel.
+type Message struct {
+	DeliveryID string
+	Channel    string
+	// Attempt is the 1-based number of this delivery attempt.
+	Attempt int
+
+	// EventID identifies the outbox event; it is the same on every channel and
+	// every retry, so receivers can use it to drop duplicates.
+	EventID    string
+	Type       string
+	UserID     string
+	Data       map[string]any
+	OccurredAt time.Time
+
+	// Email and DisplayName describe the recipient at delivery time.
+	Email       string
+	DisplayName *string
+}
+
+// retryDelay returns how long to wait after the given failed attempt.
+func retryDelay(attempt int) time.Duration {
+	d := RetryBaseDelay
+	for i := 1; i < attempt && d < RetryMaxDelay; i++ {
+		d *= 2
+	}
+	return min(d, RetryMaxDelay)
+}
+
+// Notification is an item in a user's in-app inbox.
+type Notification struct {
+	ID     string
+	UserID string
+	Type   string
+	Data   map[string]any
+	// CreatedAt is when the event occurred.
+	CreatedAt time.Time
+	// ReadAt is nil while the notification is unread.
+	ReadAt *time.Time
+}
+
+// Filter defines parameters for listing a user's notifications.
+type Filter struct {
+	UserID     string
+	UnreadOnly bool
+	Type       string
+	Page       int
+	PageSize   int
+}
+
+// UnreadCount is the number of a user's unread notifications, in total and
+// by type.
+type UnreadCount struct {
+	Total  int
+	ByType map[string]int
+}
+
+// Preference is a user's choice for one event type. Types the user never
+// chose are enabled.
+type Preference struct {
+	Type  string
+	InApp bool
+}
@@ -0,0 +1,75 @@
+package notification
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/nekogravitycat/court-booking-backend/internal/announcement"
+	"github.com/nekogravitycat/court-booking-backend/internal/booking"
+	"github.com/nekogravitycat/court-booking-backend/internal/organization"
+	"github.com/nekogravitycat/court-booking-backend/internal/pickup"
+	"github.com/nekogravitycat/court-booking-backend/internal/user"
+)
+
+// subjects are the human-readable titles of the known event types. Unknown
+// types fall back to the type itself.
+var subjects = map[string]string{
+	announcement.EventPublished:          "New announcement",
+	booking.EventBookingConfirmed:        "Your booking is confirmed",
+	booking.EventBookingCancelled:        "Your booking was cancelled",
+	booking.EventBookingReminder:         "Reminder: your booking starts soon",
+	pickup.EventOrderConfirmed:           "Your pickup enrollment is confirmed",
+	pickup.EventOrderRejected:            "Your pickup enrollment was declined",
+	pickup.EventOrderCancelled:           "Your pickup enrollment was cancelled",
+	pickup.EventOrderReminder:            "Reminder: your pickup game starts soon",
+	pickup.EventGroupCancelled:           "A pickup game you joined was cancelled",
+	pickup.EventGroupChanged:             "A pickup game you joined has changed",
+	pickup.EventFavoriteHostGroupCreated: "A host you follow posted a new pickup game",
+	organization.EventMemberAdded:        "You were added to an organization",
+	organization.EventManagerAdded:       "You are now an organization manager",
+	user.EventRegistered:                 "Welcome to Court Booking",
+	user.EventPickupHostGranted:          "You can now host pickup games",
+}
+
+// Types returns the known event types, which are the types users can set
+// preferences for, in sorted order.
+func Types() []string {
+	types := make([]string, 0, len(subjects))
+	for t := range subjects {
+		types = append(types, t)
+	}
+	slices.Sort(types)
+	return types
+}
+
+// Subject returns the human-readable title of an event type.
+func Subject(eventType string) string {
+	if subject, ok := subjects[eventType]; ok {
+		return subject
+	}
+	return eventType
+}
+
+// render returns the subject and plain-text body of a message.
+func render(m *Message) (string, string) {
+	subject := Subject(m.Type)
+
+	var b strings.Builder
+	name := m.Email
+	if m.DisplayName != nil && *m.DisplayName != "" {
+		name = *m.DisplayName
+	}
+	fmt.Fprintf(&b, "Hi %s,\n\n%s.\n\n", name, subject)
+
+	keys := make([]string, 0, len(m.Data))
+	for k := range m.Data {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	for _, k := range keys {
+		fmt.Fprintf(&b, "%s: %v\n", k, m.Data[k])
+	}
+	fmt.Fprintf(&b, "\nSent for an event at %s.\n", m.OccurredAt.UTC().Format("2006-01-02 15:04 MST"))
+	return subject, b.String()
+}
@@ -0,0 +1,433 @@
+package notification
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/nekogravitycat/court-booking-backend/internal/pkg/event"
+)
+
+// Repository defines persistence for the notification outbox.
+type Repository interface {
+	// Enqueue stores the events in the outbox with a pending delivery per
+	// channel, within the caller's transaction.
+	Enqueue(ctx context.Context, tx pgx.Tx, events []event.Event, channels []string) error
+	// ClaimDue claims up to limit pending deliveries that are due, counting
+	// the attempt and hiding them from other dispatchers for lease.
+	ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]*Message, error)
+	MarkSent(ctx context.Context, deliveryID string) error
+	// MarkRetry records a failed attempt and reschedules the delivery for at.
+	MarkRetry(ctx context.Context, deliveryID, lastError string, at time.Time) error
+	// MarkFailed records a failed attempt and gives up on the delivery.
+	MarkFailed(ctx context.Context, deliveryID, lastError string) error
+
+	// InsertInboxItem adds the message to its user's in-app inbox unless the
+	// user turned its type off. Inserting the same event again is a no-op.
+	InsertInboxItem(ctx context.Context, m *Message) error
+	// InsertBroadcast stores an event for every user, to be fanned out to
+	// their inboxes by FanOutBroadcast.
+	InsertBroadcast(ctx context.Context, e event.Event) error
+	// FanOutBroadcast adds the oldest pending broadcast to the inboxes of up
+	// to limit more users, skipping inactive users and those who turned its
+	// type off. It reports false when no broadcast is pending.
+	FanOutBroadcast(ctx context.Context, limit int) (bool, error)
+
+	List(ctx context.Context, filter Filter) ([]*Notification, int, error)
+	CountUnread(ctx context.Context, userID string) (*UnreadCount, error)
+	// MarkRead marks one of the user's notifications read; reading it again
+	// keeps the first read time.
+	MarkRead(ctx context.Context, userID, id string) (*Notification, error)
+	// MarkAllRead marks every unread notification of the user read, returning
+	// how many there were.
+	MarkAllRead(ctx context.Context, userID string) (int64, error)
+
+	// ListPreferences returns the preferences the user has set explicitly.
+	ListPreferences(ctx context.Context, userID string) ([]*Preference, error)
+	SetPreference(ctx context.Context, userID string, p *Preference) error
+}
+
+type pgxRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewPgxRepository(pool *pgxpool.Pool) Repository {
+	return &pgxRepository{pool: pool}
+}
+
+func (r *pgxRepository) Enqueue(ctx context.Context, tx pgx.Tx, events []event.Event, channels []string) error {
+	for _, e := range events {
+		data, err := json.Marshal(e.Data)
+		if err != nil {
+			return fmt.Errorf("marshal %s event data failed: %w", e.Type, err)
+		}
+		if e.Data == nil {
+			data = []byte("{}")
+		}
+
+		var id string
+		if err := tx.QueryRow(ctx, `
+			INSERT INTO public.notification_outbox (user_id, type, data, occurred_at)
+			VALUES ($1, $2, $3, $4)
+			RETURNING id`,
+			e.UserID, e.Type, data, e.OccurredAt,
+		).Scan(&id); err != nil {
+			return fmt.Errorf("insert notification outbox failed: %w", err)
+		}
+
+		if _, err := tx.Exec(ctx, `
+			INSERT INTO public.notification_deliveries (outbox_id, channel)
+			SELECT $1, unnest($2::text[])`,
+			id, channels,
+		); err != nil {
+			return fmt.Errorf("insert notification deliveries failed: %w", err)
+		}
+	}
+	return nil
+}
+
+func (r *pgxRepository) ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]*Message, error) {
+	rows, err := r.pool.Query(ctx, `
+		WITH due AS (
+			SELECT id FROM public.notification_deliveries
+			WHERE status = 'pending' AND next_attempt_at <= now()
+			ORDER BY next_attempt_at
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		), claimed AS (
+			UPDATE public.notification_deliveries d
+			SET attempts = d.attempts + 1, next_attempt_at = now() + make_interval(secs => $2), updated_at = now()
+			FROM due
+			WHERE d.id = due.id
+			RETURNING d.id, d.outbox_id, d.channel, d.attempts
+		)
+		SELECT c.id, c.channel, c.attempts, o.id, o.type, o.user_id, o.data, o.occurred_at,
+		       u.email, u.display_name
+		FROM claimed c
+		JOIN public.notification_outbox o ON o.id = c.outbox_id
+		JOIN public.users u ON u.id = o.user_id
+		ORDER BY o.occurred_at`,
+		limit, lease.Seconds(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("claim notification deliveries failed: %w", err)
+	}
+	defer rows.Close()
+
+	var messages []*Message
+	for rows.Next() {
+		var m Message
+		if err := rows.Scan(&m.DeliveryID, &m.Channel, &m.Attempt, &m.EventID, &m.Type, &m.UserID,
+			&m.Data, &m.OccurredAt, &m.Email, &m.DisplayName); err != nil {
+			return nil, fmt.Errorf("scan notification delivery failed: %w", err)
+		}
+		messages = append(messages, &m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate notification deliveries failed: %w", err)
+	}
+	return messages, nil
+}
+
+func (r *pgxRepository) MarkSent(ctx context.Context, deliveryID string) error {
+	if _, err := r.pool.Exec(ctx, `
+		UPDATE public.notification_deliveries
+		SET status = 'sent', sent_at = now(), last_error = NULL, updated_at = now()
+		WHERE id = $1`,
+		deliveryID,
+	); err != nil {
+		return fmt.Errorf("mark notification delivery sent failed: %w", err)
+	}
+	return nil
+}
+
+func (r *pgxRepository) MarkRetry(ctx context.Context, deliveryID, lastError string, at time.Time) error {
+	if _, err := r.pool.Exec(ctx, `
+		UPDATE public.notification_deliveries
+		SET next_attempt_at = $2, last_error = $3, updated_at = now()
+		WHERE id = $1`,
+		deliveryID, at, lastError,
+	); err != nil {
+		return fmt.Errorf("reschedule notification delivery failed: %w", err)
+	}
+	return nil
+}
+
+func (r *pgxRepository) MarkFailed(ctx context.Context, deliveryID, lastError string) error {
+	if _, err := r.pool.Exec(ctx, `
+		UPDATE public.notification_deliveries
+		SET status = 'failed', last_error = $2, updated_at = now()
+		WHERE id = $1`,
+		deliveryID, lastError,
+	); err != nil {
+		return fmt.Errorf("give up notification delivery failed: %w", err)
+	}
+	return nil
+}
+
+func (r *pgxRepository) InsertInboxItem(ctx context.Context, m *Message) error {
+	data, err := json.Marshal(m.Data)
+	if err != nil {
+		return fmt.Errorf("marshal %s notification data failed: %w", m.Type, err)
+	}
+	if m.Data == nil {
+		data = []byte("{}")
+	}
+	if _, err := r.pool.Exec(ctx, `
+		INSERT INTO public.notifications (user_id, outbox_id, type, data, created_at)
+		SELECT $1::uuid, $2::uuid, $3::text, $4::jsonb, $5::timestamptz
+		WHERE NOT EXISTS (
+			SELECT 1 FROM public.notification_preferences p
+			WHERE p.user_id = $1 AND p.type = $3 AND NOT p.in_app
+		)
+		ON CONFLICT (outbox_id) DO NOTHING`,
+		m.UserID, m.EventID, m.Type, data, m.OccurredAt,
+	); err != nil {
+		return fmt.Errorf("insert notification failed: %w", err)
+	}
+	return nil
+}
+
+func (r *pgxRepository) InsertBroadcast(ctx context.Context, e event.Event) error {
+	data, err := json.Marshal(e.Data)
+	if err != nil {
+		return fmt.Errorf("marshal %s event data failed: %w", e.Type, err)
+	}
+	if e.Data == nil {
+		data = []byte("{}")
+	}
+	if _, err := r.pool.Exec(ctx, `
+		INSERT INTO public.notification_broadcasts (type, data, occurred_at)
+		VALUES ($1, $2, $3)`,
+		e.Type, data, e.OccurredAt,
+	); err != nil {
+		return fmt.Errorf("insert notification broadcast failed: %w", err)
+	}
+	return nil
+}
+
+func (r *pgxRepository) FanOutBroadcast(ctx context.Context, limit int) (bool, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return false, fmt.Errorf("begin transaction failed: %w", err)
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck
+
+	var id string
+	err = tx.QueryRow(ctx, `
+		SELECT id FROM public.notification_broadcasts
+		WHERE completed_at IS NULL
+		ORDER BY created_at
+		LIMIT 1
+		FOR UPDATE SKIP LOCKED`,
+	).Scan(&id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("claim notification broadcast failed: %w", err)
+	}
+
+	// The batch walks the users registered before the broadcast in id order;
+	// the last id seen becomes the cursor for the next batch.
+	var handled int
+	var last *string
+	if err := tx.QueryRow(ctx, `
+		WITH b AS (
+			SELECT id, type, data, occurred_at, cursor_user_id, created_at
+			FROM public.notification_broadcasts WHERE id = $1
+		), batch AS (
+			SELECT u.id, u.is_active
+			FROM public.users u, b
+			WHERE u.created_at <= b.created_at
+			  AND (b.cursor_user_id IS NULL OR u.id > b.cursor_user_id)
+			ORDER BY u.id
+			LIMIT $2
+		), added AS (
+			INSERT INTO public.notifications (user_id, type, data, created_at)
+			SELECT batch.id, b.type, b.data, b.occurred_at
+			FROM batch, b
+			WHERE batch.is_active
+			  AND NOT EXISTS (
+				SELECT 1 FROM public.notification_preferences p
+				WHERE p.user_id = batch.id AND p.type = b.type AND NOT p.in_app
+			  )
+		)
+		SELECT count(*), (SELECT id FROM batch ORDER BY id DESC LIMIT 1)
+		FROM batch`,
+		id, limit,
+	).Scan(&handled, &last); err != nil {
+		return false, fmt.Errorf("fan out notification broadcast failed: %w", err)
+	}
+
+	if _, err := tx.Exec(ctx, `
+		UPDATE public.notification_broadcasts
+		SET cursor_user_id = COALESCE($2, cursor_user_id),
+		    completed_at = CASE WHEN $3 THEN now() END
+		WHERE id = $1`,
+		id, last, handled < limit,
+	); err != nil {
+		return false, fmt.Errorf("advance notification broadcast failed: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return false, fmt.Errorf("commit transaction failed: %w", err)
+	}
+	return true, nil
+}
+
+func (r *pgxRepository) List(ctx context.Context, filter Filter) ([]*Notification, int, error) {
+	psql := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
+	query := psql.Select("id", "user_id", "type", "data", "created_at", "read_at", "count(*) OVER() as total_count").
+		From("public.notifications").
+		Where(squirrel.Eq{"user_id": filter.UserID})
+
+	if filter.UnreadOnly {
+		query = query.Where("read_at IS NULL")
+	}
+	if filter.Type != "" {
+		query = query.Where(squirrel.Eq{"type": filter.Type})
+	}
+
+	// Pagination
+	if filter.Page < 1 {
+		filter.Page = 1
+	}
+	if filter.PageSize < 1 {
+		filter.PageSize = 20
+	}
+	offset := (filter.Page - 1) * filter.PageSize
+
+	query = query.OrderBy("created_at DESC", "id DESC").
+		Limit(uint64(filter.PageSize)).Offset(uint64(offset))
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, 0, fmt.Errorf("build list notifications query failed: %w", err)
+	}
+
+	rows, err := r.pool.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("list notifications failed: %w", err)
+	}
+	defer rows.Close()
+
+	var result []*Notification
+	var total int
+	for rows.Next() {
+		var n Notification
+		if err := rows.Scan(&n.ID, &n.UserID, &n.Type, &n.Data, &n.CreatedAt, &n.ReadAt, &total); err != nil {
+			return nil, 0, fmt.Errorf("scan notification failed: %w", err)
+		}
+		result = append(result, &n)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("iterate notifications failed: %w", err)
+	}
+	return result, total, nil
+}
+
+func (r *pgxRepository) CountUnread(ctx context.Context, userID string) (*UnreadCount, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT type, COUNT(*)
+		FROM public.notifications
+		WHERE user_id = $1 AND read_at IS NULL
+		GROUP BY type`,
+		userID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("count unread notifications failed: %w", err)
+	}
+	defer rows.Close()
+
+	count := &UnreadCount{ByType: make(map[string]int)}
+	for rows.Next() {
+		var typ string
+		var n int
+		if err := rows.Scan(&typ, &n); err != nil {
+			return nil, fmt.Errorf("scan unread notification count failed: %w", err)
+		}
+		count.ByType[typ] = n
+		count.Total += n
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate unread notification counts failed: %w", err)
+	}
+	return count, nil
+}
+
+func (r *pgxRepository) MarkRead(ctx context.Context, userID, id string) (*Notification, error) {
+	var n Notification
+	err := r.pool.QueryRow(ctx, `
+		UPDATE public.notifications
+		SET read_at = COALESCE(read_at, now())
+		WHERE id = $1 AND user_id = $2
+		RETURNING id, user_id, type, data, created_at, read_at`,
+		id, userID,
+	).Scan(&n.ID, &n.UserID, &n.Type, &n.Data, &n.CreatedAt, &n.ReadAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("mark notification read failed: %w", err)
+	}
+	return &n, nil
+}
+
+func (r *pgxRepository) MarkAllRead(ctx context.Context, userID string) (int64, error) {
+	ct, err := r.pool.Exec(ctx, `
+		UPDATE public.notifications
+		SET read_at = now()
+		WHERE user_id = $1 AND read_at IS NULL`,
+		userID,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("mark all notifications read failed: %w", err)
+	}
+	return ct.RowsAffected(), nil
+}
+
+func (r *pgxRepository) ListPreferences(ctx context.Context, userID string) ([]*Preference, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT type, in_app
+		FROM public.notification_preferences
+		WHERE user_id = $1`,
+		userID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("list notification preferences failed: %w", err)
+	}
+	defer rows.Close()
+
+	var prefs []*Preference
+	for rows.Next() {
+		var p Preference
+		if err := rows.Scan(&p.Type, &p.InApp); err != nil {
+			return nil, fmt.Errorf("scan notification preference failed: %w", err)
+		}
+		prefs = append(prefs, &p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate notification preferences failed: %w", err)
+	}
+	return prefs, nil
+}
+
+func (r *pgxRepository) SetPreference(ctx context.Context, userID string, p *Preference) error {
+	if _, err := r.pool.Exec(ctx, `
+		INSERT INTO public.notification_preferences (user_id, type, in_app)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (user_id, type) DO UPDATE
+		SET in_app = EXCLUDED.in_app, updated_at = now()`,
+		userID, p.Type, p.InApp,
+	); err != nil {
+		return fmt.Errorf("set notification preference failed: %w", err)
+	}
+	return nil
+}
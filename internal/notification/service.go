@@ -0,0 +1,164 @@
+package notification
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"slices"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/nekogravitycat/court-booking-backend/internal/pkg/event"
+)
+
+// Service is the notification outbox. It is the event.Publisher the other
+// services publish to, and dispatches the stored deliveries in the background.
+// It also serves each user's in-app inbox and notification preferences.
+type Service interface {
+	// Publish stores the events in the outbox with one delivery per channel,
+	// within the caller's transaction.
+	Publish(ctx context.Context, tx pgx.Tx, events ...event.Event) error
+	// DispatchDue fans out pending broadcasts, then sends every delivery that
+	// is due. Failed deliveries are rescheduled with backoff, or marked failed
+	// after MaxAttempts.
+	DispatchDue(ctx context.Context) error
+	// Broadcast stores an event for all users, such as a new announcement.
+	// DispatchDue later adds it to every inbox that accepts its type. It
+	// bypasses the outbox: broadcasts are in-app only. e.UserID is ignored.
+	Broadcast(ctx context.Context, e event.Event) error
+
+	List(ctx context.Context, filter Filter) ([]*Notification, int, error)
+	CountUnread(ctx context.Context, userID string) (*UnreadCount, error)
+	MarkRead(ctx context.Context, userID, id string) (*Notification, error)
+	MarkAllRead(ctx context.Context, userID string) (int64, error)
+	// ListPreferences returns the user's preference for every known type.
+	ListPreferences(ctx context.Context, userID string) ([]*Preference, error)
+	SetPreference(ctx context.Context, userID string, p *Preference) error
+}
+
+type service struct {
+	repo     Repository
+	channels map[string]Channel
+	names    []string
+}
+
+// NewService creates the notification service delivering over channels.
+func NewService(repo Repository, channels ...Channel) Service {
+	s := &service{repo: repo, channels: make(map[string]Channel, len(channels))}
+	for _, c := range channels {
+		s.channels[c.Name()] = c
+		s.names = append(s.names, c.Name())
+	}
+	return s
+}
+
+func (s *service) Publish(ctx context.Context, tx pgx.Tx, events ...event.Event) error {
+	if len(events) == 0 || len(s.names) == 0 {
+		return nil
+	}
+	return s.repo.Enqueue(ctx, tx, events, s.names)
+}
+
+func (s *service) DispatchDue(ctx context.Context) error {
+	for {
+		pending, err := s.repo.FanOutBroadcast(ctx, dispatchBatchSize)
+		if err != nil {
+			return err
+		}
+		if !pending {
+			break
+		}
+	}
+
+	for {
+		batch, err := s.repo.ClaimDue(ctx, dispatchBatchSize, claimLease)
+		if err != nil {
+			return err
+		}
+		for _, m := range batch {
+			if err := s.deliver(ctx, m); err != nil {
+				return err
+			}
+		}
+		if len(batch) < dispatchBatchSize {
+			return nil
+		}
+	}
+}
+
+// deliver sends one claimed message and records the outcome. Only a failure
+// to record the outcome is returned; the claim lease covers that case.
+func (s *service) deliver(ctx context.Context, m *Message) error {
+	channel, ok := s.channels[m.Channel]
+	if !ok {
+		// The channel was configured when the event was stored but is not
+		// any more; there is nothing to retry with.
+		return s.repo.MarkFailed(ctx, m.DeliveryID, fmt.Sprintf("channel %s is not configured", m.Channel))
+	}
+
+	sendErr := channel.Send(ctx, m)
+	if sendErr == nil {
+		return s.repo.MarkSent(ctx, m.DeliveryID)
+	}
+	if ctx.Err() != nil {
+		// Shutting down: leave the attempt to be retried once the lease lapses.
+		return ctx.Err()
+	}
+
+	if m.Attempt >= MaxAttempts {
+		log.Printf("notification: giving up %s delivery %s of %s after %d attempts: %v",
+			m.Channel, m.DeliveryID, m.Type, m.Attempt, sendErr)
+		return s.repo.MarkFailed(ctx, m.DeliveryID, sendErr.Error())
+	}
+	return s.repo.MarkRetry(ctx, m.DeliveryID, sendErr.Error(), time.Now().Add(retryDelay(m.Attempt)))
+}
+
+func (s *service) Broadcast(ctx context.Context, e event.Event) error {
+	return s.repo.InsertBroadcast(ctx, e)
+}
+
+func (s *service) List(ctx context.Context, filter Filter) ([]*Notification, int, error) {
+	if filter.Type != "" && !slices.Contains(Types(), filter.Type) {
+		return nil, 0, ErrUnknownType
+	}
+	return s.repo.List(ctx, filter)
+}
+
+func (s *service) CountUnread(ctx context.Context, userID string) (*UnreadCount, error) {
+	return s.repo.CountUnread(ctx, userID)
+}
+
+func (s *service) MarkRead(ctx context.Context, userID, id string) (*Notification, error) {
+	return s.repo.MarkRead(ctx, userID, id)
+}
+
+func (s *service) MarkAllRead(ctx context.Context, userID string) (int64, error) {
+	return s.repo.MarkAllRead(ctx, userID)
+}
+
+func (s *service) ListPreferences(ctx context.Context, userID string) ([]*Preference, error) {
+	stored, err := s.repo.ListPreferences(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	inApp := make(map[string]bool, len(stored))
+	for _, p := range stored {
+		inApp[p.Type] = p.InApp
+	}
+
+	types := Types()
+	prefs := make([]*Preference, len(types))
+	for i, t := range types {
+		enabled, ok := inApp[t]
+		prefs[i] = &Preference{Type: t, InApp: enabled || !ok}
+	}
+	return prefs, nil
+}
+
+func (s *service) SetPreference(ctx context.Context, userID string, p *Preference) error {
+	if !slices.Contains(Types(), p.Type) {
+		return ErrUnknownType
+	}
+	return s.repo.SetPreference(ctx, userID, p)
+}
@@ -17,6 +17,12 @@ var (
 	ErrInvalidRole       = apperror.New(http.StatusBadRequest, "invalid role")
 )
 
+// Events published to a user given a role in an organization.
+const (
+	EventMemberAdded  = "organization.member_added"
+	EventManagerAdded = "organization.manager_added"
+)
+
 // Organization represents a venue owner or brand entity.
 type Organization struct {
 	ID        string
@@ -10,6 +10,7 @@ import (
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/nekogravitycat/court-booking-backend/internal/pkg/event"
 	"github.com/nekogravitycat/court-booking-backend/internal/user"
 )
 
@@ -22,12 +23,12 @@ type Repository interface {
 	Update(ctx context.Context, org *Organization) error
 	Delete(ctx context.Context, id string) error
 	// Organization Manager methods
-	AddOrganizationManager(ctx context.Context, orgID string, userID string) error
+	AddOrganizationManager(ctx context.Context, orgID string, userID string, publish event.Hook) error
 	RemoveOrganizationManager(ctx context.Context, orgID string, userID string) error
 	IsOrganizationManager(ctx context.Context, orgID string, userID string) (bool, error)
 	ListOrganizationManagers(ctx context.Context, orgID string, filter ManagerFilter) ([]*user.User, int, error)
 	// Organization Member methods
-	AddMember(ctx context.Context, orgID string, userID string) error
+	AddMember(ctx context.Context, orgID string, userID string, publish event.Hook) error
 	RemoveMember(ctx context.Context, orgID string, userID string) error
 	IsMember(ctx context.Context, orgID string, userID string) (bool, error)
 	ListMembers(ctx context.Context, orgID string, filter ManagerFilter) ([]*user.User, int, error)
@@ -187,7 +188,7 @@ func (r *pgxRepository) Delete(ctx context.Context, id string) error {
 //   Organization Manager methods
 // -----------------------------
 
-func (r *pgxRepository) AddOrganizationManager(ctx context.Context, orgID string, userID string) error {
+func (r *pgxRepository) AddOrganizationManager(ctx context.Context, orgID string, userID string, publish event.Hook) error {
 	psql := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
 	query, args, err := psql.Insert("public.organization_managers").
 		Columns("organization_id", "user_id").
@@ -197,7 +198,13 @@ func (r *pgxRepository) AddOrganizationManager(ctx context.Context, orgID string
 		return fmt.Errorf("build add org manager query failed: %w", err)
 	}
 
-	_, err = r.pool.Exec(ctx, query, args...)
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin transaction failed: %w", err)
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck
+
+	_, err = tx.Exec(ctx, query, args...)
 	if err != nil {
 		var pgErr *pgconn.PgError
 		if errors.As(err, &pgErr) {
@@ -207,7 +214,11 @@ func (r *pgxRepository) AddOrganizationManager(ctx context.Context, orgID string
 		}
 		return fmt.Errorf("AddOrganizationManager failed: %w", err)
 	}
-	return nil
+
+	if err := publish.Run(tx); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
 }
 
 func (r *pgxRepository) RemoveOrganizationManager(ctx context.Context, orgID string, userID string) error {
@@ -333,7 +344,7 @@ func (r *pgxRepository) ListOrganizationManagers(ctx context.Context, orgID stri
 //   Organization Member methods
 // -----------------------------
 
-func (r *pgxRepository) AddMember(ctx context.Context, orgID string, userID string) error {
+func (r *pgxRepository) AddMember(ctx context.Context, orgID string, userID string, publish event.Hook) error {
 	psql := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
 	query, args, err := psql.Insert("public.organization_members").
 		Columns("organization_id", "user_id").
@@ -344,11 +355,21 @@ func (r *pgxRepository) AddMember(ctx context.Context, orgID string, userID stri
 		return fmt.Errorf("build add member query failed: %w", err)
 	}
 
-	_, err = r.pool.Exec(ctx, query, args...)
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin transaction failed: %w", err)
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck
+
+	_, err = tx.Exec(ctx, query, args...)
 	if err != nil {
 		return fmt.Errorf("AddMember failed: %w", err)
 	}
-	return nil
+
+	if err := publish.Run(tx); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
 }
 
 func (r *pgxRepository) RemoveMember(ctx context.Context, orgID string, userID string) error {
@@ -4,9 +4,13 @@ import (
 	"context"
 	"errors"
 	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
 
 	"github.com/nekogravitycat/court-booking-backend/internal/file"
 	"github.com/nekogravitycat/court-booking-backend/internal/pkg/apperror"
+	"github.com/nekogravitycat/court-booking-backend/internal/pkg/event"
 	"github.com/nekogravitycat/court-booking-backend/internal/user"
 )
 
@@ -62,11 +66,29 @@ type service struct {
 	userService user.Service
 	locChecker  LocationManagerChecker
 	fileService file.Service
+	publisher   event.Publisher
 }
 
 // NewService creates a new organization service.
-func NewService(repo Repository, userService user.Service, locChecker LocationManagerChecker, fileService file.Service) Service {
-	return &service{repo: repo, userService: userService, locChecker: locChecker, fileService: fileService}
+func NewService(repo Repository, userService user.Service, locChecker LocationManagerChecker, fileService file.Service, publisher event.Publisher) Service {
+	return &service{repo: repo, userService: userService, locChecker: locChecker, fileService: fileService, publisher: publisher}
+}
+
+// publishRole returns the hook telling a user they were given a role in the
+// organization.
+func (s *service) publishRole(ctx context.Context, eventType string, org *Organization, userID string) event.Hook {
+	e := event.Event{
+		Type:   eventType,
+		UserID: userID,
+		Data: map[string]any{
+			"organization_id":   org.ID,
+			"organization_name": org.Name,
+		},
+		OccurredAt: time.Now(),
+	}
+	return func(tx pgx.Tx) error {
+		return s.publisher.Publish(ctx, tx, e)
+	}
 }
 
 // ------------------------
@@ -264,7 +286,7 @@ func (s *service) AddOrganizationManager(ctx context.Context, orgID string, user
 		return apperror.New(400, "user must be a member of the organization first")
 	}
 
-	return s.repo.AddOrganizationManager(ctx, orgID, userID)
+	return s.repo.AddOrganizationManager(ctx, orgID, userID, s.publishRole(ctx, EventManagerAdded, org, userID))
 }
 
 func (s *service) RemoveOrganizationManager(ctx context.Context, orgID string, userID string) error {
@@ -311,7 +333,7 @@ func (s *service) AddMember(ctx context.Context, orgID string, email string) err
 		return apperror.New(409, "user is the owner of this organization")
 	}
 
-	return s.repo.AddMember(ctx, orgID, userID)
+	return s.repo.AddMember(ctx, orgID, userID, s.publishRole(ctx, EventMemberAdded, org, userID))
 }
 
 func (s *service) RemoveMember(ctx context.Context, orgID string, userID string) error {
@@ -9,6 +9,8 @@ import (
 	sportsHttp "github.com/nekogravitycat/court-booking-backend/internal/sports/http"
 )
 
+const dateLayout = "2006-01-02"
+
 // --- Request types ---
 
 type ListGroupsRequest struct {
@@ -17,15 +19,53 @@ type ListGroupsRequest struct {
 	SportID      string `form:"sport_id" binding:"omitempty,uuid"`
 	SkillLevelID string `form:"skill_level_id" binding:"omitempty,uuid"`
 	HostID       string `form:"host_id" binding:"omitempty,uuid"`
-	SortBy       string `form:"sort_by" binding:"omitempty,oneof=start_time created_at"`
+	SortBy       string `form:"sort_by" binding:"omitempty,oneof=start_time created_at host_rating distance"`
+	// Lat and Lng locate the searcher; RadiusKm and sort_by=distance need them.
+	Lat       *float64   `form:"lat" binding:"omitempty,min=-90,max=90"`
+	Lng       *float64   `form:"lng" binding:"omitempty,min=-180,max=180"`
+	RadiusKm  float64    `form:"radius_km" binding:"omitempty,gt=0,max=500"`
+	StartFrom *time.Time `form:"start_from" time_format:"2006-01-02T15:04:05Z07:00"`
+	StartTo   *time.Time `form:"start_to" time_format:"2006-01-02T15:04:05Z07:00"`
+	MaxFee    *int       `form:"max_fee" binding:"omitempty,min=0"`
+	// MinFreeSeats only returns groups with at least this many open seats.
+	MinFreeSeats int `form:"min_free_seats" binding:"min=0"`
+}
+
+// Validate performs custom validation for ListGroupsRequest.
+func (r *ListGroupsRequest) Validate() error {
+	if (r.Lat == nil) != (r.Lng == nil) {
+		return pickup.ErrInvalidGeoFilter
+	}
+	if r.Lat == nil && (r.RadiusKm > 0 || r.SortBy == "distance") {
+		return pickup.ErrInvalidGeoFilter
+	}
+	if r.StartFrom != nil && r.StartTo != nil && r.StartFrom.After(*r.StartTo) {
+		return pickup.ErrInvalidTimeRange
+	}
+	return nil
+}
+
+// Near returns the search point, or nil when no location was given.
+func (r *ListGroupsRequest) Near() *pickup.GeoPoint {
+	if r.Lat == nil || r.Lng == nil {
+		return nil
+	}
+	return &pickup.GeoPoint{Latitude: *r.Lat, Longitude: *r.Lng}
 }
 
 type GetGroupQuery struct {
 	IncludeOrders bool `form:"include_orders"`
+	// InviteCode grants read access to a private group.
+	InviteCode string `form:"invite_code"`
+}
+
+// InviteCodeURI binds the code path parameter for GET /pickup-invites/{code}.
+type InviteCodeURI struct {
+	Code string `uri:"code" binding:"required"`
 }
 
 // HostGroupsURI binds the host_id path parameter for
-// GET /hosts/{host_id}/pickup-groups.
+// GET /hosts/{host_id}/pickup-groups and GET /hosts/{host_id}/reviews.
 type HostGroupsURI struct {
 	HostID string `uri:"host_id" binding:"required,uuid"`
 }
@@ -40,6 +80,29 @@ type CreateGroupBody struct {
 	SportID      string    `json:"sport_id" binding:"required,uuid"`
 	SkillLevelID string    `json:"skill_level_id" binding:"required,uuid"`
 	Enable       *bool     `json:"enable"`
+	// MinParticipants auto-cancels the group if it is below this headcount at
+	// the cutoff before start; 0 disables it.
+	MinParticipants int `json:"min_participants" binding:"min=0"`
+	// MinReliability is the attendance reliability score (0-100) required to
+	// enroll; 0 disables it.
+	MinReliability int `json:"min_reliability" binding:"min=0,max=100"`
+	// Visibility defaults to public.
+	Visibility string `json:"visibility" binding:"omitempty,oneof=public unlisted private"`
+	// MinSkillLevelID and MaxSkillLevelID restrict enrollment to a skill-level
+	// range of the group's sport; either may be omitted.
+	MinSkillLevelID *string `json:"min_skill_level_id" binding:"omitempty,uuid"`
+	MaxSkillLevelID *string `json:"max_skill_level_id" binding:"omitempty,uuid"`
+	// MaxGuestsPerOrder caps the guests each order may bring; omit for no cap,
+	// 0 disallows guests.
+	MaxGuestsPerOrder *int `json:"max_guests_per_order" binding:"omitempty,min=0"`
+	// EnrollmentDeadline closes enrollment and CancelDeadline ends free
+	// cancellation; both are optional and must not be after start_time.
+	EnrollmentDeadline *time.Time `json:"enrollment_deadline"`
+	CancelDeadline     *time.Time `json:"cancel_deadline"`
+	// ResourceID books the court for the group; BookingID links an existing
+	// booking of the host's instead.
+	ResourceID *string `json:"resource_id" binding:"omitempty,uuid"`
+	BookingID  *string `json:"booking_id" binding:"omitempty,uuid"`
 }
 
 func (r *CreateGroupBody) Validate() error {
@@ -49,59 +112,209 @@ func (r *CreateGroupBody) Validate() error {
 	return nil
 }
 
+// CreateOrderQuery holds the optional query flags for
+// POST /pickup-groups/{id}/orders.
+type CreateOrderQuery struct {
+	// JoinWaitlist queues the enrollment on a full group instead of failing.
+	JoinWaitlist bool `form:"join_waitlist"`
+	// InviteCode is required to enroll in a private group.
+	InviteCode string `form:"invite_code"`
+}
+
+// CreateOrderBody is the optional JSON body of POST /pickup-groups/{id}/orders
+// for enrolling with guests. Without it the order takes a single seat.
+type CreateOrderBody struct {
+	// Seats counts the caller plus guests.
+	Seats      int      `json:"seats" binding:"omitempty,min=1"`
+	GuestNames []string `json:"guest_names" binding:"omitempty,dive,max=100"`
+}
+
 type UpdateOrderBody struct {
 	Status        *string `json:"status" binding:"omitempty,oneof=pending confirmed cancelled cancel_request rejected"`
 	PaymentStatus *string `json:"payment_status" binding:"omitempty,oneof=done pending failed"`
 }
 
+// ListReviewsRequest pages through a host's reviews (always newest first).
+type ListReviewsRequest struct {
+	Page     int `form:"page,default=1" binding:"min=1"`
+	PageSize int `form:"page_size,default=20" binding:"min=1,max=100"`
+}
+
+type CreateReviewBody struct {
+	Rating  int     `json:"rating" binding:"required,min=1,max=5"`
+	Comment *string `json:"comment" binding:"omitempty,max=1000"`
+}
+
+// CoHostURI binds the path parameters of a group co-host.
+type CoHostURI struct {
+	ID     string `uri:"id" binding:"required,uuid"`
+	UserID string `uri:"user_id" binding:"required,uuid"`
+}
+
+// CommentURI binds the path parameters of a single comment in a group's thread.
+type CommentURI struct {
+	ID        string `uri:"id" binding:"required,uuid"`
+	CommentID string `uri:"comment_id" binding:"required,uuid"`
+}
+
+// ListCommentsRequest pages through a group's comment thread.
+type ListCommentsRequest struct {
+	Page     int `form:"page,default=1" binding:"min=1"`
+	PageSize int `form:"page_size,default=20" binding:"min=1,max=100"`
+}
+
+type CreateCommentBody struct {
+	Body string `json:"body" binding:"required,max=2000"`
+}
+
+type UpdateCommentBody struct {
+	Pinned *bool `json:"pinned" binding:"required"`
+}
+
+// HostStatsQuery selects GET /me/pickup-host/stats; from and to are inclusive
+// dates in timezone.
+type HostStatsQuery struct {
+	From     string `form:"from" binding:"required,datetime=2006-01-02"`
+	To       string `form:"to" binding:"required,datetime=2006-01-02"`
+	Period   string `form:"period" binding:"omitempty,oneof=day week month"`
+	Timezone string `form:"timezone"`
+}
+
+// RosterExportQuery selects GET /pickup-groups/{id}/orders/export. Times are
+// printed in timezone (default UTC); all includes orders that hold no seat.
+type RosterExportQuery struct {
+	Format   string `form:"format" binding:"omitempty,oneof=csv pdf"`
+	Timezone string `form:"timezone"`
+	All      bool   `form:"all"`
+}
+
+type VerifySkillBody struct {
+	SkillLevelID string `json:"skill_level_id" binding:"required,uuid"`
+}
+
+type MarkAttendanceBody struct {
+	Attendance string `json:"attendance" binding:"required,oneof=attended no_show"`
+}
+
 type UpdateGroupBody struct {
-	Title        *string    `json:"title"`
-	StartTime    *time.Time `json:"start_time"`
-	EndTime      *time.Time `json:"end_time"`
-	Fee          *int       `json:"fee" binding:"omitempty,min=0"`
-	Capacity     *int       `json:"capacity" binding:"omitempty,min=1"`
-	LocationID   *string    `json:"location_id" binding:"omitempty,uuid"`
-	SportID      *string    `json:"sport_id" binding:"omitempty,uuid"`
-	SkillLevelID *string    `json:"skill_level_id" binding:"omitempty,uuid"`
-	Status       *string    `json:"status" binding:"omitempty,oneof=active cancelled completed"`
-	Enable       *bool      `json:"enable"`
+	Title           *string    `json:"title"`
+	StartTime       *time.Time `json:"start_time"`
+	EndTime         *time.Time `json:"end_time"`
+	Fee             *int       `json:"fee" binding:"omitempty,min=0"`
+	Capacity        *int       `json:"capacity" binding:"omitempty,min=1"`
+	LocationID      *string    `json:"location_id" binding:"omitempty,uuid"`
+	SportID         *string    `json:"sport_id" binding:"omitempty,uuid"`
+	SkillLevelID    *string    `json:"skill_level_id" binding:"omitempty,uuid"`
+	Status          *string    `json:"status" binding:"omitempty,oneof=active cancelled completed"`
+	Enable          *bool      `json:"enable"`
+	ResourceID      *string    `json:"resource_id" binding:"omitempty,uuid"`
+	MinParticipants *int       `json:"min_participants" binding:"omitempty,min=0"`
+	MinReliability  *int       `json:"min_reliability" binding:"omitempty,min=0,max=100"`
+	Visibility      *string    `json:"visibility" binding:"omitempty,oneof=public unlisted private"`
+	MinSkillLevelID *string    `json:"min_skill_level_id" binding:"omitempty,uuid"`
+	MaxSkillLevelID *string    `json:"max_skill_level_id" binding:"omitempty,uuid"`
+	// ClearSkillRange removes the skill-level range.
+	ClearSkillRange   bool `json:"clear_skill_range"`
+	MaxGuestsPerOrder *int `json:"max_guests_per_order" binding:"omitempty,min=0"`
+	// ClearMaxGuests removes the per-order guest cap.
+	ClearMaxGuests     bool       `json:"clear_max_guests"`
+	EnrollmentDeadline *time.Time `json:"enrollment_deadline"`
+	CancelDeadline     *time.Time `json:"cancel_deadline"`
+	// ClearEnrollmentDeadline and ClearCancelDeadline remove the deadlines.
+	ClearEnrollmentDeadline bool `json:"clear_enrollment_deadline"`
+	ClearCancelDeadline     bool `json:"clear_cancel_deadline"`
 }
 
 // --- Response types ---
 
 type PickupOrderResponse struct {
-	ID            string    `json:"id"`
-	PickupGroupID string    `json:"pickup_group_id"`
-	UserID        string    `json:"user_id"`
-	BookerName    string    `json:"booker_name"`
-	BookerPhone   string    `json:"booker_phone"`
-	Status        string    `json:"status"`
-	PaymentStatus string    `json:"payment_status"`
-	CreatedAt     time.Time `json:"created_at"`
-	UpdatedAt     time.Time `json:"updated_at"`
+	ID            string `json:"id"`
+	PickupGroupID string `json:"pickup_group_id"`
+	UserID        string `json:"user_id"`
+	BookerName    string `json:"booker_name"`
+	BookerPhone   string `json:"booker_phone"`
+	Status        string `json:"status"`
+	PaymentStatus string `json:"payment_status"`
+	// WaitlistPosition is the 1-based queue position; null unless waitlisted.
+	WaitlistPosition *int `json:"waitlist_position"`
+	// Attendance is "attended" or "no_show" once the host has marked it.
+	Attendance *string `json:"attendance"`
+	// Seats counts the booker plus guests.
+	Seats      int      `json:"seats"`
+	GuestNames []string `json:"guest_names"`
+	// RefundStatus is "pending" or "done" once a refund is owed; null otherwise.
+	RefundStatus *string `json:"refund_status"`
+	// ReconfirmRequired is set after a material group change until the
+	// participant reconfirms or withdraws.
+	ReconfirmRequired bool      `json:"reconfirm_required"`
+	CreatedAt         time.Time `json:"created_at"`
+	UpdatedAt         time.Time `json:"updated_at"`
 }
 
 func NewPickupOrderResponse(o *pickup.PickupOrder) PickupOrderResponse {
+	var attendance *string
+	if o.Attendance != nil {
+		a := string(*o.Attendance)
+		attendance = &a
+	}
+	var refundStatus *string
+	if o.RefundStatus != nil {
+		r := string(*o.RefundStatus)
+		refundStatus = &r
+	}
 	return PickupOrderResponse{
-		ID:            o.ID,
-		PickupGroupID: o.PickupGroupID,
-		UserID:        o.UserID,
-		BookerName:    o.BookerName,
-		BookerPhone:   o.BookerPhone,
-		Status:        string(o.Status),
-		PaymentStatus: string(o.PaymentStatus),
-		CreatedAt:     o.CreatedAt.UTC(),
-		UpdatedAt:     o.UpdatedAt.UTC(),
+		ID:                o.ID,
+		PickupGroupID:     o.PickupGroupID,
+		UserID:            o.UserID,
+		BookerName:        o.BookerName,
+		BookerPhone:       o.BookerPhone,
+		Status:            string(o.Status),
+		PaymentStatus:     string(o.PaymentStatus),
+		WaitlistPosition:  o.WaitlistPosition,
+		Attendance:        attendance,
+		Seats:             o.Seats,
+		GuestNames:        o.GuestNames,
+		RefundStatus:      refundStatus,
+		ReconfirmRequired: o.ReconfirmRequired,
+		CreatedAt:         o.CreatedAt.UTC(),
+		UpdatedAt:         o.UpdatedAt.UTC(),
+	}
+}
+
+// ReliabilityResponse is a participant's attendance history across all groups.
+type ReliabilityResponse struct {
+	Attended int `json:"attended"`
+	NoShow   int `json:"no_show"`
+	// Score is the attended percentage (0-100); null without any history.
+	Score *int `json:"score"`
+}
+
+// GroupOrderResponse is an order as seen by the host reviewing enrollments,
+// with the participant's reliability.
+type GroupOrderResponse struct {
+	PickupOrderResponse
+	Reliability ReliabilityResponse `json:"reliability"`
+	// BlockedByHost is whether the participant is on the group host's blocklist.
+	BlockedByHost bool `json:"blocked_by_host"`
+}
+
+func NewGroupOrderResponse(o *pickup.PickupOrder, rel pickup.Reliability, blocked bool) GroupOrderResponse {
+	return GroupOrderResponse{
+		PickupOrderResponse: NewPickupOrderResponse(o),
+		Reliability:         ReliabilityResponse{Attended: rel.Attended, NoShow: rel.NoShow, Score: rel.Score},
+		BlockedByHost:       blocked,
 	}
 }
 
 // PickupHostTag is the host representation embedded in pickup group responses.
 // Host details are resolved live from the users table (no snapshot).
 type PickupHostTag struct {
-	ID          string  `json:"id"`
-	Username    string  `json:"username"`
-	DisplayName *string `json:"display_name"`
-	Phone       *string `json:"phone"`
+	ID            string   `json:"id"`
+	Username      string   `json:"username"`
+	DisplayName   *string  `json:"display_name"`
+	Phone         *string  `json:"phone"`
+	RatingAverage *float64 `json:"rating_average"`
+	RatingCount   int      `json:"rating_count"`
 }
 
 // PickupGroupBrief is the trimmed, public-facing shape used by the list
@@ -118,9 +331,16 @@ type PickupGroupBrief struct {
 	SkillLevel      skillHttp.SkillLevelTag `json:"skill_level"`
 	StartTime       time.Time               `json:"start_time"`
 	Fee             int                     `json:"fee"`
+	Visibility      string                  `json:"visibility"`
+	// HostRatingAverage is nil while the host has no reviews.
+	HostRatingAverage *float64 `json:"host_rating_average"`
+	HostRatingCount   int      `json:"host_rating_count"`
 	// EnrolledStatus is the requesting user's status for this group: "free" when
 	// not enrolled (or unauthenticated), otherwise their order status.
 	EnrolledStatus string `json:"enrolled_status"`
+	// DistanceKm is the distance from the lat / lng query point; nil when the
+	// request gave no location.
+	DistanceKm *float64 `json:"distance_km"`
 }
 
 func NewPickupGroupBrief(g *pickup.PickupGroup) PickupGroupBrief {
@@ -129,58 +349,117 @@ func NewPickupGroupBrief(g *pickup.PickupGroup) PickupGroupBrief {
 		enrolled = pickup.EnrolledStatusFree
 	}
 	return PickupGroupBrief{
-		ID:              g.ID,
-		HostID:          g.HostID,
-		HostUsername:    g.HostUsername,
-		HostDisplayName: g.HostDisplayName,
-		LocationID:      g.LocationID,
-		Title:           g.Title,
-		Sport:           sportsHttp.SportTag{ID: g.SportID, Code: g.SportCode, Name: g.SportName},
-		SkillLevel:      skillHttp.SkillLevelTag{ID: g.SkillLevelID, Name: g.SkillLevelName},
-		StartTime:       g.StartTime.UTC(),
-		Fee:             g.Fee,
-		EnrolledStatus:  enrolled,
+		ID:                g.ID,
+		HostID:            g.HostID,
+		HostUsername:      g.HostUsername,
+		HostDisplayName:   g.HostDisplayName,
+		LocationID:        g.LocationID,
+		Title:             g.Title,
+		Sport:             sportsHttp.SportTag{ID: g.SportID, Code: g.SportCode, Name: g.SportName},
+		SkillLevel:        skillHttp.SkillLevelTag{ID: g.SkillLevelID, Name: g.SkillLevelName},
+		StartTime:         g.StartTime.UTC(),
+		Fee:               g.Fee,
+		Visibility:        string(g.Visibility),
+		HostRatingAverage: g.HostRatingAverage,
+		HostRatingCount:   g.HostRatingCount,
+		EnrolledStatus:    enrolled,
+		DistanceKm:        g.DistanceKm,
 	}
 }
 
 type PickupGroupResponse struct {
-	ID              string                  `json:"id"`
-	Host            PickupHostTag           `json:"host"`
-	Title           string                  `json:"title"`
-	StartTime       time.Time               `json:"start_time"`
-	EndTime         time.Time               `json:"end_time"`
-	Fee             int                     `json:"fee"`
-	Capacity        int                     `json:"capacity"`
+	ID              string        `json:"id"`
+	Host            PickupHostTag `json:"host"`
+	Title           string        `json:"title"`
+	StartTime       time.Time     `json:"start_time"`
+	EndTime         time.Time     `json:"end_time"`
+	Fee             int           `json:"fee"`
+	Capacity        int           `json:"capacity"`
+	MinParticipants int           `json:"min_participants"`
+	MinReliability  int           `json:"min_reliability"`
+	Visibility      string        `json:"visibility"`
+	MinSkillLevelID *string       `json:"min_skill_level_id"`
+	MaxSkillLevelID *string       `json:"max_skill_level_id"`
+	// MaxGuestsPerOrder is null when guests are not capped.
+	MaxGuestsPerOrder *int `json:"max_guests_per_order"`
+	// EnrollmentDeadline and CancelDeadline are null when not set.
+	EnrollmentDeadline *time.Time `json:"enrollment_deadline"`
+	CancelDeadline     *time.Time `json:"cancel_deadline"`
+	// CoHostIDs are the users sharing the host's duties.
+	CoHostIDs []string `json:"co_host_ids"`
+	// InviteCode is only populated for the host, co-hosts and system admins.
+	InviteCode      *string                 `json:"invite_code"`
 	LocationID      string                  `json:"location_id"`
 	Sport           sportsHttp.SportTag     `json:"sport"`
 	SkillLevel      skillHttp.SkillLevelTag `json:"skill_level"`
 	Status          string                  `json:"status"`
 	Enable          bool                    `json:"enable"`
 	CurrentEnrolled int                     `json:"current_enrolled"`
+	WaitlistCount   int                     `json:"waitlist_count"`
+	TemplateID      *string                 `json:"template_id"`
+	OccurrenceDate  *string                 `json:"occurrence_date"`
+	ResourceID      *string                 `json:"resource_id"`
+	BookingID       *string                 `json:"booking_id"`
+	BookingStatus   *string                 `json:"booking_status"`
 	CreatedAt       time.Time               `json:"created_at"`
 	UpdatedAt       time.Time               `json:"updated_at"`
 	Orders          *[]PickupOrderResponse  `json:"orders,omitempty"`
 }
 
+// utcTime returns a copy of t in UTC, or nil.
+func utcTime(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	u := t.UTC()
+	return &u
+}
+
 // NewPickupGroupResponse builds a PickupGroupResponse.
 // Pass a non-nil orders slice to include order details; nil omits the field entirely.
 func NewPickupGroupResponse(g *pickup.PickupGroup, orders []*pickup.PickupOrder) PickupGroupResponse {
 	resp := PickupGroupResponse{
-		ID:              g.ID,
-		Host:            PickupHostTag{ID: g.HostID, Username: g.HostUsername, DisplayName: g.HostDisplayName, Phone: g.HostPhone},
-		Title:           g.Title,
-		StartTime:       g.StartTime.UTC(),
-		EndTime:         g.EndTime.UTC(),
-		Fee:             g.Fee,
-		Capacity:        g.Capacity,
-		LocationID:      g.LocationID,
-		Sport:           sportsHttp.SportTag{ID: g.SportID, Code: g.SportCode, Name: g.SportName},
-		SkillLevel:      skillHttp.SkillLevelTag{ID: g.SkillLevelID, Name: g.SkillLevelName},
-		Status:          string(g.Status),
-		Enable:          g.Enable,
-		CurrentEnrolled: g.CurrentEnrolled,
-		CreatedAt:       g.CreatedAt.UTC(),
-		UpdatedAt:       g.UpdatedAt.UTC(),
+		ID: g.ID,
+		Host: PickupHostTag{
+			ID:            g.HostID,
+			Username:      g.HostUsername,
+			DisplayName:   g.HostDisplayName,
+			Phone:         g.HostPhone,
+			RatingAverage: g.HostRatingAverage,
+			RatingCount:   g.HostRatingCount,
+		},
+		Title:              g.Title,
+		StartTime:          g.StartTime.UTC(),
+		EndTime:            g.EndTime.UTC(),
+		Fee:                g.Fee,
+		Capacity:           g.Capacity,
+		MinParticipants:    g.MinParticipants,
+		MinReliability:     g.MinReliability,
+		Visibility:         string(g.Visibility),
+		MinSkillLevelID:    g.MinSkillLevelID,
+		MaxSkillLevelID:    g.MaxSkillLevelID,
+		MaxGuestsPerOrder:  g.MaxGuestsPerOrder,
+		EnrollmentDeadline: utcTime(g.EnrollmentDeadline),
+		CancelDeadline:     utcTime(g.CancelDeadline),
+		CoHostIDs:          g.CoHostIDs,
+		LocationID:         g.LocationID,
+		Sport:              sportsHttp.SportTag{ID: g.SportID, Code: g.SportCode, Name: g.SportName},
+		SkillLevel:         skillHttp.SkillLevelTag{ID: g.SkillLevelID, Name: g.SkillLevelName},
+		Status:             string(g.Status),
+		Enable:             g.Enable,
+		CurrentEnrolled:    g.CurrentEnrolled,
+		WaitlistCount:      g.WaitlistCount,
+		TemplateID:         g.TemplateID,
+		ResourceID:         g.ResourceID,
+		BookingID:          g.BookingID,
+		BookingStatus:      g.BookingStatus,
+		CreatedAt:          g.CreatedAt.UTC(),
+		UpdatedAt:          g.UpdatedAt.UTC(),
+	}
+
+	if g.OccurrenceDate != nil {
+		date := g.OccurrenceDate.Format("2006-01-02")
+		resp.OccurrenceDate = &date
 	}
 
 	if orders != nil {
@@ -193,3 +472,189 @@ func NewPickupGroupResponse(g *pickup.PickupGroup, orders []*pickup.PickupOrder)
 
 	return resp
 }
+
+// ReviewerTag identifies the participant who wrote a review.
+type ReviewerTag struct {
+	ID          string  `json:"id"`
+	Username    string  `json:"username"`
+	DisplayName *string `json:"display_name"`
+}
+
+type HostReviewResponse struct {
+	ID               string      `json:"id"`
+	OrderID          string      `json:"order_id"`
+	PickupGroupID    string      `json:"pickup_group_id"`
+	PickupGroupTitle string      `json:"pickup_group_title"`
+	HostID           string      `json:"host_id"`
+	Reviewer         ReviewerTag `json:"reviewer"`
+	Rating           int         `json:"rating"`
+	Comment          *string     `json:"comment"`
+	CreatedAt        time.Time   `json:"created_at"`
+}
+
+func NewHostReviewResponse(r *pickup.HostReview) HostReviewResponse {
+	return HostReviewResponse{
+		ID:               r.ID,
+		OrderID:          r.OrderID,
+		PickupGroupID:    r.PickupGroupID,
+		PickupGroupTitle: r.GroupTitle,
+		HostID:           r.HostID,
+		Reviewer:         ReviewerTag{ID: r.ReviewerID, Username: r.ReviewerUsername, DisplayName: r.ReviewerDisplayName},
+		Rating:           r.Rating,
+		Comment:          r.Comment,
+		CreatedAt:        r.CreatedAt.UTC(),
+	}
+}
+
+// HostBlockURI binds the path parameters of a host's blocklist entry.
+type HostBlockURI struct {
+	HostID string `uri:"host_id" binding:"required,uuid"`
+	UserID string `uri:"user_id" binding:"required,uuid"`
+}
+
+// BlockUserBody optionally notes why the user is blocked.
+type BlockUserBody struct {
+	Reason *string `json:"reason" binding:"omitempty,max=500"`
+}
+
+type HostBlockResponse struct {
+	HostID      string    `json:"host_id"`
+	UserID      string    `json:"user_id"`
+	Username    string    `json:"username"`
+	DisplayName *string   `json:"display_name"`
+	Reason      *string   `json:"reason"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+func NewHostBlockResponse(b *pickup.HostBlock) HostBlockResponse {
+	return HostBlockResponse{
+		HostID:      b.HostID,
+		UserID:      b.UserID,
+		Username:    b.Username,
+		DisplayName: b.DisplayName,
+		Reason:      b.Reason,
+		CreatedAt:   b.CreatedAt.UTC(),
+	}
+}
+
+// CommentAuthorTag identifies the author of a comment.
+type CommentAuthorTag struct {
+	ID          string  `json:"id"`
+	Username    string  `json:"username"`
+	DisplayName *string `json:"display_name"`
+	IsHost      bool    `json:"is_host"`
+}
+
+type CommentResponse struct {
+	ID            string           `json:"id"`
+	PickupGroupID string           `json:"pickup_group_id"`
+	Author        CommentAuthorTag `json:"author"`
+	Body          string           `json:"body"`
+	Pinned        bool             `json:"pinned"`
+	PinnedAt      *time.Time       `json:"pinned_at"`
+	CreatedAt     time.Time        `json:"created_at"`
+}
+
+func NewCommentResponse(c *pickup.Comment) CommentResponse {
+	var pinnedAt *time.Time
+	if c.PinnedAt != nil {
+		t := c.PinnedAt.UTC()
+		pinnedAt = &t
+	}
+	return CommentResponse{
+		ID:            c.ID,
+		PickupGroupID: c.PickupGroupID,
+		Author: CommentAuthorTag{
+			ID:          c.AuthorID,
+			Username:    c.AuthorUsername,
+			DisplayName: c.AuthorDisplayName,
+			IsHost:      c.AuthorIsHost,
+		},
+		Body:      c.Body,
+		Pinned:    c.PinnedAt != nil,
+		PinnedAt:  pinnedAt,
+		CreatedAt: c.CreatedAt.UTC(),
+	}
+}
+
+// StatsTotalsResponse is embedded in the overall and per-sport host stats.
+// Rates are percentages; they are null when there is nothing to divide by.
+type StatsTotalsResponse struct {
+	GroupCount         int      `json:"group_count"`
+	Capacity           int      `json:"capacity"`
+	Seats              int      `json:"seats"`
+	FillRate           *float64 `json:"fill_rate"`
+	AverageHeadcount   *float64 `json:"average_headcount"`
+	Attended           int      `json:"attended"`
+	NoShow             int      `json:"no_show"`
+	NoShowRate         *float64 `json:"no_show_rate"`
+	Participants       int      `json:"participants"`
+	RepeatParticipants int      `json:"repeat_participants"`
+	ExpectedIncome     int      `json:"expected_income"`
+}
+
+func NewStatsTotalsResponse(t pickup.StatsTotals) StatsTotalsResponse {
+	return StatsTotalsResponse{
+		GroupCount:         t.GroupCount,
+		Capacity:           t.Capacity,
+		Seats:              t.Seats,
+		FillRate:           t.FillRate(),
+		AverageHeadcount:   t.AverageHeadcount(),
+		Attended:           t.Attended,
+		NoShow:             t.NoShow,
+		NoShowRate:         t.NoShowRate(),
+		Participants:       t.Participants,
+		RepeatParticipants: t.RepeatParticipants,
+		ExpectedIncome:     t.ExpectedIncome,
+	}
+}
+
+type StatsTrendPointResponse struct {
+	PeriodStart    string `json:"period_start"`
+	GroupCount     int    `json:"group_count"`
+	Seats          int    `json:"seats"`
+	ExpectedIncome int    `json:"expected_income"`
+}
+
+type SportStatsResponse struct {
+	Sport sportsHttp.SportTag `json:"sport"`
+	StatsTotalsResponse
+	Trend []StatsTrendPointResponse `json:"trend"`
+}
+
+type HostStatsResponse struct {
+	From     string               `json:"from"`
+	To       string               `json:"to"`
+	Period   string               `json:"period"`
+	Timezone string               `json:"timezone"`
+	Overall  StatsTotalsResponse  `json:"overall"`
+	Sports   []SportStatsResponse `json:"sports"`
+}
+
+func NewHostStatsResponse(req pickup.HostStatsRequest, stats *pickup.HostStats) HostStatsResponse {
+	sports := make([]SportStatsResponse, len(stats.Sports))
+	for i, s := range stats.Sports {
+		trend := make([]StatsTrendPointResponse, len(s.Trend))
+		for j, p := range s.Trend {
+			trend[j] = StatsTrendPointResponse{
+				PeriodStart:    p.PeriodStart.Format(dateLayout),
+				GroupCount:     p.GroupCount,
+				Seats:          p.Seats,
+				ExpectedIncome: p.ExpectedIncome,
+			}
+		}
+		sports[i] = SportStatsResponse{
+			Sport:               sportsHttp.SportTag{ID: s.SportID, Code: s.SportCode, Name: s.SportName},
+			StatsTotalsResponse: NewStatsTotalsResponse(s.StatsTotals),
+			Trend:               trend,
+		}
+	}
+	return HostStatsResponse{
+		From:     req.From.Format(dateLayout),
+		To:       req.To.Format(dateLayout),
+		Period:   stats.Period,
+		Timezone: stats.Timezone,
+		Overall:  NewStatsTotalsResponse(stats.Overall),
+		Sports:   sports,
+	}
+}
@@ -0,0 +1,195 @@
+package http
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nekogravitycat/court-booking-backend/internal/auth"
+	"github.com/nekogravitycat/court-booking-backend/internal/pickup"
+	"github.com/nekogravitycat/court-booking-backend/internal/pkg/pdf"
+	"github.com/nekogravitycat/court-booking-backend/internal/pkg/request"
+	"github.com/nekogravitycat/court-booking-backend/internal/pkg/response"
+)
+
+// rosterHeader is the column row shared by the CSV and PDF rosters.
+var rosterHeader = []string{"#", "Name", "Phone", "Seats", "Guests", "Status", "Payment", "Attendance"}
+
+// ExportGroupOrders downloads a group's roster as CSV or a printable PDF
+// sign-in sheet. By default only orders holding seats are listed; all=true
+// includes cancelled, rejected and waitlisted orders as well.
+// Access Control: group host, co-hosts and system admins, as ListGroupOrders.
+func (h *Handler) ExportGroupOrders(c *gin.Context) {
+	var uri request.ByIDRequest
+	if err := c.ShouldBindUri(&uri); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request", "details": err.Error()})
+		return
+	}
+
+	var query RosterExportQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid query parameters", "details": err.Error()})
+		return
+	}
+	if query.Format == "" {
+		query.Format = "csv"
+	}
+	if query.Timezone == "" {
+		query.Timezone = "UTC"
+	}
+	loc, err := time.LoadLocation(query.Timezone)
+	if err != nil {
+		response.Error(c, pickup.ErrInvalidTimezone)
+		return
+	}
+
+	userID := auth.GetUserID(c)
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	group, err := h.service.GetGroupByID(c.Request.Context(), uri.ID)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	if !group.IsManager(userID) && !h.isSysAdmin(c, userID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "only group host, co-host or system admin can export orders"})
+		return
+	}
+
+	orders, err := h.service.GetOrdersByGroupID(c.Request.Context(), uri.ID)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+	if !query.All {
+		roster := orders[:0]
+		for _, o := range orders {
+			if o.Status.OnRoster() {
+				roster = append(roster, o)
+			}
+		}
+		orders = roster
+	}
+
+	var buf bytes.Buffer
+	contentType := "text/csv; charset=utf-8"
+	if query.Format == "pdf" {
+		contentType = "application/pdf"
+		writeRosterPDF(&buf, group, orders, loc)
+	} else if err := writeRosterCSV(&buf, orders); err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	c.Header("X-Content-Type-Options", "nosniff")
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=\"pickup-roster-%s.%s\"", group.ID, query.Format))
+	c.Data(http.StatusOK, contentType, buf.Bytes())
+}
+
+// rosterRow renders an order as the cells of rosterHeader.
+func rosterRow(i int, o *pickup.PickupOrder) []string {
+	attendance := ""
+	if o.Attendance != nil {
+		attendance = string(*o.Attendance)
+	}
+	return []string{
+		strconv.Itoa(i + 1),
+		o.BookerName,
+		o.BookerPhone,
+		strconv.Itoa(o.Seats),
+		strings.Join(o.GuestNames, ", "),
+		string(o.Status),
+		string(o.PaymentStatus),
+		attendance,
+	}
+}
+
+// writeRosterCSV writes the roster as UTF-8 CSV. The leading byte order mark
+// makes spreadsheet programs read Chinese names correctly.
+func writeRosterCSV(buf *bytes.Buffer, orders []*pickup.PickupOrder) error {
+	buf.WriteString("\ufeff")
+	w := csv.NewWriter(buf)
+	w.Write(rosterHeader)
+	for i, o := range orders {
+		w.Write(csvSafe(rosterRow(i, o)))
+	}
+	w.Flush()
+	return w.Error()
+}
+
+// csvSafe prefixes cells a spreadsheet would evaluate as a formula, since
+// booker and guest names are user input.
+func csvSafe(cells []string) []string {
+	for i, s := range cells {
+		if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+			cells[i] = "'" + s
+		}
+	}
+	return cells
+}
+
+// Column layout of the PDF sign-in sheet: the roster columns followed by an
+// empty signature column.
+var (
+	rosterPDFColumns = append(append([]string{}, rosterHeader...), "Signature")
+	rosterPDFWidths  = []float64{22, 90, 72, 30, 70, 62, 46, 52, 71}
+)
+
+const (
+	rosterMargin    = 40.0
+	rosterFontSize  = 9.0
+	rosterRowHeight = 20.0
+)
+
+// writeRosterPDF lays the roster out as an A4 sign-in sheet, repeating the
+// column header on every page.
+func writeRosterPDF(buf *bytes.Buffer, group *pickup.PickupGroup, orders []*pickup.PickupOrder, loc *time.Location) {
+	doc := pdf.New()
+	right := doc.Width - rosterMargin
+
+	var y float64
+	drawRow := func(cells []string) {
+		x := rosterMargin
+		for i, w := range rosterPDFWidths {
+			if i < len(cells) {
+				doc.Text(x+3, y+rosterRowHeight-6, rosterFontSize, pdf.Truncate(cells[i], rosterFontSize, w-6))
+			}
+			x += w
+		}
+		y += rosterRowHeight
+		doc.Line(rosterMargin, y, right, y, 0.5)
+	}
+	newPage := func() {
+		doc.AddPage()
+		y = rosterMargin
+		doc.Text(rosterMargin, y+14, 14, group.Title)
+		y += 32
+		doc.Text(rosterMargin, y, rosterFontSize, fmt.Sprintf("%s - %s (%s)",
+			group.StartTime.In(loc).Format("2006-01-02 15:04"),
+			group.EndTime.In(loc).Format("15:04"),
+			loc.String()))
+		doc.Text(right-100, y, rosterFontSize, fmt.Sprintf("Page %d", doc.PageCount()))
+		y += 8
+		doc.Line(rosterMargin, y, right, y, 1)
+		drawRow(rosterPDFColumns)
+	}
+
+	newPage()
+	for i, o := range orders {
+		if y+rosterRowHeight > doc.Height-rosterMargin {
+			newPage()
+		}
+		drawRow(rosterRow(i, o))
+	}
+
+	doc.WriteTo(buf)
+}
@@ -2,13 +2,16 @@ package http
 
 import (
 	"net/http"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/nekogravitycat/court-booking-backend/internal/auth"
 	"github.com/nekogravitycat/court-booking-backend/internal/pickup"
 	"github.com/nekogravitycat/court-booking-backend/internal/pkg/request"
 	"github.com/nekogravitycat/court-booking-backend/internal/pkg/response"
+	skillProfileHttp "github.com/nekogravitycat/court-booking-backend/internal/skillprofile/http"
 	"github.com/nekogravitycat/court-booking-backend/internal/user"
 )
 
@@ -59,16 +62,26 @@ func (h *Handler) CreateGroup(c *gin.Context) {
 	}
 
 	req := pickup.CreateGroupRequest{
-		HostID:       userID,
-		Title:        body.Title,
-		StartTime:    body.StartTime,
-		EndTime:      body.EndTime,
-		Fee:          body.Fee,
-		Capacity:     body.Capacity,
-		LocationID:   body.LocationID,
-		SportID:      body.SportID,
-		SkillLevelID: body.SkillLevelID,
-		Enable:       enable,
+		HostID:             userID,
+		Title:              body.Title,
+		StartTime:          body.StartTime,
+		EndTime:            body.EndTime,
+		Fee:                body.Fee,
+		Capacity:           body.Capacity,
+		LocationID:         body.LocationID,
+		SportID:            body.SportID,
+		SkillLevelID:       body.SkillLevelID,
+		Enable:             enable,
+		ResourceID:         body.ResourceID,
+		BookingID:          body.BookingID,
+		MinParticipants:    body.MinParticipants,
+		MinReliability:     body.MinReliability,
+		Visibility:         pickup.Visibility(body.Visibility),
+		MinSkillLevelID:    body.MinSkillLevelID,
+		MaxSkillLevelID:    body.MaxSkillLevelID,
+		MaxGuestsPerOrder:  body.MaxGuestsPerOrder,
+		EnrollmentDeadline: body.EnrollmentDeadline,
+		CancelDeadline:     body.CancelDeadline,
 	}
 
 	group, err := h.service.CreateGroup(c.Request.Context(), req)
@@ -77,7 +90,9 @@ func (h *Handler) CreateGroup(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusCreated, NewPickupGroupResponse(group, nil))
+	resp := NewPickupGroupResponse(group, nil)
+	resp.InviteCode = group.InviteCode
+	c.JSON(http.StatusCreated, resp)
 }
 
 // ListGroups returns the public, bookable-only list of pickup groups.
@@ -89,13 +104,25 @@ func (h *Handler) ListGroups(c *gin.Context) {
 		return
 	}
 
+	if err := req.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
 	sortOrder := strings.ToUpper(req.SortOrder)
 
 	filter := pickup.GroupFilter{
 		SportID:      req.SportID,
 		SkillLevelID: req.SkillLevelID,
 		BookableOnly: true,
+		PublicOnly:   true,
 		ViewerUserID: auth.GetUserID(c),
+		Near:         req.Near(),
+		RadiusKm:     req.RadiusKm,
+		StartFrom:    req.StartFrom,
+		StartTo:      req.StartTo,
+		MaxFee:       req.MaxFee,
+		MinFreeSeats: req.MinFreeSeats,
 		Page:         req.Page,
 		PageSize:     req.PageSize,
 		SortBy:       req.SortBy,
@@ -132,14 +159,28 @@ func (h *Handler) ListGroupsByHost(c *gin.Context) {
 		return
 	}
 
+	if err := req.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
 	sortOrder := strings.ToUpper(req.SortOrder)
+	viewerID := auth.GetUserID(c)
 
 	filter := pickup.GroupFilter{
 		Status:       req.Status,
 		SportID:      req.SportID,
 		SkillLevelID: req.SkillLevelID,
 		HostID:       uri.HostID,
-		ViewerUserID: auth.GetUserID(c),
+		// Hosts see their own unlisted and private groups.
+		PublicOnly:   viewerID != uri.HostID,
+		ViewerUserID: viewerID,
+		Near:         req.Near(),
+		RadiusKm:     req.RadiusKm,
+		StartFrom:    req.StartFrom,
+		StartTo:      req.StartTo,
+		MaxFee:       req.MaxFee,
+		MinFreeSeats: req.MinFreeSeats,
 		Page:         req.Page,
 		PageSize:     req.PageSize,
 		SortBy:       req.SortBy,
@@ -173,7 +214,10 @@ func (h *Handler) GetGroup(c *gin.Context) {
 		return
 	}
 
-	group, err := h.service.GetGroupByID(c.Request.Context(), uri.ID)
+	userID := auth.GetUserID(c)
+	isSysAdmin := h.isSysAdmin(c, userID)
+
+	group, err := h.service.GetGroupForViewer(c.Request.Context(), uri.ID, userID, isSysAdmin, query.InviteCode)
 	if err != nil {
 		response.Error(c, err)
 		return
@@ -188,7 +232,104 @@ func (h *Handler) GetGroup(c *gin.Context) {
 		}
 	}
 
-	c.JSON(http.StatusOK, NewPickupGroupResponse(group, orders))
+	resp := NewPickupGroupResponse(group, orders)
+	if isSysAdmin || group.IsManager(userID) {
+		resp.InviteCode = group.InviteCode
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// GetGroupByInviteCode resolves an invite code to its pickup group so the
+// invitee can review it before enrolling with the same code.
+func (h *Handler) GetGroupByInviteCode(c *gin.Context) {
+	var uri InviteCodeURI
+	if err := c.ShouldBindUri(&uri); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request", "details": err.Error()})
+		return
+	}
+
+	group, err := h.service.GetGroupByInviteCode(c.Request.Context(), uri.Code)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, NewPickupGroupResponse(group, nil))
+}
+
+// AddCoHost makes a user a co-host of the group.
+// Access Control: group host or system admin.
+func (h *Handler) AddCoHost(c *gin.Context) {
+	var uri CoHostURI
+	if err := c.ShouldBindUri(&uri); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request", "details": err.Error()})
+		return
+	}
+
+	userID := auth.GetUserID(c)
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	group, err := h.service.AddCoHost(c.Request.Context(), uri.ID, uri.UserID, userID, h.isSysAdmin(c, userID))
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	resp := NewPickupGroupResponse(group, nil)
+	resp.InviteCode = group.InviteCode
+	c.JSON(http.StatusOK, resp)
+}
+
+// RemoveCoHost revokes a user's co-hosting of the group.
+// Access Control: group host or system admin.
+func (h *Handler) RemoveCoHost(c *gin.Context) {
+	var uri CoHostURI
+	if err := c.ShouldBindUri(&uri); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request", "details": err.Error()})
+		return
+	}
+
+	userID := auth.GetUserID(c)
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	if err := h.service.RemoveCoHost(c.Request.Context(), uri.ID, uri.UserID, userID, h.isSysAdmin(c, userID)); err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// RotateInviteCode replaces the invite code of an unlisted or private group.
+// Access Control: group host, co-host or system admin.
+func (h *Handler) RotateInviteCode(c *gin.Context) {
+	var uri request.ByIDRequest
+	if err := c.ShouldBindUri(&uri); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request", "details": err.Error()})
+		return
+	}
+
+	userID := auth.GetUserID(c)
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	group, err := h.service.RotateInviteCode(c.Request.Context(), uri.ID, userID, h.isSysAdmin(c, userID))
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	resp := NewPickupGroupResponse(group, nil)
+	resp.InviteCode = group.InviteCode
+	c.JSON(http.StatusOK, resp)
 }
 
 func (h *Handler) UpdateGroup(c *gin.Context) {
@@ -211,15 +352,16 @@ func (h *Handler) UpdateGroup(c *gin.Context) {
 	}
 
 	// System admins may update any group; a pickup host may update only their
-	// own groups.
+	// own groups, and a co-host the groups they co-host.
 	if !u.IsSystemAdmin {
 		group, err := h.service.GetGroupByID(c.Request.Context(), uri.ID)
 		if err != nil {
 			response.Error(c, err)
 			return
 		}
-		if !u.IsPickupHost || group.HostID != userID {
-			c.JSON(http.StatusForbidden, gin.H{"error": "only the pickup host or a system admin can update this pickup group"})
+		isHost := u.IsPickupHost && group.HostID == userID
+		if !isHost && !slices.Contains(group.CoHostIDs, userID) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "only the pickup host, a co-host or a system admin can update this pickup group"})
 			return
 		}
 	}
@@ -231,16 +373,29 @@ func (h *Handler) UpdateGroup(c *gin.Context) {
 	}
 
 	req := pickup.UpdateGroupRequest{
-		Title:        body.Title,
-		StartTime:    body.StartTime,
-		EndTime:      body.EndTime,
-		Fee:          body.Fee,
-		Capacity:     body.Capacity,
-		LocationID:   body.LocationID,
-		SportID:      body.SportID,
-		SkillLevelID: body.SkillLevelID,
-		Status:       body.Status,
-		Enable:       body.Enable,
+		Title:                   body.Title,
+		StartTime:               body.StartTime,
+		EndTime:                 body.EndTime,
+		Fee:                     body.Fee,
+		Capacity:                body.Capacity,
+		LocationID:              body.LocationID,
+		SportID:                 body.SportID,
+		SkillLevelID:            body.SkillLevelID,
+		Status:                  body.Status,
+		Enable:                  body.Enable,
+		ResourceID:              body.ResourceID,
+		MinParticipants:         body.MinParticipants,
+		MinReliability:          body.MinReliability,
+		Visibility:              body.Visibility,
+		MinSkillLevelID:         body.MinSkillLevelID,
+		MaxSkillLevelID:         body.MaxSkillLevelID,
+		MaxGuestsPerOrder:       body.MaxGuestsPerOrder,
+		ClearSkillRange:         body.ClearSkillRange,
+		ClearMaxGuests:          body.ClearMaxGuests,
+		EnrollmentDeadline:      body.EnrollmentDeadline,
+		ClearEnrollmentDeadline: body.ClearEnrollmentDeadline,
+		CancelDeadline:          body.CancelDeadline,
+		ClearCancelDeadline:     body.ClearCancelDeadline,
 	}
 
 	group, err := h.service.UpdateGroup(c.Request.Context(), uri.ID, req)
@@ -249,7 +404,9 @@ func (h *Handler) UpdateGroup(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, NewPickupGroupResponse(group, nil))
+	resp := NewPickupGroupResponse(group, nil)
+	resp.InviteCode = group.InviteCode
+	c.JSON(http.StatusOK, resp)
 }
 
 func (h *Handler) DeleteGroup(c *gin.Context) {
@@ -291,6 +448,21 @@ func (h *Handler) CreateOrder(c *gin.Context) {
 		return
 	}
 
+	var query CreateOrderQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid query parameters", "details": err.Error()})
+		return
+	}
+
+	// The body is optional; without one the order takes a single seat.
+	var body CreateOrderBody
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&body); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body", "details": err.Error()})
+			return
+		}
+	}
+
 	userID := auth.GetUserID(c)
 	if userID == "" {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
@@ -317,6 +489,10 @@ func (h *Handler) CreateOrder(c *gin.Context) {
 		UserID:        userID,
 		BookerName:    bookerName,
 		BookerPhone:   bookerPhone,
+		JoinWaitlist:  query.JoinWaitlist,
+		InviteCode:    query.InviteCode,
+		Seats:         body.Seats,
+		GuestNames:    body.GuestNames,
 	}
 
 	order, err := h.service.CreateOrder(c.Request.Context(), req)
@@ -419,10 +595,10 @@ func (h *Handler) ListGroupOrders(c *gin.Context) {
 		return
 	}
 
-	if group.HostID != userID {
+	if !group.IsManager(userID) {
 		// System admins may also review enrollments.
 		if u, err := h.userService.GetByID(c.Request.Context(), userID); err != nil || !u.IsSystemAdmin {
-			c.JSON(http.StatusForbidden, gin.H{"error": "only group host or system admin can view orders"})
+			c.JSON(http.StatusForbidden, gin.H{"error": "only group host, co-host or system admin can view orders"})
 			return
 		}
 	}
@@ -433,9 +609,24 @@ func (h *Handler) ListGroupOrders(c *gin.Context) {
 		return
 	}
 
-	items := make([]PickupOrderResponse, len(orders))
+	userIDs := make([]string, len(orders))
 	for i, o := range orders {
-		items[i] = NewPickupOrderResponse(o)
+		userIDs[i] = o.UserID
+	}
+	reliability, err := h.service.GetReliability(c.Request.Context(), userIDs)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+	blocked, err := h.service.BlockedUsers(c.Request.Context(), group.HostID, userIDs)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	items := make([]GroupOrderResponse, len(orders))
+	for i, o := range orders {
+		items[i] = NewGroupOrderResponse(o, reliability[o.UserID], blocked[o.UserID])
 	}
 
 	c.JSON(http.StatusOK, items)
@@ -461,3 +652,493 @@ func (h *Handler) ListMyOrders(c *gin.Context) {
 
 	c.JSON(http.StatusOK, items)
 }
+
+// CreateReview rates the host of the group the order belongs to. Only the
+// order's participant may review, once, after the group has completed.
+func (h *Handler) CreateReview(c *gin.Context) {
+	var uri request.ByIDRequest
+	if err := c.ShouldBindUri(&uri); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request", "details": err.Error()})
+		return
+	}
+
+	var body CreateReviewBody
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body", "details": err.Error()})
+		return
+	}
+
+	userID := auth.GetUserID(c)
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	req := pickup.CreateReviewRequest{
+		OrderID:    uri.ID,
+		ReviewerID: userID,
+		Rating:     body.Rating,
+		Comment:    body.Comment,
+	}
+
+	review, err := h.service.CreateReview(c.Request.Context(), req)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, NewHostReviewResponse(review))
+}
+
+// ListHostReviews returns a host's reviews, newest first. Public.
+func (h *Handler) ListHostReviews(c *gin.Context) {
+	var uri HostGroupsURI
+	if err := c.ShouldBindUri(&uri); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request", "details": err.Error()})
+		return
+	}
+
+	var req ListReviewsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid query parameters", "details": err.Error()})
+		return
+	}
+
+	filter := pickup.ReviewFilter{
+		HostID:   uri.HostID,
+		Page:     req.Page,
+		PageSize: req.PageSize,
+	}
+
+	reviews, total, err := h.service.ListReviewsByHost(c.Request.Context(), filter)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	items := make([]HostReviewResponse, len(reviews))
+	for i, r := range reviews {
+		items[i] = NewHostReviewResponse(r)
+	}
+
+	c.JSON(http.StatusOK, response.NewPageResponse(items, req.Page, req.PageSize, total))
+}
+
+// MarkAttendance records whether a confirmed participant showed up.
+// Access Control: group host, co-host or system admin, once the group has
+// started.
+func (h *Handler) MarkAttendance(c *gin.Context) {
+	var uri request.ByIDRequest
+	if err := c.ShouldBindUri(&uri); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request", "details": err.Error()})
+		return
+	}
+
+	var body MarkAttendanceBody
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body", "details": err.Error()})
+		return
+	}
+
+	userID := auth.GetUserID(c)
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	isSysAdmin := false
+	if u, err := h.userService.GetByID(c.Request.Context(), userID); err == nil {
+		isSysAdmin = u.IsSystemAdmin
+	}
+
+	order, err := h.service.MarkAttendance(c.Request.Context(), uri.ID, pickup.Attendance(body.Attendance), userID, isSysAdmin)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, NewPickupOrderResponse(order))
+}
+
+// ReconfirmOrder keeps the caller's order after its group's time, location or
+// fee changed. Access Control: the order's booker.
+func (h *Handler) ReconfirmOrder(c *gin.Context) {
+	var uri request.ByIDRequest
+	if err := c.ShouldBindUri(&uri); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request", "details": err.Error()})
+		return
+	}
+
+	userID := auth.GetUserID(c)
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	order, err := h.service.ReconfirmOrder(c.Request.Context(), uri.ID, userID)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, NewPickupOrderResponse(order))
+}
+
+// VerifySkill records the skill level a participant showed in the group's
+// sport on their skill profile. Access Control: group host, co-host or system
+// admin, once the group has started.
+func (h *Handler) VerifySkill(c *gin.Context) {
+	var uri request.ByIDRequest
+	if err := c.ShouldBindUri(&uri); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request", "details": err.Error()})
+		return
+	}
+
+	var body VerifySkillBody
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body", "details": err.Error()})
+		return
+	}
+
+	userID := auth.GetUserID(c)
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	profile, err := h.service.VerifySkill(c.Request.Context(), uri.ID, body.SkillLevelID, userID, h.isSysAdmin(c, userID))
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, skillProfileHttp.NewSkillProfileResponse(profile))
+}
+
+// isSysAdmin reports whether the user is a system admin; lookup failures are
+// treated as not an admin.
+func (h *Handler) isSysAdmin(c *gin.Context, userID string) bool {
+	u, err := h.userService.GetByID(c.Request.Context(), userID)
+	return err == nil && u.IsSystemAdmin
+}
+
+// ListComments returns a page of the group's comment thread, pinned comments
+// first. Access Control: group host or co-host, system admin, or a participant
+// with a pending / confirmed / completed order.
+func (h *Handler) ListComments(c *gin.Context) {
+	var uri request.ByIDRequest
+	if err := c.ShouldBindUri(&uri); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request", "details": err.Error()})
+		return
+	}
+
+	var req ListCommentsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid query parameters", "details": err.Error()})
+		return
+	}
+
+	userID := auth.GetUserID(c)
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	filter := pickup.CommentFilter{
+		PickupGroupID: uri.ID,
+		Page:          req.Page,
+		PageSize:      req.PageSize,
+	}
+
+	comments, total, err := h.service.ListComments(c.Request.Context(), filter, userID, h.isSysAdmin(c, userID))
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	items := make([]CommentResponse, len(comments))
+	for i, cm := range comments {
+		items[i] = NewCommentResponse(cm)
+	}
+
+	c.JSON(http.StatusOK, response.NewPageResponse(items, req.Page, req.PageSize, total))
+}
+
+// CreateComment posts to the group's comment thread.
+// Access Control: same as ListComments.
+func (h *Handler) CreateComment(c *gin.Context) {
+	var uri request.ByIDRequest
+	if err := c.ShouldBindUri(&uri); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request", "details": err.Error()})
+		return
+	}
+
+	var body CreateCommentBody
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body", "details": err.Error()})
+		return
+	}
+
+	userID := auth.GetUserID(c)
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	req := pickup.CreateCommentRequest{
+		PickupGroupID: uri.ID,
+		AuthorID:      userID,
+		Body:          body.Body,
+	}
+
+	comment, err := h.service.CreateComment(c.Request.Context(), req, h.isSysAdmin(c, userID))
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, NewCommentResponse(comment))
+}
+
+// UpdateComment pins or unpins a comment.
+// Access Control: group host, co-host or system admin.
+func (h *Handler) UpdateComment(c *gin.Context) {
+	var uri CommentURI
+	if err := c.ShouldBindUri(&uri); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request", "details": err.Error()})
+		return
+	}
+
+	var body UpdateCommentBody
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body", "details": err.Error()})
+		return
+	}
+
+	userID := auth.GetUserID(c)
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	comment, err := h.service.SetCommentPinned(c.Request.Context(), uri.ID, uri.CommentID, *body.Pinned, userID, h.isSysAdmin(c, userID))
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, NewCommentResponse(comment))
+}
+
+// DeleteComment removes a comment.
+// Access Control: the comment's author, the group host or a co-host, or a
+// system admin.
+func (h *Handler) DeleteComment(c *gin.Context) {
+	var uri CommentURI
+	if err := c.ShouldBindUri(&uri); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request", "details": err.Error()})
+		return
+	}
+
+	userID := auth.GetUserID(c)
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	if err := h.service.DeleteComment(c.Request.Context(), uri.ID, uri.CommentID, userID, h.isSysAdmin(c, userID)); err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ListHostBlocks returns the host's blocklist, newest first.
+// Access Control: the host themselves or a system admin.
+func (h *Handler) ListHostBlocks(c *gin.Context) {
+	var uri HostGroupsURI
+	if err := c.ShouldBindUri(&uri); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request", "details": err.Error()})
+		return
+	}
+
+	userID := auth.GetUserID(c)
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	blocks, err := h.service.ListHostBlocks(c.Request.Context(), uri.HostID, userID, h.isSysAdmin(c, userID))
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	items := make([]HostBlockResponse, len(blocks))
+	for i, b := range blocks {
+		items[i] = NewHostBlockResponse(b)
+	}
+	c.JSON(http.StatusOK, items)
+}
+
+// BlockUser keeps a user out of every group of the host; blocking an already
+// blocked user updates the reason. Access Control: the host themselves or a
+// system admin.
+func (h *Handler) BlockUser(c *gin.Context) {
+	var uri HostBlockURI
+	if err := c.ShouldBindUri(&uri); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request", "details": err.Error()})
+		return
+	}
+
+	// The body is optional; it only carries the private reason.
+	var body BlockUserBody
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&body); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body", "details": err.Error()})
+			return
+		}
+	}
+
+	userID := auth.GetUserID(c)
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	block, err := h.service.BlockUser(c.Request.Context(), pickup.BlockUserRequest{
+		HostID: uri.HostID,
+		UserID: uri.UserID,
+		Reason: body.Reason,
+	}, userID, h.isSysAdmin(c, userID))
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, NewHostBlockResponse(block))
+}
+
+// UnblockUser lifts a block. Access Control: the host themselves or a system
+// admin.
+func (h *Handler) UnblockUser(c *gin.Context) {
+	var uri HostBlockURI
+	if err := c.ShouldBindUri(&uri); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request", "details": err.Error()})
+		return
+	}
+
+	userID := auth.GetUserID(c)
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	if err := h.service.UnblockUser(c.Request.Context(), uri.HostID, uri.UserID, userID, h.isSysAdmin(c, userID)); err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// ListFavoriteFeed returns the upcoming bookable public groups of the caller's
+// favorite hosts, soonest first unless another order is requested.
+// Access Control: any authenticated user, for their own favorites.
+func (h *Handler) ListFavoriteFeed(c *gin.Context) {
+	userID := auth.GetUserID(c)
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var req ListGroupsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid query parameters", "details": err.Error()})
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	sortOrder := strings.ToUpper(req.SortOrder)
+	if req.SortBy == "" && sortOrder == "" {
+		sortOrder = "ASC"
+	}
+	startFrom := req.StartFrom
+	if startFrom == nil {
+		now := time.Now()
+		startFrom = &now
+	}
+
+	filter := pickup.GroupFilter{
+		SportID:      req.SportID,
+		SkillLevelID: req.SkillLevelID,
+		FavoritedBy:  userID,
+		BookableOnly: true,
+		PublicOnly:   true,
+		ViewerUserID: userID,
+		Near:         req.Near(),
+		RadiusKm:     req.RadiusKm,
+		StartFrom:    startFrom,
+		StartTo:      req.StartTo,
+		MaxFee:       req.MaxFee,
+		MinFreeSeats: req.MinFreeSeats,
+		Page:         req.Page,
+		PageSize:     req.PageSize,
+		SortBy:       req.SortBy,
+		SortOrder:    sortOrder,
+	}
+
+	groups, total, err := h.service.ListGroups(c.Request.Context(), filter)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	items := make([]PickupGroupBrief, len(groups))
+	for i, g := range groups {
+		items[i] = NewPickupGroupBrief(g)
+	}
+
+	c.JSON(http.StatusOK, response.NewPageResponse(items, req.Page, req.PageSize, total))
+}
+
+// GetHostStats returns the caller's dashboard statistics as a host: fill rate,
+// headcount, no-shows, repeat participants and expected income per sport over
+// time.
+func (h *Handler) GetHostStats(c *gin.Context) {
+	var query HostStatsQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid query parameters", "details": err.Error()})
+		return
+	}
+
+	userID := auth.GetUserID(c)
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	// Both dates were validated by binding.
+	from, _ := time.Parse(dateLayout, query.From)
+	to, _ := time.Parse(dateLayout, query.To)
+	req := pickup.HostStatsRequest{
+		HostID:   userID,
+		From:     from,
+		To:       to,
+		Period:   query.Period,
+		Timezone: query.Timezone,
+	}
+
+	stats, err := h.service.GetHostStats(c.Request.Context(), req)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, NewHostStatsResponse(req, stats))
+}
@@ -12,6 +12,18 @@ func RegisterRoutes(g *gin.RouterGroup, h *Handler, authMiddleware, optionalAuth
 	// Public list of a specific host's pickup groups (optional auth, trimmed).
 	g.GET("/hosts/:host_id/pickup-groups", optionalAuthMiddleware, h.ListGroupsByHost)
 
+	// Public list of a host's participant reviews.
+	g.GET("/hosts/:host_id/reviews", h.ListHostReviews)
+
+	// A host's enrollment blocklist (the host or a system admin)
+	blocksGroup := g.Group("/hosts/:host_id/pickup-blocks")
+	blocksGroup.Use(authMiddleware)
+	{
+		blocksGroup.GET("", h.ListHostBlocks)
+		blocksGroup.PUT("/:user_id", h.BlockUser)
+		blocksGroup.DELETE("/:user_id", h.UnblockUser)
+	}
+
 	// Authenticated pickup group routes
 	groupsGroup := g.Group("/pickup-groups")
 	groupsGroup.Use(authMiddleware)
@@ -22,14 +34,39 @@ func RegisterRoutes(g *gin.RouterGroup, h *Handler, authMiddleware, optionalAuth
 		groupsGroup.DELETE("/:id", h.DeleteGroup)
 		groupsGroup.POST("/:id/orders", h.CreateOrder)
 		groupsGroup.GET("/:id/orders", h.ListGroupOrders)
+		groupsGroup.GET("/:id/orders/export", h.ExportGroupOrders)
+		groupsGroup.POST("/:id/invite-code", h.RotateInviteCode)
+
+		// Co-hosts (managed by the primary host or a system admin)
+		groupsGroup.PUT("/:id/cohosts/:user_id", h.AddCoHost)
+		groupsGroup.DELETE("/:id/cohosts/:user_id", h.RemoveCoHost)
+
+		// Comment thread (host and enrolled participants only)
+		groupsGroup.GET("/:id/comments", h.ListComments)
+		groupsGroup.POST("/:id/comments", h.CreateComment)
+		groupsGroup.PATCH("/:id/comments/:comment_id", h.UpdateComment)
+		groupsGroup.DELETE("/:id/comments/:comment_id", h.DeleteComment)
 	}
 
+	// The caller's own host dashboard
+	g.GET("/me/pickup-host/stats", authMiddleware, h.GetHostStats)
+
+	// Upcoming groups from the caller's favorite hosts
+	g.GET("/me/pickup-feed", authMiddleware, h.ListFavoriteFeed)
+
+	// Invite link lookup for unlisted and private groups
+	g.GET("/pickup-invites/:code", authMiddleware, h.GetGroupByInviteCode)
+
 	// Pickup order routes
 	ordersGroup := g.Group("/pickup-orders")
 	ordersGroup.Use(authMiddleware)
 	{
 		ordersGroup.GET("", h.ListMyOrders)
 		ordersGroup.PATCH("/:id", h.UpdateOrder)
+		ordersGroup.POST("/:id/reconfirm", h.ReconfirmOrder)
 		ordersGroup.DELETE("/:id", h.DeleteOrder)
+		ordersGroup.POST("/:id/review", h.CreateReview)
+		ordersGroup.PATCH("/:id/attendance", h.MarkAttendance)
+		ordersGroup.POST("/:id/skill-verification", h.VerifySkill)
 	}
 }
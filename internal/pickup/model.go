@@ -1,9 +1,12 @@
 package pickup
 
 import (
+	"math"
 	"net/http"
+	"slices"
 	"time"
 
+	"github.com/nekogravitycat/court-booking-backend/internal/booking"
 	"github.com/nekogravitycat/court-booking-backend/internal/pkg/apperror"
 )
 
@@ -23,6 +26,65 @@ var (
 	ErrSkillLevelNotFound    = apperror.New(http.StatusNotFound, "skill level not found")
 	ErrSkillLevelMismatch    = apperror.New(http.StatusBadRequest, "skill level does not belong to the selected sport")
 	ErrSkillLevelInactive    = apperror.New(http.StatusBadRequest, "skill level is not active")
+
+	ErrResourceNotFound         = apperror.New(http.StatusNotFound, "resource not found")
+	ErrResourceLocationMismatch = apperror.New(http.StatusBadRequest, "resource does not belong to the pickup group's location")
+	ErrCourtUnavailable         = apperror.New(http.StatusConflict, "the court is already booked for this time")
+	ErrBookingNotFound          = apperror.New(http.StatusNotFound, "booking not found")
+	ErrBookingNotOwned          = apperror.New(http.StatusForbidden, "only the host's own booking can be linked")
+	ErrBookingMismatch          = apperror.New(http.StatusBadRequest, "booking does not cover the pickup group's court and time range")
+	ErrBookingUnavailable       = apperror.New(http.StatusConflict, "booking is cancelled or already linked to another pickup group")
+
+	ErrInvalidMinParticipants = apperror.New(http.StatusBadRequest, "min_participants must be between 0 and the group capacity")
+
+	ErrInvalidRating    = apperror.New(http.StatusBadRequest, "rating must be between 1 and 5")
+	ErrReviewNotAllowed = apperror.New(http.StatusConflict, "only a confirmed order in a completed pickup group can be reviewed")
+	ErrReviewOwnGroup   = apperror.New(http.StatusForbidden, "hosts cannot review their own pickup groups")
+	ErrAlreadyReviewed  = apperror.New(http.StatusConflict, "this order has already been reviewed")
+
+	ErrInvalidAttendance     = apperror.New(http.StatusBadRequest, "attendance must be attended or no_show")
+	ErrAttendanceNotAllowed  = apperror.New(http.StatusConflict, "attendance can only be marked on confirmed orders after the group has started")
+	ErrInvalidMinReliability = apperror.New(http.StatusBadRequest, "min_reliability must be between 0 and 100")
+	ErrReliabilityTooLow     = apperror.New(http.StatusForbidden, "your attendance reliability is below this group's minimum")
+
+	ErrCommentNotFound = apperror.New(http.StatusNotFound, "comment not found")
+	ErrNotThreadMember = apperror.New(http.StatusForbidden, "only the host and enrolled participants can access this group's comments")
+	ErrEmptyComment    = apperror.New(http.StatusBadRequest, "comment body must not be empty")
+
+	ErrInvalidVisibility   = apperror.New(http.StatusBadRequest, "visibility must be public, unlisted or private")
+	ErrInviteCodeRequired  = apperror.New(http.StatusForbidden, "a valid invite code is required to join this group")
+	ErrInviteCodeNotFound  = apperror.New(http.StatusNotFound, "invite code not found")
+	ErrPublicGroupNoInvite = apperror.New(http.StatusConflict, "public groups do not use invite codes")
+
+	ErrInvalidSkillRange     = apperror.New(http.StatusBadRequest, "min skill level must not rank above max skill level")
+	ErrSkillProfileRequired  = apperror.New(http.StatusForbidden, "this group requires a skill profile for its sport")
+	ErrSkillLevelOutOfRange  = apperror.New(http.StatusForbidden, "your skill level is outside this group's allowed range")
+	ErrSkillVerifyNotAllowed = apperror.New(http.StatusConflict, "skill can only be verified for confirmed participants after the group has started")
+
+	ErrInvalidGeoFilter = apperror.New(http.StatusBadRequest, "lat and lng must be given together and are required for radius_km and sort_by=distance")
+
+	ErrInvalidSeats      = apperror.New(http.StatusBadRequest, "seats must be at least 1")
+	ErrTooManyGuestNames = apperror.New(http.StatusBadRequest, "guest names must not exceed the number of guests (seats - 1)")
+	ErrInvalidMaxGuests  = apperror.New(http.StatusBadRequest, "max_guests_per_order must not be negative")
+	ErrTooManyGuests     = apperror.New(http.StatusBadRequest, "this group does not allow that many guests per order")
+	ErrSeatsOverCapacity = apperror.New(http.StatusBadRequest, "seats exceed the group capacity")
+
+	ErrInvalidStatsPeriod = apperror.New(http.StatusBadRequest, "period must be one of day, week, month")
+	ErrInvalidTimezone    = apperror.New(http.StatusBadRequest, "invalid timezone")
+	ErrInvalidDateRange   = apperror.New(http.StatusBadRequest, "to must not be before from")
+
+	ErrReconfirmNotRequired = apperror.New(http.StatusConflict, "this order does not need reconfirmation")
+
+	ErrCoHostIsHost   = apperror.New(http.StatusBadRequest, "the host cannot be added as a co-host")
+	ErrCoHostNotFound = apperror.New(http.StatusNotFound, "co-host not found")
+	ErrUserNotFound   = apperror.New(http.StatusNotFound, "user not found")
+
+	ErrInvalidDeadline  = apperror.New(http.StatusBadRequest, "enrollment and cancellation deadlines must not be after start_time")
+	ErrEnrollmentClosed = apperror.New(http.StatusConflict, "enrollment for this pickup group has closed")
+
+	ErrBlockedByHost = apperror.New(http.StatusForbidden, "you cannot join this host's pickup groups")
+	ErrBlockSelf     = apperror.New(http.StatusBadRequest, "hosts cannot block themselves")
+	ErrBlockNotFound = apperror.New(http.StatusNotFound, "blocked user not found")
 )
 
 type GroupStatus string
@@ -33,6 +95,29 @@ const (
 	GroupStatusCompleted GroupStatus = "completed"
 )
 
+// Visibility controls who can find, view and join a group.
+type Visibility string
+
+const (
+	// VisibilityPublic groups are listed and open to everyone.
+	VisibilityPublic Visibility = "public"
+	// VisibilityUnlisted groups are hidden from listings but viewable and
+	// joinable by anyone who has the link.
+	VisibilityUnlisted Visibility = "unlisted"
+	// VisibilityPrivate groups are hidden from listings; viewing and joining
+	// require the invite code.
+	VisibilityPrivate Visibility = "private"
+)
+
+// IsValid reports whether the visibility is a recognized value.
+func (v Visibility) IsValid() bool {
+	switch v {
+	case VisibilityPublic, VisibilityUnlisted, VisibilityPrivate:
+		return true
+	}
+	return false
+}
+
 type PaymentStatus string
 
 const (
@@ -50,6 +135,15 @@ func (p PaymentStatus) IsValid() bool {
 	return false
 }
 
+// RefundStatus tracks the fee owed back on a paid order whose group was
+// cancelled, or which was withdrawn after a material group change.
+type RefundStatus string
+
+const (
+	RefundStatusPending RefundStatus = "pending"
+	RefundStatusDone    RefundStatus = "done"
+)
+
 type OrderStatus string
 
 const (
@@ -61,17 +155,43 @@ const (
 	// excluded from the enrolled count and permanently blocks the user from
 	// re-enrolling in the group (the row is retained rather than hard-deleted).
 	OrderStatusRejected OrderStatus = "rejected"
+	// OrderStatusWaitlisted marks an order queued on a full group. It does not
+	// occupy a seat and is promoted to pending, oldest first, when a seat frees
+	// up through cancellation, rejection, deletion or a capacity increase.
+	OrderStatusWaitlisted OrderStatus = "waitlisted"
+	// OrderStatusCompleted marks a confirmed order whose group has ended. It is
+	// set only by the lifecycle worker and is terminal.
+	OrderStatusCompleted OrderStatus = "completed"
 )
 
 // IsValid reports whether the order status is a recognized value.
 func (s OrderStatus) IsValid() bool {
 	switch s {
-	case OrderStatusPending, OrderStatusConfirmed, OrderStatusCancelled, OrderStatusCancelRequest, OrderStatusRejected:
+	case OrderStatusPending, OrderStatusConfirmed, OrderStatusCancelled, OrderStatusCancelRequest, OrderStatusRejected, OrderStatusWaitlisted, OrderStatusCompleted:
 		return true
 	}
 	return false
 }
 
+// OnRoster reports whether an order in this status belongs on the group's
+// roster: it holds its seats, or held them through a completed group.
+func (s OrderStatus) OnRoster() bool {
+	return s != OrderStatusCancelled && s != OrderStatusRejected && s != OrderStatusWaitlisted
+}
+
+// Attendance records whether a confirmed participant showed up.
+type Attendance string
+
+const (
+	AttendanceAttended Attendance = "attended"
+	AttendanceNoShow   Attendance = "no_show"
+)
+
+// IsValid reports whether the attendance is a recognized value.
+func (a Attendance) IsValid() bool {
+	return a == AttendanceAttended || a == AttendanceNoShow
+}
+
 // EnrolledStatusFree is the enrolled_status reported for a viewer that has no
 // order in a group (or an anonymous viewer).
 const EnrolledStatusFree = "free"
@@ -90,9 +210,55 @@ type PickupGroup struct {
 	Status          GroupStatus
 	Enable          bool
 	CurrentEnrolled int
+	WaitlistCount   int
 	CreatedAt       time.Time
 	UpdatedAt       time.Time
 
+	// TemplateID and OccurrenceDate are set on groups materialised from a
+	// recurring template (see the pickuptemplate package); nil for one-off groups.
+	TemplateID     *string
+	OccurrenceDate *time.Time
+
+	// ResourceID is the court the group plays on and BookingID the booking
+	// reserving it. The booking follows the group: it is created (or linked)
+	// with the group, moved with it, and cancelled when the group is.
+	ResourceID *string
+	BookingID  *string
+
+	// MinParticipants is the headcount the group needs by the auto-cancel
+	// cutoff before start_time; 0 disables auto-cancellation.
+	MinParticipants int
+	// MinReliability is the reliability score (0-100) a user needs to enroll;
+	// 0 disables the check. Users without attendance history always pass.
+	MinReliability int
+
+	// Visibility decides where the group can be found; InviteCode is the
+	// shareable code of unlisted and private groups (nil for public ones). It
+	// must only be shown to the host and system admins.
+	Visibility Visibility
+	InviteCode *string
+
+	// MinSkillLevelID and MaxSkillLevelID bound, inclusively by sort_order, the
+	// skill levels allowed to enroll. Either may be nil for an open range; with
+	// any bound set, users need a skill profile for the group's sport.
+	MinSkillLevelID *string
+	MaxSkillLevelID *string
+
+	// MaxGuestsPerOrder caps the guests (seats - 1) an order may bring; nil
+	// means no cap beyond the capacity and 0 disallows guests.
+	MaxGuestsPerOrder *int
+
+	// EnrollmentDeadline closes enrollment (waitlist included); CancelDeadline
+	// ends free cancellation, after which an owner's cancellation becomes a
+	// cancel_request for the host to approve. Nil disables either.
+	EnrollmentDeadline *time.Time
+	CancelDeadline     *time.Time
+
+	// CoHostIDs are the users sharing the host's duties, in the order they
+	// were added. Co-hosts may review enrollments, edit the group and mark
+	// attendance; deleting the group and managing co-hosts stay with the host.
+	CoHostIDs []string
+
 	// Fields resolved via JOIN for display; not stored on pickup_groups.
 	SportCode       string
 	SportName       string
@@ -100,11 +266,44 @@ type PickupGroup struct {
 	HostUsername    string
 	HostDisplayName *string
 	HostPhone       *string
+	BookingStatus   *string
+
+	// HostRatingAverage and HostRatingCount aggregate the host's reviews
+	// across all of their groups; the average is nil while unrated.
+	HostRatingAverage *float64
+	HostRatingCount   int
 
 	// EnrolledStatus is the requesting viewer's order status for this group.
 	// It is only populated by list queries that receive a viewer id; it is the
 	// empty string otherwise (the handler maps empty to "free").
 	EnrolledStatus string
+
+	// DistanceKm is the great-circle distance from the search point to the
+	// group's location. It is only populated by ListGroups with Near set.
+	DistanceKm *float64
+}
+
+// IsManager reports whether the user is the group's host or one of its
+// co-hosts.
+func (g *PickupGroup) IsManager(userID string) bool {
+	return g.HostID == userID || slices.Contains(g.CoHostIDs, userID)
+}
+
+// CancelDeadlinePassed reports whether free cancellation has ended at now.
+func (g *PickupGroup) CancelDeadlinePassed(now time.Time) bool {
+	return g.CancelDeadline != nil && now.After(*g.CancelDeadline)
+}
+
+// deadlinesValid reports whether neither deadline falls after the start time.
+func (g *PickupGroup) deadlinesValid() bool {
+	return (g.EnrollmentDeadline == nil || !g.EnrollmentDeadline.After(g.StartTime)) &&
+		(g.CancelDeadline == nil || !g.CancelDeadline.After(g.StartTime))
+}
+
+// GeoPoint is a WGS84 coordinate in decimal degrees.
+type GeoPoint struct {
+	Latitude  float64
+	Longitude float64
 }
 
 type PickupOrder struct {
@@ -117,6 +316,259 @@ type PickupOrder struct {
 	PaymentStatus PaymentStatus
 	CreatedAt     time.Time
 	UpdatedAt     time.Time
+
+	// WaitlistPosition is the 1-based queue position while the order is
+	// waitlisted (derived live, not stored); nil otherwise.
+	WaitlistPosition *int
+
+	// Attendance is set by the host once the group has started; nil until
+	// marked.
+	Attendance *Attendance
+
+	// Seats is the number of places the order takes: the booker plus guests.
+	// GuestNames optionally names up to Seats-1 guests without accounts.
+	Seats      int
+	GuestNames []string
+
+	// RefundStatus is nil while no refund is owed.
+	RefundStatus *RefundStatus
+	// ReconfirmRequired is set on open orders when the group's time or
+	// location moves or its fee rises, until the participant reconfirms.
+	ReconfirmRequired bool
+}
+
+// Material group changes that require participants to reconfirm.
+const (
+	ChangeTime     = "time"
+	ChangeLocation = "location"
+	ChangeFee      = "fee"
+)
+
+// Events published to participants affected by a group change.
+const (
+	EventGroupCancelled = "pickup.group_cancelled"
+	EventGroupChanged   = "pickup.group_changed"
+)
+
+// Events published to a participant when a host or system admin decides on
+// their order.
+const (
+	EventOrderConfirmed = "pickup.order_confirmed"
+	EventOrderRejected  = "pickup.order_rejected"
+	EventOrderCancelled = "pickup.order_cancelled"
+)
+
+// EventFavoriteHostGroupCreated tells a user that a host they follow (see the
+// favorite package) created a new public group.
+const EventFavoriteHostGroupCreated = "pickup.favorite_host_group_created"
+
+// EventOrderReminder reminds a participant of their confirmed order shortly
+// before the group starts (see Service.SendReminders).
+const EventOrderReminder = "pickup.order_reminder"
+
+// OrderReminder is a reminder claimed for a confirmed order.
+type OrderReminder struct {
+	OrderID       string
+	UserID        string
+	PickupGroupID string
+	GroupTitle    string
+	LocationName  string
+	StartTime     time.Time
+	EndTime       time.Time
+	// Before is the configured offset before StartTime it was sent for.
+	Before time.Duration
+}
+
+// AffectedOrder is an open order touched by its group's cancellation or a
+// material change to it.
+type AffectedOrder struct {
+	OrderID       string
+	UserID        string
+	PickupGroupID string
+	GroupTitle    string
+	// RefundDue is set when the order had been paid and was cancelled with
+	// its group.
+	RefundDue bool
+}
+
+// GroupChange is the cascade a group update applied to its orders and court
+// bookings. Changes lists the material changes (ChangeTime, ...); Cancelled is
+// set when the update cancelled the group.
+type GroupChange struct {
+	Cancelled bool
+	Changes   []string
+	Orders    []*AffectedOrder
+	Courts    []booking.Change
+}
+
+// Reliability summarises a user's marked attendance across all groups.
+type Reliability struct {
+	Attended int
+	NoShow   int
+	// Score is the attended percentage (0-100), or nil without any history.
+	Score *int
+}
+
+// StatsTotals aggregates a host's non-cancelled groups. Seats count the
+// seat-occupying orders; ExpectedIncome is the fee of every confirmed or
+// completed seat, paid or not (the payment ledger records what was actually
+// collected); Attended and NoShow count marked orders.
+type StatsTotals struct {
+	GroupCount     int
+	Capacity       int
+	Seats          int
+	ExpectedIncome int
+	Attended       int
+	NoShow         int
+	// Participants counts distinct users holding a seat; RepeatParticipants
+	// those who did so in at least two groups.
+	Participants       int
+	RepeatParticipants int
+}
+
+// FillRate is the percentage of capacity taken, or nil without groups.
+func (t StatsTotals) FillRate() *float64 {
+	return percentage(t.Seats, t.Capacity)
+}
+
+// AverageHeadcount is the mean number of seats taken per group, or nil
+// without groups.
+func (t StatsTotals) AverageHeadcount() *float64 {
+	if t.GroupCount == 0 {
+		return nil
+	}
+	avg := math.Round(float64(t.Seats)/float64(t.GroupCount)*100) / 100
+	return &avg
+}
+
+// NoShowRate is the percentage of marked orders that did not show up, or nil
+// when nothing was marked.
+func (t StatsTotals) NoShowRate() *float64 {
+	return percentage(t.NoShow, t.Attended+t.NoShow)
+}
+
+func (t *StatsTotals) add(o StatsTotals) {
+	t.GroupCount += o.GroupCount
+	t.Capacity += o.Capacity
+	t.Seats += o.Seats
+	t.ExpectedIncome += o.ExpectedIncome
+	t.Attended += o.Attended
+	t.NoShow += o.NoShow
+}
+
+// percentage is part/whole as a percentage rounded to one decimal, or nil for
+// an empty whole.
+func percentage(part, whole int) *float64 {
+	if whole == 0 {
+		return nil
+	}
+	p := math.Round(float64(part)/float64(whole)*1000) / 10
+	return &p
+}
+
+// StatsTrendPoint is one period of a sport's trend, bucketed by start_time.
+type StatsTrendPoint struct {
+	PeriodStart    time.Time // local calendar date the period starts on
+	GroupCount     int
+	Seats          int
+	ExpectedIncome int
+}
+
+// SportStats is a host's statistics for one sport.
+type SportStats struct {
+	SportID   string
+	SportCode string
+	SportName string
+	StatsTotals
+	Trend []StatsTrendPoint
+}
+
+// HostStats is a host's dashboard over a date range: per sport and overall.
+// Period and Timezone are those the trends were computed with, defaults
+// applied.
+type HostStats struct {
+	Period   string
+	Timezone string
+	Overall  StatsTotals
+	Sports   []*SportStats
+}
+
+// HostStatsFilter selects the host's groups starting in [From, To); trends
+// use Period buckets of Timezone's calendar.
+type HostStatsFilter struct {
+	HostID   string
+	From     time.Time
+	To       time.Time
+	Period   string
+	Timezone string
+}
+
+// HostReview is a participant's rating of the host of a completed group.
+// There is at most one review per order.
+type HostReview struct {
+	ID            string
+	OrderID       string
+	PickupGroupID string
+	HostID        string
+	ReviewerID    string
+	Rating        int
+	Comment       *string
+	CreatedAt     time.Time
+
+	// Fields resolved via JOIN for display.
+	GroupTitle          string
+	ReviewerUsername    string
+	ReviewerDisplayName *string
+}
+
+// HostBlock keeps a user from enrolling in any group of the host.
+type HostBlock struct {
+	HostID string
+	UserID string
+	// Reason is a private note for the host and system admins.
+	Reason    *string
+	CreatedAt time.Time
+
+	// Fields resolved via JOIN for display.
+	Username    string
+	DisplayName *string
+}
+
+// Comment is a message in a pickup group's thread.
+type Comment struct {
+	ID            string
+	PickupGroupID string
+	AuthorID      string
+	Body          string
+	// PinnedAt is when the host pinned the comment; nil while unpinned.
+	PinnedAt  *time.Time
+	CreatedAt time.Time
+
+	// Fields resolved via JOIN for display.
+	AuthorUsername    string
+	AuthorDisplayName *string
+	AuthorIsHost      bool
+}
+
+// CommentFilter selects a page of a group's thread: pinned comments first,
+// then the rest oldest first.
+type CommentFilter struct {
+	PickupGroupID string
+	Page          int
+	PageSize      int
+}
+
+// ReviewFilter selects a page of one host's reviews, newest first.
+type ReviewFilter struct {
+	HostID   string
+	Page     int
+	PageSize int
+}
+
+// LifecycleResult reports what one lifecycle pass changed.
+type LifecycleResult struct {
+	Completed int // groups completed because they ended
+	Cancelled int // groups cancelled for missing their minimum headcount
 }
 
 type GroupFilter struct {
@@ -124,11 +576,30 @@ type GroupFilter struct {
 	SportID      string
 	SkillLevelID string
 	HostID       string
+	// FavoritedBy limits results to groups hosted by that user's favorite hosts.
+	FavoritedBy string
 	// BookableOnly limits results to groups that can still be enrolled into:
-	// status=active, enable=true, not yet ended, and not fully booked.
+	// status=active, enable=true, not yet ended, enrollment not closed, and
+	// not fully booked.
 	BookableOnly bool
+	// PublicOnly hides unlisted and private groups.
+	PublicOnly bool
 	// ViewerUserID, when set, resolves each group's enrolled_status for that user.
 	ViewerUserID string
+	// Near, when set, resolves each group's distance from that point and
+	// enables RadiusKm and sorting by "distance".
+	Near *GeoPoint
+	// RadiusKm limits results to groups within this many kilometres of Near;
+	// 0 disables it.
+	RadiusKm float64
+	// StartFrom and StartTo bound start_time, inclusively.
+	StartFrom *time.Time
+	StartTo   *time.Time
+	// MaxFee limits results to groups costing at most this much.
+	MaxFee *int
+	// MinFreeSeats limits results to groups with at least this many seats
+	// left; 0 disables it.
+	MinFreeSeats int
 	Page         int
 	PageSize     int
 	SortBy       string
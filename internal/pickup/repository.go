@@ -5,35 +5,133 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/Masterminds/squirrel"
 	"github.com/jackc/pgerrcode"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/nekogravitycat/court-booking-backend/internal/booking"
+	"github.com/nekogravitycat/court-booking-backend/internal/pkg/event"
 )
 
 type Repository interface {
-	CreateGroup(ctx context.Context, group *PickupGroup) error
+	// CreateGroup inserts the group and runs publish in the same transaction,
+	// handing it the court booking made for the group, if any.
+	CreateGroup(ctx context.Context, group *PickupGroup, publish func(tx pgx.Tx, courts []booking.Change) error) error
 	GetGroupByID(ctx context.Context, id string) (*PickupGroup, error)
 	ListGroups(ctx context.Context, filter GroupFilter) ([]*PickupGroup, int, error)
-	UpdateGroup(ctx context.Context, group *PickupGroup) error
-	DeleteGroup(ctx context.Context, id string) error
+	// UpdateGroup saves the group and cascades onto its open orders:
+	// cancelling the group cancels them, and a material change flags them for
+	// reconfirmation. The cascade applied, including the changes to the
+	// group's court bookings, is handed to publish in the same transaction.
+	UpdateGroup(ctx context.Context, group *PickupGroup, publish func(tx pgx.Tx, change *GroupChange) error) error
+	// DeleteGroup deletes the group and cancels its court booking, handing
+	// the cancellation to publish in the same transaction.
+	DeleteGroup(ctx context.Context, id string, publish func(tx pgx.Tx, courts []booking.Change) error) error
+
+	// AddCoHost makes the user a co-host of the group; adding an existing
+	// co-host is a no-op. An unknown user returns ErrUserNotFound.
+	AddCoHost(ctx context.Context, groupID, userID, addedBy string) error
+	// RemoveCoHost returns ErrCoHostNotFound when the user is not a co-host.
+	RemoveCoHost(ctx context.Context, groupID, userID string) error
+
+	// BlockUser adds the user to the host's blocklist, or updates the reason
+	// of an existing block. An unknown host or user returns ErrUserNotFound.
+	BlockUser(ctx context.Context, block *HostBlock) error
+	// UnblockUser returns ErrBlockNotFound when the user is not blocked.
+	UnblockUser(ctx context.Context, hostID, userID string) error
+	// ListHostBlocks returns the host's blocklist, newest first.
+	ListHostBlocks(ctx context.Context, hostID string) ([]*HostBlock, error)
+	// BlockedUsers reports which of the users the host has blocked.
+	BlockedUsers(ctx context.Context, hostID string, userIDs []string) (map[string]bool, error)
 
 	// CreateOrder uses a transaction with SELECT FOR UPDATE to prevent overbooking.
-	CreateOrder(ctx context.Context, order *PickupOrder) error
+	// When the group is full and joinWaitlist is set, the order is queued as
+	// waitlisted instead of failing with ErrGroupFullyBooked. A first
+	// enrollment in a private group must present its inviteCode. Users on the
+	// host's blocklist get ErrBlockedByHost.
+	CreateOrder(ctx context.Context, order *PickupOrder, joinWaitlist bool, inviteCode string) error
 	GetOrderByID(ctx context.Context, id string) (*PickupOrder, error)
 	GetOrdersByGroupID(ctx context.Context, groupID string) ([]*PickupOrder, error)
 	GetOrdersByUserID(ctx context.Context, userID string) ([]*PickupOrder, error)
-	UpdateOrder(ctx context.Context, order *PickupOrder) error
+	// UpdateOrder and its variants below run publish in the transaction
+	// writing the order.
+	UpdateOrder(ctx context.Context, order *PickupOrder, publish event.Hook) error
+	// UpdateOrderAndPromote applies an update that releases the order's seat
+	// (cancelled / rejected) and promotes waitlisted orders into the freed seat
+	// within the same group-locking transaction.
+	UpdateOrderAndPromote(ctx context.Context, order *PickupOrder, publish event.Hook) error
 	// DeleteOrder hard-deletes an order. The group's current_enrolled is derived
-	// from a live COUNT, so removing the row decrements it automatically.
+	// from a live COUNT, so removing the row decrements it automatically; any
+	// seat it held is handed to the waitlist in the same transaction.
 	DeleteOrder(ctx context.Context, id string) error
 
+	// CompleteEndedGroups marks active groups whose end_time is at or before now
+	// as completed. Their confirmed orders become completed; pending,
+	// cancel-requested and waitlisted orders are cancelled. Returns the number
+	// of groups completed.
+	CompleteEndedGroups(ctx context.Context, now time.Time) (int, error)
+	// CancelUnderfilledGroups cancels active groups starting at or before
+	// cutoff that hold fewer seats than their min_participants, together with
+	// their open orders and court booking. Paid orders are flagged for a
+	// refund. The orders and court bookings cancelled with the groups are
+	// handed to publish in the same transaction. Returns the number of groups
+	// cancelled.
+	CancelUnderfilledGroups(ctx context.Context, cutoff time.Time, publish func(tx pgx.Tx, cancelled []*AffectedOrder, courts []booking.Change) error) (int, error)
+	// ClaimReminders records the reminders due at now for confirmed orders of
+	// active groups, at most one per order: that of the smallest offset
+	// already reached. Orders awaiting reconfirmation, and orders made after
+	// that point, are skipped. A reminder recorded before, by this or another
+	// replica, is not claimed again. The claimed reminders are handed to send
+	// in the claiming transaction, so a failed send leaves them to the next
+	// run. It returns how many were sent.
+	ClaimReminders(ctx context.Context, now time.Time, offsets []time.Duration, send func(tx pgx.Tx, reminders []*OrderReminder) error) (int, error)
+
 	// UpdateOrderWithCapacityCheck re-validates the group capacity inside a
 	// transaction (with SELECT FOR UPDATE) before applying the update. It is used
 	// when an order moves back into a seat-occupying state to prevent overbooking.
-	UpdateOrderWithCapacityCheck(ctx context.Context, order *PickupOrder) error
+	UpdateOrderWithCapacityCheck(ctx context.Context, order *PickupOrder, publish event.Hook) error
+
+	// CreateReview stores a review of the order's group host. The group and
+	// reviewer are taken from the order, and the insert only succeeds while the
+	// group is completed and the order confirmed (or completed); otherwise it
+	// returns ErrReviewNotAllowed. A second review of the same order returns
+	// ErrAlreadyReviewed.
+	CreateReview(ctx context.Context, review *HostReview) error
+	// ListReviewsByHost returns a page of the host's reviews, newest first.
+	ListReviewsByHost(ctx context.Context, filter ReviewFilter) ([]*HostReview, int, error)
+
+	// UpdateAttendance records the order's attendance mark.
+	UpdateAttendance(ctx context.Context, order *PickupOrder) error
+	// GetReliability returns the attendance history of each given user, keyed
+	// by user id. Users without history map to a zero Reliability.
+	GetReliability(ctx context.Context, userIDs []string) (map[string]Reliability, error)
+
+	// HasParticipatingOrder reports whether the user holds a pending,
+	// confirmed or completed order in the group.
+	HasParticipatingOrder(ctx context.Context, groupID, userID string) (bool, error)
+	CreateComment(ctx context.Context, comment *Comment) error
+	GetCommentByID(ctx context.Context, groupID, commentID string) (*Comment, error)
+	ListComments(ctx context.Context, filter CommentFilter) ([]*Comment, int, error)
+	// SetCommentPinned pins (stamping pinned_at) or unpins a comment.
+	SetCommentPinned(ctx context.Context, comment *Comment, pinned bool) error
+	DeleteComment(ctx context.Context, groupID, commentID string) error
+
+	// GetGroupByInviteCode resolves an invite code to its group.
+	GetGroupByInviteCode(ctx context.Context, code string) (*PickupGroup, error)
+	// SetInviteCode replaces the group's invite code, invalidating the old one.
+	SetInviteCode(ctx context.Context, groupID, code string) error
+	// HasOrder reports whether the user has any order in the group.
+	HasOrder(ctx context.Context, groupID, userID string) (bool, error)
+	// ListFollowerIDs returns the users who have the host in their favorites.
+	ListFollowerIDs(ctx context.Context, hostID string) ([]string, error)
+
+	// GetHostStats aggregates the host's non-cancelled groups starting in the
+	// filter's range, per sport (ordered by sport code) and overall.
+	GetHostStats(ctx context.Context, filter HostStatsFilter) (*HostStats, error)
 }
 
 type pgxRepository struct {
@@ -51,20 +149,37 @@ var groupSelectColumns = []string{
 	"pg.id", "pg.host_id", "pg.title", "pg.start_time", "pg.end_time", "pg.fee",
 	"pg.capacity", "pg.location_id", "pg.sport_id", "s.code", "s.name",
 	"pg.skill_level_id", "sl.name", "u.username", "u.display_name", "u.phone",
-	"pg.status", "pg.enable", "pg.created_at", "pg.updated_at",
-	"COALESCE(COUNT(po.id) FILTER (WHERE po.status NOT IN ('cancelled', 'rejected')), 0) AS current_enrolled",
+	"pg.status", "pg.enable", "pg.created_at", "pg.updated_at", "pg.template_id", "pg.occurrence_date",
+	"pg.resource_id", "pg.booking_id", "bk.status::TEXT", "pg.min_participants", "pg.min_reliability",
+	"pg.visibility", "pg.invite_code", "pg.min_skill_level_id", "pg.max_skill_level_id", "pg.max_guests_per_order",
+	"pg.enrollment_deadline", "pg.cancel_deadline",
+	occupiedSeatsExpr + " AS current_enrolled",
+	"COALESCE(COUNT(po.id) FILTER (WHERE po.status = 'waitlisted'), 0) AS waitlist_count",
+	hostRatingAverageExpr + " AS host_rating_average",
+	"(SELECT COUNT(*) FROM public.pickup_host_reviews r WHERE r.host_id = pg.host_id) AS host_rating_count",
+	"ARRAY(SELECT ch.user_id::TEXT FROM public.pickup_group_cohosts ch " +
+		"WHERE ch.pickup_group_id = pg.id ORDER BY ch.created_at, ch.user_id) AS cohost_ids",
 }
 
-// groupJoins wires the sport, skill-level, host, and orders tables onto a base
-// "public.pickup_groups pg" selection.
+// occupiedSeatsExpr sums the seats of the seat-occupying orders joined as "po".
+const occupiedSeatsExpr = "COALESCE(SUM(po.seats) FILTER (WHERE po.status NOT IN ('cancelled', 'rejected', 'waitlisted')), 0)"
+
+// hostRatingAverageExpr is the average review rating of the group's host,
+// rounded to two decimals, or NULL while the host has no reviews.
+const hostRatingAverageExpr = "(SELECT ROUND(AVG(r.rating), 2)::FLOAT8 " +
+	"FROM public.pickup_host_reviews r WHERE r.host_id = pg.host_id)"
+
+// groupJoins wires the sport, skill-level, host, court booking, and orders
+// tables onto a base "public.pickup_groups pg" selection.
 func groupJoins(b squirrel.SelectBuilder) squirrel.SelectBuilder {
 	return b.
 		From("public.pickup_groups pg").
 		Join("public.sports s ON pg.sport_id = s.id").
 		Join("public.skill_levels sl ON pg.skill_level_id = sl.id").
 		Join("public.users u ON pg.host_id = u.id").
+		LeftJoin("public.bookings bk ON pg.booking_id = bk.id").
 		LeftJoin("public.pickup_orders po ON pg.id = po.pickup_group_id").
-		GroupBy("pg.id", "s.id", "sl.id", "u.id")
+		GroupBy("pg.id", "s.id", "sl.id", "u.id", "bk.id")
 }
 
 // scanGroup scans a group row in the groupSelectColumns order. Extra trailing
@@ -74,25 +189,264 @@ func scanGroupInto(g *PickupGroup, extra ...any) []any {
 		&g.ID, &g.HostID, &g.Title, &g.StartTime, &g.EndTime, &g.Fee,
 		&g.Capacity, &g.LocationID, &g.SportID, &g.SportCode, &g.SportName,
 		&g.SkillLevelID, &g.SkillLevelName, &g.HostUsername, &g.HostDisplayName, &g.HostPhone,
-		&g.Status, &g.Enable, &g.CreatedAt, &g.UpdatedAt, &g.CurrentEnrolled,
+		&g.Status, &g.Enable, &g.CreatedAt, &g.UpdatedAt, &g.TemplateID, &g.OccurrenceDate,
+		&g.ResourceID, &g.BookingID, &g.BookingStatus, &g.MinParticipants, &g.MinReliability,
+		&g.Visibility, &g.InviteCode, &g.MinSkillLevelID, &g.MaxSkillLevelID, &g.MaxGuestsPerOrder,
+		&g.EnrollmentDeadline, &g.CancelDeadline,
+		&g.CurrentEnrolled, &g.WaitlistCount, &g.HostRatingAverage, &g.HostRatingCount,
+		&g.CoHostIDs,
 	}
 	return append(targets, extra...)
 }
 
-func (r *pgxRepository) CreateGroup(ctx context.Context, g *PickupGroup) error {
+// waitlistPositionExpr is the 1-based queue position of a waitlisted order
+// "po", derived live from the (waitlisted_at, id) ordering, or NULL for orders
+// that are not waitlisted.
+const waitlistPositionExpr = "CASE WHEN po.status = 'waitlisted' THEN (" +
+	"SELECT COUNT(*) FROM public.pickup_orders w " +
+	"WHERE w.pickup_group_id = po.pickup_group_id AND w.status = 'waitlisted' " +
+	"AND (w.waitlisted_at, w.id) <= (po.waitlisted_at, po.id)) END"
+
+// orderSelectColumns are the columns returned by the order read queries on a
+// "public.pickup_orders po" selection, in the order scanOrderInto expects.
+var orderSelectColumns = []string{
+	"po.id", "po.pickup_group_id", "po.user_id", "po.booker_name", "po.booker_phone",
+	"po.status", "po.payment_status", "po.created_at", "po.updated_at",
+	waitlistPositionExpr + " AS waitlist_position",
+	"po.attendance::TEXT", "po.seats", "po.guest_names",
+	"po.refund_status::TEXT", "po.reconfirm_required",
+}
+
+// scanOrderInto returns scan targets in the orderSelectColumns order.
+func scanOrderInto(o *PickupOrder) []any {
+	return []any{
+		&o.ID, &o.PickupGroupID, &o.UserID, &o.BookerName, &o.BookerPhone,
+		&o.Status, &o.PaymentStatus, &o.CreatedAt, &o.UpdatedAt, &o.WaitlistPosition,
+		&o.Attendance, &o.Seats, &o.GuestNames,
+		&o.RefundStatus, &o.ReconfirmRequired,
+	}
+}
+
+// checkSkillRange verifies the user's skill profile for the group's sport lies
+// within the group's skill-level range, comparing skill_levels.sort_order.
+func checkSkillRange(ctx context.Context, tx pgx.Tx, groupID, userID string) error {
+	var userOrder, minOrder, maxOrder *int
+	err := tx.QueryRow(ctx,
+		`SELECT usl.sort_order, lo.sort_order, hi.sort_order
+		 FROM public.pickup_groups pg
+		 LEFT JOIN public.skill_levels lo ON lo.id = pg.min_skill_level_id
+		 LEFT JOIN public.skill_levels hi ON hi.id = pg.max_skill_level_id
+		 LEFT JOIN public.user_skill_profiles usp ON usp.user_id = $2 AND usp.sport_id = pg.sport_id
+		 LEFT JOIN public.skill_levels usl ON usl.id = usp.skill_level_id
+		 WHERE pg.id = $1`,
+		groupID, userID,
+	).Scan(&userOrder, &minOrder, &maxOrder)
+	if err != nil {
+		return fmt.Errorf("check skill range failed: %w", err)
+	}
+	if userOrder == nil {
+		return ErrSkillProfileRequired
+	}
+	if (minOrder != nil && *userOrder < *minOrder) || (maxOrder != nil && *userOrder > *maxOrder) {
+		return ErrSkillLevelOutOfRange
+	}
+	return nil
+}
+
+// lockedGroup is the subset of a pickup group read under SELECT ... FOR UPDATE.
+type lockedGroup struct {
+	HostID         string
+	Capacity       int
+	Status         GroupStatus
+	StartTime      time.Time
+	EndTime        time.Time
+	LocationID     string
+	Fee            int
+	BookingID      *string
+	MinReliability int
+	Visibility     Visibility
+	InviteCode     *string
+	SportID        string
+	SkillRangeSet  bool
+	MaxGuests      *int
+	// EnrollmentClosed is evaluated against the database clock.
+	EnrollmentClosed bool
+}
+
+// lockGroup locks the pickup group row for the rest of the transaction,
+// serializing every capacity-affecting change to the group.
+func lockGroup(ctx context.Context, tx pgx.Tx, groupID string) (*lockedGroup, error) {
+	var g lockedGroup
+	if err := tx.QueryRow(ctx,
+		"SELECT host_id, capacity, status::TEXT, start_time, end_time, location_id, fee, booking_id, min_reliability, "+
+			"visibility::TEXT, invite_code, sport_id, "+
+			"(min_skill_level_id IS NOT NULL OR max_skill_level_id IS NOT NULL), max_guests_per_order, "+
+			"COALESCE(enrollment_deadline <= now(), false) "+
+			"FROM public.pickup_groups WHERE id = $1 FOR UPDATE",
+		groupID,
+	).Scan(&g.HostID, &g.Capacity, &g.Status, &g.StartTime, &g.EndTime, &g.LocationID, &g.Fee, &g.BookingID, &g.MinReliability,
+		&g.Visibility, &g.InviteCode, &g.SportID, &g.SkillRangeSet, &g.MaxGuests, &g.EnrollmentClosed); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrGroupNotFound
+		}
+		return nil, fmt.Errorf("lock pickup group failed: %w", err)
+	}
+	return &g, nil
+}
+
+// countOccupying sums the seats of a group's seat-occupying orders, optionally
+// excluding one order (pass "" to count all). Callers must hold the group lock.
+func countOccupying(ctx context.Context, tx pgx.Tx, groupID, excludeOrderID string) (int, error) {
+	var n int
+	if err := tx.QueryRow(ctx,
+		"SELECT COALESCE(SUM(seats), 0) FROM public.pickup_orders WHERE pickup_group_id = $1 "+
+			"AND id IS DISTINCT FROM NULLIF($2, '')::uuid "+
+			"AND status NOT IN ('cancelled', 'rejected', 'waitlisted')",
+		groupID, excludeOrderID,
+	).Scan(&n); err != nil {
+		return 0, fmt.Errorf("count enrollments failed: %w", err)
+	}
+	return n, nil
+}
+
+// promoteWaitlisted moves waitlisted orders into free seats, oldest first, as
+// pending orders awaiting host review. Promotion is strictly in queue order: it
+// stops at the first order whose seats no longer fit, so a large party at the
+// head is never overtaken by smaller ones behind it. It must run in the
+// transaction holding the group lock, after the change that freed the seats.
+// Groups that are no longer active keep their queue untouched.
+func promoteWaitlisted(ctx context.Context, tx pgx.Tx, groupID string) error {
+	g, err := lockGroup(ctx, tx, groupID)
+	if err != nil {
+		return err
+	}
+	if g.Status != GroupStatusActive {
+		return nil
+	}
+
+	occupied, err := countOccupying(ctx, tx, groupID, "")
+	if err != nil {
+		return err
+	}
+	free := g.Capacity - occupied
+	if free <= 0 {
+		return nil
+	}
+
+	if _, err := tx.Exec(ctx,
+		"UPDATE public.pickup_orders SET status = 'pending', waitlisted_at = NULL, updated_at = now() "+
+			"WHERE id IN (SELECT id FROM (SELECT id, "+
+			"SUM(seats) OVER (ORDER BY waitlisted_at, id) AS needed "+
+			"FROM public.pickup_orders WHERE pickup_group_id = $1 AND status = 'waitlisted') q "+
+			"WHERE needed <= $2)",
+		groupID, free,
+	); err != nil {
+		return fmt.Errorf("promote waitlisted pickup orders failed: %w", err)
+	}
+	return nil
+}
+
+// CreateGroup inserts the group. When the group names a court (ResourceID)
+// without a booking, a booking for the group's time is created in the same
+// transaction; when it names an existing BookingID, that booking is locked and
+// linked instead.
+func (r *pgxRepository) CreateGroup(ctx context.Context, g *PickupGroup, publish func(tx pgx.Tx, courts []booking.Change) error) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin transaction failed: %w", err)
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck
+
+	var courts []booking.Change
+	switch {
+	case g.BookingID != nil:
+		if err := lockLinkableBooking(ctx, tx, *g.BookingID); err != nil {
+			return err
+		}
+	case g.ResourceID != nil:
+		bookingID, err := insertCourtBooking(ctx, tx, g)
+		if err != nil {
+			return err
+		}
+		g.BookingID = &bookingID
+		courts = append(courts, booking.Change{BookingID: bookingID})
+	}
+
 	psql := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
 	query, args, err := psql.Insert("public.pickup_groups").
 		Columns("host_id", "title", "start_time", "end_time",
-			"fee", "capacity", "location_id", "sport_id", "skill_level_id", "status", "enable").
+			"fee", "capacity", "location_id", "sport_id", "skill_level_id", "status", "enable",
+			"resource_id", "booking_id", "min_participants", "min_reliability", "visibility", "invite_code",
+			"min_skill_level_id", "max_skill_level_id", "max_guests_per_order",
+			"enrollment_deadline", "cancel_deadline").
 		Values(g.HostID, g.Title, g.StartTime, g.EndTime,
-			g.Fee, g.Capacity, g.LocationID, g.SportID, g.SkillLevelID, g.Status, g.Enable).
+			g.Fee, g.Capacity, g.LocationID, g.SportID, g.SkillLevelID, g.Status, g.Enable,
+			g.ResourceID, g.BookingID, g.MinParticipants, g.MinReliability, g.Visibility, g.InviteCode,
+			g.MinSkillLevelID, g.MaxSkillLevelID, g.MaxGuestsPerOrder,
+			g.EnrollmentDeadline, g.CancelDeadline).
 		Suffix("RETURNING id, created_at, updated_at").
 		ToSql()
 	if err != nil {
 		return fmt.Errorf("build create pickup group query failed: %w", err)
 	}
 
-	return r.pool.QueryRow(ctx, query, args...).Scan(&g.ID, &g.CreatedAt, &g.UpdatedAt)
+	if err := tx.QueryRow(ctx, query, args...).Scan(&g.ID, &g.CreatedAt, &g.UpdatedAt); err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == pgerrcode.UniqueViolation &&
+			pgErr.ConstraintName == "uq_pickup_groups_booking_id" {
+			return ErrBookingUnavailable
+		}
+		return fmt.Errorf("create pickup group failed: %w", err)
+	}
+
+	if err := publish(tx, courts); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// insertCourtBooking books g's court for g's time on behalf of the host. The
+// bookings_no_overlap exclusion constraint is the final guard against the
+// court having been taken since the service pre-check.
+func insertCourtBooking(ctx context.Context, tx pgx.Tx, g *PickupGroup) (string, error) {
+	var id string
+	if err := tx.QueryRow(ctx,
+		"INSERT INTO public.bookings (resource_id, user_id, start_time, end_time, status) "+
+			"VALUES ($1, $2, $3, $4, 'pending') RETURNING id",
+		g.ResourceID, g.HostID, g.StartTime, g.EndTime,
+	).Scan(&id); err != nil {
+		return "", mapCourtError(err, "create court booking failed")
+	}
+	return id, nil
+}
+
+// lockLinkableBooking locks an existing booking so it cannot be cancelled while
+// it is being linked, and checks it is still live.
+func lockLinkableBooking(ctx context.Context, tx pgx.Tx, bookingID string) error {
+	var status string
+	if err := tx.QueryRow(ctx,
+		"SELECT status::TEXT FROM public.bookings WHERE id = $1 FOR UPDATE",
+		bookingID,
+	).Scan(&status); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrBookingNotFound
+		}
+		return fmt.Errorf("lock booking failed: %w", err)
+	}
+	if status == "cancelled" {
+		return ErrBookingUnavailable
+	}
+	return nil
+}
+
+// mapCourtError translates a bookings_no_overlap violation into
+// ErrCourtUnavailable and wraps any other error with msg.
+func mapCourtError(err error, msg string) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == pgerrcode.ExclusionViolation {
+		return ErrCourtUnavailable
+	}
+	return fmt.Errorf("%s: %w", msg, err)
 }
 
 func (r *pgxRepository) GetGroupByID(ctx context.Context, id string) (*PickupGroup, error) {
@@ -114,17 +468,38 @@ func (r *pgxRepository) GetGroupByID(ctx context.Context, id string) (*PickupGro
 	return &g, nil
 }
 
+// distanceKmExpr is the haversine great-circle distance in kilometres between
+// the joined location "l" and the point given by its latitude and longitude
+// arguments, in that order.
+const distanceKmExpr = "(6371 * 2 * ASIN(LEAST(1, SQRT(" +
+	"POWER(SIN(RADIANS(l.latitude::FLOAT8 - ?::FLOAT8) / 2), 2) + " +
+	"COS(RADIANS(?::FLOAT8)) * COS(RADIANS(l.latitude::FLOAT8)) * " +
+	"POWER(SIN(RADIANS(l.longitude::FLOAT8 - ?::FLOAT8) / 2), 2)))))"
+
 func (r *pgxRepository) ListGroups(ctx context.Context, filter GroupFilter) ([]*PickupGroup, int, error) {
 	psql := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
 
-	query := groupJoins(psql.Select(groupSelectColumns...).
+	base := psql.Select(groupSelectColumns...).
 		// enrolled_status resolves the viewer's own order status for the group.
 		// NULLIF guards against an empty (anonymous) viewer id, which cannot be
 		// cast to uuid; the unique (group, user) constraint bounds it to one row.
 		Column("(SELECT po2.status FROM public.pickup_orders po2 "+
 			"WHERE po2.pickup_group_id = pg.id AND po2.user_id = NULLIF(?, '')::uuid "+
-			"LIMIT 1) AS enrolled_status", filter.ViewerUserID).
-		Column("COUNT(*) OVER() AS total_count"))
+			"LIMIT 1) AS enrolled_status", filter.ViewerUserID)
+	var nearArgs []any
+	if filter.Near != nil {
+		nearArgs = []any{filter.Near.Latitude, filter.Near.Latitude, filter.Near.Longitude}
+		base = base.Column(distanceKmExpr+" AS distance_km", nearArgs...)
+	} else {
+		base = base.Column("NULL::FLOAT8 AS distance_km")
+	}
+	query := groupJoins(base.Column("COUNT(*) OVER() AS total_count"))
+	if filter.Near != nil {
+		query = query.Join("public.locations l ON pg.location_id = l.id").GroupBy("l.id")
+		if filter.RadiusKm > 0 {
+			query = query.Where(distanceKmExpr+" <= ?", append(nearArgs, filter.RadiusKm)...)
+		}
+	}
 
 	if filter.Status != "" {
 		query = query.Where(squirrel.Eq{"pg.status": filter.Status})
@@ -138,25 +513,54 @@ func (r *pgxRepository) ListGroups(ctx context.Context, filter GroupFilter) ([]*
 	if filter.HostID != "" {
 		query = query.Where(squirrel.Eq{"pg.host_id": filter.HostID})
 	}
+	if filter.FavoritedBy != "" {
+		query = query.Where("pg.host_id IN (SELECT fh.host_id FROM public.favorite_hosts fh WHERE fh.user_id = ?)",
+			filter.FavoritedBy)
+	}
+	if filter.PublicOnly {
+		query = query.Where(squirrel.Eq{"pg.visibility": string(VisibilityPublic)})
+	}
+	if filter.StartFrom != nil {
+		query = query.Where(squirrel.GtOrEq{"pg.start_time": *filter.StartFrom})
+	}
+	if filter.StartTo != nil {
+		query = query.Where(squirrel.LtOrEq{"pg.start_time": *filter.StartTo})
+	}
+	if filter.MaxFee != nil {
+		query = query.Where(squirrel.LtOrEq{"pg.fee": *filter.MaxFee})
+	}
+	if filter.MinFreeSeats > 0 {
+		query = query.Having("pg.capacity - "+occupiedSeatsExpr+" >= ?", filter.MinFreeSeats)
+	}
 	if filter.BookableOnly {
 		// Only groups that can still be enrolled into: active, enabled, not yet
-		// ended, and not fully booked.
+		// ended, enrollment still open, and not fully booked.
 		query = query.
 			Where(squirrel.Eq{"pg.status": string(GroupStatusActive)}).
 			Where(squirrel.Eq{"pg.enable": true}).
 			Where("pg.end_time > now()").
-			Having("COUNT(po.id) FILTER (WHERE po.status NOT IN ('cancelled', 'rejected')) < pg.capacity")
+			Where("(pg.enrollment_deadline IS NULL OR pg.enrollment_deadline > now())").
+			Having(occupiedSeatsExpr + " < pg.capacity")
 	}
 
-	orderBy := "pg.start_time"
-	if filter.SortBy != "" {
-		orderBy = "pg." + filter.SortBy
-	}
 	orderDir := "DESC"
 	if filter.SortOrder != "" {
 		orderDir = strings.ToUpper(filter.SortOrder)
+	} else if filter.SortBy == "distance" {
+		orderDir = "ASC"
+	}
+	switch filter.SortBy {
+	case "":
+		query = query.OrderBy("pg.start_time " + orderDir)
+	case "distance":
+		query = query.OrderBy("distance_km "+orderDir, "pg.start_time ASC")
+	case "host_rating":
+		// Unrated hosts sort last in either direction; ties fall back to the
+		// soonest session.
+		query = query.OrderBy("host_rating_average "+orderDir+" NULLS LAST", "host_rating_count DESC", "pg.start_time ASC")
+	default:
+		query = query.OrderBy("pg." + filter.SortBy + " " + orderDir)
 	}
-	query = query.OrderBy(orderBy + " " + orderDir)
 
 	if filter.Page < 1 {
 		filter.Page = 1
@@ -184,7 +588,7 @@ func (r *pgxRepository) ListGroups(ctx context.Context, filter GroupFilter) ([]*
 	for rows.Next() {
 		var g PickupGroup
 		var enrolledStatus *string
-		if err := rows.Scan(scanGroupInto(&g, &enrolledStatus, &total)...); err != nil {
+		if err := rows.Scan(scanGroupInto(&g, &enrolledStatus, &g.DistanceKm, &total)...); err != nil {
 			return nil, 0, fmt.Errorf("scan pickup group failed: %w", err)
 		}
 		if enrolledStatus != nil {
@@ -196,7 +600,40 @@ func (r *pgxRepository) ListGroups(ctx context.Context, filter GroupFilter) ([]*
 	return groups, total, nil
 }
 
-func (r *pgxRepository) UpdateGroup(ctx context.Context, g *PickupGroup) error {
+// UpdateGroup saves the group inside a transaction that locks the group row, so
+// the capacity can be re-validated against the live enrolled count and, when it
+// is raised, waitlisted orders are promoted into the new seats atomically. The
+// court booking is kept in step in the same transaction: a new court (ResourceID
+// set, BookingID nil) cancels the old booking and books the new court; otherwise
+// the linked booking follows the group's time and cancellation. The cascade
+// onto the group's orders (cancelled, completed or asked to reconfirm) is
+// decided against the locked previous state, so concurrent edits cannot apply
+// it twice.
+func (r *pgxRepository) UpdateGroup(ctx context.Context, g *PickupGroup, publish func(tx pgx.Tx, change *GroupChange) error) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin transaction failed: %w", err)
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck
+
+	locked, err := lockGroup(ctx, tx, g.ID)
+	if err != nil {
+		return err
+	}
+
+	currentEnrolled, err := countOccupying(ctx, tx, g.ID, "")
+	if err != nil {
+		return err
+	}
+	if g.Capacity < currentEnrolled {
+		return ErrCapacityBelowEnrolled
+	}
+
+	courts, err := syncCourtBooking(ctx, tx, g, locked)
+	if err != nil {
+		return err
+	}
+
 	psql := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
 	query, args, err := psql.Update("public.pickup_groups").
 		Set("title", g.Title).
@@ -209,6 +646,17 @@ func (r *pgxRepository) UpdateGroup(ctx context.Context, g *PickupGroup) error {
 		Set("skill_level_id", g.SkillLevelID).
 		Set("status", g.Status).
 		Set("enable", g.Enable).
+		Set("resource_id", g.ResourceID).
+		Set("booking_id", g.BookingID).
+		Set("min_participants", g.MinParticipants).
+		Set("min_reliability", g.MinReliability).
+		Set("visibility", g.Visibility).
+		Set("invite_code", g.InviteCode).
+		Set("min_skill_level_id", g.MinSkillLevelID).
+		Set("max_skill_level_id", g.MaxSkillLevelID).
+		Set("max_guests_per_order", g.MaxGuestsPerOrder).
+		Set("enrollment_deadline", g.EnrollmentDeadline).
+		Set("cancel_deadline", g.CancelDeadline).
 		Set("updated_at", squirrel.Expr("now()")).
 		Where(squirrel.Eq{"id": g.ID}).
 		Suffix("RETURNING updated_at").
@@ -217,16 +665,238 @@ func (r *pgxRepository) UpdateGroup(ctx context.Context, g *PickupGroup) error {
 		return fmt.Errorf("build update pickup group query failed: %w", err)
 	}
 
-	if err := r.pool.QueryRow(ctx, query, args...).Scan(&g.UpdatedAt); err != nil {
+	if err := tx.QueryRow(ctx, query, args...).Scan(&g.UpdatedAt); err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return ErrGroupNotFound
 		}
 		return fmt.Errorf("update pickup group failed: %w", err)
 	}
+
+	change := &GroupChange{Courts: courts}
+	switch {
+	case g.Status == GroupStatusCancelled && locked.Status != GroupStatusCancelled:
+		change.Cancelled = true
+		if change.Orders, err = cancelOpenOrders(ctx, tx, g); err != nil {
+			return err
+		}
+	case g.Status == GroupStatusCompleted && locked.Status != GroupStatusCompleted:
+		if err := completeOpenOrders(ctx, tx, g); err != nil {
+			return err
+		}
+	case g.Status == GroupStatusActive:
+		if change.Changes = materialChanges(g, locked); len(change.Changes) > 0 {
+			if change.Orders, err = requireReconfirm(ctx, tx, g); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := promoteWaitlisted(ctx, tx, g.ID); err != nil {
+		return err
+	}
+
+	if err := publish(tx, change); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// materialChanges lists the changes to g since locked that participants must
+// reconfirm: a new time or location, or a higher fee.
+func materialChanges(g *PickupGroup, locked *lockedGroup) []string {
+	var changes []string
+	if !g.StartTime.Equal(locked.StartTime) || !g.EndTime.Equal(locked.EndTime) {
+		changes = append(changes, ChangeTime)
+	}
+	if g.LocationID != locked.LocationID {
+		changes = append(changes, ChangeLocation)
+	}
+	if g.Fee > locked.Fee {
+		changes = append(changes, ChangeFee)
+	}
+	return changes
+}
+
+// cancelOpenOrders cancels the group's open orders within tx, flagging paid
+// ones for a refund.
+func cancelOpenOrders(ctx context.Context, tx pgx.Tx, g *PickupGroup) ([]*AffectedOrder, error) {
+	return queryAffectedOrders(ctx, tx, g, `
+		UPDATE public.pickup_orders
+		SET status = 'cancelled', waitlisted_at = NULL, reconfirm_required = false,
+		    refund_status = CASE WHEN payment_status = 'done' THEN 'pending'::pickup_refund_status
+		                         ELSE refund_status END,
+		    updated_at = now()
+		WHERE pickup_group_id = $1 AND status IN `+openOrderStatuses+`
+		RETURNING id, user_id, payment_status = 'done'`)
+}
+
+// completeOpenOrders settles the group's open orders within tx the way
+// CompleteEndedGroups does: confirmed orders are completed, the rest cancelled.
+func completeOpenOrders(ctx context.Context, tx pgx.Tx, g *PickupGroup) error {
+	if _, err := tx.Exec(ctx, `
+		UPDATE public.pickup_orders
+		SET status = CASE WHEN status = 'confirmed' THEN 'completed'::pickup_order_status
+		                  ELSE 'cancelled'::pickup_order_status END,
+		    waitlisted_at = NULL, reconfirm_required = false, updated_at = now()
+		WHERE pickup_group_id = $1 AND status IN `+openOrderStatuses,
+		g.ID,
+	); err != nil {
+		return fmt.Errorf("complete pickup group orders failed: %w", err)
+	}
 	return nil
 }
 
-func (r *pgxRepository) DeleteGroup(ctx context.Context, id string) error {
+// requireReconfirm flags the group's open orders for reconfirmation within tx.
+func requireReconfirm(ctx context.Context, tx pgx.Tx, g *PickupGroup) ([]*AffectedOrder, error) {
+	return queryAffectedOrders(ctx, tx, g, `
+		UPDATE public.pickup_orders
+		SET reconfirm_required = true, updated_at = now()
+		WHERE pickup_group_id = $1 AND status IN `+openOrderStatuses+`
+		RETURNING id, user_id, false`)
+}
+
+// queryAffectedOrders runs an order update on group g returning (id, user_id,
+// refund_due) rows.
+func queryAffectedOrders(ctx context.Context, tx pgx.Tx, g *PickupGroup, query string) ([]*AffectedOrder, error) {
+	rows, err := tx.Query(ctx, query, g.ID)
+	if err != nil {
+		return nil, fmt.Errorf("cascade pickup group change to orders failed: %w", err)
+	}
+	defer rows.Close()
+
+	var orders []*AffectedOrder
+	for rows.Next() {
+		o := &AffectedOrder{PickupGroupID: g.ID, GroupTitle: g.Title}
+		if err := rows.Scan(&o.OrderID, &o.UserID, &o.RefundDue); err != nil {
+			return nil, fmt.Errorf("scan affected pickup order failed: %w", err)
+		}
+		orders = append(orders, o)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("cascade pickup group change to orders failed: %w", err)
+	}
+	return orders, nil
+}
+
+// syncCourtBooking applies g's court, time and status to its booking and
+// returns the bookings it changed. It must run in the transaction holding the
+// group lock, before the group row is written; locked holds the group's
+// previous state.
+func syncCourtBooking(ctx context.Context, tx pgx.Tx, g *PickupGroup, locked *lockedGroup) ([]booking.Change, error) {
+	var courts []booking.Change
+
+	// Switching courts: release the old booking and reserve the new court.
+	if g.ResourceID != nil && g.BookingID == nil {
+		if locked.BookingID != nil {
+			cancelled, err := cancelBooking(ctx, tx, *locked.BookingID)
+			if err != nil {
+				return nil, err
+			}
+			if cancelled != nil {
+				courts = append(courts, *cancelled)
+			}
+		}
+		bookingID, err := insertCourtBooking(ctx, tx, g)
+		if err != nil {
+			return nil, err
+		}
+		g.BookingID = &bookingID
+		return append(courts, booking.Change{BookingID: bookingID}), nil
+	}
+
+	if g.BookingID == nil {
+		return nil, nil
+	}
+
+	previous, err := lockBookingSnapshot(ctx, tx, *g.BookingID)
+	if err != nil || previous == nil {
+		return nil, err
+	}
+
+	status := squirrel.Expr("status")
+	switch {
+	case g.Status == GroupStatusCancelled && locked.Status != GroupStatusCancelled:
+		status = squirrel.Expr("'cancelled'")
+	case g.Status == GroupStatusActive && locked.Status == GroupStatusCancelled:
+		// Re-activating the group asks the venue for the court again.
+		status = squirrel.Expr("'pending'")
+	}
+
+	psql := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
+	builder := psql.Update("public.bookings").
+		Set("status", status).
+		Set("updated_at", squirrel.Expr("now()")).
+		Where(squirrel.Eq{"id": *g.BookingID})
+	if !g.StartTime.Equal(locked.StartTime) || !g.EndTime.Equal(locked.EndTime) {
+		builder = builder.Set("start_time", g.StartTime).Set("end_time", g.EndTime)
+	}
+
+	query, args, err := builder.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build sync court booking query failed: %w", err)
+	}
+	if _, err := tx.Exec(ctx, query, args...); err != nil {
+		return nil, mapCourtError(err, "sync court booking failed")
+	}
+	return []booking.Change{{BookingID: *g.BookingID, Previous: previous}}, nil
+}
+
+// lockBookingSnapshot locks the booking for the rest of tx and returns its
+// current state, or nil when it no longer exists.
+func lockBookingSnapshot(ctx context.Context, tx pgx.Tx, bookingID string) (*booking.Snapshot, error) {
+	var s booking.Snapshot
+	if err := tx.QueryRow(ctx,
+		"SELECT start_time, end_time, status::TEXT, payment_status::TEXT FROM public.bookings WHERE id = $1 FOR UPDATE",
+		bookingID,
+	).Scan(&s.StartTime, &s.EndTime, &s.Status, &s.PaymentStatus); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("lock court booking failed: %w", err)
+	}
+	return &s, nil
+}
+
+// cancelBooking cancels the court booking, returning the change or nil when
+// it was cancelled already or no longer exists.
+func cancelBooking(ctx context.Context, tx pgx.Tx, bookingID string) (*booking.Change, error) {
+	previous, err := lockBookingSnapshot(ctx, tx, bookingID)
+	if err != nil || previous == nil || previous.Status == booking.StatusCancelled {
+		return nil, err
+	}
+	if _, err := tx.Exec(ctx,
+		"UPDATE public.bookings SET status = 'cancelled', updated_at = now() WHERE id = $1",
+		bookingID,
+	); err != nil {
+		return nil, fmt.Errorf("cancel court booking failed: %w", err)
+	}
+	return &booking.Change{BookingID: bookingID, Previous: previous}, nil
+}
+
+// DeleteGroup deletes the group and cancels its court booking in one
+// transaction, so a deleted group never leaves the court reserved.
+func (r *pgxRepository) DeleteGroup(ctx context.Context, id string, publish func(tx pgx.Tx, courts []booking.Change) error) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin transaction failed: %w", err)
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck
+
+	locked, err := lockGroup(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+	var courts []booking.Change
+	if locked.BookingID != nil {
+		cancelled, err := cancelBooking(ctx, tx, *locked.BookingID)
+		if err != nil {
+			return err
+		}
+		if cancelled != nil {
+			courts = append(courts, *cancelled)
+		}
+	}
+
 	psql := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
 	query, args, err := psql.Delete("public.pickup_groups").
 		Where(squirrel.Eq{"id": id}).
@@ -235,7 +905,7 @@ func (r *pgxRepository) DeleteGroup(ctx context.Context, id string) error {
 		return fmt.Errorf("build delete pickup group query failed: %w", err)
 	}
 
-	result, err := r.pool.Exec(ctx, query, args...)
+	result, err := tx.Exec(ctx, query, args...)
 	if err != nil {
 		return fmt.Errorf("delete pickup group failed: %w", err)
 	}
@@ -243,13 +913,17 @@ func (r *pgxRepository) DeleteGroup(ctx context.Context, id string) error {
 	if result.RowsAffected() == 0 {
 		return ErrGroupNotFound
 	}
-	return nil
+
+	if err := publish(tx, courts); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
 }
 
 // CreateOrder enrolls a user in a pickup group.
 // It uses a database transaction with SELECT FOR UPDATE on the pickup group row
 // to prevent overbooking under concurrent requests.
-func (r *pgxRepository) CreateOrder(ctx context.Context, order *PickupOrder) error {
+func (r *pgxRepository) CreateOrder(ctx context.Context, order *PickupOrder, joinWaitlist bool, inviteCode string) error {
 	tx, err := r.pool.Begin(ctx)
 	if err != nil {
 		return fmt.Errorf("begin transaction failed: %w", err)
@@ -257,28 +931,61 @@ func (r *pgxRepository) CreateOrder(ctx context.Context, order *PickupOrder) err
 	defer tx.Rollback(ctx) //nolint:errcheck
 
 	// Lock the pickup group row to serialize concurrent enrollment attempts.
-	var capacity int
-	var status string
+	locked, err := lockGroup(ctx, tx, order.PickupGroupID)
+	if err != nil {
+		return err
+	}
+
+	if locked.Status != GroupStatusActive {
+		return ErrGroupNotActive
+	}
+	if locked.EnrollmentClosed {
+		return ErrEnrollmentClosed
+	}
+	var blocked bool
 	if err := tx.QueryRow(ctx,
-		"SELECT capacity, status::TEXT FROM public.pickup_groups WHERE id = $1 FOR UPDATE",
-		order.PickupGroupID,
-	).Scan(&capacity, &status); err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
-			return ErrGroupNotFound
+		"SELECT EXISTS(SELECT 1 FROM public.pickup_host_blocks WHERE host_id = $1 AND user_id = $2)",
+		locked.HostID, order.UserID,
+	).Scan(&blocked); err != nil {
+		return fmt.Errorf("check pickup host block failed: %w", err)
+	}
+	if blocked {
+		return ErrBlockedByHost
+	}
+
+	// Users without any marked attendance have no score and are let in.
+	if locked.MinReliability > 0 {
+		rel, err := queryReliability(ctx, tx, []string{order.UserID})
+		if err != nil {
+			return err
+		}
+		if score := rel[order.UserID].Score; score != nil && *score < locked.MinReliability {
+			return ErrReliabilityTooLow
 		}
-		return fmt.Errorf("lock pickup group failed: %w", err)
 	}
 
-	if status != string(GroupStatusActive) {
-		return ErrGroupNotActive
+	if locked.SkillRangeSet {
+		if err := checkSkillRange(ctx, tx, order.PickupGroupID, order.UserID); err != nil {
+			return err
+		}
+	}
+
+	if locked.MaxGuests != nil && order.Seats-1 > *locked.MaxGuests {
+		return ErrTooManyGuests
+	}
+	// A party larger than the whole group could never be seated, not even
+	// from the waitlist.
+	if order.Seats > locked.Capacity {
+		return ErrSeatsOverCapacity
 	}
 
 	// Look up any order this user already has for the group. A rejected user is
 	// permanently blocked; a still-occupying enrollment (pending / confirmed /
-	// cancel_request) is a duplicate; only a fully cancelled order is re-usable,
-	// so the user may re-enroll and the existing row is reset in place. The group
-	// row is locked FOR UPDATE above, so enrollment attempts for this group are
-	// serialized and this read is stable within the transaction.
+	// cancel_request) or a waitlisted one is a duplicate; only a fully cancelled
+	// order is re-usable, so the user may re-enroll and the existing row is reset
+	// in place. The group row is locked FOR UPDATE above, so enrollment attempts
+	// for this group are serialized and this read is stable within the
+	// transaction.
 	var existingID, existingStatus string
 	err = tx.QueryRow(ctx,
 		"SELECT id, status::TEXT FROM public.pickup_orders WHERE pickup_group_id = $1 AND user_id = $2",
@@ -300,19 +1007,30 @@ func (r *pgxRepository) CreateOrder(ctx context.Context, order *PickupOrder) err
 		return fmt.Errorf("check existing pickup order failed: %w", err)
 	}
 
-	// Count occupying enrollments within the same transaction (reads the locked
+	// Private groups admit newcomers by invite code only; a user re-enrolling
+	// after cancelling was already let in once.
+	if locked.Visibility == VisibilityPrivate && existingID == "" &&
+		(locked.InviteCode == nil || *locked.InviteCode != inviteCode) {
+		return ErrInviteCodeRequired
+	}
+
+	// Count occupied seats within the same transaction (reads the locked
 	// snapshot). A re-usable cancelled row is excluded here, so it never
 	// double-counts against the capacity.
-	var currentEnrolled int
-	if err := tx.QueryRow(ctx,
-		"SELECT COUNT(*) FROM public.pickup_orders WHERE pickup_group_id = $1 AND status NOT IN ('cancelled', 'rejected')",
-		order.PickupGroupID,
-	).Scan(&currentEnrolled); err != nil {
-		return fmt.Errorf("count enrollments failed: %w", err)
+	currentEnrolled, err := countOccupying(ctx, tx, order.PickupGroupID, "")
+	if err != nil {
+		return err
 	}
 
-	if currentEnrolled >= capacity {
-		return ErrGroupFullyBooked
+	// A group without room for every seat of the order either queues it or
+	// turns it away.
+	waitlistedAt := any(nil)
+	if currentEnrolled+order.Seats > locked.Capacity {
+		if !joinWaitlist {
+			return ErrGroupFullyBooked
+		}
+		order.Status = OrderStatusWaitlisted
+		waitlistedAt = squirrel.Expr("now()")
 	}
 
 	psql := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
@@ -325,6 +1043,12 @@ func (r *pgxRepository) CreateOrder(ctx context.Context, order *PickupOrder) err
 			Set("payment_status", order.PaymentStatus).
 			Set("booker_name", order.BookerName).
 			Set("booker_phone", order.BookerPhone).
+			Set("seats", order.Seats).
+			Set("guest_names", order.GuestNames).
+			Set("waitlisted_at", waitlistedAt).
+			Set("attendance", nil).
+			Set("attendance_marked_at", nil).
+			Set("reconfirm_required", false).
 			Set("updated_at", squirrel.Expr("now()")).
 			Where(squirrel.Eq{"id": existingID}).
 			Suffix("RETURNING id, created_at, updated_at").
@@ -335,12 +1059,14 @@ func (r *pgxRepository) CreateOrder(ctx context.Context, order *PickupOrder) err
 		if err := tx.QueryRow(ctx, q, args...).Scan(&order.ID, &order.CreatedAt, &order.UpdatedAt); err != nil {
 			return fmt.Errorf("re-enroll pickup order failed: %w", err)
 		}
-		return tx.Commit(ctx)
+		return r.commitWithPosition(ctx, tx, order)
 	}
 
 	q, args, err := psql.Insert("public.pickup_orders").
-		Columns("pickup_group_id", "user_id", "booker_name", "booker_phone", "status", "payment_status").
-		Values(order.PickupGroupID, order.UserID, order.BookerName, order.BookerPhone, order.Status, order.PaymentStatus).
+		Columns("pickup_group_id", "user_id", "booker_name", "booker_phone", "status", "payment_status", "waitlisted_at",
+			"seats", "guest_names").
+		Values(order.PickupGroupID, order.UserID, order.BookerName, order.BookerPhone, order.Status, order.PaymentStatus, waitlistedAt,
+			order.Seats, order.GuestNames).
 		Suffix("RETURNING id, created_at, updated_at").
 		ToSql()
 	if err != nil {
@@ -355,27 +1081,37 @@ func (r *pgxRepository) CreateOrder(ctx context.Context, order *PickupOrder) err
 		return fmt.Errorf("create pickup order failed: %w", err)
 	}
 
+	return r.commitWithPosition(ctx, tx, order)
+}
+
+// commitWithPosition resolves the queue position of a freshly waitlisted order
+// inside the enrollment transaction, then commits it.
+func (r *pgxRepository) commitWithPosition(ctx context.Context, tx pgx.Tx, order *PickupOrder) error {
+	if order.Status == OrderStatusWaitlisted {
+		var position int
+		if err := tx.QueryRow(ctx,
+			"SELECT "+waitlistPositionExpr+" FROM public.pickup_orders po WHERE po.id = $1",
+			order.ID,
+		).Scan(&position); err != nil {
+			return fmt.Errorf("get waitlist position failed: %w", err)
+		}
+		order.WaitlistPosition = &position
+	}
 	return tx.Commit(ctx)
 }
 
 func (r *pgxRepository) GetOrderByID(ctx context.Context, id string) (*PickupOrder, error) {
 	psql := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
-	query, args, err := psql.Select(
-		"id", "pickup_group_id", "user_id", "booker_name", "booker_phone",
-		"status", "payment_status", "created_at", "updated_at",
-	).
-		From("public.pickup_orders").
-		Where(squirrel.Eq{"id": id}).
+	query, args, err := psql.Select(orderSelectColumns...).
+		From("public.pickup_orders po").
+		Where(squirrel.Eq{"po.id": id}).
 		ToSql()
 	if err != nil {
 		return nil, fmt.Errorf("build get pickup order query failed: %w", err)
 	}
 
 	var o PickupOrder
-	if err := r.pool.QueryRow(ctx, query, args...).Scan(
-		&o.ID, &o.PickupGroupID, &o.UserID, &o.BookerName, &o.BookerPhone,
-		&o.Status, &o.PaymentStatus, &o.CreatedAt, &o.UpdatedAt,
-	); err != nil {
+	if err := r.pool.QueryRow(ctx, query, args...).Scan(scanOrderInto(&o)...); err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, ErrOrderNotFound
 		}
@@ -386,13 +1122,10 @@ func (r *pgxRepository) GetOrderByID(ctx context.Context, id string) (*PickupOrd
 
 func (r *pgxRepository) GetOrdersByGroupID(ctx context.Context, groupID string) ([]*PickupOrder, error) {
 	psql := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
-	query, args, err := psql.Select(
-		"id", "pickup_group_id", "user_id", "booker_name", "booker_phone",
-		"status", "payment_status", "created_at", "updated_at",
-	).
-		From("public.pickup_orders").
-		Where(squirrel.Eq{"pickup_group_id": groupID}).
-		OrderBy("created_at ASC").
+	query, args, err := psql.Select(orderSelectColumns...).
+		From("public.pickup_orders po").
+		Where(squirrel.Eq{"po.pickup_group_id": groupID}).
+		OrderBy("po.created_at ASC").
 		ToSql()
 	if err != nil {
 		return nil, fmt.Errorf("build list pickup orders query failed: %w", err)
@@ -407,10 +1140,7 @@ func (r *pgxRepository) GetOrdersByGroupID(ctx context.Context, groupID string)
 	var orders []*PickupOrder
 	for rows.Next() {
 		var o PickupOrder
-		if err := rows.Scan(
-			&o.ID, &o.PickupGroupID, &o.UserID, &o.BookerName, &o.BookerPhone,
-			&o.Status, &o.PaymentStatus, &o.CreatedAt, &o.UpdatedAt,
-		); err != nil {
+		if err := rows.Scan(scanOrderInto(&o)...); err != nil {
 			return nil, fmt.Errorf("scan pickup order failed: %w", err)
 		}
 		orders = append(orders, &o)
@@ -420,13 +1150,10 @@ func (r *pgxRepository) GetOrdersByGroupID(ctx context.Context, groupID string)
 
 func (r *pgxRepository) GetOrdersByUserID(ctx context.Context, userID string) ([]*PickupOrder, error) {
 	psql := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
-	query, args, err := psql.Select(
-		"id", "pickup_group_id", "user_id", "booker_name", "booker_phone",
-		"status", "payment_status", "created_at", "updated_at",
-	).
-		From("public.pickup_orders").
-		Where(squirrel.Eq{"user_id": userID}).
-		OrderBy("created_at DESC").
+	query, args, err := psql.Select(orderSelectColumns...).
+		From("public.pickup_orders po").
+		Where(squirrel.Eq{"po.user_id": userID}).
+		OrderBy("po.created_at DESC").
 		ToSql()
 	if err != nil {
 		return nil, fmt.Errorf("build list pickup orders by user query failed: %w", err)
@@ -441,10 +1168,7 @@ func (r *pgxRepository) GetOrdersByUserID(ctx context.Context, userID string) ([
 	var orders []*PickupOrder
 	for rows.Next() {
 		var o PickupOrder
-		if err := rows.Scan(
-			&o.ID, &o.PickupGroupID, &o.UserID, &o.BookerName, &o.BookerPhone,
-			&o.Status, &o.PaymentStatus, &o.CreatedAt, &o.UpdatedAt,
-		); err != nil {
+		if err := rows.Scan(scanOrderInto(&o)...); err != nil {
 			return nil, fmt.Errorf("scan pickup order failed: %w", err)
 		}
 		orders = append(orders, &o)
@@ -452,29 +1176,74 @@ func (r *pgxRepository) GetOrdersByUserID(ctx context.Context, userID string) ([
 	return orders, nil
 }
 
-func (r *pgxRepository) UpdateOrder(ctx context.Context, o *PickupOrder) error {
-	psql := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
-	query, args, err := psql.Update("public.pickup_orders").
-		Set("status", o.Status).
-		Set("payment_status", o.PaymentStatus).
-		Set("updated_at", squirrel.Expr("now()")).
-		Where(squirrel.Eq{"id": o.ID}).
-		Suffix("RETURNING updated_at").
-		ToSql()
+func (r *pgxRepository) UpdateOrder(ctx context.Context, o *PickupOrder, publish event.Hook) error {
+	tx, err := r.pool.Begin(ctx)
 	if err != nil {
-		return fmt.Errorf("build update pickup order query failed: %w", err)
+		return fmt.Errorf("begin transaction failed: %w", err)
 	}
+	defer tx.Rollback(ctx) //nolint:errcheck
 
-	if err := r.pool.QueryRow(ctx, query, args...).Scan(&o.UpdatedAt); err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
-			return ErrOrderNotFound
-		}
-		return fmt.Errorf("update pickup order failed: %w", err)
+	if err := updateOrderTx(ctx, tx, o); err != nil {
+		return err
 	}
-	return nil
+
+	if err := publish.Run(tx); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// UpdateOrderAndPromote applies a seat-releasing order update and hands the
+// freed seat to the waitlist. Locking the group first serializes this against
+// enrollments, so a concurrent CreateOrder cannot take the seat ahead of the
+// queue.
+func (r *pgxRepository) UpdateOrderAndPromote(ctx context.Context, o *PickupOrder, publish event.Hook) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin transaction failed: %w", err)
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck
+
+	if _, err := lockGroup(ctx, tx, o.PickupGroupID); err != nil {
+		return err
+	}
+
+	if err := updateOrderTx(ctx, tx, o); err != nil {
+		return err
+	}
+
+	if err := promoteWaitlisted(ctx, tx, o.PickupGroupID); err != nil {
+		return err
+	}
+
+	if err := publish.Run(tx); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
 }
 
 func (r *pgxRepository) DeleteOrder(ctx context.Context, id string) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin transaction failed: %w", err)
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck
+
+	var groupID string
+	if err := tx.QueryRow(ctx,
+		"SELECT pickup_group_id FROM public.pickup_orders WHERE id = $1",
+		id,
+	).Scan(&groupID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrOrderNotFound
+		}
+		return fmt.Errorf("get pickup order group failed: %w", err)
+	}
+
+	if _, err := lockGroup(ctx, tx, groupID); err != nil {
+		return err
+	}
+
 	psql := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
 	query, args, err := psql.Delete("public.pickup_orders").
 		Where(squirrel.Eq{"id": id}).
@@ -483,56 +1252,76 @@ func (r *pgxRepository) DeleteOrder(ctx context.Context, id string) error {
 		return fmt.Errorf("build delete pickup order query failed: %w", err)
 	}
 
-	result, err := r.pool.Exec(ctx, query, args...)
+	result, err := tx.Exec(ctx, query, args...)
 	if err != nil {
 		return fmt.Errorf("delete pickup order failed: %w", err)
 	}
 	if result.RowsAffected() == 0 {
 		return ErrOrderNotFound
 	}
-	return nil
+
+	if err := promoteWaitlisted(ctx, tx, groupID); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
 }
 
 // UpdateOrderWithCapacityCheck applies an order update only if the group still
-// has room. It locks the group row and counts the other occupying orders within
+// has room for all of the order's seats. It locks the group row and sums the
+// seats of the other occupying orders within
 // the same transaction, mirroring CreateOrder, so concurrent reactivations and
 // enrollments cannot push the group over capacity.
-func (r *pgxRepository) UpdateOrderWithCapacityCheck(ctx context.Context, o *PickupOrder) error {
+func (r *pgxRepository) UpdateOrderWithCapacityCheck(ctx context.Context, o *PickupOrder, publish event.Hook) error {
 	tx, err := r.pool.Begin(ctx)
 	if err != nil {
 		return fmt.Errorf("begin transaction failed: %w", err)
 	}
 	defer tx.Rollback(ctx) //nolint:errcheck
 
-	var capacity int
-	if err := tx.QueryRow(ctx,
-		"SELECT capacity FROM public.pickup_groups WHERE id = $1 FOR UPDATE",
-		o.PickupGroupID,
-	).Scan(&capacity); err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
-			return ErrGroupNotFound
-		}
-		return fmt.Errorf("lock pickup group failed: %w", err)
+	g, err := lockGroup(ctx, tx, o.PickupGroupID)
+	if err != nil {
+		return err
 	}
 
-	// Count occupying orders other than this one; this order is about to become
-	// occupying, so it must fit within the remaining capacity.
-	var currentEnrolled int
-	if err := tx.QueryRow(ctx,
-		"SELECT COUNT(*) FROM public.pickup_orders WHERE pickup_group_id = $1 AND id <> $2 AND status NOT IN ('cancelled', 'rejected')",
-		o.PickupGroupID, o.ID,
-	).Scan(&currentEnrolled); err != nil {
-		return fmt.Errorf("count enrollments failed: %w", err)
+	// Count seats held by orders other than this one; this order is about to
+	// become occupying, so all of its seats must fit within the remaining
+	// capacity.
+	currentEnrolled, err := countOccupying(ctx, tx, o.PickupGroupID, o.ID)
+	if err != nil {
+		return err
 	}
 
-	if currentEnrolled >= capacity {
+	if currentEnrolled+o.Seats > g.Capacity {
 		return ErrGroupFullyBooked
 	}
 
+	if err := updateOrderTx(ctx, tx, o); err != nil {
+		return err
+	}
+
+	if err := publish.Run(tx); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// clearWaitlistedAt keeps an order's waitlist timestamp only while it stays
+// waitlisted, so the queue ordering never carries stale entries.
+func clearWaitlistedAt(status OrderStatus) squirrel.Sqlizer {
+	return squirrel.Expr("CASE WHEN ?::TEXT = 'waitlisted' THEN waitlisted_at END", string(status))
+}
+
+// updateOrderTx writes the order's status, payment and refund state within tx. The
+// waitlist timestamp is cleared whenever the order is no longer waitlisted.
+func updateOrderTx(ctx context.Context, tx pgx.Tx, o *PickupOrder) error {
 	psql := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
 	query, args, err := psql.Update("public.pickup_orders").
 		Set("status", o.Status).
 		Set("payment_status", o.PaymentStatus).
+		Set("waitlisted_at", clearWaitlistedAt(o.Status)).
+		Set("refund_status", o.RefundStatus).
+		Set("reconfirm_required", o.ReconfirmRequired).
 		Set("updated_at", squirrel.Expr("now()")).
 		Where(squirrel.Eq{"id": o.ID}).
 		Suffix("RETURNING updated_at").
@@ -547,6 +1336,775 @@ func (r *pgxRepository) UpdateOrderWithCapacityCheck(ctx context.Context, o *Pic
 		}
 		return fmt.Errorf("update pickup order failed: %w", err)
 	}
+	if o.Status != OrderStatusWaitlisted {
+		o.WaitlistPosition = nil
+	}
+	return nil
+}
 
-	return tx.Commit(ctx)
+// openOrderStatuses are the order statuses still awaiting an outcome; the
+// lifecycle transitions move them to a terminal status.
+const openOrderStatuses = "('pending', 'confirmed', 'cancel_request', 'waitlisted')"
+
+func (r *pgxRepository) CompleteEndedGroups(ctx context.Context, now time.Time) (int, error) {
+	// Groups locked by an in-flight enrollment or edit are skipped and picked up
+	// by the next run.
+	var n int
+	if err := r.pool.QueryRow(ctx, `
+		WITH due AS (
+			SELECT id FROM public.pickup_groups
+			WHERE status = 'active' AND end_time <= $1
+			FOR UPDATE SKIP LOCKED
+		), done AS (
+			UPDATE public.pickup_groups SET status = 'completed', updated_at = now()
+			WHERE id IN (SELECT id FROM due)
+			RETURNING id
+		), orders AS (
+			UPDATE public.pickup_orders
+			SET status = CASE WHEN status = 'confirmed' THEN 'completed'::pickup_order_status
+			                  ELSE 'cancelled'::pickup_order_status END,
+			    waitlisted_at = NULL, reconfirm_required = false, updated_at = now()
+			WHERE pickup_group_id IN (SELECT id FROM done) AND status IN `+openOrderStatuses+`
+		)
+		SELECT COUNT(*) FROM done`,
+		now,
+	).Scan(&n); err != nil {
+		return 0, fmt.Errorf("complete ended pickup groups failed: %w", err)
+	}
+	return n, nil
+}
+
+func (r *pgxRepository) CancelUnderfilledGroups(ctx context.Context, cutoff time.Time, publish func(tx pgx.Tx, cancelled []*AffectedOrder, courts []booking.Change) error) (int, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("begin transaction failed: %w", err)
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck
+
+	// One row per cancelled group and order; a group without open orders
+	// yields a single row with NULL order columns.
+	rows, err := tx.Query(ctx, `
+		WITH due AS (
+			SELECT pg.id FROM public.pickup_groups pg
+			WHERE pg.status = 'active' AND pg.min_participants > 0 AND pg.start_time <= $1
+			  AND (SELECT COALESCE(SUM(po.seats), 0) FROM public.pickup_orders po
+			       WHERE po.pickup_group_id = pg.id
+			         AND po.status NOT IN ('cancelled', 'rejected', 'waitlisted')) < pg.min_participants
+			FOR UPDATE OF pg SKIP LOCKED
+		), cancelled AS (
+			UPDATE public.pickup_groups SET status = 'cancelled', updated_at = now()
+			WHERE id IN (SELECT id FROM due)
+			RETURNING id, title, booking_id
+		), orders AS (
+			UPDATE public.pickup_orders
+			SET status = 'cancelled', waitlisted_at = NULL, reconfirm_required = false,
+			    refund_status = CASE WHEN payment_status = 'done' THEN 'pending'::pickup_refund_status
+			                         ELSE refund_status END,
+			    updated_at = now()
+			WHERE pickup_group_id IN (SELECT id FROM cancelled) AND status IN `+openOrderStatuses+`
+			RETURNING id, pickup_group_id, user_id, payment_status = 'done' AS refund_due
+		)
+		SELECT c.id, c.title, c.booking_id, o.id, o.user_id, o.refund_due
+		FROM cancelled c
+		LEFT JOIN orders o ON o.pickup_group_id = c.id`,
+		cutoff,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("cancel underfilled pickup groups failed: %w", err)
+	}
+	defer rows.Close()
+
+	groups := map[string]*string{}
+	var orders []*AffectedOrder
+	for rows.Next() {
+		var groupID, title string
+		var bookingID, orderID, userID *string
+		var refundDue *bool
+		if err := rows.Scan(&groupID, &title, &bookingID, &orderID, &userID, &refundDue); err != nil {
+			return 0, fmt.Errorf("scan cancelled pickup group failed: %w", err)
+		}
+		groups[groupID] = bookingID
+		if orderID != nil {
+			orders = append(orders, &AffectedOrder{
+				OrderID:       *orderID,
+				UserID:        *userID,
+				PickupGroupID: groupID,
+				GroupTitle:    title,
+				RefundDue:     *refundDue,
+			})
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("cancel underfilled pickup groups failed: %w", err)
+	}
+	if len(groups) == 0 {
+		return 0, nil
+	}
+
+	// The courts are released once the rows are read: the cancellations
+	// report each booking's state before it.
+	var courts []booking.Change
+	for _, bookingID := range groups {
+		if bookingID == nil {
+			continue
+		}
+		cancelled, err := cancelBooking(ctx, tx, *bookingID)
+		if err != nil {
+			return 0, err
+		}
+		if cancelled != nil {
+			courts = append(courts, *cancelled)
+		}
+	}
+
+	if err := publish(tx, orders, courts); err != nil {
+		return 0, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return 0, err
+	}
+	return len(groups), nil
+}
+
+func (r *pgxRepository) ClaimReminders(ctx context.Context, now time.Time, offsets []time.Duration, send func(tx pgx.Tx, reminders []*OrderReminder) error) (int, error) {
+	secs := make([]int32, len(offsets))
+	var maxSecs int32
+	for i, o := range offsets {
+		secs[i] = int32(o.Seconds())
+		maxSecs = max(maxSecs, secs[i])
+	}
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("begin transaction failed: %w", err)
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck
+
+	rows, err := tx.Query(ctx, `
+		WITH due AS (
+			SELECT po.id, pg.start_time, d.secs
+			FROM public.pickup_orders po
+			JOIN public.pickup_groups pg ON pg.id = po.pickup_group_id
+			CROSS JOIN LATERAL (
+				SELECT min(s) AS secs
+				FROM unnest($2::int[]) AS s
+				WHERE pg.start_time - make_interval(secs => s) <= $1
+			) d
+			WHERE po.status = 'confirmed' AND NOT po.reconfirm_required
+			  AND pg.status = 'active'
+			  AND pg.start_time > $1
+			  AND pg.start_time <= $1 + make_interval(secs => $3)
+			  AND d.secs IS NOT NULL
+			  AND po.created_at < pg.start_time - make_interval(secs => d.secs)
+		), claimed AS (
+			INSERT INTO public.pickup_order_reminders (order_id, before_seconds, start_time)
+			SELECT id, secs, start_time FROM due
+			ON CONFLICT DO NOTHING
+			RETURNING order_id, before_seconds
+		)
+		SELECT po.id, po.user_id, pg.id, pg.title, l.name, pg.start_time, pg.end_time, c.before_seconds
+		FROM claimed c
+		JOIN public.pickup_orders po ON po.id = c.order_id
+		JOIN public.pickup_groups pg ON pg.id = po.pickup_group_id
+		JOIN public.locations l ON l.id = pg.location_id
+		ORDER BY pg.start_time`,
+		now, secs, maxSecs,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("claim pickup order reminders failed: %w", err)
+	}
+	defer rows.Close()
+
+	var reminders []*OrderReminder
+	for rows.Next() {
+		var rem OrderReminder
+		var before int32
+		if err := rows.Scan(&rem.OrderID, &rem.UserID, &rem.PickupGroupID, &rem.GroupTitle, &rem.LocationName,
+			&rem.StartTime, &rem.EndTime, &before); err != nil {
+			return 0, fmt.Errorf("scan pickup order reminder failed: %w", err)
+		}
+		rem.Before = time.Duration(before) * time.Second
+		reminders = append(reminders, &rem)
+	}
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("iterate pickup order reminders failed: %w", err)
+	}
+	if len(reminders) == 0 {
+		return 0, nil
+	}
+
+	if err := send(tx, reminders); err != nil {
+		return 0, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return 0, err
+	}
+	return len(reminders), nil
+}
+
+func (r *pgxRepository) AddCoHost(ctx context.Context, groupID, userID, addedBy string) error {
+	if _, err := r.pool.Exec(ctx,
+		"INSERT INTO public.pickup_group_cohosts (pickup_group_id, user_id, added_by) VALUES ($1, $2, $3) "+
+			"ON CONFLICT DO NOTHING",
+		groupID, userID, addedBy,
+	); err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == pgerrcode.ForeignKeyViolation {
+			if pgErr.ConstraintName == "fk_pickup_group_cohosts_group" {
+				return ErrGroupNotFound
+			}
+			return ErrUserNotFound
+		}
+		return fmt.Errorf("add pickup group co-host failed: %w", err)
+	}
+	return nil
+}
+
+func (r *pgxRepository) RemoveCoHost(ctx context.Context, groupID, userID string) error {
+	result, err := r.pool.Exec(ctx,
+		"DELETE FROM public.pickup_group_cohosts WHERE pickup_group_id = $1 AND user_id = $2",
+		groupID, userID,
+	)
+	if err != nil {
+		return fmt.Errorf("remove pickup group co-host failed: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return ErrCoHostNotFound
+	}
+	return nil
+}
+
+func (r *pgxRepository) BlockUser(ctx context.Context, block *HostBlock) error {
+	if err := r.pool.QueryRow(ctx,
+		"INSERT INTO public.pickup_host_blocks (host_id, user_id, reason) VALUES ($1, $2, $3) "+
+			"ON CONFLICT (host_id, user_id) DO UPDATE SET reason = EXCLUDED.reason "+
+			"RETURNING created_at",
+		block.HostID, block.UserID, block.Reason,
+	).Scan(&block.CreatedAt); err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == pgerrcode.ForeignKeyViolation {
+			return ErrUserNotFound
+		}
+		return fmt.Errorf("block pickup user failed: %w", err)
+	}
+	return nil
+}
+
+func (r *pgxRepository) UnblockUser(ctx context.Context, hostID, userID string) error {
+	result, err := r.pool.Exec(ctx,
+		"DELETE FROM public.pickup_host_blocks WHERE host_id = $1 AND user_id = $2",
+		hostID, userID,
+	)
+	if err != nil {
+		return fmt.Errorf("unblock pickup user failed: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return ErrBlockNotFound
+	}
+	return nil
+}
+
+func (r *pgxRepository) ListHostBlocks(ctx context.Context, hostID string) ([]*HostBlock, error) {
+	rows, err := r.pool.Query(ctx,
+		"SELECT b.host_id, b.user_id, b.reason, b.created_at, u.username, u.display_name "+
+			"FROM public.pickup_host_blocks b JOIN public.users u ON u.id = b.user_id "+
+			"WHERE b.host_id = $1 ORDER BY b.created_at DESC, b.user_id",
+		hostID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("list pickup host blocks failed: %w", err)
+	}
+	defer rows.Close()
+
+	var blocks []*HostBlock
+	for rows.Next() {
+		var b HostBlock
+		if err := rows.Scan(&b.HostID, &b.UserID, &b.Reason, &b.CreatedAt, &b.Username, &b.DisplayName); err != nil {
+			return nil, fmt.Errorf("scan pickup host block failed: %w", err)
+		}
+		blocks = append(blocks, &b)
+	}
+	return blocks, rows.Err()
+}
+
+func (r *pgxRepository) BlockedUsers(ctx context.Context, hostID string, userIDs []string) (map[string]bool, error) {
+	blocked := make(map[string]bool)
+	if len(userIDs) == 0 {
+		return blocked, nil
+	}
+	rows, err := r.pool.Query(ctx,
+		"SELECT user_id::TEXT FROM public.pickup_host_blocks WHERE host_id = $1 AND user_id = ANY($2::uuid[])",
+		hostID, userIDs,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("check pickup host blocks failed: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("scan pickup host block failed: %w", err)
+		}
+		blocked[id] = true
+	}
+	return blocked, rows.Err()
+}
+
+func (r *pgxRepository) CreateReview(ctx context.Context, review *HostReview) error {
+	if err := r.pool.QueryRow(ctx, `
+		INSERT INTO public.pickup_host_reviews (order_id, pickup_group_id, host_id, reviewer_id, rating, comment)
+		SELECT po.id, pg.id, pg.host_id, po.user_id, $2, $3
+		FROM public.pickup_orders po
+		JOIN public.pickup_groups pg ON pg.id = po.pickup_group_id
+		WHERE po.id = $1 AND pg.status = 'completed' AND po.status IN ('confirmed', 'completed')
+		RETURNING id, pickup_group_id, host_id, reviewer_id, created_at`,
+		review.OrderID, review.Rating, review.Comment,
+	).Scan(&review.ID, &review.PickupGroupID, &review.HostID, &review.ReviewerID, &review.CreatedAt); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrReviewNotAllowed
+		}
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == pgerrcode.UniqueViolation {
+			return ErrAlreadyReviewed
+		}
+		return fmt.Errorf("create host review failed: %w", err)
+	}
+	return nil
+}
+
+func (r *pgxRepository) ListReviewsByHost(ctx context.Context, filter ReviewFilter) ([]*HostReview, int, error) {
+	if filter.Page < 1 {
+		filter.Page = 1
+	}
+	if filter.PageSize < 1 {
+		filter.PageSize = 20
+	}
+	offset := (filter.Page - 1) * filter.PageSize
+
+	psql := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
+	query, args, err := psql.Select(
+		"r.id", "r.order_id", "r.pickup_group_id", "r.host_id", "r.reviewer_id",
+		"r.rating", "r.comment", "r.created_at", "pg.title", "u.username", "u.display_name",
+		"COUNT(*) OVER() AS total_count",
+	).
+		From("public.pickup_host_reviews r").
+		Join("public.pickup_groups pg ON pg.id = r.pickup_group_id").
+		Join("public.users u ON u.id = r.reviewer_id").
+		Where(squirrel.Eq{"r.host_id": filter.HostID}).
+		OrderBy("r.created_at DESC", "r.id DESC").
+		Limit(uint64(filter.PageSize)).
+		Offset(uint64(offset)).
+		ToSql()
+	if err != nil {
+		return nil, 0, fmt.Errorf("build list host reviews query failed: %w", err)
+	}
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("list host reviews failed: %w", err)
+	}
+	defer rows.Close()
+
+	var reviews []*HostReview
+	var total int
+	for rows.Next() {
+		var rv HostReview
+		if err := rows.Scan(
+			&rv.ID, &rv.OrderID, &rv.PickupGroupID, &rv.HostID, &rv.ReviewerID,
+			&rv.Rating, &rv.Comment, &rv.CreatedAt, &rv.GroupTitle, &rv.ReviewerUsername, &rv.ReviewerDisplayName,
+			&total,
+		); err != nil {
+			return nil, 0, fmt.Errorf("scan host review failed: %w", err)
+		}
+		reviews = append(reviews, &rv)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("iterate host reviews failed: %w", err)
+	}
+
+	return reviews, total, nil
+}
+
+func (r *pgxRepository) UpdateAttendance(ctx context.Context, o *PickupOrder) error {
+	if err := r.pool.QueryRow(ctx,
+		"UPDATE public.pickup_orders SET attendance = $2, attendance_marked_at = now(), updated_at = now() "+
+			"WHERE id = $1 RETURNING updated_at",
+		o.ID, o.Attendance,
+	).Scan(&o.UpdatedAt); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrOrderNotFound
+		}
+		return fmt.Errorf("update pickup order attendance failed: %w", err)
+	}
+	return nil
+}
+
+func (r *pgxRepository) GetReliability(ctx context.Context, userIDs []string) (map[string]Reliability, error) {
+	return queryReliability(ctx, r.pool, userIDs)
+}
+
+// queryer is satisfied by both *pgxpool.Pool and pgx.Tx.
+type queryer interface {
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+}
+
+// queryReliability aggregates the marked attendance of each user. The score is
+// the attended share rounded to a whole percentage.
+func queryReliability(ctx context.Context, q queryer, userIDs []string) (map[string]Reliability, error) {
+	result := make(map[string]Reliability, len(userIDs))
+	if len(userIDs) == 0 {
+		return result, nil
+	}
+
+	rows, err := q.Query(ctx,
+		"SELECT user_id, "+
+			"COUNT(*) FILTER (WHERE attendance = 'attended'), "+
+			"COUNT(*) FILTER (WHERE attendance = 'no_show') "+
+			"FROM public.pickup_orders "+
+			"WHERE user_id = ANY($1::uuid[]) AND attendance IS NOT NULL "+
+			"GROUP BY user_id",
+		userIDs,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("query attendance reliability failed: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var userID string
+		var rel Reliability
+		if err := rows.Scan(&userID, &rel.Attended, &rel.NoShow); err != nil {
+			return nil, fmt.Errorf("scan attendance reliability failed: %w", err)
+		}
+		if total := rel.Attended + rel.NoShow; total > 0 {
+			score := (rel.Attended*100 + total/2) / total
+			rel.Score = &score
+		}
+		result[userID] = rel
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate attendance reliability failed: %w", err)
+	}
+	return result, nil
+}
+
+func (r *pgxRepository) HasParticipatingOrder(ctx context.Context, groupID, userID string) (bool, error) {
+	var ok bool
+	if err := r.pool.QueryRow(ctx,
+		"SELECT EXISTS(SELECT 1 FROM public.pickup_orders "+
+			"WHERE pickup_group_id = $1 AND user_id = $2 AND status IN ('pending', 'confirmed', 'completed'))",
+		groupID, userID,
+	).Scan(&ok); err != nil {
+		return false, fmt.Errorf("check participating pickup order failed: %w", err)
+	}
+	return ok, nil
+}
+
+// commentSelectColumns are the columns returned by the comment read queries on
+// a "public.pickup_group_comments c" selection, in the order scanCommentInto
+// expects.
+var commentSelectColumns = []string{
+	"c.id", "c.pickup_group_id", "c.author_id", "c.body", "c.pinned_at", "c.created_at",
+	"u.username", "u.display_name", "(c.author_id = pg.host_id) AS author_is_host",
+}
+
+func commentJoins(b squirrel.SelectBuilder) squirrel.SelectBuilder {
+	return b.
+		From("public.pickup_group_comments c").
+		Join("public.users u ON u.id = c.author_id").
+		Join("public.pickup_groups pg ON pg.id = c.pickup_group_id")
+}
+
+func scanCommentInto(c *Comment, extra ...any) []any {
+	targets := []any{
+		&c.ID, &c.PickupGroupID, &c.AuthorID, &c.Body, &c.PinnedAt, &c.CreatedAt,
+		&c.AuthorUsername, &c.AuthorDisplayName, &c.AuthorIsHost,
+	}
+	return append(targets, extra...)
+}
+
+func (r *pgxRepository) CreateComment(ctx context.Context, c *Comment) error {
+	if err := r.pool.QueryRow(ctx,
+		"INSERT INTO public.pickup_group_comments (pickup_group_id, author_id, body) "+
+			"VALUES ($1, $2, $3) RETURNING id, created_at",
+		c.PickupGroupID, c.AuthorID, c.Body,
+	).Scan(&c.ID, &c.CreatedAt); err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == pgerrcode.ForeignKeyViolation {
+			return ErrGroupNotFound
+		}
+		return fmt.Errorf("create pickup group comment failed: %w", err)
+	}
+	return nil
+}
+
+func (r *pgxRepository) GetCommentByID(ctx context.Context, groupID, commentID string) (*Comment, error) {
+	psql := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
+	query, args, err := commentJoins(psql.Select(commentSelectColumns...)).
+		Where(squirrel.Eq{"c.id": commentID, "c.pickup_group_id": groupID}).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build get pickup group comment query failed: %w", err)
+	}
+
+	var c Comment
+	if err := r.pool.QueryRow(ctx, query, args...).Scan(scanCommentInto(&c)...); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrCommentNotFound
+		}
+		return nil, fmt.Errorf("get pickup group comment failed: %w", err)
+	}
+	return &c, nil
+}
+
+func (r *pgxRepository) ListComments(ctx context.Context, filter CommentFilter) ([]*Comment, int, error) {
+	if filter.Page < 1 {
+		filter.Page = 1
+	}
+	if filter.PageSize < 1 {
+		filter.PageSize = 20
+	}
+	offset := (filter.Page - 1) * filter.PageSize
+
+	psql := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
+	query, args, err := commentJoins(psql.Select(commentSelectColumns...).
+		Column("COUNT(*) OVER() AS total_count")).
+		Where(squirrel.Eq{"c.pickup_group_id": filter.PickupGroupID}).
+		OrderBy("c.pinned_at DESC NULLS LAST", "c.created_at ASC", "c.id ASC").
+		Limit(uint64(filter.PageSize)).
+		Offset(uint64(offset)).
+		ToSql()
+	if err != nil {
+		return nil, 0, fmt.Errorf("build list pickup group comments query failed: %w", err)
+	}
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("list pickup group comments failed: %w", err)
+	}
+	defer rows.Close()
+
+	var comments []*Comment
+	var total int
+	for rows.Next() {
+		var c Comment
+		if err := rows.Scan(scanCommentInto(&c, &total)...); err != nil {
+			return nil, 0, fmt.Errorf("scan pickup group comment failed: %w", err)
+		}
+		comments = append(comments, &c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("iterate pickup group comments failed: %w", err)
+	}
+
+	return comments, total, nil
+}
+
+func (r *pgxRepository) SetCommentPinned(ctx context.Context, c *Comment, pinned bool) error {
+	if err := r.pool.QueryRow(ctx,
+		"UPDATE public.pickup_group_comments "+
+			"SET pinned_at = CASE WHEN $3 THEN COALESCE(pinned_at, now()) END "+
+			"WHERE id = $1 AND pickup_group_id = $2 RETURNING pinned_at",
+		c.ID, c.PickupGroupID, pinned,
+	).Scan(&c.PinnedAt); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrCommentNotFound
+		}
+		return fmt.Errorf("pin pickup group comment failed: %w", err)
+	}
+	return nil
+}
+
+func (r *pgxRepository) DeleteComment(ctx context.Context, groupID, commentID string) error {
+	ct, err := r.pool.Exec(ctx,
+		"DELETE FROM public.pickup_group_comments WHERE id = $1 AND pickup_group_id = $2",
+		commentID, groupID,
+	)
+	if err != nil {
+		return fmt.Errorf("delete pickup group comment failed: %w", err)
+	}
+	if ct.RowsAffected() == 0 {
+		return ErrCommentNotFound
+	}
+	return nil
+}
+
+func (r *pgxRepository) GetGroupByInviteCode(ctx context.Context, code string) (*PickupGroup, error) {
+	psql := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
+	query, args, err := groupJoins(psql.Select(groupSelectColumns...)).
+		Where(squirrel.Eq{"pg.invite_code": code}).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build get pickup group by invite code query failed: %w", err)
+	}
+
+	var g PickupGroup
+	if err := r.pool.QueryRow(ctx, query, args...).Scan(scanGroupInto(&g)...); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrInviteCodeNotFound
+		}
+		return nil, fmt.Errorf("get pickup group by invite code failed: %w", err)
+	}
+	return &g, nil
+}
+
+func (r *pgxRepository) SetInviteCode(ctx context.Context, groupID, code string) error {
+	ct, err := r.pool.Exec(ctx,
+		"UPDATE public.pickup_groups SET invite_code = $2, updated_at = now() WHERE id = $1",
+		groupID, code,
+	)
+	if err != nil {
+		return fmt.Errorf("set pickup group invite code failed: %w", err)
+	}
+	if ct.RowsAffected() == 0 {
+		return ErrGroupNotFound
+	}
+	return nil
+}
+
+func (r *pgxRepository) HasOrder(ctx context.Context, groupID, userID string) (bool, error) {
+	var ok bool
+	if err := r.pool.QueryRow(ctx,
+		"SELECT EXISTS(SELECT 1 FROM public.pickup_orders WHERE pickup_group_id = $1 AND user_id = $2)",
+		groupID, userID,
+	).Scan(&ok); err != nil {
+		return false, fmt.Errorf("check pickup order failed: %w", err)
+	}
+	return ok, nil
+}
+
+func (r *pgxRepository) ListFollowerIDs(ctx context.Context, hostID string) ([]string, error) {
+	rows, err := r.pool.Query(ctx,
+		"SELECT user_id::TEXT FROM public.favorite_hosts WHERE host_id = $1 ORDER BY created_at",
+		hostID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("list host followers failed: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("scan host follower failed: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// hostStatsGroups selects, per non-cancelled group of host $1 starting in
+// [$2, $3), its occupied and confirmed seats and marked attendance.
+const hostStatsGroups = `
+	SELECT pg.id, pg.sport_id, pg.capacity, pg.fee, pg.start_time,
+	       COALESCE(SUM(po.seats) FILTER (WHERE po.status NOT IN ('cancelled', 'rejected', 'waitlisted')), 0) AS seats,
+	       COALESCE(SUM(po.seats) FILTER (WHERE po.status IN ('confirmed', 'completed')), 0) AS paid_seats,
+	       COUNT(po.id) FILTER (WHERE po.attendance = 'attended') AS attended,
+	       COUNT(po.id) FILTER (WHERE po.attendance = 'no_show') AS no_show
+	FROM public.pickup_groups pg
+	LEFT JOIN public.pickup_orders po ON po.pickup_group_id = pg.id
+	WHERE pg.host_id = $1 AND pg.status <> 'cancelled'
+	  AND pg.start_time >= $2 AND pg.start_time < $3
+	GROUP BY pg.id`
+
+func (r *pgxRepository) GetHostStats(ctx context.Context, filter HostStatsFilter) (*HostStats, error) {
+	stats := &HostStats{}
+	bySport := map[string]*SportStats{}
+
+	rows, err := r.pool.Query(ctx, `
+		SELECT s.id, s.code, s.name, COUNT(g.id), SUM(g.capacity), SUM(g.seats),
+		       SUM(g.fee * g.paid_seats), SUM(g.attended), SUM(g.no_show)
+		FROM (`+hostStatsGroups+`) g
+		JOIN public.sports s ON s.id = g.sport_id
+		GROUP BY s.id
+		ORDER BY s.code`,
+		filter.HostID, filter.From, filter.To,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("get pickup host stats failed: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		s := &SportStats{}
+		if err := rows.Scan(&s.SportID, &s.SportCode, &s.SportName, &s.GroupCount, &s.Capacity, &s.Seats,
+			&s.ExpectedIncome, &s.Attended, &s.NoShow); err != nil {
+			return nil, fmt.Errorf("scan pickup host stats failed: %w", err)
+		}
+		stats.Sports = append(stats.Sports, s)
+		stats.Overall.add(s.StatsTotals)
+		bySport[s.SportID] = s
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("get pickup host stats failed: %w", err)
+	}
+
+	// Participants per sport, plus the overall row (sport_id NULL) so a user
+	// playing several sports is counted once there.
+	rows, err = r.pool.Query(ctx, `
+		SELECT u.sport_id, COUNT(*), COUNT(*) FILTER (WHERE u.groups >= 2)
+		FROM (
+			SELECT g.sport_id, po.user_id, COUNT(DISTINCT g.id) AS groups
+			FROM (`+hostStatsGroups+`) g
+			JOIN public.pickup_orders po ON po.pickup_group_id = g.id
+			WHERE po.status NOT IN ('cancelled', 'rejected', 'waitlisted')
+			GROUP BY GROUPING SETS ((g.sport_id, po.user_id), (po.user_id))
+		) u
+		GROUP BY u.sport_id`,
+		filter.HostID, filter.From, filter.To,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("count pickup host participants failed: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var sportID *string
+		var participants, repeat int
+		if err := rows.Scan(&sportID, &participants, &repeat); err != nil {
+			return nil, fmt.Errorf("scan pickup host participants failed: %w", err)
+		}
+		target := &stats.Overall
+		if sportID != nil {
+			s, ok := bySport[*sportID]
+			if !ok {
+				continue
+			}
+			target = &s.StatsTotals
+		}
+		target.Participants = participants
+		target.RepeatParticipants = repeat
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("count pickup host participants failed: %w", err)
+	}
+
+	rows, err = r.pool.Query(ctx, `
+		SELECT g.sport_id, date_trunc($4::TEXT, g.start_time AT TIME ZONE $5::TEXT)::DATE,
+		       COUNT(*), SUM(g.seats), SUM(g.fee * g.paid_seats)
+		FROM (`+hostStatsGroups+`) g
+		GROUP BY 1, 2
+		ORDER BY 1, 2`,
+		filter.HostID, filter.From, filter.To, filter.Period, filter.Timezone,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("get pickup host trend failed: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var sportID string
+		var p StatsTrendPoint
+		if err := rows.Scan(&sportID, &p.PeriodStart, &p.GroupCount, &p.Seats, &p.ExpectedIncome); err != nil {
+			return nil, fmt.Errorf("scan pickup host trend failed: %w", err)
+		}
+		if s, ok := bySport[sportID]; ok {
+			s.Trend = append(s.Trend, p)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("get pickup host trend failed: %w", err)
+	}
+	return stats, nil
 }
@@ -2,10 +2,19 @@ package pickup
 
 import (
 	"context"
+	"crypto/rand"
 	"errors"
+	"fmt"
+	"strings"
 	"time"
 
+	"github.com/jackc/pgx/v5"
+
+	"github.com/nekogravitycat/court-booking-backend/internal/booking"
+	"github.com/nekogravitycat/court-booking-backend/internal/pkg/event"
+	"github.com/nekogravitycat/court-booking-backend/internal/resource"
 	"github.com/nekogravitycat/court-booking-backend/internal/skilllevel"
+	"github.com/nekogravitycat/court-booking-backend/internal/skillprofile"
 	"github.com/nekogravitycat/court-booking-backend/internal/sports"
 	"github.com/nekogravitycat/court-booking-backend/internal/user"
 )
@@ -21,6 +30,32 @@ type CreateGroupRequest struct {
 	SportID      string
 	SkillLevelID string
 	Enable       bool
+	// MinParticipants enables auto-cancellation when the group has fewer
+	// seat-holding orders at the cutoff; 0 disables it.
+	MinParticipants int
+	// MinReliability is the reliability score (0-100) required to enroll; 0
+	// disables it.
+	MinReliability int
+	// Visibility defaults to public; unlisted and private groups get an
+	// invite code.
+	Visibility Visibility
+	// MinSkillLevelID and MaxSkillLevelID optionally bound the skill levels
+	// allowed to enroll; both must be levels of the group's sport.
+	MinSkillLevelID *string
+	MaxSkillLevelID *string
+	// MaxGuestsPerOrder caps the guests each order may bring; nil leaves it
+	// uncapped and 0 disallows guests.
+	MaxGuestsPerOrder *int
+	// EnrollmentDeadline and CancelDeadline optionally close enrollment and
+	// free cancellation; neither may fall after StartTime.
+	EnrollmentDeadline *time.Time
+	CancelDeadline     *time.Time
+	// ResourceID books this court for the group's time in the same
+	// transaction as the group.
+	ResourceID *string
+	// BookingID links an existing booking of the host's instead; it must cover
+	// the group's time range. ResourceID, if also given, must match it.
+	BookingID *string
 }
 
 type CreateOrderRequest struct {
@@ -28,6 +63,15 @@ type CreateOrderRequest struct {
 	UserID        string
 	BookerName    string
 	BookerPhone   string
+	// JoinWaitlist queues the order as waitlisted when the group is full,
+	// instead of failing with ErrGroupFullyBooked.
+	JoinWaitlist bool
+	// InviteCode admits the user to a private group.
+	InviteCode string
+	// Seats is the number of places to book, the user plus guests; 0 means 1.
+	// GuestNames optionally names up to Seats-1 guests without accounts.
+	Seats      int
+	GuestNames []string
 }
 
 type UpdateOrderRequest struct {
@@ -46,21 +90,156 @@ type UpdateGroupRequest struct {
 	SkillLevelID *string
 	Status       *string
 	Enable       *bool
+	// MinParticipants changes the auto-cancel headcount; 0 disables it.
+	MinParticipants *int
+	// MinReliability changes the reliability score required to enroll.
+	MinReliability *int
+	// Visibility changes who can find the group. Switching to public drops the
+	// invite code; switching away from public issues one.
+	Visibility *string
+	// MinSkillLevelID and MaxSkillLevelID change the allowed skill range.
+	// ClearSkillRange removes both bounds before any new bound is applied.
+	MinSkillLevelID *string
+	MaxSkillLevelID *string
+	ClearSkillRange bool
+	// MaxGuestsPerOrder changes the per-order guest cap; ClearMaxGuests removes
+	// it. The cap applies to new enrollments only.
+	MaxGuestsPerOrder *int
+	ClearMaxGuests    bool
+	// EnrollmentDeadline and CancelDeadline change the deadlines; the Clear
+	// flags remove them. They are re-checked against a moved start time.
+	EnrollmentDeadline      *time.Time
+	ClearEnrollmentDeadline bool
+	CancelDeadline          *time.Time
+	ClearCancelDeadline     bool
+	// ResourceID moves the group to another court: the old booking is
+	// cancelled and the new court is booked.
+	ResourceID *string
+}
+
+// CreateReviewRequest rates the host of the group an order belongs to.
+type CreateReviewRequest struct {
+	OrderID    string
+	ReviewerID string
+	Rating     int
+	Comment    *string
+}
+
+// HostStatsRequest selects a host's groups starting between two local calendar
+// dates (both inclusive).
+type HostStatsRequest struct {
+	HostID   string
+	From     time.Time
+	To       time.Time
+	Period   string // trend bucket: day, week or month; defaults to month
+	Timezone string // defaults to UTC
+}
+
+// BlockUserRequest adds a user to a host's blocklist.
+type BlockUserRequest struct {
+	HostID string
+	UserID string
+	Reason *string
+}
+
+// CreateCommentRequest posts a message to a group's thread.
+type CreateCommentRequest struct {
+	PickupGroupID string
+	AuthorID      string
+	Body          string
 }
 
 type Service interface {
 	CreateGroup(ctx context.Context, req CreateGroupRequest) (*PickupGroup, error)
 	GetGroupByID(ctx context.Context, id string) (*PickupGroup, error)
+	// GetGroupForViewer is GetGroupByID with visibility enforced: a private
+	// group is reported as not found unless the viewer is its host, a system
+	// admin, already has an order in it, or presents the invite code.
+	GetGroupForViewer(ctx context.Context, id, viewerUserID string, isSysAdmin bool, inviteCode string) (*PickupGroup, error)
+	// GetGroupByInviteCode resolves an invite link to its group.
+	GetGroupByInviteCode(ctx context.Context, code string) (*PickupGroup, error)
+	// RotateInviteCode issues a new invite code for an unlisted or private
+	// group, invalidating the old one. Host, co-host or system admin only.
+	RotateInviteCode(ctx context.Context, id, userID string, isSysAdmin bool) (*PickupGroup, error)
 	ListGroups(ctx context.Context, filter GroupFilter) ([]*PickupGroup, int, error)
+	// UpdateGroup applies the changes and cascades them onto the group's open
+	// orders: cancelling the group cancels them (paid ones owe a refund), and
+	// moving its time or location or raising its fee asks participants to
+	// reconfirm. Every affected participant is sent an event.
 	UpdateGroup(ctx context.Context, id string, req UpdateGroupRequest) (*PickupGroup, error)
 	DeleteGroup(ctx context.Context, id string) error
 
+	// AddCoHost and RemoveCoHost manage the group's co-hosts. Only the group
+	// host or a system admin may manage them.
+	AddCoHost(ctx context.Context, groupID, coHostID, userID string, isSysAdmin bool) (*PickupGroup, error)
+	RemoveCoHost(ctx context.Context, groupID, coHostID, userID string, isSysAdmin bool) error
+
+	// BlockUser, UnblockUser and ListHostBlocks manage a host's blocklist,
+	// which keeps users out of every group of the host. Only the host or a
+	// system admin may manage or view it.
+	BlockUser(ctx context.Context, req BlockUserRequest, actorID string, isSysAdmin bool) (*HostBlock, error)
+	UnblockUser(ctx context.Context, hostID, userID, actorID string, isSysAdmin bool) error
+	ListHostBlocks(ctx context.Context, hostID, actorID string, isSysAdmin bool) ([]*HostBlock, error)
+	// BlockedUsers reports which of the users the host has blocked.
+	BlockedUsers(ctx context.Context, hostID string, userIDs []string) (map[string]bool, error)
+
 	GetOrdersByGroupID(ctx context.Context, groupID string) ([]*PickupOrder, error)
 	GetOrdersByUserID(ctx context.Context, userID string) ([]*PickupOrder, error)
 
 	CreateOrder(ctx context.Context, req CreateOrderRequest) (*PickupOrder, error)
 	UpdateOrder(ctx context.Context, id string, req UpdateOrderRequest, updaterUserID string, isSysAdmin bool) (*PickupOrder, error)
+	// ReconfirmOrder keeps the caller's order after a material group change.
+	// Withdrawing instead is a plain cancellation, refunded in full if paid.
+	ReconfirmOrder(ctx context.Context, id, userID string) (*PickupOrder, error)
 	DeleteOrder(ctx context.Context, id, requesterUserID string, isSysAdmin bool) error
+
+	// CreateReview lets the participant of a confirmed order in a completed
+	// group rate that group's host, once per order.
+	CreateReview(ctx context.Context, req CreateReviewRequest) (*HostReview, error)
+	ListReviewsByHost(ctx context.Context, filter ReviewFilter) ([]*HostReview, int, error)
+
+	// MarkAttendance records whether a confirmed participant showed up. Only
+	// the group host, a co-host or a system admin may mark, and only once the
+	// group has started.
+	MarkAttendance(ctx context.Context, orderID string, attendance Attendance, markerUserID string, isSysAdmin bool) (*PickupOrder, error)
+	// GetReliability returns the attendance history of each given user.
+	GetReliability(ctx context.Context, userIDs []string) (map[string]Reliability, error)
+
+	// VerifySkill lets the host or a co-host confirm, after the group has started, the skill
+	// level a confirmed participant showed in the group's sport.
+	VerifySkill(ctx context.Context, orderID, skillLevelID, verifierUserID string, isSysAdmin bool) (*skillprofile.SkillProfile, error)
+
+	// The comment thread of a group is open to its host and co-hosts, system admins and
+	// users holding a pending, confirmed or completed order.
+	ListComments(ctx context.Context, filter CommentFilter, viewerUserID string, isSysAdmin bool) ([]*Comment, int, error)
+	CreateComment(ctx context.Context, req CreateCommentRequest, isSysAdmin bool) (*Comment, error)
+	// SetCommentPinned pins or unpins a comment; host, co-host or system admin
+	// only.
+	SetCommentPinned(ctx context.Context, groupID, commentID string, pinned bool, userID string, isSysAdmin bool) (*Comment, error)
+	// DeleteComment removes a comment; allowed for its author, the host, a
+	// co-host, or a system admin.
+	DeleteComment(ctx context.Context, groupID, commentID, userID string, isSysAdmin bool) error
+
+	// AdvanceLifecycle runs one pass of the automatic group lifecycle: active
+	// groups that have ended are completed, then active groups starting within
+	// cancelCutoff of now that are below their minimum headcount are cancelled.
+	// It is run periodically by a background worker.
+	AdvanceLifecycle(ctx context.Context, now time.Time, cancelCutoff time.Duration) (LifecycleResult, error)
+	// SendReminders publishes the reminders due at now for confirmed orders,
+	// offsets being how long before the group starts to remind, and returns
+	// how many were sent. It is run periodically by a background worker and
+	// is safe to run on several replicas at once.
+	SendReminders(ctx context.Context, now time.Time, offsets []time.Duration) (int, error)
+
+	// GetHostStats computes a host's dashboard statistics over a date range.
+	GetHostStats(ctx context.Context, req HostStatsRequest) (*HostStats, error)
+
+	// ValidateSportAndSkill verifies the sport exists and is active, and that the
+	// skill level exists, is active, and belongs to that sport.
+	ValidateSportAndSkill(ctx context.Context, sportID, skillLevelID string) error
+	// NotifyFollowers tells the users who favorited the host about a group
+	// created outside CreateGroup, such as a template occurrence, within tx.
+	NotifyFollowers(ctx context.Context, tx pgx.Tx, group *PickupGroup) error
 }
 
 type service struct {
@@ -68,20 +247,26 @@ type service struct {
 	userService       user.Service
 	sportsService     sports.Service
 	skillLevelService skilllevel.Service
+	resService        resource.Service
+	bookingService    booking.Service
+	skillProfiles     skillprofile.Service
+	publisher         event.Publisher
 }
 
-func NewService(repo Repository, userService user.Service, sportsService sports.Service, skillLevelService skilllevel.Service) Service {
+func NewService(repo Repository, userService user.Service, sportsService sports.Service, skillLevelService skilllevel.Service, resService resource.Service, bookingService booking.Service, skillProfiles skillprofile.Service, publisher event.Publisher) Service {
 	return &service{
 		repo:              repo,
 		userService:       userService,
 		sportsService:     sportsService,
 		skillLevelService: skillLevelService,
+		resService:        resService,
+		bookingService:    bookingService,
+		skillProfiles:     skillProfiles,
+		publisher:         publisher,
 	}
 }
 
-// validateSportAndSkill verifies the sport exists and is active, and that the
-// skill level exists, is active, and belongs to that sport.
-func (s *service) validateSportAndSkill(ctx context.Context, sportID, skillLevelID string) error {
+func (s *service) ValidateSportAndSkill(ctx context.Context, sportID, skillLevelID string) error {
 	sport, err := s.sportsService.GetByID(ctx, sportID)
 	if err != nil {
 		if errors.Is(err, sports.ErrNotFound) {
@@ -109,40 +294,217 @@ func (s *service) validateSportAndSkill(ctx context.Context, sportID, skillLevel
 	return nil
 }
 
+// validateSkillRange checks that each given bound is a skill level of the
+// sport and that the minimum does not rank above the maximum.
+func (s *service) validateSkillRange(ctx context.Context, sportID string, minID, maxID *string) error {
+	orders := make([]int, 0, 2)
+	for _, id := range []*string{minID, maxID} {
+		if id == nil {
+			continue
+		}
+		sl, err := s.skillLevelService.GetByID(ctx, *id)
+		if err != nil {
+			if errors.Is(err, skilllevel.ErrNotFound) {
+				return ErrSkillLevelNotFound
+			}
+			return err
+		}
+		if sl.SportID != sportID {
+			return ErrSkillLevelMismatch
+		}
+		orders = append(orders, sl.SortOrder)
+	}
+	if minID != nil && maxID != nil && orders[0] > orders[1] {
+		return ErrInvalidSkillRange
+	}
+	return nil
+}
+
 func (s *service) CreateGroup(ctx context.Context, req CreateGroupRequest) (*PickupGroup, error) {
 	if !req.EndTime.After(req.StartTime) {
 		return nil, ErrInvalidTimeRange
 	}
+	if req.MinParticipants < 0 || req.MinParticipants > req.Capacity {
+		return nil, ErrInvalidMinParticipants
+	}
+	if req.MinReliability < 0 || req.MinReliability > 100 {
+		return nil, ErrInvalidMinReliability
+	}
+	if req.MaxGuestsPerOrder != nil && *req.MaxGuestsPerOrder < 0 {
+		return nil, ErrInvalidMaxGuests
+	}
+	if req.Visibility == "" {
+		req.Visibility = VisibilityPublic
+	}
+	if !req.Visibility.IsValid() {
+		return nil, ErrInvalidVisibility
+	}
 
-	if err := s.validateSportAndSkill(ctx, req.SportID, req.SkillLevelID); err != nil {
+	if err := s.ValidateSportAndSkill(ctx, req.SportID, req.SkillLevelID); err != nil {
+		return nil, err
+	}
+	if err := s.validateSkillRange(ctx, req.SportID, req.MinSkillLevelID, req.MaxSkillLevelID); err != nil {
 		return nil, err
 	}
 
 	group := &PickupGroup{
-		HostID:       req.HostID,
-		Title:        req.Title,
-		StartTime:    req.StartTime,
-		EndTime:      req.EndTime,
-		Fee:          req.Fee,
-		Capacity:     req.Capacity,
-		LocationID:   req.LocationID,
-		SportID:      req.SportID,
-		SkillLevelID: req.SkillLevelID,
-		Status:       GroupStatusActive,
-		Enable:       req.Enable,
+		HostID:             req.HostID,
+		Title:              req.Title,
+		StartTime:          req.StartTime,
+		EndTime:            req.EndTime,
+		Fee:                req.Fee,
+		Capacity:           req.Capacity,
+		LocationID:         req.LocationID,
+		SportID:            req.SportID,
+		SkillLevelID:       req.SkillLevelID,
+		Status:             GroupStatusActive,
+		Enable:             req.Enable,
+		MinParticipants:    req.MinParticipants,
+		MinReliability:     req.MinReliability,
+		Visibility:         req.Visibility,
+		MinSkillLevelID:    req.MinSkillLevelID,
+		MaxSkillLevelID:    req.MaxSkillLevelID,
+		MaxGuestsPerOrder:  req.MaxGuestsPerOrder,
+		EnrollmentDeadline: req.EnrollmentDeadline,
+		CancelDeadline:     req.CancelDeadline,
+	}
+	if !group.deadlinesValid() {
+		return nil, ErrInvalidDeadline
+	}
+	if group.Visibility != VisibilityPublic {
+		code, err := newInviteCode()
+		if err != nil {
+			return nil, err
+		}
+		group.InviteCode = &code
+	}
+
+	switch {
+	case req.BookingID != nil:
+		if err := s.checkLinkableBooking(ctx, group, *req.BookingID, req.ResourceID); err != nil {
+			return nil, err
+		}
+	case req.ResourceID != nil:
+		group.ResourceID = req.ResourceID
+		if err := s.checkCourt(ctx, group, ""); err != nil {
+			return nil, err
+		}
 	}
 
-	if err := s.repo.CreateGroup(ctx, group); err != nil {
+	err := s.repo.CreateGroup(ctx, group, func(tx pgx.Tx, courts []booking.Change) error {
+		if err := s.bookingService.PublishChanges(ctx, tx, courts, false); err != nil {
+			return err
+		}
+		return s.NotifyFollowers(ctx, tx, group)
+	})
+	if err != nil {
 		return nil, err
 	}
 
 	return s.repo.GetGroupByID(ctx, group.ID)
 }
 
+// NotifyFollowers tells the users who favorited the host about a new group
+// they could join. Unlisted, private and disabled groups are not announced.
+func (s *service) NotifyFollowers(ctx context.Context, tx pgx.Tx, group *PickupGroup) error {
+	if group.Visibility != VisibilityPublic || !group.Enable {
+		return nil
+	}
+	followers, err := s.repo.ListFollowerIDs(ctx, group.HostID)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	events := make([]event.Event, 0, len(followers))
+	for _, userID := range followers {
+		if userID == group.HostID {
+			continue
+		}
+		events = append(events, event.Event{
+			Type:   EventFavoriteHostGroupCreated,
+			UserID: userID,
+			Data: map[string]any{
+				"pickup_group_id": group.ID,
+				"group_title":     group.Title,
+				"host_id":         group.HostID,
+				"sport_id":        group.SportID,
+				"start_time":      group.StartTime,
+			},
+			OccurredAt: now,
+		})
+	}
+	return s.publish(ctx, tx, events)
+}
+
 func (s *service) GetGroupByID(ctx context.Context, id string) (*PickupGroup, error) {
 	return s.repo.GetGroupByID(ctx, id)
 }
 
+func (s *service) GetGroupForViewer(ctx context.Context, id, viewerUserID string, isSysAdmin bool, inviteCode string) (*PickupGroup, error) {
+	group, err := s.repo.GetGroupByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if group.Visibility != VisibilityPrivate || isSysAdmin || group.IsManager(viewerUserID) {
+		return group, nil
+	}
+	if inviteCode != "" && group.InviteCode != nil && *group.InviteCode == inviteCode {
+		return group, nil
+	}
+	enrolled, err := s.repo.HasOrder(ctx, id, viewerUserID)
+	if err != nil {
+		return nil, err
+	}
+	if !enrolled {
+		// Do not reveal that a private group exists.
+		return nil, ErrGroupNotFound
+	}
+	return group, nil
+}
+
+func (s *service) GetGroupByInviteCode(ctx context.Context, code string) (*PickupGroup, error) {
+	return s.repo.GetGroupByInviteCode(ctx, code)
+}
+
+func (s *service) RotateInviteCode(ctx context.Context, id, userID string, isSysAdmin bool) (*PickupGroup, error) {
+	group, err := s.repo.GetGroupByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !isSysAdmin && !group.IsManager(userID) {
+		return nil, ErrPermissionDenied
+	}
+	if group.Visibility == VisibilityPublic {
+		return nil, ErrPublicGroupNoInvite
+	}
+
+	code, err := newInviteCode()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.SetInviteCode(ctx, id, code); err != nil {
+		return nil, err
+	}
+	return s.repo.GetGroupByID(ctx, id)
+}
+
+// inviteCodeAlphabet omits look-alike characters (0/O, 1/I/L) so codes can be
+// read out loud or typed from a screenshot.
+const inviteCodeAlphabet = "23456789ABCDEFGHJKMNPQRSTUVWXYZ"
+
+// newInviteCode returns a random 10-character invite code (~49 bits).
+func newInviteCode() (string, error) {
+	buf := make([]byte, 10)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("generate invite code failed: %w", err)
+	}
+	for i, b := range buf {
+		buf[i] = inviteCodeAlphabet[int(b)%len(inviteCodeAlphabet)]
+	}
+	return string(buf), nil
+}
+
 func (s *service) ListGroups(ctx context.Context, filter GroupFilter) ([]*PickupGroup, int, error) {
 	return s.repo.ListGroups(ctx, filter)
 }
@@ -152,6 +514,8 @@ func (s *service) UpdateGroup(ctx context.Context, id string, req UpdateGroupReq
 	if err != nil {
 		return nil, err
 	}
+	oldStatus := group.Status
+	oldStart, oldEnd, oldLocation := group.StartTime, group.EndTime, group.LocationID
 
 	if req.Title != nil {
 		group.Title = *req.Title
@@ -168,11 +532,46 @@ func (s *service) UpdateGroup(ctx context.Context, id string, req UpdateGroupReq
 	if req.Capacity != nil {
 		// Do not allow lowering capacity below the number of participants already
 		// occupying a seat; otherwise the group would be silently over capacity.
+		// The repository re-checks this under the group lock and promotes
+		// waitlisted orders into any seats a capacity increase opens up.
 		if *req.Capacity < group.CurrentEnrolled {
 			return nil, ErrCapacityBelowEnrolled
 		}
 		group.Capacity = *req.Capacity
 	}
+	if req.MinParticipants != nil {
+		group.MinParticipants = *req.MinParticipants
+	}
+	if group.MinParticipants < 0 || group.MinParticipants > group.Capacity {
+		return nil, ErrInvalidMinParticipants
+	}
+	if req.MinReliability != nil {
+		if *req.MinReliability < 0 || *req.MinReliability > 100 {
+			return nil, ErrInvalidMinReliability
+		}
+		group.MinReliability = *req.MinReliability
+	}
+	if req.ClearMaxGuests {
+		group.MaxGuestsPerOrder = nil
+	}
+	if req.MaxGuestsPerOrder != nil {
+		if *req.MaxGuestsPerOrder < 0 {
+			return nil, ErrInvalidMaxGuests
+		}
+		group.MaxGuestsPerOrder = req.MaxGuestsPerOrder
+	}
+	if req.ClearEnrollmentDeadline {
+		group.EnrollmentDeadline = nil
+	}
+	if req.EnrollmentDeadline != nil {
+		group.EnrollmentDeadline = req.EnrollmentDeadline
+	}
+	if req.ClearCancelDeadline {
+		group.CancelDeadline = nil
+	}
+	if req.CancelDeadline != nil {
+		group.CancelDeadline = req.CancelDeadline
+	}
 	if req.LocationID != nil {
 		group.LocationID = *req.LocationID
 	}
@@ -189,7 +588,28 @@ func (s *service) UpdateGroup(ctx context.Context, id string, req UpdateGroupReq
 		sportOrSkillChanged = true
 	}
 	if sportOrSkillChanged {
-		if err := s.validateSportAndSkill(ctx, group.SportID, group.SkillLevelID); err != nil {
+		if err := s.ValidateSportAndSkill(ctx, group.SportID, group.SkillLevelID); err != nil {
+			return nil, err
+		}
+	}
+
+	// The skill range is re-validated with the sport so a sport change cannot
+	// leave bounds pointing at another sport's levels.
+	skillRangeChanged := req.SportID != nil
+	if req.ClearSkillRange {
+		group.MinSkillLevelID = nil
+		group.MaxSkillLevelID = nil
+	}
+	if req.MinSkillLevelID != nil {
+		group.MinSkillLevelID = req.MinSkillLevelID
+		skillRangeChanged = true
+	}
+	if req.MaxSkillLevelID != nil {
+		group.MaxSkillLevelID = req.MaxSkillLevelID
+		skillRangeChanged = true
+	}
+	if skillRangeChanged {
+		if err := s.validateSkillRange(ctx, group.SportID, group.MinSkillLevelID, group.MaxSkillLevelID); err != nil {
 			return nil, err
 		}
 	}
@@ -204,20 +624,329 @@ func (s *service) UpdateGroup(ctx context.Context, id string, req UpdateGroupReq
 	if req.Enable != nil {
 		group.Enable = *req.Enable
 	}
+	if req.Visibility != nil {
+		v := Visibility(*req.Visibility)
+		if !v.IsValid() {
+			return nil, ErrInvalidVisibility
+		}
+		group.Visibility = v
+		switch {
+		case v == VisibilityPublic:
+			group.InviteCode = nil
+		case group.InviteCode == nil:
+			code, err := newInviteCode()
+			if err != nil {
+				return nil, err
+			}
+			group.InviteCode = &code
+		}
+	}
 
 	if !group.EndTime.After(group.StartTime) {
 		return nil, ErrInvalidTimeRange
 	}
+	if !group.deadlinesValid() {
+		return nil, ErrInvalidDeadline
+	}
 
-	if err := s.repo.UpdateGroup(ctx, group); err != nil {
+	// Keep the court booking valid for the group's new shape. Only active
+	// groups hold the court, so nothing is checked for a cancelled or
+	// completed group other than the location.
+	courtChanged := req.ResourceID != nil && (group.ResourceID == nil || *req.ResourceID != *group.ResourceID)
+	if courtChanged {
+		if group.Status != GroupStatusActive {
+			return nil, ErrGroupNotActive
+		}
+		excludeBookingID := ""
+		if group.BookingID != nil {
+			excludeBookingID = *group.BookingID
+		}
+		group.ResourceID = req.ResourceID
+		group.BookingID = nil // the repository books the new court
+		if err := s.checkCourt(ctx, group, excludeBookingID); err != nil {
+			return nil, err
+		}
+	} else if group.ResourceID != nil {
+		timeChanged := !group.StartTime.Equal(oldStart) || !group.EndTime.Equal(oldEnd)
+		reactivated := oldStatus == GroupStatusCancelled && group.Status == GroupStatusActive
+		switch {
+		case group.Status == GroupStatusActive && group.BookingID != nil && (timeChanged || reactivated):
+			if err := s.checkCourt(ctx, group, *group.BookingID); err != nil {
+				return nil, err
+			}
+		case group.LocationID != oldLocation:
+			if err := s.checkCourtLocation(ctx, group); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	err = s.repo.UpdateGroup(ctx, group, func(tx pgx.Tx, change *GroupChange) error {
+		return s.publishGroupChange(ctx, tx, group, change)
+	})
+	if err != nil {
 		return nil, err
 	}
 
 	return s.repo.GetGroupByID(ctx, id)
 }
 
+// publishGroupChange tells each participant affected by a group update what
+// happened to their order.
+func (s *service) publishGroupChange(ctx context.Context, tx pgx.Tx, group *PickupGroup, change *GroupChange) error {
+	if err := s.bookingService.PublishChanges(ctx, tx, change.Courts, false); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	events := make([]event.Event, 0, len(change.Orders))
+	for _, o := range change.Orders {
+		if change.Cancelled {
+			events = append(events, groupCancelledEvent(o, "host", now))
+			continue
+		}
+		events = append(events, event.Event{
+			Type:   EventGroupChanged,
+			UserID: o.UserID,
+			Data: map[string]any{
+				"pickup_group_id": o.PickupGroupID,
+				"group_title":     o.GroupTitle,
+				"order_id":        o.OrderID,
+				"changes":         change.Changes,
+				"start_time":      group.StartTime,
+				"end_time":        group.EndTime,
+				"location_id":     group.LocationID,
+				"fee":             group.Fee,
+			},
+			OccurredAt: now,
+		})
+	}
+	return s.publish(ctx, tx, events)
+}
+
+// groupCancelledEvent tells the participant their order was cancelled with
+// its group; reason is "host" or "underfilled".
+func groupCancelledEvent(o *AffectedOrder, reason string, at time.Time) event.Event {
+	return event.Event{
+		Type:   EventGroupCancelled,
+		UserID: o.UserID,
+		Data: map[string]any{
+			"pickup_group_id": o.PickupGroupID,
+			"group_title":     o.GroupTitle,
+			"order_id":        o.OrderID,
+			"reason":          reason,
+			"refund_due":      o.RefundDue,
+		},
+		OccurredAt: at,
+	}
+}
+
+// publish hands events to the publisher within tx.
+func (s *service) publish(ctx context.Context, tx pgx.Tx, events []event.Event) error {
+	if len(events) == 0 {
+		return nil
+	}
+	return s.publisher.Publish(ctx, tx, events...)
+}
+
+// checkCourtLocation verifies the group's court belongs to the group's location.
+func (s *service) checkCourtLocation(ctx context.Context, group *PickupGroup) error {
+	res, err := s.resService.GetByID(ctx, *group.ResourceID)
+	if err != nil {
+		if errors.Is(err, resource.ErrNotFound) {
+			return ErrResourceNotFound
+		}
+		return err
+	}
+	if res.LocationID != group.LocationID {
+		return ErrResourceLocationMismatch
+	}
+	return nil
+}
+
+// checkCourt verifies the group's court can be booked for the group's time,
+// applying the same rules as a regular booking (opening hours, no overlap,
+// ...). excludeBookingID is the group's current booking, which may overlap
+// its own new slot.
+func (s *service) checkCourt(ctx context.Context, group *PickupGroup, excludeBookingID string) error {
+	if err := s.checkCourtLocation(ctx, group); err != nil {
+		return err
+	}
+	err := s.bookingService.CheckSlot(ctx, *group.ResourceID, group.StartTime, group.EndTime, excludeBookingID)
+	switch {
+	case errors.Is(err, booking.ErrTimeConflict):
+		return ErrCourtUnavailable
+	case errors.Is(err, booking.ErrResourceNotFound):
+		return ErrResourceNotFound
+	}
+	return err
+}
+
+// checkLinkableBooking verifies an existing booking may back the group and
+// copies its court onto the group.
+func (s *service) checkLinkableBooking(ctx context.Context, group *PickupGroup, bookingID string, resourceID *string) error {
+	b, err := s.bookingService.GetByID(ctx, bookingID)
+	if err != nil {
+		if errors.Is(err, booking.ErrNotFound) {
+			return ErrBookingNotFound
+		}
+		return err
+	}
+	if b.UserID != group.HostID {
+		return ErrBookingNotOwned
+	}
+	if b.Status == booking.StatusCancelled || b.PickupGroupID != nil {
+		return ErrBookingUnavailable
+	}
+	if resourceID != nil && *resourceID != b.ResourceID {
+		return ErrBookingMismatch
+	}
+	if b.StartTime.After(group.StartTime) || b.EndTime.Before(group.EndTime) {
+		return ErrBookingMismatch
+	}
+	if b.LocationID != group.LocationID {
+		return ErrResourceLocationMismatch
+	}
+
+	group.ResourceID = &b.ResourceID
+	group.BookingID = &b.ID
+	return nil
+}
+
+func (s *service) AdvanceLifecycle(ctx context.Context, now time.Time, cancelCutoff time.Duration) (LifecycleResult, error) {
+	var result LifecycleResult
+	var err error
+
+	// Complete first, so a group that already ended is recorded as played
+	// rather than cancelled for its headcount.
+	if result.Completed, err = s.repo.CompleteEndedGroups(ctx, now); err != nil {
+		return result, err
+	}
+	result.Cancelled, err = s.repo.CancelUnderfilledGroups(ctx, now.Add(cancelCutoff), func(tx pgx.Tx, cancelled []*AffectedOrder, courts []booking.Change) error {
+		// Nobody asked for these courts to be released, so the host hears of
+		// it as the booker.
+		if err := s.bookingService.PublishChanges(ctx, tx, courts, true); err != nil {
+			return err
+		}
+		events := make([]event.Event, len(cancelled))
+		for i, o := range cancelled {
+			events[i] = groupCancelledEvent(o, "underfilled", now)
+		}
+		return s.publish(ctx, tx, events)
+	})
+	return result, err
+}
+
+func (s *service) SendReminders(ctx context.Context, now time.Time, offsets []time.Duration) (int, error) {
+	if len(offsets) == 0 {
+		return 0, nil
+	}
+	return s.repo.ClaimReminders(ctx, now, offsets, func(tx pgx.Tx, reminders []*OrderReminder) error {
+		return s.publish(ctx, tx, reminderEvents(reminders, now))
+	})
+}
+
+// reminderEvents tells each participant their group starts soon.
+func reminderEvents(reminders []*OrderReminder, now time.Time) []event.Event {
+	events := make([]event.Event, len(reminders))
+	for i, r := range reminders {
+		events[i] = event.Event{
+			Type:   EventOrderReminder,
+			UserID: r.UserID,
+			Data: map[string]any{
+				"pickup_group_id":       r.PickupGroupID,
+				"group_title":           r.GroupTitle,
+				"order_id":              r.OrderID,
+				"location_name":         r.LocationName,
+				"start_time":            r.StartTime,
+				"end_time":              r.EndTime,
+				"remind_before_minutes": int(r.Before.Minutes()),
+			},
+			OccurredAt: now,
+		}
+	}
+	return events
+}
+
 func (s *service) DeleteGroup(ctx context.Context, id string) error {
-	return s.repo.DeleteGroup(ctx, id)
+	return s.repo.DeleteGroup(ctx, id, func(tx pgx.Tx, courts []booking.Change) error {
+		return s.bookingService.PublishChanges(ctx, tx, courts, false)
+	})
+}
+
+func (s *service) AddCoHost(ctx context.Context, groupID, coHostID, userID string, isSysAdmin bool) (*PickupGroup, error) {
+	group, err := s.repo.GetGroupByID(ctx, groupID)
+	if err != nil {
+		return nil, err
+	}
+	if !isSysAdmin && group.HostID != userID {
+		return nil, ErrPermissionDenied
+	}
+	if coHostID == group.HostID {
+		return nil, ErrCoHostIsHost
+	}
+
+	if err := s.repo.AddCoHost(ctx, groupID, coHostID, userID); err != nil {
+		return nil, err
+	}
+	return s.repo.GetGroupByID(ctx, groupID)
+}
+
+func (s *service) RemoveCoHost(ctx context.Context, groupID, coHostID, userID string, isSysAdmin bool) error {
+	group, err := s.repo.GetGroupByID(ctx, groupID)
+	if err != nil {
+		return err
+	}
+	if !isSysAdmin && group.HostID != userID {
+		return ErrPermissionDenied
+	}
+	return s.repo.RemoveCoHost(ctx, groupID, coHostID)
+}
+
+func (s *service) BlockUser(ctx context.Context, req BlockUserRequest, actorID string, isSysAdmin bool) (*HostBlock, error) {
+	if !isSysAdmin && actorID != req.HostID {
+		return nil, ErrPermissionDenied
+	}
+	if req.UserID == req.HostID {
+		return nil, ErrBlockSelf
+	}
+	blocked, err := s.userService.GetByID(ctx, req.UserID)
+	if err != nil {
+		if errors.Is(err, user.ErrNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+
+	block := &HostBlock{
+		HostID:      req.HostID,
+		UserID:      req.UserID,
+		Reason:      req.Reason,
+		Username:    blocked.Username,
+		DisplayName: blocked.DisplayName,
+	}
+	if err := s.repo.BlockUser(ctx, block); err != nil {
+		return nil, err
+	}
+	return block, nil
+}
+
+func (s *service) UnblockUser(ctx context.Context, hostID, userID, actorID string, isSysAdmin bool) error {
+	if !isSysAdmin && actorID != hostID {
+		return ErrPermissionDenied
+	}
+	return s.repo.UnblockUser(ctx, hostID, userID)
+}
+
+func (s *service) ListHostBlocks(ctx context.Context, hostID, actorID string, isSysAdmin bool) ([]*HostBlock, error) {
+	if !isSysAdmin && actorID != hostID {
+		return nil, ErrPermissionDenied
+	}
+	return s.repo.ListHostBlocks(ctx, hostID)
+}
+
+func (s *service) BlockedUsers(ctx context.Context, hostID string, userIDs []string) (map[string]bool, error) {
+	return s.repo.BlockedUsers(ctx, hostID, userIDs)
 }
 
 func (s *service) GetOrdersByGroupID(ctx context.Context, groupID string) ([]*PickupOrder, error) {
@@ -232,6 +961,22 @@ func (s *service) GetOrdersByUserID(ctx context.Context, userID string) ([]*Pick
 }
 
 func (s *service) CreateOrder(ctx context.Context, req CreateOrderRequest) (*PickupOrder, error) {
+	if req.Seats == 0 {
+		req.Seats = 1
+	}
+	if req.Seats < 1 {
+		return nil, ErrInvalidSeats
+	}
+	if len(req.GuestNames) > req.Seats-1 {
+		return nil, ErrTooManyGuestNames
+	}
+	guestNames := make([]string, 0, len(req.GuestNames))
+	for _, name := range req.GuestNames {
+		if name = strings.TrimSpace(name); name != "" {
+			guestNames = append(guestNames, name)
+		}
+	}
+
 	order := &PickupOrder{
 		PickupGroupID: req.PickupGroupID,
 		UserID:        req.UserID,
@@ -239,9 +984,11 @@ func (s *service) CreateOrder(ctx context.Context, req CreateOrderRequest) (*Pic
 		BookerPhone:   req.BookerPhone,
 		Status:        OrderStatusPending,
 		PaymentStatus: PaymentStatusPending,
+		Seats:         req.Seats,
+		GuestNames:    guestNames,
 	}
 
-	if err := s.repo.CreateOrder(ctx, order); err != nil {
+	if err := s.repo.CreateOrder(ctx, order, req.JoinWaitlist, req.InviteCode); err != nil {
 		return nil, err
 	}
 
@@ -254,7 +1001,9 @@ func (s *service) CreateOrder(ctx context.Context, req CreateOrderRequest) (*Pic
 //   - The pickup group host (or a system admin) may set any status and the
 //     payment status (this covers reviewing enrollments).
 //   - The enrolling user (booker) may only move their own order to 'cancelled'
-//     or 'cancel_request', and may not touch the payment status.
+//     or 'cancel_request', and may not touch the payment status. After the
+//     group's cancel deadline a 'cancelled' request on a held seat is recorded
+//     as 'cancel_request'.
 func (s *service) UpdateOrder(ctx context.Context, id string, req UpdateOrderRequest, updaterUserID string, isSysAdmin bool) (*PickupOrder, error) {
 	order, err := s.repo.GetOrderByID(ctx, id)
 	if err != nil {
@@ -267,7 +1016,7 @@ func (s *service) UpdateOrder(ctx context.Context, id string, req UpdateOrderReq
 	}
 
 	isOwner := order.UserID == updaterUserID
-	isReviewer := isSysAdmin || group.HostID == updaterUserID
+	isReviewer := isSysAdmin || group.IsManager(updaterUserID)
 
 	if !isOwner && !isReviewer {
 		return nil, ErrPermissionDenied
@@ -292,37 +1041,122 @@ func (s *service) UpdateOrder(ctx context.Context, id string, req UpdateOrderReq
 		if !st.IsValid() {
 			return nil, ErrInvalidStatus
 		}
+		// An order only joins the waitlist through enrollment on a full group.
+		if st == OrderStatusWaitlisted && oldStatus != OrderStatusWaitlisted {
+			return nil, ErrInvalidStatus
+		}
+		// Completion is recorded by the lifecycle worker when the group ends.
+		if st == OrderStatusCompleted && oldStatus != OrderStatusCompleted {
+			return nil, ErrInvalidStatus
+		}
 		// A plain booker may only cancel or request cancellation of their order.
 		if isOwner && !isReviewer {
 			if st != OrderStatusCancelled && st != OrderStatusCancelRequest {
 				return nil, ErrPermissionDenied
 			}
+			// A cancel request only makes sense for a held seat; leaving the
+			// waitlist is a plain cancellation.
+			if st == OrderStatusCancelRequest && oldStatus == OrderStatusWaitlisted {
+				return nil, ErrInvalidStatus
+			}
+			// Past the cancellation deadline, giving up a held seat needs the
+			// host's approval, unless the group changed materially since.
+			if st == OrderStatusCancelled && isOccupyingStatus(oldStatus) &&
+				!order.ReconfirmRequired && group.CancelDeadlinePassed(time.Now()) {
+				st = OrderStatusCancelRequest
+			}
 		}
 		order.Status = st
 	}
 
-	// If the order is moving from a non-occupying state (cancelled / cancel
-	// request) into a seat-occupying state, re-validate capacity inside a
-	// transaction so a reviewer cannot push the group over its limit.
-	if isOccupyingStatus(order.Status) && !isOccupyingStatus(oldStatus) {
-		if err := s.repo.UpdateOrderWithCapacityCheck(ctx, order); err != nil {
-			return nil, err
+	// Leaving the group settles a pending reconfirmation. A participant who
+	// withdraws instead of reconfirming a material change is owed their fee.
+	if order.Status == OrderStatusCancelled || order.Status == OrderStatusRejected {
+		if order.ReconfirmRequired && isOwner && order.Status == OrderStatusCancelled &&
+			order.PaymentStatus == PaymentStatusDone {
+			pending := RefundStatusPending
+			order.RefundStatus = &pending
 		}
-		return order, nil
+		order.ReconfirmRequired = false
+	}
+
+	var publish event.Hook
+	if !isOwner && order.Status != oldStatus {
+		publish = func(tx pgx.Tx) error {
+			return s.publishOrderDecision(ctx, tx, group, order)
+		}
+	}
+
+	if isOccupyingStatus(order.Status) && !isOccupyingStatus(oldStatus) {
+		// The order is moving from a non-occupying state (cancelled / rejected /
+		// waitlisted) into a seat-occupying state: re-validate capacity inside a
+		// transaction so a reviewer cannot push the group over its limit.
+		err = s.repo.UpdateOrderWithCapacityCheck(ctx, order, publish)
+	} else if isOccupyingStatus(oldStatus) && !isOccupyingStatus(order.Status) {
+		// The order is releasing its seat: promote the next waitlisted order in
+		// the same transaction.
+		err = s.repo.UpdateOrderAndPromote(ctx, order, publish)
+	} else {
+		err = s.repo.UpdateOrder(ctx, order, publish)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return order, nil
+}
+
+// publishOrderDecision tells the participant that a reviewer confirmed,
+// rejected or cancelled their order.
+func (s *service) publishOrderDecision(ctx context.Context, tx pgx.Tx, group *PickupGroup, order *PickupOrder) error {
+	var eventType string
+	switch order.Status {
+	case OrderStatusConfirmed:
+		eventType = EventOrderConfirmed
+	case OrderStatusRejected:
+		eventType = EventOrderRejected
+	case OrderStatusCancelled:
+		eventType = EventOrderCancelled
+	default:
+		return nil
 	}
+	return s.publish(ctx, tx, []event.Event{{
+		Type:   eventType,
+		UserID: order.UserID,
+		Data: map[string]any{
+			"pickup_group_id": group.ID,
+			"group_title":     group.Title,
+			"order_id":        order.ID,
+			"start_time":      group.StartTime,
+		},
+		OccurredAt: time.Now(),
+	}})
+}
 
-	if err := s.repo.UpdateOrder(ctx, order); err != nil {
+func (s *service) ReconfirmOrder(ctx context.Context, id, userID string) (*PickupOrder, error) {
+	order, err := s.repo.GetOrderByID(ctx, id)
+	if err != nil {
 		return nil, err
 	}
+	if order.UserID != userID {
+		return nil, ErrPermissionDenied
+	}
+	if !order.ReconfirmRequired {
+		return nil, ErrReconfirmNotRequired
+	}
 
+	order.ReconfirmRequired = false
+	if err := s.repo.UpdateOrder(ctx, order, nil); err != nil {
+		return nil, err
+	}
 	return order, nil
 }
 
 // DeleteOrder hard-deletes an enrollment. Only a system admin may do this; a
 // host removes a participant by rejecting the order (status=rejected) instead,
 // which keeps the row and blocks the user from re-enrolling. The group's
-// current_enrolled is derived from a live COUNT, so deleting the row decrements
-// it automatically.
+// current_enrolled is derived from a live sum of seats, so deleting the row
+// decrements it automatically, and the freed seats are offered to the waitlist.
 func (s *service) DeleteOrder(ctx context.Context, id, requesterUserID string, isSysAdmin bool) error {
 	_ = requesterUserID // deletion is admin-only; the requester identity is not consulted.
 	if !isSysAdmin {
@@ -331,6 +1165,231 @@ func (s *service) DeleteOrder(ctx context.Context, id, requesterUserID string, i
 	return s.repo.DeleteOrder(ctx, id)
 }
 
+// CreateReview checks the reviewer owns the order before inserting; whether the
+// order is eligible (completed group, confirmed order) is enforced atomically
+// by the repository insert.
+func (s *service) CreateReview(ctx context.Context, req CreateReviewRequest) (*HostReview, error) {
+	if req.Rating < 1 || req.Rating > 5 {
+		return nil, ErrInvalidRating
+	}
+
+	order, err := s.repo.GetOrderByID(ctx, req.OrderID)
+	if err != nil {
+		return nil, err
+	}
+	if order.UserID != req.ReviewerID {
+		return nil, ErrPermissionDenied
+	}
+
+	group, err := s.repo.GetGroupByID(ctx, order.PickupGroupID)
+	if err != nil {
+		return nil, err
+	}
+	if group.IsManager(req.ReviewerID) {
+		return nil, ErrReviewOwnGroup
+	}
+
+	review := &HostReview{
+		OrderID: req.OrderID,
+		Rating:  req.Rating,
+		Comment: req.Comment,
+	}
+	if err := s.repo.CreateReview(ctx, review); err != nil {
+		return nil, err
+	}
+
+	reviewer, err := s.userService.GetByID(ctx, review.ReviewerID)
+	if err != nil {
+		return nil, err
+	}
+	review.GroupTitle = group.Title
+	review.ReviewerUsername = reviewer.Username
+	review.ReviewerDisplayName = reviewer.DisplayName
+	return review, nil
+}
+
+func (s *service) ListReviewsByHost(ctx context.Context, filter ReviewFilter) ([]*HostReview, int, error) {
+	return s.repo.ListReviewsByHost(ctx, filter)
+}
+
+func (s *service) MarkAttendance(ctx context.Context, orderID string, attendance Attendance, markerUserID string, isSysAdmin bool) (*PickupOrder, error) {
+	if !attendance.IsValid() {
+		return nil, ErrInvalidAttendance
+	}
+
+	order, err := s.repo.GetOrderByID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	group, err := s.repo.GetGroupByID(ctx, order.PickupGroupID)
+	if err != nil {
+		return nil, err
+	}
+	if !isSysAdmin && !group.IsManager(markerUserID) {
+		return nil, ErrPermissionDenied
+	}
+
+	if group.Status == GroupStatusCancelled || time.Now().Before(group.StartTime) {
+		return nil, ErrAttendanceNotAllowed
+	}
+	if order.Status != OrderStatusConfirmed && order.Status != OrderStatusCompleted {
+		return nil, ErrAttendanceNotAllowed
+	}
+
+	order.Attendance = &attendance
+	if err := s.repo.UpdateAttendance(ctx, order); err != nil {
+		return nil, err
+	}
+	return order, nil
+}
+
+func (s *service) GetReliability(ctx context.Context, userIDs []string) (map[string]Reliability, error) {
+	return s.repo.GetReliability(ctx, userIDs)
+}
+
+func (s *service) VerifySkill(ctx context.Context, orderID, skillLevelID, verifierUserID string, isSysAdmin bool) (*skillprofile.SkillProfile, error) {
+	order, err := s.repo.GetOrderByID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	group, err := s.repo.GetGroupByID(ctx, order.PickupGroupID)
+	if err != nil {
+		return nil, err
+	}
+	if !isSysAdmin && !group.IsManager(verifierUserID) {
+		return nil, ErrPermissionDenied
+	}
+
+	if group.Status == GroupStatusCancelled || time.Now().Before(group.StartTime) {
+		return nil, ErrSkillVerifyNotAllowed
+	}
+	if order.Status != OrderStatusConfirmed && order.Status != OrderStatusCompleted {
+		return nil, ErrSkillVerifyNotAllowed
+	}
+
+	return s.skillProfiles.Verify(ctx, order.UserID, group.SportID, skillLevelID, verifierUserID)
+}
+
+// authorizeThread loads the group and checks the user may read and write its
+// comment thread.
+func (s *service) authorizeThread(ctx context.Context, groupID, userID string, isSysAdmin bool) (*PickupGroup, error) {
+	group, err := s.repo.GetGroupByID(ctx, groupID)
+	if err != nil {
+		return nil, err
+	}
+	if isSysAdmin || group.IsManager(userID) {
+		return group, nil
+	}
+	ok, err := s.repo.HasParticipatingOrder(ctx, groupID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrNotThreadMember
+	}
+	return group, nil
+}
+
+func (s *service) ListComments(ctx context.Context, filter CommentFilter, viewerUserID string, isSysAdmin bool) ([]*Comment, int, error) {
+	if _, err := s.authorizeThread(ctx, filter.PickupGroupID, viewerUserID, isSysAdmin); err != nil {
+		return nil, 0, err
+	}
+	return s.repo.ListComments(ctx, filter)
+}
+
+func (s *service) CreateComment(ctx context.Context, req CreateCommentRequest, isSysAdmin bool) (*Comment, error) {
+	body := strings.TrimSpace(req.Body)
+	if body == "" {
+		return nil, ErrEmptyComment
+	}
+
+	if _, err := s.authorizeThread(ctx, req.PickupGroupID, req.AuthorID, isSysAdmin); err != nil {
+		return nil, err
+	}
+
+	comment := &Comment{
+		PickupGroupID: req.PickupGroupID,
+		AuthorID:      req.AuthorID,
+		Body:          body,
+	}
+	if err := s.repo.CreateComment(ctx, comment); err != nil {
+		return nil, err
+	}
+	return s.repo.GetCommentByID(ctx, comment.PickupGroupID, comment.ID)
+}
+
+func (s *service) SetCommentPinned(ctx context.Context, groupID, commentID string, pinned bool, userID string, isSysAdmin bool) (*Comment, error) {
+	group, err := s.repo.GetGroupByID(ctx, groupID)
+	if err != nil {
+		return nil, err
+	}
+	if !isSysAdmin && !group.IsManager(userID) {
+		return nil, ErrPermissionDenied
+	}
+
+	comment, err := s.repo.GetCommentByID(ctx, groupID, commentID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.SetCommentPinned(ctx, comment, pinned); err != nil {
+		return nil, err
+	}
+	return comment, nil
+}
+
+func (s *service) DeleteComment(ctx context.Context, groupID, commentID, userID string, isSysAdmin bool) error {
+	group, err := s.repo.GetGroupByID(ctx, groupID)
+	if err != nil {
+		return err
+	}
+
+	comment, err := s.repo.GetCommentByID(ctx, groupID, commentID)
+	if err != nil {
+		return err
+	}
+	if !isSysAdmin && !group.IsManager(userID) && comment.AuthorID != userID {
+		return ErrPermissionDenied
+	}
+	return s.repo.DeleteComment(ctx, groupID, commentID)
+}
+
+func (s *service) GetHostStats(ctx context.Context, req HostStatsRequest) (*HostStats, error) {
+	if req.Period == "" {
+		req.Period = "month"
+	}
+	if req.Period != "day" && req.Period != "week" && req.Period != "month" {
+		return nil, ErrInvalidStatsPeriod
+	}
+	if req.Timezone == "" {
+		req.Timezone = "UTC"
+	}
+	loc, err := time.LoadLocation(req.Timezone)
+	if err != nil {
+		return nil, ErrInvalidTimezone
+	}
+	if req.To.Before(req.From) {
+		return nil, ErrInvalidDateRange
+	}
+
+	fy, fm, fd := req.From.Date()
+	ty, tm, td := req.To.Date()
+	stats, err := s.repo.GetHostStats(ctx, HostStatsFilter{
+		HostID:   req.HostID,
+		From:     time.Date(fy, fm, fd, 0, 0, 0, 0, loc),
+		To:       time.Date(ty, tm, td+1, 0, 0, 0, 0, loc),
+		Period:   req.Period,
+		Timezone: req.Timezone,
+	})
+	if err != nil {
+		return nil, err
+	}
+	stats.Period = req.Period
+	stats.Timezone = req.Timezone
+	return stats, nil
+}
+
 // isOccupyingStatus reports whether an order in the given status counts against
 // the group's capacity (i.e. occupies a seat). A cancel_request still holds the
 // seat: it is only released once the order is actually cancelled (or rejected).
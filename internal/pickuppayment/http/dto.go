@@ -0,0 +1,178 @@
+package http
+
+import (
+	"time"
+
+	"github.com/nekogravitycat/court-booking-backend/internal/pickuppayment"
+)
+
+const dateLayout = "2006-01-02"
+
+type RecordPaymentBody struct {
+	// Amount defaults to the group fee times the order's seats.
+	Amount *int       `json:"amount" binding:"omitempty,min=1"`
+	Method string     `json:"method" binding:"required,oneof=cash bank_transfer line_pay other"`
+	PaidAt *time.Time `json:"paid_at"`
+	Note   *string    `json:"note" binding:"omitempty,max=500"`
+}
+
+// EarningsQuery selects GET /me/pickup-earnings; from and to are inclusive
+// dates in timezone.
+type EarningsQuery struct {
+	From     string `form:"from" binding:"required,datetime=2006-01-02"`
+	To       string `form:"to" binding:"required,datetime=2006-01-02"`
+	Period   string `form:"period" binding:"omitempty,oneof=day week month"`
+	Timezone string `form:"timezone"`
+}
+
+// HostEarningsQuery pages through GET /pickup-earnings; from and to are
+// optional inclusive dates in timezone.
+type HostEarningsQuery struct {
+	From     *string `form:"from" binding:"omitempty,datetime=2006-01-02"`
+	To       *string `form:"to" binding:"omitempty,datetime=2006-01-02"`
+	Timezone string  `form:"timezone"`
+	Page     int     `form:"page,default=1" binding:"min=1"`
+	PageSize int     `form:"page_size,default=20" binding:"min=1,max=100"`
+}
+
+// parseDate parses an optional YYYY-MM-DD value already checked by binding.
+func parseDate(s *string) *time.Time {
+	if s == nil {
+		return nil
+	}
+	d, err := time.Parse(dateLayout, *s)
+	if err != nil {
+		return nil
+	}
+	return &d
+}
+
+// --- Response types ---
+
+type PaymentResponse struct {
+	ID            string     `json:"id"`
+	OrderID       *string    `json:"order_id"`
+	PickupGroupID *string    `json:"pickup_group_id"`
+	HostID        string     `json:"host_id"`
+	PayerUserID   *string    `json:"payer_user_id"`
+	Amount        int        `json:"amount"`
+	Method        string     `json:"method"`
+	PaidAt        time.Time  `json:"paid_at"`
+	Note          *string    `json:"note"`
+	RecordedBy    *string    `json:"recorded_by"`
+	Refunded      bool       `json:"refunded"`
+	RefundedAt    *time.Time `json:"refunded_at"`
+	RefundedBy    *string    `json:"refunded_by"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+func NewPaymentResponse(p *pickuppayment.Payment) PaymentResponse {
+	var refundedAt *time.Time
+	if p.RefundedAt != nil {
+		t := p.RefundedAt.UTC()
+		refundedAt = &t
+	}
+	return PaymentResponse{
+		ID:            p.ID,
+		OrderID:       p.OrderID,
+		PickupGroupID: p.PickupGroupID,
+		HostID:        p.HostID,
+		PayerUserID:   p.PayerUserID,
+		Amount:        p.Amount,
+		Method:        string(p.Method),
+		PaidAt:        p.PaidAt.UTC(),
+		Note:          p.Note,
+		RecordedBy:    p.RecordedBy,
+		Refunded:      p.Refunded(),
+		RefundedAt:    refundedAt,
+		RefundedBy:    p.RefundedBy,
+		CreatedAt:     p.CreatedAt.UTC(),
+	}
+}
+
+// TotalsResponse is embedded in every earnings summary.
+type TotalsResponse struct {
+	Collected    int `json:"collected"`
+	Refunded     int `json:"refunded"`
+	Net          int `json:"net"`
+	PaymentCount int `json:"payment_count"`
+}
+
+func NewTotalsResponse(t pickuppayment.Totals) TotalsResponse {
+	return TotalsResponse{
+		Collected:    t.Collected,
+		Refunded:     t.Refunded,
+		Net:          t.Net(),
+		PaymentCount: t.PaymentCount,
+	}
+}
+
+type GroupEarningsResponse struct {
+	PickupGroupID string `json:"pickup_group_id"`
+	Fee           int    `json:"fee"`
+	OccupiedSeats int    `json:"occupied_seats"`
+	Expected      int    `json:"expected"`
+	Outstanding   int    `json:"outstanding"`
+	TotalsResponse
+}
+
+func NewGroupEarningsResponse(g *pickuppayment.GroupEarnings) GroupEarningsResponse {
+	return GroupEarningsResponse{
+		PickupGroupID:  g.PickupGroupID,
+		Fee:            g.Fee,
+		OccupiedSeats:  g.OccupiedSeats,
+		Expected:       g.Expected(),
+		Outstanding:    g.Outstanding(),
+		TotalsResponse: NewTotalsResponse(g.Totals),
+	}
+}
+
+type PeriodEarningsResponse struct {
+	PeriodStart string `json:"period_start"`
+	TotalsResponse
+}
+
+type EarningsResponse struct {
+	From     string                   `json:"from"`
+	To       string                   `json:"to"`
+	Period   string                   `json:"period"`
+	Timezone string                   `json:"timezone"`
+	Periods  []PeriodEarningsResponse `json:"periods"`
+	Totals   TotalsResponse           `json:"totals"`
+}
+
+func NewEarningsResponse(req pickuppayment.EarningsRequest, periods []*pickuppayment.PeriodEarnings, totals pickuppayment.Totals) EarningsResponse {
+	items := make([]PeriodEarningsResponse, len(periods))
+	for i, p := range periods {
+		items[i] = PeriodEarningsResponse{
+			PeriodStart:    p.PeriodStart.Format(dateLayout),
+			TotalsResponse: NewTotalsResponse(p.Totals),
+		}
+	}
+	return EarningsResponse{
+		From:     req.From.Format(dateLayout),
+		To:       req.To.Format(dateLayout),
+		Period:   string(req.Period),
+		Timezone: req.Timezone,
+		Periods:  items,
+		Totals:   NewTotalsResponse(totals),
+	}
+}
+
+type HostEarningsResponse struct {
+	HostID          string  `json:"host_id"`
+	HostUsername    string  `json:"host_username"`
+	HostDisplayName *string `json:"host_display_name"`
+	GroupCount      int     `json:"group_count"`
+	TotalsResponse
+}
+
+func NewHostEarningsResponse(h *pickuppayment.HostEarnings) HostEarningsResponse {
+	return HostEarningsResponse{
+		HostID:          h.HostID,
+		HostUsername:    h.HostUsername,
+		HostDisplayName: h.HostDisplayName,
+		GroupCount:      h.GroupCount,
+		TotalsResponse:  NewTotalsResponse(h.Totals),
+	}
+}
@@ -0,0 +1,207 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nekogravitycat/court-booking-backend/internal/auth"
+	"github.com/nekogravitycat/court-booking-backend/internal/pickuppayment"
+	"github.com/nekogravitycat/court-booking-backend/internal/pkg/request"
+	"github.com/nekogravitycat/court-booking-backend/internal/pkg/response"
+	"github.com/nekogravitycat/court-booking-backend/internal/user"
+)
+
+type Handler struct {
+	service     pickuppayment.Service
+	userService user.Service
+}
+
+func NewHandler(service pickuppayment.Service, userService user.Service) *Handler {
+	return &Handler{
+		service:     service,
+		userService: userService,
+	}
+}
+
+func (h *Handler) isSysAdmin(c *gin.Context, userID string) bool {
+	u, err := h.userService.GetByID(c.Request.Context(), userID)
+	return err == nil && u.IsSystemAdmin
+}
+
+// Record adds a payment to an order's ledger. Access Control: group host or
+// system admin.
+func (h *Handler) Record(c *gin.Context) {
+	var uri request.ByIDRequest
+	if err := c.ShouldBindUri(&uri); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request", "details": err.Error()})
+		return
+	}
+
+	var body RecordPaymentBody
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body", "details": err.Error()})
+		return
+	}
+
+	userID := auth.GetUserID(c)
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	p, err := h.service.Record(c.Request.Context(), pickuppayment.RecordRequest{
+		OrderID:    uri.ID,
+		RecorderID: userID,
+		IsSysAdmin: h.isSysAdmin(c, userID),
+		Amount:     body.Amount,
+		Method:     pickuppayment.Method(body.Method),
+		PaidAt:     body.PaidAt,
+		Note:       body.Note,
+	})
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, NewPaymentResponse(p))
+}
+
+// ListByOrder returns an order's payments. Access Control: group host, the
+// order's booker, or system admin.
+func (h *Handler) ListByOrder(c *gin.Context) {
+	var uri request.ByIDRequest
+	if err := c.ShouldBindUri(&uri); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request", "details": err.Error()})
+		return
+	}
+
+	userID := auth.GetUserID(c)
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	payments, err := h.service.ListByOrder(c.Request.Context(), uri.ID, userID, h.isSysAdmin(c, userID))
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	items := make([]PaymentResponse, len(payments))
+	for i, p := range payments {
+		items[i] = NewPaymentResponse(p)
+	}
+	c.JSON(http.StatusOK, items)
+}
+
+// Refund marks a payment refunded. Access Control: group host or system admin.
+func (h *Handler) Refund(c *gin.Context) {
+	var uri request.ByIDRequest
+	if err := c.ShouldBindUri(&uri); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request", "details": err.Error()})
+		return
+	}
+
+	userID := auth.GetUserID(c)
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	p, err := h.service.Refund(c.Request.Context(), uri.ID, userID, h.isSysAdmin(c, userID))
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, NewPaymentResponse(p))
+}
+
+// GetGroupEarnings summarises a group's payments. Access Control: group host
+// or system admin.
+func (h *Handler) GetGroupEarnings(c *gin.Context) {
+	var uri request.ByIDRequest
+	if err := c.ShouldBindUri(&uri); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request", "details": err.Error()})
+		return
+	}
+
+	userID := auth.GetUserID(c)
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	earnings, err := h.service.GetGroupEarnings(c.Request.Context(), uri.ID, userID, h.isSysAdmin(c, userID))
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, NewGroupEarningsResponse(earnings))
+}
+
+// GetMyEarnings returns the caller's earnings as a host, bucketed by period.
+func (h *Handler) GetMyEarnings(c *gin.Context) {
+	var query EarningsQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid query parameters", "details": err.Error()})
+		return
+	}
+
+	userID := auth.GetUserID(c)
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	req := pickuppayment.EarningsRequest{
+		HostID:   userID,
+		From:     *parseDate(&query.From),
+		To:       *parseDate(&query.To),
+		Period:   pickuppayment.Period(query.Period),
+		Timezone: query.Timezone,
+	}
+	if req.Period == "" {
+		req.Period = pickuppayment.PeriodMonth
+	}
+	if req.Timezone == "" {
+		req.Timezone = "UTC"
+	}
+
+	periods, totals, err := h.service.GetHostEarnings(c.Request.Context(), req)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, NewEarningsResponse(req, periods, totals))
+}
+
+// ListHostEarnings returns every host's earnings for reconciliation. Access
+// Control: system admin (enforced by the route middleware).
+func (h *Handler) ListHostEarnings(c *gin.Context) {
+	var query HostEarningsQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid query parameters", "details": err.Error()})
+		return
+	}
+
+	hosts, total, err := h.service.ListHostEarnings(c.Request.Context(), pickuppayment.HostEarningsRequest{
+		From:     parseDate(query.From),
+		To:       parseDate(query.To),
+		Timezone: query.Timezone,
+		Page:     query.Page,
+		PageSize: query.PageSize,
+	})
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	items := make([]HostEarningsResponse, len(hosts))
+	for i, e := range hosts {
+		items[i] = NewHostEarningsResponse(e)
+	}
+	c.JSON(http.StatusOK, response.NewPageResponse(items, query.Page, query.PageSize, total))
+}
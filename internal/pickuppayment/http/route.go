@@ -0,0 +1,19 @@
+package http
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterRoutes(g *gin.RouterGroup, h *Handler, authMiddleware, sysAdminMiddleware gin.HandlerFunc) {
+	// Per-order ledger (host or system admin records; the booker may read)
+	g.GET("/pickup-orders/:id/payments", authMiddleware, h.ListByOrder)
+	g.POST("/pickup-orders/:id/payments", authMiddleware, h.Record)
+	g.POST("/pickup-payments/:id/refund", authMiddleware, h.Refund)
+
+	// Earnings summaries
+	g.GET("/pickup-groups/:id/earnings", authMiddleware, h.GetGroupEarnings)
+	g.GET("/me/pickup-earnings", authMiddleware, h.GetMyEarnings)
+
+	// Reconciliation across all hosts (System Admin Only)
+	g.GET("/pickup-earnings", authMiddleware, sysAdminMiddleware, h.ListHostEarnings)
+}
@@ -0,0 +1,161 @@
+package pickuppayment
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/nekogravitycat/court-booking-backend/internal/pickup"
+	"github.com/nekogravitycat/court-booking-backend/internal/pkg/apperror"
+)
+
+var (
+	ErrPaymentNotFound  = apperror.New(http.StatusNotFound, "pickup payment not found")
+	ErrOrderNotFound    = apperror.New(http.StatusNotFound, "pickup order not found")
+	ErrPermissionDenied = apperror.New(http.StatusForbidden, "permission denied")
+	ErrInvalidAmount    = apperror.New(http.StatusBadRequest, "amount must be greater than 0")
+	ErrInvalidMethod    = apperror.New(http.StatusBadRequest, "invalid payment method")
+	ErrAlreadyRefunded  = apperror.New(http.StatusConflict, "payment has already been refunded")
+	ErrOrderNotOnRoster = apperror.New(http.StatusConflict, "payments can only be recorded for orders on the roster")
+	ErrInvalidPeriod    = apperror.New(http.StatusBadRequest, "period must be one of day, week, month")
+	ErrInvalidTimezone  = apperror.New(http.StatusBadRequest, "invalid timezone")
+	ErrInvalidDateRange = apperror.New(http.StatusBadRequest, "to must not be before from")
+)
+
+// Method is how a pickup fee was paid to the host.
+type Method string
+
+const (
+	MethodCash         Method = "cash"
+	MethodBankTransfer Method = "bank_transfer"
+	MethodLinePay      Method = "line_pay"
+	MethodOther        Method = "other"
+)
+
+func (m Method) IsValid() bool {
+	switch m {
+	case MethodCash, MethodBankTransfer, MethodLinePay, MethodOther:
+		return true
+	}
+	return false
+}
+
+// Payment is one ledger entry: money a participant paid the host for an order.
+// OrderID and PickupGroupID become nil once the order or group is deleted; the
+// entry itself is kept for reconciliation.
+type Payment struct {
+	ID            string
+	OrderID       *string
+	PickupGroupID *string
+	HostID        string
+	PayerUserID   *string
+	Amount        int
+	Method        Method
+	PaidAt        time.Time
+	Note          *string
+	RecordedBy    *string
+	RefundedAt    *time.Time
+	RefundedBy    *string
+	CreatedAt     time.Time
+}
+
+// Refunded reports whether the payment has been refunded.
+func (p *Payment) Refunded() bool {
+	return p.RefundedAt != nil
+}
+
+// OrderRef is the order and group data a payment is recorded against.
+type OrderRef struct {
+	OrderID       string
+	PickupGroupID string
+	HostID        string
+	PayerUserID   string
+	Fee           int
+	Seats         int
+	Status        pickup.OrderStatus
+}
+
+// Totals sums ledger entries. Collected counts every payment, refunded or
+// not; Refunded is the part of it that was paid back.
+type Totals struct {
+	Collected    int
+	Refunded     int
+	PaymentCount int
+}
+
+// Net is the amount the host kept.
+func (t Totals) Net() int {
+	return t.Collected - t.Refunded
+}
+
+func (t *Totals) add(o Totals) {
+	t.Collected += o.Collected
+	t.Refunded += o.Refunded
+	t.PaymentCount += o.PaymentCount
+}
+
+// GroupEarnings compares what a group's participants paid with what its
+// occupied seats owe.
+type GroupEarnings struct {
+	PickupGroupID string
+	Fee           int
+	OccupiedSeats int
+	Totals
+}
+
+// Expected is the fee owed by the occupied seats.
+func (g *GroupEarnings) Expected() int {
+	return g.Fee * g.OccupiedSeats
+}
+
+// Outstanding is the part of Expected not yet covered by net payments.
+func (g *GroupEarnings) Outstanding() int {
+	return max(g.Expected()-g.Net(), 0)
+}
+
+// Period is the bucket size of a periodic earnings summary.
+type Period string
+
+const (
+	PeriodDay   Period = "day"
+	PeriodWeek  Period = "week"
+	PeriodMonth Period = "month"
+)
+
+func (p Period) IsValid() bool {
+	return p == PeriodDay || p == PeriodWeek || p == PeriodMonth
+}
+
+// PeriodEarnings is a host's earnings within one period. Payments are booked
+// at paid_at and refunds at refunded_at.
+type PeriodEarnings struct {
+	PeriodStart time.Time // local calendar date the period starts on
+	Totals
+}
+
+// HostEarnings is one host's row in the admin reconciliation view.
+type HostEarnings struct {
+	HostID          string
+	HostUsername    string
+	HostDisplayName *string
+	GroupCount      int
+	Totals
+}
+
+// PeriodFilter selects a host's earnings over [From, To) in Period buckets of
+// Timezone's calendar.
+type PeriodFilter struct {
+	HostID   string
+	From     time.Time
+	To       time.Time
+	Period   Period
+	Timezone string
+}
+
+// HostFilter pages through the per-host earnings over an optional [From, To)
+// range.
+type HostFilter struct {
+	From     *time.Time
+	To       *time.Time
+	Page     int
+	PageSize int
+}
@@ -0,0 +1,312 @@
+package pickuppayment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type Repository interface {
+	// GetOrderRef resolves an order to its group, host, fee, seats and status.
+	GetOrderRef(ctx context.Context, orderID string) (*OrderRef, error)
+
+	// Create inserts the payment and marks its order's payment_status as done
+	// in the same transaction.
+	Create(ctx context.Context, p *Payment) error
+	GetByID(ctx context.Context, id string) (*Payment, error)
+	// ListByOrder returns the order's payments, oldest first.
+	ListByOrder(ctx context.Context, orderID string) ([]*Payment, error)
+	// Refund marks the payment refunded. It returns ErrAlreadyRefunded when it
+	// already was. Refunding an order's last outstanding payment resets the
+	// order's payment status and settles its pending refund in the same
+	// transaction.
+	Refund(ctx context.Context, id, refundedBy string) (*Payment, error)
+
+	// GroupTotals sums the payments recorded for a group.
+	GroupTotals(ctx context.Context, groupID string) (Totals, error)
+	// PeriodTotals buckets a host's payments and refunds by period; periods
+	// without activity are omitted.
+	PeriodTotals(ctx context.Context, filter PeriodFilter) ([]*PeriodEarnings, error)
+	// HostTotals sums payments and refunds per host, highest net first.
+	HostTotals(ctx context.Context, filter HostFilter) ([]*HostEarnings, int, error)
+}
+
+type pgxRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewPgxRepository(pool *pgxpool.Pool) Repository {
+	return &pgxRepository{pool: pool}
+}
+
+// paymentSelectColumns are the columns returned by the payment read queries,
+// in the order scanPaymentInto expects.
+var paymentSelectColumns = []string{
+	"id", "order_id", "pickup_group_id", "host_id", "payer_user_id",
+	"amount", "method::TEXT", "paid_at", "note", "recorded_by",
+	"refunded_at", "refunded_by", "created_at",
+}
+
+// scanPaymentInto returns scan targets in the paymentSelectColumns order.
+func scanPaymentInto(p *Payment) []any {
+	return []any{
+		&p.ID, &p.OrderID, &p.PickupGroupID, &p.HostID, &p.PayerUserID,
+		&p.Amount, &p.Method, &p.PaidAt, &p.Note, &p.RecordedBy,
+		&p.RefundedAt, &p.RefundedBy, &p.CreatedAt,
+	}
+}
+
+// ledgerEvents flattens the ledger into dated entries: each payment at its
+// paid_at, and each refund again at its refunded_at.
+const ledgerEvents = `
+	SELECT host_id, pickup_group_id, paid_at AS at, amount AS collected, 0 AS refunded, 1 AS payments
+	FROM public.pickup_payments
+	UNION ALL
+	SELECT host_id, pickup_group_id, refunded_at, 0, amount, 0
+	FROM public.pickup_payments WHERE refunded_at IS NOT NULL`
+
+func (r *pgxRepository) GetOrderRef(ctx context.Context, orderID string) (*OrderRef, error) {
+	var ref OrderRef
+	err := r.pool.QueryRow(ctx,
+		`SELECT po.id, pg.id, pg.host_id, po.user_id, pg.fee, po.seats, po.status::TEXT
+		 FROM public.pickup_orders po
+		 JOIN public.pickup_groups pg ON pg.id = po.pickup_group_id
+		 WHERE po.id = $1`,
+		orderID,
+	).Scan(&ref.OrderID, &ref.PickupGroupID, &ref.HostID, &ref.PayerUserID, &ref.Fee, &ref.Seats, &ref.Status)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrOrderNotFound
+		}
+		return nil, fmt.Errorf("get pickup order failed: %w", err)
+	}
+	return &ref, nil
+}
+
+func (r *pgxRepository) Create(ctx context.Context, p *Payment) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin transaction failed: %w", err)
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck
+
+	psql := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
+	query, args, err := psql.Insert("public.pickup_payments").
+		Columns("order_id", "pickup_group_id", "host_id", "payer_user_id",
+			"amount", "method", "paid_at", "note", "recorded_by").
+		Values(p.OrderID, p.PickupGroupID, p.HostID, p.PayerUserID,
+			p.Amount, p.Method, p.PaidAt, p.Note, p.RecordedBy).
+		Suffix("RETURNING id, created_at").
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("build create pickup payment query failed: %w", err)
+	}
+	if err := tx.QueryRow(ctx, query, args...).Scan(&p.ID, &p.CreatedAt); err != nil {
+		return fmt.Errorf("create pickup payment failed: %w", err)
+	}
+
+	if _, err := tx.Exec(ctx,
+		"UPDATE public.pickup_orders SET payment_status = 'done', updated_at = now() "+
+			"WHERE id = $1 AND payment_status <> 'done'",
+		p.OrderID,
+	); err != nil {
+		return fmt.Errorf("update pickup order payment status failed: %w", err)
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (r *pgxRepository) GetByID(ctx context.Context, id string) (*Payment, error) {
+	psql := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
+	query, args, err := psql.Select(paymentSelectColumns...).
+		From("public.pickup_payments").
+		Where(squirrel.Eq{"id": id}).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build get pickup payment query failed: %w", err)
+	}
+
+	var p Payment
+	if err := r.pool.QueryRow(ctx, query, args...).Scan(scanPaymentInto(&p)...); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrPaymentNotFound
+		}
+		return nil, fmt.Errorf("get pickup payment failed: %w", err)
+	}
+	return &p, nil
+}
+
+func (r *pgxRepository) ListByOrder(ctx context.Context, orderID string) ([]*Payment, error) {
+	psql := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
+	query, args, err := psql.Select(paymentSelectColumns...).
+		From("public.pickup_payments").
+		Where(squirrel.Eq{"order_id": orderID}).
+		OrderBy("paid_at ASC", "id ASC").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build list pickup payments query failed: %w", err)
+	}
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("list pickup payments failed: %w", err)
+	}
+	defer rows.Close()
+
+	var payments []*Payment
+	for rows.Next() {
+		var p Payment
+		if err := rows.Scan(scanPaymentInto(&p)...); err != nil {
+			return nil, fmt.Errorf("scan pickup payment failed: %w", err)
+		}
+		payments = append(payments, &p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("list pickup payments failed: %w", err)
+	}
+	return payments, nil
+}
+
+func (r *pgxRepository) Refund(ctx context.Context, id, refundedBy string) (*Payment, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("begin transaction failed: %w", err)
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck
+
+	psql := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
+	query, args, err := psql.Update("public.pickup_payments").
+		Set("refunded_at", squirrel.Expr("now()")).
+		Set("refunded_by", refundedBy).
+		Where(squirrel.Eq{"id": id}).
+		Where("refunded_at IS NULL").
+		Suffix("RETURNING " + strings.Join(paymentSelectColumns, ", ")).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build refund pickup payment query failed: %w", err)
+	}
+
+	var p Payment
+	if err := tx.QueryRow(ctx, query, args...).Scan(scanPaymentInto(&p)...); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			// Either the payment does not exist or it was refunded already.
+			if _, getErr := r.GetByID(ctx, id); getErr != nil {
+				return nil, getErr
+			}
+			return nil, ErrAlreadyRefunded
+		}
+		return nil, fmt.Errorf("refund pickup payment failed: %w", err)
+	}
+
+	if p.OrderID != nil {
+		if _, err := tx.Exec(ctx, `
+			UPDATE public.pickup_orders
+			SET payment_status = 'pending',
+			    refund_status = CASE WHEN refund_status = 'pending' THEN 'done' ELSE refund_status END,
+			    updated_at = now()
+			WHERE id = $1
+			  AND NOT EXISTS (SELECT 1 FROM public.pickup_payments
+			                  WHERE order_id = $1 AND refunded_at IS NULL)`,
+			*p.OrderID,
+		); err != nil {
+			return nil, fmt.Errorf("settle pickup order refund failed: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+func (r *pgxRepository) GroupTotals(ctx context.Context, groupID string) (Totals, error) {
+	var t Totals
+	if err := r.pool.QueryRow(ctx,
+		`SELECT COALESCE(SUM(amount), 0),
+		        COALESCE(SUM(amount) FILTER (WHERE refunded_at IS NOT NULL), 0),
+		        COUNT(*)
+		 FROM public.pickup_payments WHERE pickup_group_id = $1`,
+		groupID,
+	).Scan(&t.Collected, &t.Refunded, &t.PaymentCount); err != nil {
+		return Totals{}, fmt.Errorf("sum pickup group payments failed: %w", err)
+	}
+	return t, nil
+}
+
+func (r *pgxRepository) PeriodTotals(ctx context.Context, filter PeriodFilter) ([]*PeriodEarnings, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT date_trunc($2::TEXT, e.at AT TIME ZONE $3::TEXT)::DATE AS period_start,
+		       SUM(e.collected), SUM(e.refunded), SUM(e.payments)
+		FROM (`+ledgerEvents+`) e
+		WHERE e.host_id = $1 AND e.at >= $4 AND e.at < $5
+		GROUP BY 1
+		ORDER BY 1`,
+		filter.HostID, string(filter.Period), filter.Timezone, filter.From, filter.To,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("sum pickup payments by period failed: %w", err)
+	}
+	defer rows.Close()
+
+	var periods []*PeriodEarnings
+	for rows.Next() {
+		var p PeriodEarnings
+		if err := rows.Scan(&p.PeriodStart, &p.Collected, &p.Refunded, &p.PaymentCount); err != nil {
+			return nil, fmt.Errorf("scan pickup earnings period failed: %w", err)
+		}
+		periods = append(periods, &p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("sum pickup payments by period failed: %w", err)
+	}
+	return periods, nil
+}
+
+func (r *pgxRepository) HostTotals(ctx context.Context, filter HostFilter) ([]*HostEarnings, int, error) {
+	if filter.Page < 1 {
+		filter.Page = 1
+	}
+	if filter.PageSize < 1 {
+		filter.PageSize = 20
+	}
+	offset := (filter.Page - 1) * filter.PageSize
+
+	rows, err := r.pool.Query(ctx, `
+		SELECT e.host_id, u.username, u.display_name,
+		       COUNT(DISTINCT e.pickup_group_id),
+		       SUM(e.collected), SUM(e.refunded), SUM(e.payments),
+		       COUNT(*) OVER() AS total_count
+		FROM (`+ledgerEvents+`) e
+		JOIN public.users u ON u.id = e.host_id
+		WHERE ($1::TIMESTAMPTZ IS NULL OR e.at >= $1)
+		  AND ($2::TIMESTAMPTZ IS NULL OR e.at < $2)
+		GROUP BY e.host_id, u.id
+		ORDER BY SUM(e.collected) - SUM(e.refunded) DESC, e.host_id
+		LIMIT $3 OFFSET $4`,
+		filter.From, filter.To, filter.PageSize, offset,
+	)
+	if err != nil {
+		return nil, 0, fmt.Errorf("sum pickup payments by host failed: %w", err)
+	}
+	defer rows.Close()
+
+	var hosts []*HostEarnings
+	var total int
+	for rows.Next() {
+		var h HostEarnings
+		if err := rows.Scan(&h.HostID, &h.HostUsername, &h.HostDisplayName, &h.GroupCount,
+			&h.Collected, &h.Refunded, &h.PaymentCount, &total); err != nil {
+			return nil, 0, fmt.Errorf("scan pickup host earnings failed: %w", err)
+		}
+		hosts = append(hosts, &h)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("sum pickup payments by host failed: %w", err)
+	}
+	return hosts, total, nil
+}
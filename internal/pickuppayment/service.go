@@ -0,0 +1,238 @@
+package pickuppayment
+
+import (
+	"context"
+	"time"
+
+	"github.com/nekogravitycat/court-booking-backend/internal/pickup"
+)
+
+// RecordRequest records a payment against an order.
+type RecordRequest struct {
+	OrderID    string
+	RecorderID string
+	IsSysAdmin bool
+	// Amount defaults to the group fee times the order's seats.
+	Amount *int
+	Method Method
+	// PaidAt defaults to now.
+	PaidAt *time.Time
+	Note   *string
+}
+
+// EarningsRequest selects a host's earnings between two local calendar dates
+// (both inclusive).
+type EarningsRequest struct {
+	HostID   string
+	From     time.Time
+	To       time.Time
+	Period   Period // defaults to month
+	Timezone string // defaults to UTC
+}
+
+// HostEarningsRequest selects the per-host reconciliation view. From and To
+// are optional local calendar dates (both inclusive).
+type HostEarningsRequest struct {
+	From     *time.Time
+	To       *time.Time
+	Timezone string // defaults to UTC
+	Page     int
+	PageSize int
+}
+
+type Service interface {
+	// Record adds a payment to the ledger and marks the order paid. Only the
+	// group host or a system admin may record payments, and only for orders
+	// on the group's roster.
+	Record(ctx context.Context, req RecordRequest) (*Payment, error)
+	// ListByOrder returns an order's payments to its host, its booker, or a
+	// system admin.
+	ListByOrder(ctx context.Context, orderID, viewerUserID string, isSysAdmin bool) ([]*Payment, error)
+	// Refund marks a payment refunded; host or system admin only.
+	Refund(ctx context.Context, paymentID, userID string, isSysAdmin bool) (*Payment, error)
+
+	// GetGroupEarnings summarises a group's payments against its fee; host or
+	// system admin only.
+	GetGroupEarnings(ctx context.Context, groupID, userID string, isSysAdmin bool) (*GroupEarnings, error)
+	// GetHostEarnings buckets a host's payments by period and returns the
+	// totals over the whole range.
+	GetHostEarnings(ctx context.Context, req EarningsRequest) ([]*PeriodEarnings, Totals, error)
+	// ListHostEarnings sums every host's payments for reconciliation by
+	// system admins.
+	ListHostEarnings(ctx context.Context, req HostEarningsRequest) ([]*HostEarnings, int, error)
+}
+
+type service struct {
+	repo          Repository
+	pickupService pickup.Service
+	now           func() time.Time
+}
+
+func NewService(repo Repository, pickupService pickup.Service) Service {
+	return &service{
+		repo:          repo,
+		pickupService: pickupService,
+		now:           time.Now,
+	}
+}
+
+func (s *service) Record(ctx context.Context, req RecordRequest) (*Payment, error) {
+	if !req.Method.IsValid() {
+		return nil, ErrInvalidMethod
+	}
+
+	ref, err := s.repo.GetOrderRef(ctx, req.OrderID)
+	if err != nil {
+		return nil, err
+	}
+	if !req.IsSysAdmin && ref.HostID != req.RecorderID {
+		return nil, ErrPermissionDenied
+	}
+	if !ref.Status.OnRoster() {
+		return nil, ErrOrderNotOnRoster
+	}
+
+	amount := ref.Fee * ref.Seats
+	if req.Amount != nil {
+		amount = *req.Amount
+	}
+	if amount <= 0 {
+		return nil, ErrInvalidAmount
+	}
+	paidAt := s.now()
+	if req.PaidAt != nil {
+		paidAt = *req.PaidAt
+	}
+
+	p := &Payment{
+		OrderID:       &ref.OrderID,
+		PickupGroupID: &ref.PickupGroupID,
+		HostID:        ref.HostID,
+		PayerUserID:   &ref.PayerUserID,
+		Amount:        amount,
+		Method:        req.Method,
+		PaidAt:        paidAt,
+		Note:          req.Note,
+		RecordedBy:    &req.RecorderID,
+	}
+	if err := s.repo.Create(ctx, p); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func (s *service) ListByOrder(ctx context.Context, orderID, viewerUserID string, isSysAdmin bool) ([]*Payment, error) {
+	ref, err := s.repo.GetOrderRef(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if !isSysAdmin && ref.HostID != viewerUserID && ref.PayerUserID != viewerUserID {
+		return nil, ErrPermissionDenied
+	}
+	return s.repo.ListByOrder(ctx, orderID)
+}
+
+func (s *service) Refund(ctx context.Context, paymentID, userID string, isSysAdmin bool) (*Payment, error) {
+	p, err := s.repo.GetByID(ctx, paymentID)
+	if err != nil {
+		return nil, err
+	}
+	if !isSysAdmin && p.HostID != userID {
+		return nil, ErrPermissionDenied
+	}
+	return s.repo.Refund(ctx, paymentID, userID)
+}
+
+func (s *service) GetGroupEarnings(ctx context.Context, groupID, userID string, isSysAdmin bool) (*GroupEarnings, error) {
+	group, err := s.pickupService.GetGroupByID(ctx, groupID)
+	if err != nil {
+		return nil, err
+	}
+	if !isSysAdmin && group.HostID != userID {
+		return nil, ErrPermissionDenied
+	}
+
+	totals, err := s.repo.GroupTotals(ctx, groupID)
+	if err != nil {
+		return nil, err
+	}
+	return &GroupEarnings{
+		PickupGroupID: group.ID,
+		Fee:           group.Fee,
+		OccupiedSeats: group.CurrentEnrolled,
+		Totals:        totals,
+	}, nil
+}
+
+func (s *service) GetHostEarnings(ctx context.Context, req EarningsRequest) ([]*PeriodEarnings, Totals, error) {
+	if req.Period == "" {
+		req.Period = PeriodMonth
+	}
+	if !req.Period.IsValid() {
+		return nil, Totals{}, ErrInvalidPeriod
+	}
+	loc, err := loadLocation(&req.Timezone)
+	if err != nil {
+		return nil, Totals{}, err
+	}
+	if req.To.Before(req.From) {
+		return nil, Totals{}, ErrInvalidDateRange
+	}
+
+	periods, err := s.repo.PeriodTotals(ctx, PeriodFilter{
+		HostID:   req.HostID,
+		From:     dayStart(req.From, loc),
+		To:       dayStart(req.To, loc).AddDate(0, 0, 1),
+		Period:   req.Period,
+		Timezone: req.Timezone,
+	})
+	if err != nil {
+		return nil, Totals{}, err
+	}
+
+	var totals Totals
+	for _, p := range periods {
+		totals.add(p.Totals)
+	}
+	return periods, totals, nil
+}
+
+func (s *service) ListHostEarnings(ctx context.Context, req HostEarningsRequest) ([]*HostEarnings, int, error) {
+	loc, err := loadLocation(&req.Timezone)
+	if err != nil {
+		return nil, 0, err
+	}
+	if req.From != nil && req.To != nil && req.To.Before(*req.From) {
+		return nil, 0, ErrInvalidDateRange
+	}
+
+	filter := HostFilter{Page: req.Page, PageSize: req.PageSize}
+	if req.From != nil {
+		from := dayStart(*req.From, loc)
+		filter.From = &from
+	}
+	if req.To != nil {
+		to := dayStart(*req.To, loc).AddDate(0, 0, 1)
+		filter.To = &to
+	}
+	return s.repo.HostTotals(ctx, filter)
+}
+
+// loadLocation resolves the timezone name, defaulting an empty one to UTC in
+// place.
+func loadLocation(name *string) (*time.Location, error) {
+	if *name == "" {
+		*name = "UTC"
+	}
+	loc, err := time.LoadLocation(*name)
+	if err != nil {
+		return nil, ErrInvalidTimezone
+	}
+	return loc, nil
+}
+
+// dayStart is midnight in loc of t's calendar date.
+func dayStart(t time.Time, loc *time.Location) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, loc)
+}
@@ -0,0 +1,130 @@
+package http
+
+import (
+	"time"
+
+	"github.com/nekogravitycat/court-booking-backend/internal/pickuptemplate"
+	"github.com/nekogravitycat/court-booking-backend/internal/pkg/request"
+)
+
+const dateLayout = "2006-01-02"
+
+// --- Request types ---
+
+type ListTemplatesRequest struct {
+	request.ListParams
+	// HostID lets a system admin list another host's templates. Other callers
+	// always see only their own.
+	HostID string `form:"host_id" binding:"omitempty,uuid"`
+}
+
+type CreateTemplateBody struct {
+	Title           string  `json:"title" binding:"required"`
+	Fee             int     `json:"fee" binding:"min=0"`
+	Capacity        int     `json:"capacity" binding:"required,min=1"`
+	LocationID      string  `json:"location_id" binding:"required,uuid"`
+	SportID         string  `json:"sport_id" binding:"required,uuid"`
+	SkillLevelID    string  `json:"skill_level_id" binding:"required,uuid"`
+	Weekdays        []int   `json:"weekdays" binding:"required,min=1,dive,min=0,max=6"`
+	StartLocal      string  `json:"start_local" binding:"required"`
+	DurationMinutes int     `json:"duration_minutes" binding:"required,min=1"`
+	Timezone        string  `json:"timezone"`
+	AdvanceDays     int     `json:"advance_days" binding:"omitempty,min=1"`
+	StartsOn        *string `json:"starts_on" binding:"omitempty,datetime=2006-01-02"`
+	EndsOn          *string `json:"ends_on" binding:"omitempty,datetime=2006-01-02"`
+	IsActive        *bool   `json:"is_active"`
+}
+
+type UpdateTemplateBody struct {
+	Title           *string `json:"title"`
+	Fee             *int    `json:"fee" binding:"omitempty,min=0"`
+	Capacity        *int    `json:"capacity" binding:"omitempty,min=1"`
+	LocationID      *string `json:"location_id" binding:"omitempty,uuid"`
+	SportID         *string `json:"sport_id" binding:"omitempty,uuid"`
+	SkillLevelID    *string `json:"skill_level_id" binding:"omitempty,uuid"`
+	Weekdays        []int   `json:"weekdays" binding:"omitempty,min=1,dive,min=0,max=6"`
+	StartLocal      *string `json:"start_local"`
+	DurationMinutes *int    `json:"duration_minutes" binding:"omitempty,min=1"`
+	Timezone        *string `json:"timezone"`
+	AdvanceDays     *int    `json:"advance_days" binding:"omitempty,min=1"`
+	StartsOn        *string `json:"starts_on" binding:"omitempty,datetime=2006-01-02"`
+	EndsOn          *string `json:"ends_on" binding:"omitempty,datetime=2006-01-02"`
+	// ClearEndsOn makes the template open-ended again.
+	ClearEndsOn bool  `json:"clear_ends_on"`
+	IsActive    *bool `json:"is_active"`
+}
+
+// parseDate parses an optional YYYY-MM-DD value already checked by binding.
+func parseDate(s *string) *time.Time {
+	if s == nil {
+		return nil
+	}
+	d, err := time.Parse(dateLayout, *s)
+	if err != nil {
+		return nil
+	}
+	return &d
+}
+
+// CoHostURI binds the path parameters of a template co-host.
+type CoHostURI struct {
+	ID     string `uri:"id" binding:"required,uuid"`
+	UserID string `uri:"user_id" binding:"required,uuid"`
+}
+
+// --- Response types ---
+
+type TemplateResponse struct {
+	ID                  string    `json:"id"`
+	HostID              string    `json:"host_id"`
+	Title               string    `json:"title"`
+	Fee                 int       `json:"fee"`
+	Capacity            int       `json:"capacity"`
+	LocationID          string    `json:"location_id"`
+	SportID             string    `json:"sport_id"`
+	SkillLevelID        string    `json:"skill_level_id"`
+	Weekdays            []int     `json:"weekdays"`
+	StartLocal          string    `json:"start_local"`
+	DurationMinutes     int       `json:"duration_minutes"`
+	Timezone            string    `json:"timezone"`
+	AdvanceDays         int       `json:"advance_days"`
+	StartsOn            string    `json:"starts_on"`
+	EndsOn              *string   `json:"ends_on"`
+	IsActive            bool      `json:"is_active"`
+	MaterializedThrough *string   `json:"materialized_through"`
+	CoHostIDs           []string  `json:"co_host_ids"`
+	CreatedAt           time.Time `json:"created_at"`
+	UpdatedAt           time.Time `json:"updated_at"`
+}
+
+func NewTemplateResponse(t *pickuptemplate.Template) TemplateResponse {
+	resp := TemplateResponse{
+		ID:              t.ID,
+		HostID:          t.HostID,
+		Title:           t.Title,
+		Fee:             t.Fee,
+		Capacity:        t.Capacity,
+		LocationID:      t.LocationID,
+		SportID:         t.SportID,
+		SkillLevelID:    t.SkillLevelID,
+		Weekdays:        t.Weekdays,
+		StartLocal:      t.StartLocal,
+		DurationMinutes: t.DurationMinutes,
+		Timezone:        t.Timezone,
+		AdvanceDays:     t.AdvanceDays,
+		StartsOn:        t.StartsOn.Format(dateLayout),
+		IsActive:        t.IsActive,
+		CoHostIDs:       t.CoHostIDs,
+		CreatedAt:       t.CreatedAt.UTC(),
+		UpdatedAt:       t.UpdatedAt.UTC(),
+	}
+	if t.EndsOn != nil {
+		endsOn := t.EndsOn.Format(dateLayout)
+		resp.EndsOn = &endsOn
+	}
+	if t.MaterializedThrough != nil {
+		through := t.MaterializedThrough.Format(dateLayout)
+		resp.MaterializedThrough = &through
+	}
+	return resp
+}
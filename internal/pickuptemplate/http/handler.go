@@ -0,0 +1,267 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nekogravitycat/court-booking-backend/internal/auth"
+	"github.com/nekogravitycat/court-booking-backend/internal/pickuptemplate"
+	"github.com/nekogravitycat/court-booking-backend/internal/pkg/request"
+	"github.com/nekogravitycat/court-booking-backend/internal/pkg/response"
+	"github.com/nekogravitycat/court-booking-backend/internal/user"
+)
+
+type Handler struct {
+	service     pickuptemplate.Service
+	userService user.Service
+}
+
+func NewHandler(service pickuptemplate.Service, userService user.Service) *Handler {
+	return &Handler{
+		service:     service,
+		userService: userService,
+	}
+}
+
+func (h *Handler) Create(c *gin.Context) {
+	var body CreateTemplateBody
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body", "details": err.Error()})
+		return
+	}
+
+	userID := auth.GetUserID(c)
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	// Only pickup hosts (or system admins) may create pickup templates, since
+	// every template materialises pickup groups on the creator's behalf.
+	u, err := h.userService.GetByID(c.Request.Context(), userID)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+	if !u.IsSystemAdmin && !u.IsPickupHost {
+		c.JSON(http.StatusForbidden, gin.H{"error": "only pickup hosts can create pickup templates"})
+		return
+	}
+
+	isActive := true
+	if body.IsActive != nil {
+		isActive = *body.IsActive
+	}
+
+	req := pickuptemplate.CreateRequest{
+		HostID:          userID,
+		Title:           body.Title,
+		Fee:             body.Fee,
+		Capacity:        body.Capacity,
+		LocationID:      body.LocationID,
+		SportID:         body.SportID,
+		SkillLevelID:    body.SkillLevelID,
+		Weekdays:        body.Weekdays,
+		StartLocal:      body.StartLocal,
+		DurationMinutes: body.DurationMinutes,
+		Timezone:        body.Timezone,
+		AdvanceDays:     body.AdvanceDays,
+		StartsOn:        parseDate(body.StartsOn),
+		EndsOn:          parseDate(body.EndsOn),
+		IsActive:        isActive,
+	}
+
+	t, err := h.service.Create(c.Request.Context(), req)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, NewTemplateResponse(t))
+}
+
+// List returns the caller's own templates. A system admin may pass host_id to
+// list another host's templates, or omit it to list all of them.
+func (h *Handler) List(c *gin.Context) {
+	var req ListTemplatesRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid query parameters", "details": err.Error()})
+		return
+	}
+
+	userID := auth.GetUserID(c)
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	u, err := h.userService.GetByID(c.Request.Context(), userID)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	filter := pickuptemplate.Filter{
+		HostID:   userID,
+		Page:     req.Page,
+		PageSize: req.PageSize,
+	}
+	if u.IsSystemAdmin {
+		filter.HostID = req.HostID
+	}
+
+	templates, total, err := h.service.List(c.Request.Context(), filter)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	items := make([]TemplateResponse, len(templates))
+	for i, t := range templates {
+		items[i] = NewTemplateResponse(t)
+	}
+
+	c.JSON(http.StatusOK, response.NewPageResponse(items, req.Page, req.PageSize, total))
+}
+
+func (h *Handler) Get(c *gin.Context) {
+	t, ok := h.authorize(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, NewTemplateResponse(t))
+}
+
+func (h *Handler) Update(c *gin.Context) {
+	t, ok := h.authorize(c)
+	if !ok {
+		return
+	}
+
+	var body UpdateTemplateBody
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body", "details": err.Error()})
+		return
+	}
+
+	req := pickuptemplate.UpdateRequest{
+		Title:           body.Title,
+		Fee:             body.Fee,
+		Capacity:        body.Capacity,
+		LocationID:      body.LocationID,
+		SportID:         body.SportID,
+		SkillLevelID:    body.SkillLevelID,
+		Weekdays:        body.Weekdays,
+		StartLocal:      body.StartLocal,
+		DurationMinutes: body.DurationMinutes,
+		Timezone:        body.Timezone,
+		AdvanceDays:     body.AdvanceDays,
+		StartsOn:        parseDate(body.StartsOn),
+		EndsOn:          parseDate(body.EndsOn),
+		ClearEndsOn:     body.ClearEndsOn,
+		IsActive:        body.IsActive,
+	}
+
+	updated, err := h.service.Update(c.Request.Context(), t.ID, req)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, NewTemplateResponse(updated))
+}
+
+func (h *Handler) Delete(c *gin.Context) {
+	t, ok := h.authorize(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.Delete(c.Request.Context(), t.ID); err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// AddCoHost adds a co-host copied onto the template's future groups.
+// Access Control: template host or system admin.
+func (h *Handler) AddCoHost(c *gin.Context) {
+	var uri CoHostURI
+	if err := c.ShouldBindUri(&uri); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request", "details": err.Error()})
+		return
+	}
+
+	t, ok := h.authorize(c)
+	if !ok {
+		return
+	}
+
+	updated, err := h.service.AddCoHost(c.Request.Context(), t.ID, uri.UserID, auth.GetUserID(c))
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, NewTemplateResponse(updated))
+}
+
+// RemoveCoHost removes a template co-host; groups already materialised keep
+// theirs. Access Control: template host or system admin.
+func (h *Handler) RemoveCoHost(c *gin.Context) {
+	var uri CoHostURI
+	if err := c.ShouldBindUri(&uri); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request", "details": err.Error()})
+		return
+	}
+
+	t, ok := h.authorize(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.RemoveCoHost(c.Request.Context(), t.ID, uri.UserID); err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// authorize loads the template named by the :id path parameter and checks the
+// caller is its host or a system admin. On failure it writes the response and
+// returns ok=false.
+func (h *Handler) authorize(c *gin.Context) (*pickuptemplate.Template, bool) {
+	var uri request.ByIDRequest
+	if err := c.ShouldBindUri(&uri); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request", "details": err.Error()})
+		return nil, false
+	}
+
+	userID := auth.GetUserID(c)
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return nil, false
+	}
+
+	u, err := h.userService.GetByID(c.Request.Context(), userID)
+	if err != nil {
+		response.Error(c, err)
+		return nil, false
+	}
+
+	t, err := h.service.GetByID(c.Request.Context(), uri.ID)
+	if err != nil {
+		response.Error(c, err)
+		return nil, false
+	}
+
+	if !u.IsSystemAdmin && t.HostID != userID {
+		response.Error(c, pickuptemplate.ErrPermissionDenied)
+		return nil, false
+	}
+	return t, true
+}
@@ -0,0 +1,19 @@
+package http
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterRoutes(g *gin.RouterGroup, h *Handler, authMiddleware gin.HandlerFunc) {
+	templatesGroup := g.Group("/pickup-templates")
+	templatesGroup.Use(authMiddleware)
+	{
+		templatesGroup.POST("", h.Create)
+		templatesGroup.GET("", h.List)
+		templatesGroup.GET("/:id", h.Get)
+		templatesGroup.PATCH("/:id", h.Update)
+		templatesGroup.DELETE("/:id", h.Delete)
+		templatesGroup.PUT("/:id/cohosts/:user_id", h.AddCoHost)
+		templatesGroup.DELETE("/:id/cohosts/:user_id", h.RemoveCoHost)
+	}
+}
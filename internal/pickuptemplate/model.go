@@ -0,0 +1,75 @@
+package pickuptemplate
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/nekogravitycat/court-booking-backend/internal/pkg/apperror"
+)
+
+var (
+	ErrTemplateNotFound   = apperror.New(http.StatusNotFound, "pickup template not found")
+	ErrPermissionDenied   = apperror.New(http.StatusForbidden, "permission denied")
+	ErrInvalidWeekdays    = apperror.New(http.StatusBadRequest, "weekdays must be distinct values between 0 (Sunday) and 6 (Saturday)")
+	ErrInvalidStartLocal  = apperror.New(http.StatusBadRequest, "start_local must be a time in HH:MM format")
+	ErrInvalidDuration    = apperror.New(http.StatusBadRequest, "duration_minutes must be between 1 and 1440")
+	ErrInvalidAdvanceDays = apperror.New(http.StatusBadRequest, "advance_days must be between 1 and 90")
+	ErrInvalidTimezone    = apperror.New(http.StatusBadRequest, "invalid timezone")
+	ErrInvalidDateRange   = apperror.New(http.StatusBadRequest, "ends_on must not be before starts_on")
+	ErrCoHostIsHost       = apperror.New(http.StatusBadRequest, "the host cannot be added as a co-host")
+	ErrCoHostNotFound     = apperror.New(http.StatusNotFound, "co-host not found")
+	ErrUserNotFound       = apperror.New(http.StatusNotFound, "user not found")
+)
+
+const (
+	// DefaultAdvanceDays is how far ahead occurrences are materialised when a
+	// template does not specify it.
+	DefaultAdvanceDays = 14
+	// MaxAdvanceDays bounds advance_days so a template cannot flood the group
+	// list with months of empty sessions.
+	MaxAdvanceDays = 90
+	// MaxDurationMinutes bounds a single occurrence to one day.
+	MaxDurationMinutes = 24 * 60
+)
+
+// Template is a recurring pickup group definition. Weekdays, StartLocal and
+// Timezone form a weekly rule in the host's wall-clock time; the remaining
+// group fields are copied onto every materialised PickupGroup.
+type Template struct {
+	ID              string
+	HostID          string
+	Title           string
+	Fee             int
+	Capacity        int
+	LocationID      string
+	SportID         string
+	SkillLevelID    string
+	Weekdays        []int  // 0 = Sunday ... 6 = Saturday
+	StartLocal      string // "HH:MM:SS" in Timezone
+	DurationMinutes int
+	Timezone        string
+	AdvanceDays     int
+	StartsOn        time.Time
+	EndsOn          *time.Time
+	IsActive        bool
+	// MaterializedThrough is the last local date already considered for
+	// materialisation; later runs only generate dates after it.
+	MaterializedThrough *time.Time
+	// CoHostIDs are copied onto every group materialised from the template.
+	CoHostIDs []string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// Occurrence is a single concrete session generated from a template.
+type Occurrence struct {
+	Date      time.Time // local calendar date (midnight UTC)
+	StartTime time.Time
+	EndTime   time.Time
+}
+
+type Filter struct {
+	HostID   string
+	Page     int
+	PageSize int
+}
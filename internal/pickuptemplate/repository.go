@@ -0,0 +1,347 @@
+package pickuptemplate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/nekogravitycat/court-booking-backend/internal/pickup"
+)
+
+type Repository interface {
+	Create(ctx context.Context, t *Template) error
+	GetByID(ctx context.Context, id string) (*Template, error)
+	List(ctx context.Context, filter Filter) ([]*Template, int, error)
+	// Update saves the template. resetWatermark clears materialized_through so
+	// the next materialisation re-applies a changed rule to the whole window.
+	Update(ctx context.Context, t *Template, resetWatermark bool) error
+	Delete(ctx context.Context, id string) error
+	// AddCoHost makes the user a co-host of the template; adding an existing
+	// co-host is a no-op. An unknown user returns ErrUserNotFound.
+	AddCoHost(ctx context.Context, templateID, userID, addedBy string) error
+	// RemoveCoHost returns ErrCoHostNotFound when the user is not a co-host.
+	RemoveCoHost(ctx context.Context, templateID, userID string) error
+	// ListActiveIDs returns the ids of all templates eligible for materialisation.
+	ListActiveIDs(ctx context.Context) ([]string, error)
+
+	// Materialize inserts a pickup group for each occurrence dated after the
+	// template's watermark and advances the watermark to through. It locks the
+	// template row and relies on the (template_id, occurrence_date) unique index,
+	// so concurrent runs never create duplicates. The template's co-hosts are
+	// copied onto each new group, and the new groups are handed to publish in
+	// the same transaction. Returns the number of groups created.
+	Materialize(ctx context.Context, templateID string, occurrences []Occurrence, through time.Time, publish func(tx pgx.Tx, created []*pickup.PickupGroup) error) (int, error)
+}
+
+type pgxRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewPgxRepository(pool *pgxpool.Pool) Repository {
+	return &pgxRepository{pool: pool}
+}
+
+// templateSelectColumns are the columns returned by the template read queries,
+// in the order scanTemplateInto expects.
+var templateSelectColumns = []string{
+	"id", "host_id", "title", "fee", "capacity", "location_id", "sport_id", "skill_level_id",
+	"weekdays", "start_local::text", "duration_minutes", "timezone", "advance_days",
+	"starts_on", "ends_on", "is_active", "materialized_through", "created_at", "updated_at",
+	"ARRAY(SELECT ch.user_id::TEXT FROM public.pickup_template_cohosts ch " +
+		"WHERE ch.template_id = pickup_group_templates.id ORDER BY ch.created_at, ch.user_id) AS cohost_ids",
+}
+
+// scanTemplateInto returns scan targets in the templateSelectColumns order.
+// Extra trailing targets (e.g. total_count) are appended by callers.
+func scanTemplateInto(t *Template, extra ...any) []any {
+	targets := []any{
+		&t.ID, &t.HostID, &t.Title, &t.Fee, &t.Capacity, &t.LocationID, &t.SportID, &t.SkillLevelID,
+		&t.Weekdays, &t.StartLocal, &t.DurationMinutes, &t.Timezone, &t.AdvanceDays,
+		&t.StartsOn, &t.EndsOn, &t.IsActive, &t.MaterializedThrough, &t.CreatedAt, &t.UpdatedAt,
+		&t.CoHostIDs,
+	}
+	return append(targets, extra...)
+}
+
+func (r *pgxRepository) Create(ctx context.Context, t *Template) error {
+	psql := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
+	query, args, err := psql.Insert("public.pickup_group_templates").
+		Columns("host_id", "title", "fee", "capacity", "location_id", "sport_id", "skill_level_id",
+			"weekdays", "start_local", "duration_minutes", "timezone", "advance_days",
+			"starts_on", "ends_on", "is_active").
+		Values(t.HostID, t.Title, t.Fee, t.Capacity, t.LocationID, t.SportID, t.SkillLevelID,
+			t.Weekdays, t.StartLocal, t.DurationMinutes, t.Timezone, t.AdvanceDays,
+			t.StartsOn, t.EndsOn, t.IsActive).
+		Suffix("RETURNING id, created_at, updated_at").
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("build create pickup template query failed: %w", err)
+	}
+
+	if err := r.pool.QueryRow(ctx, query, args...).Scan(&t.ID, &t.CreatedAt, &t.UpdatedAt); err != nil {
+		return fmt.Errorf("create pickup template failed: %w", err)
+	}
+	return nil
+}
+
+func (r *pgxRepository) GetByID(ctx context.Context, id string) (*Template, error) {
+	psql := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
+	query, args, err := psql.Select(templateSelectColumns...).
+		From("public.pickup_group_templates").
+		Where(squirrel.Eq{"id": id}).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build get pickup template query failed: %w", err)
+	}
+
+	var t Template
+	if err := r.pool.QueryRow(ctx, query, args...).Scan(scanTemplateInto(&t)...); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrTemplateNotFound
+		}
+		return nil, fmt.Errorf("get pickup template failed: %w", err)
+	}
+	return &t, nil
+}
+
+func (r *pgxRepository) List(ctx context.Context, filter Filter) ([]*Template, int, error) {
+	psql := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
+	query := psql.Select(templateSelectColumns...).
+		Column("COUNT(*) OVER() AS total_count").
+		From("public.pickup_group_templates")
+
+	if filter.HostID != "" {
+		query = query.Where(squirrel.Eq{"host_id": filter.HostID})
+	}
+
+	if filter.Page < 1 {
+		filter.Page = 1
+	}
+	if filter.PageSize < 1 {
+		filter.PageSize = 20
+	}
+	offset := (filter.Page - 1) * filter.PageSize
+	query = query.OrderBy("created_at DESC").Limit(uint64(filter.PageSize)).Offset(uint64(offset))
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, 0, fmt.Errorf("build list pickup templates query failed: %w", err)
+	}
+
+	rows, err := r.pool.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("list pickup templates failed: %w", err)
+	}
+	defer rows.Close()
+
+	var templates []*Template
+	var total int
+	for rows.Next() {
+		var t Template
+		if err := rows.Scan(scanTemplateInto(&t, &total)...); err != nil {
+			return nil, 0, fmt.Errorf("scan pickup template failed: %w", err)
+		}
+		templates = append(templates, &t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("list pickup templates failed: %w", err)
+	}
+	return templates, total, nil
+}
+
+func (r *pgxRepository) Update(ctx context.Context, t *Template, resetWatermark bool) error {
+	psql := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
+	builder := psql.Update("public.pickup_group_templates").
+		Set("title", t.Title).
+		Set("fee", t.Fee).
+		Set("capacity", t.Capacity).
+		Set("location_id", t.LocationID).
+		Set("sport_id", t.SportID).
+		Set("skill_level_id", t.SkillLevelID).
+		Set("weekdays", t.Weekdays).
+		Set("start_local", t.StartLocal).
+		Set("duration_minutes", t.DurationMinutes).
+		Set("timezone", t.Timezone).
+		Set("advance_days", t.AdvanceDays).
+		Set("starts_on", t.StartsOn).
+		Set("ends_on", t.EndsOn).
+		Set("is_active", t.IsActive).
+		Set("updated_at", squirrel.Expr("now()"))
+	if resetWatermark {
+		builder = builder.Set("materialized_through", nil)
+	}
+
+	query, args, err := builder.
+		Where(squirrel.Eq{"id": t.ID}).
+		Suffix("RETURNING materialized_through, updated_at").
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("build update pickup template query failed: %w", err)
+	}
+
+	if err := r.pool.QueryRow(ctx, query, args...).Scan(&t.MaterializedThrough, &t.UpdatedAt); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrTemplateNotFound
+		}
+		return fmt.Errorf("update pickup template failed: %w", err)
+	}
+	return nil
+}
+
+func (r *pgxRepository) Delete(ctx context.Context, id string) error {
+	psql := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
+	query, args, err := psql.Delete("public.pickup_group_templates").
+		Where(squirrel.Eq{"id": id}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("build delete pickup template query failed: %w", err)
+	}
+
+	result, err := r.pool.Exec(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("delete pickup template failed: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return ErrTemplateNotFound
+	}
+	return nil
+}
+
+func (r *pgxRepository) ListActiveIDs(ctx context.Context) ([]string, error) {
+	rows, err := r.pool.Query(ctx,
+		"SELECT id FROM public.pickup_group_templates WHERE is_active AND (ends_on IS NULL OR ends_on >= current_date - 1)")
+	if err != nil {
+		return nil, fmt.Errorf("list active pickup templates failed: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("scan pickup template id failed: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("list active pickup templates failed: %w", err)
+	}
+	return ids, nil
+}
+
+func (r *pgxRepository) AddCoHost(ctx context.Context, templateID, userID, addedBy string) error {
+	if _, err := r.pool.Exec(ctx,
+		"INSERT INTO public.pickup_template_cohosts (template_id, user_id, added_by) VALUES ($1, $2, $3) "+
+			"ON CONFLICT DO NOTHING",
+		templateID, userID, addedBy,
+	); err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == pgerrcode.ForeignKeyViolation {
+			if pgErr.ConstraintName == "fk_pickup_template_cohosts_template" {
+				return ErrTemplateNotFound
+			}
+			return ErrUserNotFound
+		}
+		return fmt.Errorf("add pickup template co-host failed: %w", err)
+	}
+	return nil
+}
+
+func (r *pgxRepository) RemoveCoHost(ctx context.Context, templateID, userID string) error {
+	result, err := r.pool.Exec(ctx,
+		"DELETE FROM public.pickup_template_cohosts WHERE template_id = $1 AND user_id = $2",
+		templateID, userID,
+	)
+	if err != nil {
+		return fmt.Errorf("remove pickup template co-host failed: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return ErrCoHostNotFound
+	}
+	return nil
+}
+
+func (r *pgxRepository) Materialize(ctx context.Context, templateID string, occurrences []Occurrence, through time.Time, publish func(tx pgx.Tx, created []*pickup.PickupGroup) error) (int, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("begin transaction failed: %w", err)
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck
+
+	// Lock the template so concurrent runs serialize on the watermark.
+	var watermark *time.Time
+	if err := tx.QueryRow(ctx,
+		"SELECT materialized_through FROM public.pickup_group_templates WHERE id = $1 FOR UPDATE",
+		templateID,
+	).Scan(&watermark); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, ErrTemplateNotFound
+		}
+		return 0, fmt.Errorf("lock pickup template failed: %w", err)
+	}
+
+	// Group fields are copied from the template row itself, so the insert always
+	// reflects the template as committed, not a possibly stale caller copy.
+	const insertOccurrence = `
+		INSERT INTO public.pickup_groups (
+			host_id, title, start_time, end_time, fee, capacity,
+			location_id, sport_id, skill_level_id, status, enable,
+			template_id, occurrence_date
+		)
+		SELECT t.host_id, t.title, $2::timestamptz, $3::timestamptz, t.fee, t.capacity,
+			t.location_id, t.sport_id, t.skill_level_id, 'active', true,
+			t.id, $4::date
+		FROM public.pickup_group_templates t
+		WHERE t.id = $1
+		ON CONFLICT (template_id, occurrence_date) WHERE template_id IS NOT NULL DO NOTHING
+		RETURNING id, host_id, title, sport_id, start_time, visibility::TEXT, enable`
+	const copyCoHosts = `
+		INSERT INTO public.pickup_group_cohosts (pickup_group_id, user_id, added_by)
+		SELECT $2, user_id, added_by FROM public.pickup_template_cohosts WHERE template_id = $1`
+
+	var created []*pickup.PickupGroup
+	for _, o := range occurrences {
+		// Dates at or before the watermark were already considered; skipping
+		// them keeps edited, cancelled or deleted occurrences from reappearing.
+		if watermark != nil && !o.Date.After(*watermark) {
+			continue
+		}
+		var g pickup.PickupGroup
+		err := tx.QueryRow(ctx, insertOccurrence, templateID, o.StartTime, o.EndTime, o.Date).
+			Scan(&g.ID, &g.HostID, &g.Title, &g.SportID, &g.StartTime, &g.Visibility, &g.Enable)
+		if errors.Is(err, pgx.ErrNoRows) {
+			continue // the occurrence already exists
+		}
+		if err != nil {
+			return 0, fmt.Errorf("materialize pickup group failed: %w", err)
+		}
+		if _, err := tx.Exec(ctx, copyCoHosts, templateID, g.ID); err != nil {
+			return 0, fmt.Errorf("copy pickup template co-hosts failed: %w", err)
+		}
+		created = append(created, &g)
+	}
+
+	if _, err := tx.Exec(ctx,
+		"UPDATE public.pickup_group_templates SET materialized_through = GREATEST(materialized_through, $2::date) WHERE id = $1",
+		templateID, through,
+	); err != nil {
+		return 0, fmt.Errorf("advance pickup template watermark failed: %w", err)
+	}
+
+	if len(created) > 0 {
+		if err := publish(tx, created); err != nil {
+			return 0, err
+		}
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("commit materialization failed: %w", err)
+	}
+	return len(created), nil
+}
@@ -0,0 +1,396 @@
+package pickuptemplate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/nekogravitycat/court-booking-backend/internal/pickup"
+)
+
+type CreateRequest struct {
+	HostID          string
+	Title           string
+	Fee             int
+	Capacity        int
+	LocationID      string
+	SportID         string
+	SkillLevelID    string
+	Weekdays        []int
+	StartLocal      string
+	DurationMinutes int
+	Timezone        string
+	AdvanceDays     int        // 0 means DefaultAdvanceDays
+	StartsOn        *time.Time // nil means today in Timezone
+	EndsOn          *time.Time
+	IsActive        bool
+}
+
+type UpdateRequest struct {
+	Title           *string
+	Fee             *int
+	Capacity        *int
+	LocationID      *string
+	SportID         *string
+	SkillLevelID    *string
+	Weekdays        []int
+	StartLocal      *string
+	DurationMinutes *int
+	Timezone        *string
+	AdvanceDays     *int
+	StartsOn        *time.Time
+	EndsOn          *time.Time
+	ClearEndsOn     bool
+	IsActive        *bool
+}
+
+type Service interface {
+	Create(ctx context.Context, req CreateRequest) (*Template, error)
+	GetByID(ctx context.Context, id string) (*Template, error)
+	List(ctx context.Context, filter Filter) ([]*Template, int, error)
+	Update(ctx context.Context, id string, req UpdateRequest) (*Template, error)
+	Delete(ctx context.Context, id string) error
+
+	// AddCoHost and RemoveCoHost manage the co-hosts copied onto the groups
+	// materialised from now on; groups already created keep their own list.
+	AddCoHost(ctx context.Context, id, coHostID, addedBy string) (*Template, error)
+	RemoveCoHost(ctx context.Context, id, coHostID string) error
+
+	// Materialize creates the template's pickup groups that fall inside its
+	// advance window and have not been generated yet. Returns the number of
+	// groups created.
+	Materialize(ctx context.Context, id string) (int, error)
+	// MaterializeAll materialises every active template. It is run periodically
+	// by a background worker; a failing template does not stop the others.
+	MaterializeAll(ctx context.Context) error
+}
+
+type service struct {
+	repo          Repository
+	pickupService pickup.Service
+	now           func() time.Time
+}
+
+func NewService(repo Repository, pickupService pickup.Service) Service {
+	return &service{
+		repo:          repo,
+		pickupService: pickupService,
+		now:           time.Now,
+	}
+}
+
+func (s *service) Create(ctx context.Context, req CreateRequest) (*Template, error) {
+	t := &Template{
+		HostID:          req.HostID,
+		Title:           req.Title,
+		Fee:             req.Fee,
+		Capacity:        req.Capacity,
+		LocationID:      req.LocationID,
+		SportID:         req.SportID,
+		SkillLevelID:    req.SkillLevelID,
+		Weekdays:        req.Weekdays,
+		StartLocal:      req.StartLocal,
+		DurationMinutes: req.DurationMinutes,
+		Timezone:        req.Timezone,
+		AdvanceDays:     req.AdvanceDays,
+		EndsOn:          req.EndsOn,
+		IsActive:        req.IsActive,
+	}
+	if t.Timezone == "" {
+		t.Timezone = "UTC"
+	}
+	if t.AdvanceDays == 0 {
+		t.AdvanceDays = DefaultAdvanceDays
+	}
+
+	loc, err := time.LoadLocation(t.Timezone)
+	if err != nil {
+		return nil, ErrInvalidTimezone
+	}
+	if req.StartsOn != nil {
+		t.StartsOn = dateOf(*req.StartsOn)
+	} else {
+		t.StartsOn = dateOf(s.now().In(loc))
+	}
+	if t.EndsOn != nil {
+		endsOn := dateOf(*t.EndsOn)
+		t.EndsOn = &endsOn
+	}
+
+	if err := validateRule(t); err != nil {
+		return nil, err
+	}
+	if err := s.pickupService.ValidateSportAndSkill(ctx, t.SportID, t.SkillLevelID); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Create(ctx, t); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.materialize(ctx, t); err != nil {
+		return nil, err
+	}
+	return s.repo.GetByID(ctx, t.ID)
+}
+
+func (s *service) GetByID(ctx context.Context, id string) (*Template, error) {
+	return s.repo.GetByID(ctx, id)
+}
+
+func (s *service) List(ctx context.Context, filter Filter) ([]*Template, int, error) {
+	return s.repo.List(ctx, filter)
+}
+
+// Update changes the template. Occurrences that were already materialised keep
+// their own copy of the group fields; hosts edit or cancel those individually
+// through the pickup group endpoints. When the recurrence rule itself changes
+// the watermark is reset, so the new rule is applied to the whole advance
+// window (dates that already have an occurrence are left untouched).
+func (s *service) Update(ctx context.Context, id string, req UpdateRequest) (*Template, error) {
+	t, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Title != nil {
+		t.Title = *req.Title
+	}
+	if req.Fee != nil {
+		t.Fee = *req.Fee
+	}
+	if req.Capacity != nil {
+		t.Capacity = *req.Capacity
+	}
+	if req.LocationID != nil {
+		t.LocationID = *req.LocationID
+	}
+
+	sportOrSkillChanged := false
+	if req.SportID != nil {
+		t.SportID = *req.SportID
+		sportOrSkillChanged = true
+	}
+	if req.SkillLevelID != nil {
+		t.SkillLevelID = *req.SkillLevelID
+		sportOrSkillChanged = true
+	}
+
+	ruleChanged := false
+	if req.Weekdays != nil {
+		t.Weekdays = req.Weekdays
+		ruleChanged = true
+	}
+	if req.StartLocal != nil {
+		t.StartLocal = *req.StartLocal
+		ruleChanged = true
+	}
+	if req.DurationMinutes != nil {
+		t.DurationMinutes = *req.DurationMinutes
+		ruleChanged = true
+	}
+	if req.Timezone != nil {
+		t.Timezone = *req.Timezone
+		ruleChanged = true
+	}
+	if req.AdvanceDays != nil {
+		t.AdvanceDays = *req.AdvanceDays
+	}
+	if req.StartsOn != nil {
+		t.StartsOn = dateOf(*req.StartsOn)
+		ruleChanged = true
+	}
+	if req.ClearEndsOn {
+		t.EndsOn = nil
+	} else if req.EndsOn != nil {
+		endsOn := dateOf(*req.EndsOn)
+		t.EndsOn = &endsOn
+	}
+	if req.IsActive != nil {
+		t.IsActive = *req.IsActive
+	}
+
+	if err := validateRule(t); err != nil {
+		return nil, err
+	}
+	if sportOrSkillChanged {
+		if err := s.pickupService.ValidateSportAndSkill(ctx, t.SportID, t.SkillLevelID); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.repo.Update(ctx, t, ruleChanged); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.materialize(ctx, t); err != nil {
+		return nil, err
+	}
+	return s.repo.GetByID(ctx, id)
+}
+
+// Delete removes the template. Already materialised groups are kept (their
+// template_id is cleared by the foreign key) so existing enrollments survive.
+func (s *service) Delete(ctx context.Context, id string) error {
+	return s.repo.Delete(ctx, id)
+}
+
+func (s *service) AddCoHost(ctx context.Context, id, coHostID, addedBy string) (*Template, error) {
+	t, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if coHostID == t.HostID {
+		return nil, ErrCoHostIsHost
+	}
+	if err := s.repo.AddCoHost(ctx, id, coHostID, addedBy); err != nil {
+		return nil, err
+	}
+	return s.repo.GetByID(ctx, id)
+}
+
+func (s *service) RemoveCoHost(ctx context.Context, id, coHostID string) error {
+	return s.repo.RemoveCoHost(ctx, id, coHostID)
+}
+
+func (s *service) Materialize(ctx context.Context, id string) (int, error) {
+	t, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return 0, err
+	}
+	return s.materialize(ctx, t)
+}
+
+func (s *service) MaterializeAll(ctx context.Context) error {
+	ids, err := s.repo.ListActiveIDs(ctx)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, id := range ids {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if _, err := s.Materialize(ctx, id); err != nil && !errors.Is(err, ErrTemplateNotFound) {
+			errs = append(errs, fmt.Errorf("template %s: %w", id, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (s *service) materialize(ctx context.Context, t *Template) (int, error) {
+	if !t.IsActive {
+		return 0, nil
+	}
+	occurrences, through, err := computeOccurrences(t, s.now())
+	if err != nil {
+		return 0, err
+	}
+	return s.repo.Materialize(ctx, t.ID, occurrences, through, func(tx pgx.Tx, created []*pickup.PickupGroup) error {
+		for _, g := range created {
+			if err := s.pickupService.NotifyFollowers(ctx, tx, g); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// computeOccurrences computes the occurrences of t between the day after its
+// watermark (or today, whichever is later) and today + AdvanceDays, in the
+// template's timezone. Occurrences that have already started are skipped. The
+// returned date is the new watermark.
+func computeOccurrences(t *Template, now time.Time) ([]Occurrence, time.Time, error) {
+	loc, err := time.LoadLocation(t.Timezone)
+	if err != nil {
+		return nil, time.Time{}, ErrInvalidTimezone
+	}
+	clock, err := parseStartLocal(t.StartLocal)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	today := dateOf(now.In(loc))
+	from := today
+	if t.StartsOn.After(from) {
+		from = t.StartsOn
+	}
+	if t.MaterializedThrough != nil && !t.MaterializedThrough.Before(from) {
+		from = t.MaterializedThrough.AddDate(0, 0, 1)
+	}
+	through := today.AddDate(0, 0, t.AdvanceDays)
+	if t.EndsOn != nil && t.EndsOn.Before(through) {
+		through = *t.EndsOn
+	}
+
+	var occurrences []Occurrence
+	for d := from; !d.After(through); d = d.AddDate(0, 0, 1) {
+		if !slices.Contains(t.Weekdays, int(d.Weekday())) {
+			continue
+		}
+		start := time.Date(d.Year(), d.Month(), d.Day(), clock.Hour(), clock.Minute(), clock.Second(), 0, loc)
+		if !start.After(now) {
+			continue
+		}
+		occurrences = append(occurrences, Occurrence{
+			Date:      d,
+			StartTime: start,
+			EndTime:   start.Add(time.Duration(t.DurationMinutes) * time.Minute),
+		})
+	}
+	return occurrences, through, nil
+}
+
+func validateRule(t *Template) error {
+	if len(t.Weekdays) == 0 {
+		return ErrInvalidWeekdays
+	}
+	seen := make(map[int]bool, len(t.Weekdays))
+	for _, d := range t.Weekdays {
+		if d < 0 || d > 6 || seen[d] {
+			return ErrInvalidWeekdays
+		}
+		seen[d] = true
+	}
+	slices.Sort(t.Weekdays)
+
+	clock, err := parseStartLocal(t.StartLocal)
+	if err != nil {
+		return err
+	}
+	t.StartLocal = clock.Format("15:04:05")
+
+	if t.DurationMinutes < 1 || t.DurationMinutes > MaxDurationMinutes {
+		return ErrInvalidDuration
+	}
+	if t.AdvanceDays < 1 || t.AdvanceDays > MaxAdvanceDays {
+		return ErrInvalidAdvanceDays
+	}
+	if _, err := time.LoadLocation(t.Timezone); err != nil {
+		return ErrInvalidTimezone
+	}
+	if t.EndsOn != nil && t.EndsOn.Before(t.StartsOn) {
+		return ErrInvalidDateRange
+	}
+	return nil
+}
+
+func parseStartLocal(s string) (time.Time, error) {
+	for _, layout := range []string{"15:04", "15:04:05"} {
+		if clock, err := time.Parse(layout, s); err == nil {
+			return clock, nil
+		}
+	}
+	return time.Time{}, ErrInvalidStartLocal
+}
+
+// dateOf returns the calendar date of t (in t's location) as midnight UTC, the
+// representation pgx uses for DATE columns.
+func dateOf(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,40 @@
+// Package event carries domain events from the services that raise them to
+// whatever delivers them to users.
+package event
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Event is something that happened which concerns one user.
+type Event struct {
+	Type string
+	// UserID is the user the event is addressed to.
+	UserID string
+	// Data is the event payload; it must be serialisable as JSON.
+	Data       map[string]any
+	OccurredAt time.Time
+}
+
+// Publisher records events for delivery in the caller's transaction. Services
+// publish in the transaction that makes the change, so the events are stored
+// if and only if the change commits.
+type Publisher interface {
+	Publish(ctx context.Context, tx pgx.Tx, events ...Event) error
+}
+
+// Hook publishes the events describing a change. Repositories run it inside
+// the transaction writing the change, after the write and before the commit;
+// an error rolls the change back.
+type Hook func(tx pgx.Tx) error
+
+// Run calls h in tx. A nil Hook publishes nothing.
+func (h Hook) Run(tx pgx.Tx) error {
+	if h == nil {
+		return nil
+	}
+	return h(tx)
+}
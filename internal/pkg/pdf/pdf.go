@@ -0,0 +1,149 @@
+// Package pdf writes simple text-and-line PDF documents, enough for printable
+// sheets. Text is set in Adobe's MSung-Light, a CJK font that PDF viewers
+// provide themselves, so Chinese names render without shipping font files.
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// A4 page size in points.
+const (
+	A4Width  = 595.28
+	A4Height = 841.89
+)
+
+// Document is a multi-page PDF under construction. Coordinates are in points
+// from the top-left corner of the page.
+type Document struct {
+	Width  float64
+	Height float64
+	pages  []*bytes.Buffer
+}
+
+// New returns an empty A4 portrait document.
+func New() *Document {
+	return &Document{Width: A4Width, Height: A4Height}
+}
+
+// AddPage starts a new page; subsequent drawing goes onto it.
+func (d *Document) AddPage() {
+	d.pages = append(d.pages, &bytes.Buffer{})
+}
+
+// PageCount returns the number of pages added so far.
+func (d *Document) PageCount() int {
+	return len(d.pages)
+}
+
+func (d *Document) page() *bytes.Buffer {
+	if len(d.pages) == 0 {
+		d.AddPage()
+	}
+	return d.pages[len(d.pages)-1]
+}
+
+// Text draws s with its baseline starting at (x, y).
+func (d *Document) Text(x, y, size float64, s string) {
+	fmt.Fprintf(d.page(), "BT /F1 %.2f Tf %.2f %.2f Td <%s> Tj ET\n", size, x, d.Height-y, encodeText(s))
+}
+
+// Line draws a line from (x1, y1) to (x2, y2).
+func (d *Document) Line(x1, y1, x2, y2, width float64) {
+	fmt.Fprintf(d.page(), "%.2f w %.2f %.2f m %.2f %.2f l S\n", width, x1, d.Height-y1, x2, d.Height-y2)
+}
+
+// TextWidth estimates the width of s at the given size: half an em for ASCII,
+// a full em for everything else.
+func TextWidth(s string, size float64) float64 {
+	var w float64
+	for _, r := range s {
+		if r < 0x80 {
+			w += size / 2
+		} else {
+			w += size
+		}
+	}
+	return w
+}
+
+// Truncate shortens s with an ellipsis so it fits in maxWidth at size.
+func Truncate(s string, size, maxWidth float64) string {
+	if TextWidth(s, size) <= maxWidth {
+		return s
+	}
+	var b strings.Builder
+	limit := maxWidth - TextWidth("...", size)
+	var w float64
+	for _, r := range s {
+		rw := TextWidth(string(r), size)
+		if w+rw > limit {
+			break
+		}
+		w += rw
+		b.WriteRune(r)
+	}
+	return b.String() + "..."
+}
+
+// encodeText hex-encodes s as UCS-2 big-endian for the UniCNS-UCS2-H
+// encoding. Control characters and characters outside the BMP become '?'.
+func encodeText(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if r < 0x20 || r > 0xFFFF {
+			r = '?'
+		}
+		fmt.Fprintf(&b, "%04X", r)
+	}
+	return b.String()
+}
+
+// WriteTo writes the finished document to w.
+func (d *Document) WriteTo(w io.Writer) (int64, error) {
+	if len(d.pages) == 0 {
+		d.AddPage()
+	}
+
+	var buf bytes.Buffer
+	var offsets []int
+	object := func(body string) {
+		offsets = append(offsets, buf.Len())
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	buf.WriteString("%PDF-1.4\n%\xE2\xE3\xCF\xD3\n")
+
+	// Objects 1-5 are the catalog, page tree and font; each page then takes
+	// two objects, the page and its content stream.
+	kids := make([]string, len(d.pages))
+	for i := range d.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 6+2*i)
+	}
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(d.pages)))
+	object("<< /Type /Font /Subtype /Type0 /BaseFont /MSung-Light /Encoding /UniCNS-UCS2-H " +
+		"/DescendantFonts [4 0 R] >>")
+	object("<< /Type /Font /Subtype /CIDFontType0 /BaseFont /MSung-Light " +
+		"/CIDSystemInfo << /Registry (Adobe) /Ordering (CNS1) /Supplement 0 >> " +
+		"/FontDescriptor 5 0 R /DW 1000 /W [1 95 500] >>")
+	object("<< /Type /FontDescriptor /FontName /MSung-Light /Flags 6 /FontBBox [-160 -249 1015 1071] " +
+		"/ItalicAngle 0 /Ascent 880 /Descent -120 /CapHeight 880 /StemV 93 >>")
+	for i, content := range d.pages {
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.2f %.2f] "+
+			"/Resources << /Font << /F1 3 0 R >> >> /Contents %d 0 R >>", d.Width, d.Height, 7+2*i))
+		object(fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", content.Len(), content.String()))
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+
+	return buf.WriteTo(w)
+}
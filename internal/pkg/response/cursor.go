@@ -0,0 +1,23 @@
+package response
+
+// CursorPageResponse is the wrapper for keyset-paginated list endpoints.
+// NextCursor is empty on the last page.
+type CursorPageResponse[T any] struct {
+	Items      []T    `json:"items"`
+	PageSize   int    `json:"page_size"`
+	NextCursor string `json:"next_cursor"`
+}
+
+// NewCursorPageResponse is a helper to quickly create a cursor page response
+func NewCursorPageResponse[T any](items []T, pageSize int, nextCursor string) CursorPageResponse[T] {
+	// Handle empty slice to avoid JSON outputting null
+	if items == nil {
+		items = make([]T, 0)
+	}
+
+	return CursorPageResponse[T]{
+		Items:      items,
+		PageSize:   pageSize,
+		NextCursor: nextCursor,
+	}
+}
@@ -0,0 +1,35 @@
+// Package worker runs periodic background jobs alongside the HTTP server.
+package worker
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// Periodic is a background job executed once at start-up and then on every
+// Interval tick until the context is cancelled.
+type Periodic struct {
+	Name     string
+	Interval time.Duration
+	Run      func(ctx context.Context) error
+}
+
+// Start blocks, running the job until ctx is done. A failing run is logged and
+// retried on the next tick; it never stops the loop.
+func (p Periodic) Start(ctx context.Context) {
+	ticker := time.NewTicker(p.Interval)
+	defer ticker.Stop()
+
+	for {
+		if err := p.Run(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("worker %s: run failed: %v", p.Name, err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package http
+
+import (
+	"time"
+
+	skillHttp "github.com/nekogravitycat/court-booking-backend/internal/skilllevel/http"
+	"github.com/nekogravitycat/court-booking-backend/internal/skillprofile"
+	sportsHttp "github.com/nekogravitycat/court-booking-backend/internal/sports/http"
+)
+
+// SportURI binds the sport_id path parameter for /me/skill-profiles/{sport_id}.
+type SportURI struct {
+	SportID string `uri:"sport_id" binding:"required,uuid"`
+}
+
+type DeclareSkillBody struct {
+	SkillLevelID string `json:"skill_level_id" binding:"required,uuid"`
+}
+
+type SkillProfileResponse struct {
+	Sport      sportsHttp.SportTag     `json:"sport"`
+	SkillLevel skillHttp.SkillLevelTag `json:"skill_level"`
+	// Verified is true once a host has confirmed the level after a game.
+	Verified   bool       `json:"verified"`
+	VerifiedBy *string    `json:"verified_by"`
+	VerifiedAt *time.Time `json:"verified_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+func NewSkillProfileResponse(p *skillprofile.SkillProfile) SkillProfileResponse {
+	resp := SkillProfileResponse{
+		Sport:      sportsHttp.SportTag{ID: p.SportID, Code: p.SportCode, Name: p.SportName},
+		SkillLevel: skillHttp.SkillLevelTag{ID: p.SkillLevelID, Name: p.SkillLevelName},
+		Verified:   p.Verified(),
+		VerifiedBy: p.VerifiedBy,
+		UpdatedAt:  p.UpdatedAt.UTC(),
+	}
+	if p.VerifiedAt != nil {
+		t := p.VerifiedAt.UTC()
+		resp.VerifiedAt = &t
+	}
+	return resp
+}
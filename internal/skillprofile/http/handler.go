@@ -0,0 +1,106 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nekogravitycat/court-booking-backend/internal/auth"
+	"github.com/nekogravitycat/court-booking-backend/internal/pkg/request"
+	"github.com/nekogravitycat/court-booking-backend/internal/pkg/response"
+	"github.com/nekogravitycat/court-booking-backend/internal/skillprofile"
+)
+
+type Handler struct {
+	service skillprofile.Service
+}
+
+func NewHandler(service skillprofile.Service) *Handler {
+	return &Handler{service: service}
+}
+
+// ListMine returns the current user's skill profiles, one per sport.
+func (h *Handler) ListMine(c *gin.Context) {
+	userID := auth.GetUserID(c)
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	h.list(c, userID)
+}
+
+// ListByUser returns another user's skill profiles so hosts can review
+// applicants.
+func (h *Handler) ListByUser(c *gin.Context) {
+	var uri request.ByIDRequest
+	if err := c.ShouldBindUri(&uri); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request", "details": err.Error()})
+		return
+	}
+	h.list(c, uri.ID)
+}
+
+func (h *Handler) list(c *gin.Context, userID string) {
+	profiles, err := h.service.List(c.Request.Context(), userID)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	items := make([]SkillProfileResponse, len(profiles))
+	for i, p := range profiles {
+		items[i] = NewSkillProfileResponse(p)
+	}
+
+	c.JSON(http.StatusOK, items)
+}
+
+// Declare sets the current user's self-declared level for a sport.
+func (h *Handler) Declare(c *gin.Context) {
+	var uri SportURI
+	if err := c.ShouldBindUri(&uri); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request", "details": err.Error()})
+		return
+	}
+
+	userID := auth.GetUserID(c)
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var body DeclareSkillBody
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body", "details": err.Error()})
+		return
+	}
+
+	p, err := h.service.Declare(c.Request.Context(), userID, uri.SportID, body.SkillLevelID)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, NewSkillProfileResponse(p))
+}
+
+// Delete removes the current user's profile for a sport.
+func (h *Handler) Delete(c *gin.Context) {
+	var uri SportURI
+	if err := c.ShouldBindUri(&uri); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request", "details": err.Error()})
+		return
+	}
+
+	userID := auth.GetUserID(c)
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	if err := h.service.Delete(c.Request.Context(), userID, uri.SportID); err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
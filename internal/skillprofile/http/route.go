@@ -0,0 +1,19 @@
+package http
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterRoutes(g *gin.RouterGroup, h *Handler, authMiddleware gin.HandlerFunc) {
+	// Current user's own profiles
+	meGroup := g.Group("/me/skill-profiles")
+	meGroup.Use(authMiddleware)
+	{
+		meGroup.GET("", h.ListMine)
+		meGroup.PUT("/:sport_id", h.Declare)
+		meGroup.DELETE("/:sport_id", h.Delete)
+	}
+
+	// Any logged-in user may view another user's profiles.
+	g.GET("/users/:id/skill-profiles", authMiddleware, h.ListByUser)
+}
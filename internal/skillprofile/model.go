@@ -0,0 +1,38 @@
+package skillprofile
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/nekogravitycat/court-booking-backend/internal/pkg/apperror"
+)
+
+var (
+	ErrNotFound           = apperror.New(http.StatusNotFound, "skill profile not found")
+	ErrSportNotFound      = apperror.New(http.StatusNotFound, "sport not found")
+	ErrSportInactive      = apperror.New(http.StatusBadRequest, "sport is inactive")
+	ErrSkillLevelNotFound = apperror.New(http.StatusNotFound, "skill level not found")
+	ErrSkillLevelMismatch = apperror.New(http.StatusBadRequest, "skill level does not belong to the sport")
+	ErrSkillLevelInactive = apperror.New(http.StatusBadRequest, "skill level is inactive")
+)
+
+// SkillProfile is a user's skill level in one sport. It is self-declared and
+// optionally verified by the host of a game the user played in.
+type SkillProfile struct {
+	UserID         string
+	SportID        string
+	SportCode      string // Joined from sports
+	SportName      string // Joined from sports
+	SkillLevelID   string
+	SkillLevelName string // Joined from skill_levels
+	SortOrder      int    // The skill level's sort_order, used for range checks
+	VerifiedBy     *string
+	VerifiedAt     *time.Time
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+// Verified reports whether a host has confirmed the current level.
+func (p *SkillProfile) Verified() bool {
+	return p.VerifiedAt != nil
+}
@@ -0,0 +1,137 @@
+package skillprofile
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type Repository interface {
+	// Upsert stores a self-declared level, replacing any existing profile for
+	// the sport and clearing its verification.
+	Upsert(ctx context.Context, userID, sportID, skillLevelID string) error
+	// Verify stores a host-verified level for the sport.
+	Verify(ctx context.Context, userID, sportID, skillLevelID, verifierID string) error
+	Get(ctx context.Context, userID, sportID string) (*SkillProfile, error)
+	ListByUser(ctx context.Context, userID string) ([]*SkillProfile, error)
+	Delete(ctx context.Context, userID, sportID string) error
+}
+
+type pgxRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewPgxRepository(pool *pgxpool.Pool) Repository {
+	return &pgxRepository{pool: pool}
+}
+
+var profileSelectColumns = []string{
+	"p.user_id", "p.sport_id", "s.code", "s.name", "p.skill_level_id", "sl.name", "sl.sort_order",
+	"p.verified_by", "p.verified_at", "p.created_at", "p.updated_at",
+}
+
+func profileSelect() squirrel.SelectBuilder {
+	return squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar).
+		Select(profileSelectColumns...).
+		From("public.user_skill_profiles p").
+		Join("public.sports s ON p.sport_id = s.id").
+		Join("public.skill_levels sl ON p.skill_level_id = sl.id")
+}
+
+func scanProfileInto(p *SkillProfile) []any {
+	return []any{
+		&p.UserID, &p.SportID, &p.SportCode, &p.SportName, &p.SkillLevelID, &p.SkillLevelName, &p.SortOrder,
+		&p.VerifiedBy, &p.VerifiedAt, &p.CreatedAt, &p.UpdatedAt,
+	}
+}
+
+func (r *pgxRepository) Upsert(ctx context.Context, userID, sportID, skillLevelID string) error {
+	_, err := r.pool.Exec(ctx,
+		`INSERT INTO public.user_skill_profiles (user_id, sport_id, skill_level_id)
+		 VALUES ($1, $2, $3)
+		 ON CONFLICT (user_id, sport_id) DO UPDATE
+		 SET skill_level_id = EXCLUDED.skill_level_id, verified_by = NULL, verified_at = NULL, updated_at = now()`,
+		userID, sportID, skillLevelID,
+	)
+	if err != nil {
+		return fmt.Errorf("upsert skill profile failed: %w", err)
+	}
+	return nil
+}
+
+func (r *pgxRepository) Verify(ctx context.Context, userID, sportID, skillLevelID, verifierID string) error {
+	_, err := r.pool.Exec(ctx,
+		`INSERT INTO public.user_skill_profiles (user_id, sport_id, skill_level_id, verified_by, verified_at)
+		 VALUES ($1, $2, $3, $4, now())
+		 ON CONFLICT (user_id, sport_id) DO UPDATE
+		 SET skill_level_id = EXCLUDED.skill_level_id, verified_by = EXCLUDED.verified_by,
+		     verified_at = EXCLUDED.verified_at, updated_at = now()`,
+		userID, sportID, skillLevelID, verifierID,
+	)
+	if err != nil {
+		return fmt.Errorf("verify skill profile failed: %w", err)
+	}
+	return nil
+}
+
+func (r *pgxRepository) Get(ctx context.Context, userID, sportID string) (*SkillProfile, error) {
+	query, args, err := profileSelect().
+		Where(squirrel.Eq{"p.user_id": userID, "p.sport_id": sportID}).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build get skill profile query failed: %w", err)
+	}
+
+	var p SkillProfile
+	if err := r.pool.QueryRow(ctx, query, args...).Scan(scanProfileInto(&p)...); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("get skill profile failed: %w", err)
+	}
+	return &p, nil
+}
+
+func (r *pgxRepository) ListByUser(ctx context.Context, userID string) ([]*SkillProfile, error) {
+	query, args, err := profileSelect().
+		Where(squirrel.Eq{"p.user_id": userID}).
+		OrderBy("s.name ASC").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build list skill profiles query failed: %w", err)
+	}
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("list skill profiles failed: %w", err)
+	}
+	defer rows.Close()
+
+	profiles := []*SkillProfile{}
+	for rows.Next() {
+		var p SkillProfile
+		if err := rows.Scan(scanProfileInto(&p)...); err != nil {
+			return nil, fmt.Errorf("scan skill profile failed: %w", err)
+		}
+		profiles = append(profiles, &p)
+	}
+	return profiles, rows.Err()
+}
+
+func (r *pgxRepository) Delete(ctx context.Context, userID, sportID string) error {
+	ct, err := r.pool.Exec(ctx,
+		"DELETE FROM public.user_skill_profiles WHERE user_id = $1 AND sport_id = $2",
+		userID, sportID,
+	)
+	if err != nil {
+		return fmt.Errorf("delete skill profile failed: %w", err)
+	}
+	if ct.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+package skillprofile
+
+import (
+	"context"
+	"errors"
+
+	"github.com/nekogravitycat/court-booking-backend/internal/skilllevel"
+	"github.com/nekogravitycat/court-booking-backend/internal/sports"
+)
+
+type Service interface {
+	List(ctx context.Context, userID string) ([]*SkillProfile, error)
+	Get(ctx context.Context, userID, sportID string) (*SkillProfile, error)
+	// Declare sets the user's own level for a sport. Re-declaring drops any
+	// earlier host verification.
+	Declare(ctx context.Context, userID, sportID, skillLevelID string) (*SkillProfile, error)
+	// Verify records a level confirmed by a host. Callers are responsible for
+	// checking that the verifier hosted a game the user played in.
+	Verify(ctx context.Context, userID, sportID, skillLevelID, verifierID string) (*SkillProfile, error)
+	Delete(ctx context.Context, userID, sportID string) error
+}
+
+type service struct {
+	repo              Repository
+	sportsService     sports.Service
+	skillLevelService skilllevel.Service
+}
+
+func NewService(repo Repository, sportsService sports.Service, skillLevelService skilllevel.Service) Service {
+	return &service{
+		repo:              repo,
+		sportsService:     sportsService,
+		skillLevelService: skillLevelService,
+	}
+}
+
+func (s *service) List(ctx context.Context, userID string) ([]*SkillProfile, error) {
+	return s.repo.ListByUser(ctx, userID)
+}
+
+func (s *service) Get(ctx context.Context, userID, sportID string) (*SkillProfile, error) {
+	return s.repo.Get(ctx, userID, sportID)
+}
+
+func (s *service) Declare(ctx context.Context, userID, sportID, skillLevelID string) (*SkillProfile, error) {
+	if err := s.validateLevel(ctx, sportID, skillLevelID); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Upsert(ctx, userID, sportID, skillLevelID); err != nil {
+		return nil, err
+	}
+	return s.repo.Get(ctx, userID, sportID)
+}
+
+func (s *service) Verify(ctx context.Context, userID, sportID, skillLevelID, verifierID string) (*SkillProfile, error) {
+	if err := s.validateLevel(ctx, sportID, skillLevelID); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Verify(ctx, userID, sportID, skillLevelID, verifierID); err != nil {
+		return nil, err
+	}
+	return s.repo.Get(ctx, userID, sportID)
+}
+
+func (s *service) Delete(ctx context.Context, userID, sportID string) error {
+	return s.repo.Delete(ctx, userID, sportID)
+}
+
+// validateLevel checks that the sport is active and the skill level is an
+// active level of that sport.
+func (s *service) validateLevel(ctx context.Context, sportID, skillLevelID string) error {
+	sport, err := s.sportsService.GetByID(ctx, sportID)
+	if err != nil {
+		if errors.Is(err, sports.ErrNotFound) {
+			return ErrSportNotFound
+		}
+		return err
+	}
+	if !sport.IsActive {
+		return ErrSportInactive
+	}
+
+	sl, err := s.skillLevelService.GetByID(ctx, skillLevelID)
+	if err != nil {
+		if errors.Is(err, skilllevel.ErrNotFound) {
+			return ErrSkillLevelNotFound
+		}
+		return err
+	}
+	if sl.SportID != sportID {
+		return ErrSkillLevelMismatch
+	}
+	if !sl.IsActive {
+		return ErrSkillLevelInactive
+	}
+	return nil
+}
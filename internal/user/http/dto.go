@@ -24,6 +24,17 @@ func (r *ListUsersRequest) Validate() error {
 	return nil
 }
 
+// ListPickupHostsRequest defines query parameters for listing pickup hosts.
+// It extends the user list filters with sorting by host rating.
+type ListPickupHostsRequest struct {
+	request.ListParams
+	Email       string   `form:"email"`
+	IDs         []string `form:"ids"`
+	DisplayName string   `form:"display_name"`
+	IsActive    *bool    `form:"is_active"`
+	SortBy      string   `form:"sort_by" binding:"omitempty,oneof=name email created_at rating"`
+}
+
 // UserResponse is the shape of user data returned in API responses.
 type UserResponse struct {
 	ID              string                      `json:"id"`
@@ -99,6 +110,23 @@ func NewUserResponse(u *user.User) UserResponse {
 	}
 }
 
+// PickupHostResponse is a user in the pickup host list, with the aggregate of
+// participant reviews of their groups.
+type PickupHostResponse struct {
+	UserResponse
+	RatingAverage *float64 `json:"rating_average"` // null while unrated
+	RatingCount   int      `json:"rating_count"`
+}
+
+// NewPickupHostResponse converts a domain user.User to PickupHostResponse.
+func NewPickupHostResponse(u *user.User) PickupHostResponse {
+	return PickupHostResponse{
+		UserResponse:  NewUserResponse(u),
+		RatingAverage: u.HostRatingAverage,
+		RatingCount:   u.HostRatingCount,
+	}
+}
+
 // RegisterRequest defines the payload for user registration.
 type RegisterRequest struct {
 	Email       string `json:"email" binding:"required,email"`
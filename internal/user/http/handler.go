@@ -278,10 +278,11 @@ func (h *UserHandler) Delete(c *gin.Context) {
 	c.Status(http.StatusNoContent)
 }
 
-// ListPickupHosts lists all users who hold the pickup host role.
+// ListPickupHosts lists all users who hold the pickup host role, with their
+// review rating; sort_by=rating orders them by average rating.
 // Access Control: System Admin only.
 func (h *UserHandler) ListPickupHosts(c *gin.Context) {
-	var req ListUsersRequest
+	var req ListPickupHostsRequest
 	if err := c.ShouldBindQuery(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid query parameters", "details": err.Error()})
 		return
@@ -313,9 +314,9 @@ func (h *UserHandler) ListPickupHosts(c *gin.Context) {
 		return
 	}
 
-	items := make([]UserResponse, len(users))
+	items := make([]PickupHostResponse, len(users))
 	for i, u := range users {
-		items[i] = NewUserResponse(u)
+		items[i] = NewPickupHostResponse(u)
 	}
 
 	c.JSON(http.StatusOK, response.NewPageResponse(items, req.Page, req.PageSize, total))
@@ -23,6 +23,12 @@ var (
 	ErrCannotRevokeOwnAdmin = apperror.New(http.StatusForbidden, "cannot revoke your own system admin privilege")
 )
 
+// Events published to the user about their own account.
+const (
+	EventRegistered        = "user.registered"
+	EventPickupHostGranted = "user.pickup_host_granted"
+)
+
 // User represents a user in the system.
 type User struct {
 	ID            string // UUID
@@ -38,6 +44,12 @@ type User struct {
 	IsSystemAdmin bool
 	IsPickupHost  bool
 	Organizations []UserOrganizationBrief
+
+	// HostRatingAverage and HostRatingCount aggregate participant reviews of
+	// the user as a pickup host. They are only populated by List; the average
+	// is nil while the user has no reviews.
+	HostRatingAverage *float64
+	HostRatingCount   int
 }
 
 // UserFilter defines filter options for listing users.
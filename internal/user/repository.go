@@ -13,13 +13,16 @@ import (
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/nekogravitycat/court-booking-backend/internal/pkg/event"
 )
 
 // Repository defines methods for accessing user data from storage.
 type Repository interface {
 	GetByEmail(ctx context.Context, email string) (*User, error)
 	GetByID(ctx context.Context, id string) (*User, error)
-	Create(ctx context.Context, u *User) error
+	// Create inserts the user and runs publish in the same transaction.
+	Create(ctx context.Context, u *User, publish event.Hook) error
 	UpdateLastLogin(ctx context.Context, id string, t time.Time) error
 	List(ctx context.Context, filter UserFilter) ([]*User, int, error)
 	Update(ctx context.Context, u *User) error
@@ -27,7 +30,7 @@ type Repository interface {
 
 	// Pickup host role management
 	IsPickupHost(ctx context.Context, userID string) (bool, error)
-	AddPickupHost(ctx context.Context, userID string) error
+	AddPickupHost(ctx context.Context, userID string, publish event.Hook) error
 	RemovePickupHost(ctx context.Context, userID string) error
 	ListPickupHosts(ctx context.Context, filter UserFilter) ([]*User, int, error)
 }
@@ -175,7 +178,7 @@ func (r *pgxUserRepository) GetByID(ctx context.Context, id string) (*User, erro
 	return &u, nil
 }
 
-func (r *pgxUserRepository) Create(ctx context.Context, u *User) error {
+func (r *pgxUserRepository) Create(ctx context.Context, u *User, publish event.Hook) error {
 	psql := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
 	query, args, err := psql.Insert("public.users").
 		Columns("email", "username", "password_hash", "display_name", "is_active", "is_system_admin").
@@ -186,7 +189,13 @@ func (r *pgxUserRepository) Create(ctx context.Context, u *User) error {
 		return fmt.Errorf("build create user query failed: %w", err)
 	}
 
-	if err := r.pool.QueryRow(ctx, query, args...).Scan(&u.ID, &u.CreatedAt); err != nil {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin transaction failed: %w", err)
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck
+
+	if err := tx.QueryRow(ctx, query, args...).Scan(&u.ID, &u.CreatedAt); err != nil {
 		var e *pgconn.PgError
 		if errors.As(err, &e) && e.Code == pgerrcode.UniqueViolation {
 			if e.ConstraintName == "users_username_key" {
@@ -197,7 +206,10 @@ func (r *pgxUserRepository) Create(ctx context.Context, u *User) error {
 		return fmt.Errorf("Create user failed: %w", err)
 	}
 
-	return nil
+	if err := publish.Run(tx); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
 }
 
 func (r *pgxUserRepository) UpdateLastLogin(ctx context.Context, id string, t time.Time) error {
@@ -229,6 +241,8 @@ func (r *pgxUserRepository) List(ctx context.Context, filter UserFilter) ([]*Use
 		"u.last_login_at", "u.is_active", "u.is_system_admin",
 		"EXISTS(SELECT 1 FROM public.pickup_hosts ph WHERE ph.user_id = u.id) AS is_pickup_host",
 		"count(*) OVER() AS total_count",
+		"(SELECT ROUND(AVG(r.rating), 2)::FLOAT8 FROM public.pickup_host_reviews r WHERE r.host_id = u.id) AS host_rating_average",
+		"(SELECT COUNT(*) FROM public.pickup_host_reviews r WHERE r.host_id = u.id) AS host_rating_count",
 		`COALESCE(
 				(
 					SELECT json_agg(json_build_object(
@@ -275,7 +289,12 @@ func (r *pgxUserRepository) List(ctx context.Context, filter UserFilter) ([]*Use
 		orderDir = filter.SortOrder
 	}
 
-	queryBuilder = queryBuilder.OrderBy(orderBy + " " + orderDir)
+	if orderBy == "rating" {
+		// Unrated users sort last in either direction.
+		queryBuilder = queryBuilder.OrderBy("host_rating_average "+orderDir+" NULLS LAST", "host_rating_count DESC")
+	} else {
+		queryBuilder = queryBuilder.OrderBy(orderBy + " " + orderDir)
+	}
 
 	// Pagination
 	if filter.Page < 1 {
@@ -319,7 +338,9 @@ func (r *pgxUserRepository) List(ctx context.Context, filter UserFilter) ([]*Use
 			&u.IsActive,
 			&u.IsSystemAdmin,
 			&u.IsPickupHost,
-			&total,    // Scan the window function result
+			&total, // Scan the window function result
+			&u.HostRatingAverage,
+			&u.HostRatingCount,
 			&orgsJSON, // Scan the JSON result for organizations
 		); err != nil {
 			return nil, 0, fmt.Errorf("scan user failed: %w", err)
@@ -379,8 +400,14 @@ func (r *pgxUserRepository) IsPickupHost(ctx context.Context, userID string) (bo
 	return exists, nil
 }
 
-func (r *pgxUserRepository) AddPickupHost(ctx context.Context, userID string) error {
-	_, err := r.pool.Exec(ctx,
+func (r *pgxUserRepository) AddPickupHost(ctx context.Context, userID string, publish event.Hook) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin transaction failed: %w", err)
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck
+
+	_, err = tx.Exec(ctx,
 		"INSERT INTO public.pickup_hosts (user_id) VALUES ($1)",
 		userID,
 	)
@@ -396,7 +423,11 @@ func (r *pgxUserRepository) AddPickupHost(ctx context.Context, userID string) er
 		}
 		return fmt.Errorf("add pickup host failed: %w", err)
 	}
-	return nil
+
+	if err := publish.Run(tx); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
 }
 
 func (r *pgxUserRepository) RemovePickupHost(ctx context.Context, userID string) error {
@@ -8,8 +8,11 @@ import (
 	"strings"
 	"time"
 
+	"github.com/jackc/pgx/v5"
+
 	"github.com/nekogravitycat/court-booking-backend/internal/auth"
 	"github.com/nekogravitycat/court-booking-backend/internal/file"
+	"github.com/nekogravitycat/court-booking-backend/internal/pkg/event"
 )
 
 // usernamePattern enforces a Twitter-style handle: 4-15 characters of lowercase
@@ -55,6 +58,7 @@ type service struct {
 	hasher          auth.PasswordHasher
 	fileService     file.Service
 	favoriteCleaner HostFavoriteCleaner
+	publisher       event.Publisher
 
 	minPasswordLength int
 	maxPasswordLength int
@@ -68,7 +72,7 @@ type service struct {
 // NewService creates a new user Service.
 // favoriteCleaner may be nil (e.g. in tests that don't exercise favorites);
 // account deletion simply skips favorite cleanup in that case.
-func NewService(repo Repository, hasher auth.PasswordHasher, fileService file.Service, favoriteCleaner HostFavoriteCleaner) Service {
+func NewService(repo Repository, hasher auth.PasswordHasher, fileService file.Service, favoriteCleaner HostFavoriteCleaner, publisher event.Publisher) Service {
 	// Precompute a dummy hash at the configured cost so login timing for
 	// unknown accounts matches the real bcrypt comparison cost.
 	dummyHash, _ := hasher.Hash("dummy-password-for-constant-time-login")
@@ -78,6 +82,7 @@ func NewService(repo Repository, hasher auth.PasswordHasher, fileService file.Se
 		hasher:            hasher,
 		fileService:       fileService,
 		favoriteCleaner:   favoriteCleaner,
+		publisher:         publisher,
 		minPasswordLength: 8,
 		// bcrypt only considers the first 72 bytes of a password and silently
 		// ignores the rest; reject longer inputs so users are not misled.
@@ -138,7 +143,10 @@ func (s *service) Register(ctx context.Context, email, username, password, displ
 		IsActive:     true,
 	}
 
-	if err := s.repo.Create(ctx, u); err != nil {
+	err = s.repo.Create(ctx, u, func(tx pgx.Tx) error {
+		return s.publish(ctx, tx, EventRegistered, u.ID, map[string]any{"username": u.Username})
+	})
+	if err != nil {
 		// Pass through unique-violation errors mapped by the repository.
 		if errors.Is(err, ErrEmailAlreadyUsed) || errors.Is(err, ErrUsernameAlreadyUsed) {
 			return nil, err
@@ -149,6 +157,12 @@ func (s *service) Register(ctx context.Context, email, username, password, displ
 	return u, nil
 }
 
+// publish sends the user an event about their account, within tx.
+func (s *service) publish(ctx context.Context, tx pgx.Tx, eventType, userID string, data map[string]any) error {
+	e := event.Event{Type: eventType, UserID: userID, Data: data, OccurredAt: time.Now()}
+	return s.publisher.Publish(ctx, tx, e)
+}
+
 func (s *service) Login(ctx context.Context, email, password string) (*User, error) {
 	cleanEmail := normalizeEmail(email)
 	if cleanEmail == "" || strings.TrimSpace(password) == "" {
@@ -285,7 +299,9 @@ func (s *service) AddPickupHost(ctx context.Context, userID string) error {
 	if _, err := s.repo.GetByID(ctx, userID); err != nil {
 		return err
 	}
-	return s.repo.AddPickupHost(ctx, userID)
+	return s.repo.AddPickupHost(ctx, userID, func(tx pgx.Tx) error {
+		return s.publish(ctx, tx, EventPickupHostGranted, userID, nil)
+	})
 }
 
 func (s *service) RemovePickupHost(ctx context.Context, userID string) error {
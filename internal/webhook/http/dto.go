@@ -0,0 +1,122 @@
+package http
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/nekogravitycat/court-booking-backend/internal/pkg/request"
+	"github.com/nekogravitycat/court-booking-backend/internal/webhook"
+)
+
+// WebhookURI identifies a webhook of an organization.
+type WebhookURI struct {
+	ID        string `uri:"id" binding:"required,uuid"`
+	WebhookID string `uri:"webhook_id" binding:"required,uuid"`
+}
+
+// DeliveryURI identifies a delivery of an organization's webhook.
+type DeliveryURI struct {
+	ID         string `uri:"id" binding:"required,uuid"`
+	WebhookID  string `uri:"webhook_id" binding:"required,uuid"`
+	DeliveryID string `uri:"delivery_id" binding:"required,uuid"`
+}
+
+type CreateWebhookBody struct {
+	URL         string   `json:"url" binding:"required"`
+	Description *string  `json:"description"`
+	EventTypes  []string `json:"event_types" binding:"required"`
+	IsActive    *bool    `json:"is_active"`
+}
+
+// UpdateWebhookBody changes the fields that are present. rotate_secret
+// replaces the signing secret.
+type UpdateWebhookBody struct {
+	URL          *string  `json:"url"`
+	Description  *string  `json:"description"`
+	EventTypes   []string `json:"event_types"`
+	IsActive     *bool    `json:"is_active"`
+	RotateSecret bool     `json:"rotate_secret"`
+}
+
+// WebhookResponse describes a webhook. Secret is only included where the
+// owner looks at a single webhook.
+type WebhookResponse struct {
+	ID             string    `json:"id"`
+	OrganizationID string    `json:"organization_id"`
+	URL            string    `json:"url"`
+	Description    *string   `json:"description"`
+	Secret         string    `json:"secret,omitempty"`
+	EventTypes     []string  `json:"event_types"`
+	IsActive       bool      `json:"is_active"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+func NewWebhookResponse(w *webhook.Webhook, withSecret bool) WebhookResponse {
+	resp := WebhookResponse{
+		ID:             w.ID,
+		OrganizationID: w.OrganizationID,
+		URL:            w.URL,
+		Description:    w.Description,
+		EventTypes:     w.EventTypes,
+		IsActive:       w.IsActive,
+		CreatedAt:      w.CreatedAt.UTC(),
+		UpdatedAt:      w.UpdatedAt.UTC(),
+	}
+	if withSecret {
+		resp.Secret = w.Secret
+	}
+	return resp
+}
+
+// ListDeliveriesRequest defines query parameters for a webhook's delivery
+// log, newest first.
+type ListDeliveriesRequest struct {
+	request.ListParams
+	EventType string `form:"event_type"`
+	Status    string `form:"status"`
+}
+
+type DeliveryResponse struct {
+	ID        string          `json:"id"`
+	WebhookID string          `json:"webhook_id"`
+	EventID   string          `json:"event_id"`
+	EventType string          `json:"event_type"`
+	Payload   json.RawMessage `json:"payload"`
+	Status    string          `json:"status"`
+	Attempts  int             `json:"attempts"`
+	// NextAttemptAt is only set while the delivery is pending.
+	NextAttemptAt  *time.Time `json:"next_attempt_at"`
+	ResponseStatus *int       `json:"response_status"`
+	ResponseBody   *string    `json:"response_body"`
+	LastError      *string    `json:"last_error"`
+	DeliveredAt    *time.Time `json:"delivered_at"`
+	RedeliveryOf   *string    `json:"redelivery_of"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+func NewDeliveryResponse(d *webhook.Delivery) DeliveryResponse {
+	resp := DeliveryResponse{
+		ID:             d.ID,
+		WebhookID:      d.WebhookID,
+		EventID:        d.EventID,
+		EventType:      d.EventType,
+		Payload:        json.RawMessage(d.Payload),
+		Status:         string(d.Status),
+		Attempts:       d.Attempts,
+		ResponseStatus: d.ResponseStatus,
+		ResponseBody:   d.ResponseBody,
+		LastError:      d.LastError,
+		RedeliveryOf:   d.RedeliveryOf,
+		CreatedAt:      d.CreatedAt.UTC(),
+	}
+	if d.Status == webhook.DeliveryStatusPending {
+		t := d.NextAttemptAt.UTC()
+		resp.NextAttemptAt = &t
+	}
+	if d.DeliveredAt != nil {
+		t := d.DeliveredAt.UTC()
+		resp.DeliveredAt = &t
+	}
+	return resp
+}
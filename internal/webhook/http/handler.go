@@ -0,0 +1,239 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nekogravitycat/court-booking-backend/internal/auth"
+	"github.com/nekogravitycat/court-booking-backend/internal/pkg/request"
+	"github.com/nekogravitycat/court-booking-backend/internal/pkg/response"
+	"github.com/nekogravitycat/court-booking-backend/internal/webhook"
+)
+
+type Handler struct {
+	service webhook.Service
+}
+
+func NewHandler(service webhook.Service) *Handler {
+	return &Handler{service: service}
+}
+
+// List returns the organization's webhooks, without their secrets.
+// Access Control: Organization Owner or System Admin.
+func (h *Handler) List(c *gin.Context) {
+	var uri request.ByIDRequest
+	if err := c.ShouldBindUri(&uri); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request", "details": err.Error()})
+		return
+	}
+
+	userID := auth.GetUserID(c)
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	hooks, err := h.service.List(c.Request.Context(), uri.ID, userID)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	items := make([]WebhookResponse, len(hooks))
+	for i, w := range hooks {
+		items[i] = NewWebhookResponse(w, false)
+	}
+
+	c.JSON(http.StatusOK, items)
+}
+
+// Create registers a webhook for the organization. The response carries the
+// generated signing secret.
+// Access Control: Organization Owner or System Admin.
+func (h *Handler) Create(c *gin.Context) {
+	var uri request.ByIDRequest
+	if err := c.ShouldBindUri(&uri); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request", "details": err.Error()})
+		return
+	}
+
+	userID := auth.GetUserID(c)
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var body CreateWebhookBody
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body", "details": err.Error()})
+		return
+	}
+
+	w, err := h.service.Create(c.Request.Context(), webhook.CreateRequest{
+		OrganizationID: uri.ID,
+		UserID:         userID,
+		URL:            body.URL,
+		Description:    body.Description,
+		EventTypes:     body.EventTypes,
+		IsActive:       body.IsActive,
+	})
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, NewWebhookResponse(w, true))
+}
+
+// Get returns one of the organization's webhooks, including its secret.
+// Access Control: Organization Owner or System Admin.
+func (h *Handler) Get(c *gin.Context) {
+	var uri WebhookURI
+	if err := c.ShouldBindUri(&uri); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request", "details": err.Error()})
+		return
+	}
+
+	userID := auth.GetUserID(c)
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	w, err := h.service.Get(c.Request.Context(), uri.ID, uri.WebhookID, userID)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, NewWebhookResponse(w, true))
+}
+
+// Update changes a webhook's url, description, event types or active flag,
+// or rotates its secret.
+// Access Control: Organization Owner or System Admin.
+func (h *Handler) Update(c *gin.Context) {
+	var uri WebhookURI
+	if err := c.ShouldBindUri(&uri); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request", "details": err.Error()})
+		return
+	}
+
+	userID := auth.GetUserID(c)
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var body UpdateWebhookBody
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body", "details": err.Error()})
+		return
+	}
+
+	w, err := h.service.Update(c.Request.Context(), uri.ID, uri.WebhookID, userID, webhook.UpdateRequest{
+		URL:          body.URL,
+		Description:  body.Description,
+		EventTypes:   body.EventTypes,
+		IsActive:     body.IsActive,
+		RotateSecret: body.RotateSecret,
+	})
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, NewWebhookResponse(w, true))
+}
+
+// Delete removes a webhook together with its delivery log.
+// Access Control: Organization Owner or System Admin.
+func (h *Handler) Delete(c *gin.Context) {
+	var uri WebhookURI
+	if err := c.ShouldBindUri(&uri); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request", "details": err.Error()})
+		return
+	}
+
+	userID := auth.GetUserID(c)
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	if err := h.service.Delete(c.Request.Context(), uri.ID, uri.WebhookID, userID); err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ListDeliveries returns a webhook's delivery log, newest first, optionally
+// only one event type or status.
+// Access Control: Organization Owner or System Admin.
+func (h *Handler) ListDeliveries(c *gin.Context) {
+	var uri WebhookURI
+	if err := c.ShouldBindUri(&uri); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request", "details": err.Error()})
+		return
+	}
+
+	userID := auth.GetUserID(c)
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var req ListDeliveriesRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid query parameters", "details": err.Error()})
+		return
+	}
+
+	filter := webhook.DeliveryFilter{
+		WebhookID: uri.WebhookID,
+		EventType: req.EventType,
+		Status:    req.Status,
+		Page:      req.Page,
+		PageSize:  req.PageSize,
+	}
+
+	list, total, err := h.service.ListDeliveries(c.Request.Context(), uri.ID, userID, filter)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	items := make([]DeliveryResponse, len(list))
+	for i, d := range list {
+		items[i] = NewDeliveryResponse(d)
+	}
+
+	c.JSON(http.StatusOK, response.NewPageResponse(items, req.Page, req.PageSize, total))
+}
+
+// Redeliver queues a finished delivery to be sent again. The new delivery
+// carries the same payload and event ID.
+// Access Control: Organization Owner or System Admin.
+func (h *Handler) Redeliver(c *gin.Context) {
+	var uri DeliveryURI
+	if err := c.ShouldBindUri(&uri); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request", "details": err.Error()})
+		return
+	}
+
+	userID := auth.GetUserID(c)
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	d, err := h.service.Redeliver(c.Request.Context(), uri.ID, uri.WebhookID, uri.DeliveryID, userID)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, NewDeliveryResponse(d))
+}
@@ -0,0 +1,22 @@
+package http
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterRoutes(g *gin.RouterGroup, h *Handler, authMiddleware gin.HandlerFunc) {
+	// Organization webhooks (permissions are checked by the service)
+	hookGroup := g.Group("/organizations/:id/webhooks")
+	hookGroup.Use(authMiddleware)
+	{
+		hookGroup.GET("", h.List)
+		hookGroup.POST("", h.Create)
+		hookGroup.GET("/:webhook_id", h.Get)
+		hookGroup.PATCH("/:webhook_id", h.Update)
+		hookGroup.DELETE("/:webhook_id", h.Delete)
+
+		// Delivery log and manual redelivery
+		hookGroup.GET("/:webhook_id/deliveries", h.ListDeliveries)
+		hookGroup.POST("/:webhook_id/deliveries/:delivery_id/redeliver", h.Redeliver)
+	}
+}
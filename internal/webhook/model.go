@@ -0,0 +1,174 @@
+// Package webhook lets organization owners subscribe endpoints of their own
+// systems to the organization's booking changes. Each matching event is
+// stored as a delivery, signed with the webhook's secret and POSTed by a
+// background dispatcher that retries failures with exponential backoff. The
+// stored deliveries form the log owners browse and redeliver from.
+package webhook
+
+import (
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/nekogravitycat/court-booking-backend/internal/booking"
+	"github.com/nekogravitycat/court-booking-backend/internal/pkg/apperror"
+)
+
+var (
+	ErrNotFound          = apperror.New(http.StatusNotFound, "webhook not found")
+	ErrDeliveryNotFound  = apperror.New(http.StatusNotFound, "webhook delivery not found")
+	ErrPermissionDenied  = apperror.New(http.StatusForbidden, "permission denied")
+	ErrInvalidURL        = apperror.New(http.StatusBadRequest, "webhook url must be an absolute http or https url")
+	ErrHTTPSRequired     = apperror.New(http.StatusBadRequest, "webhook url must use https")
+	ErrPrivateURL        = apperror.New(http.StatusBadRequest, "webhook url must point to a public address")
+	ErrNoEventTypes      = apperror.New(http.StatusBadRequest, "at least one event type is required")
+	ErrUnknownEventType  = apperror.New(http.StatusBadRequest, "unknown webhook event type")
+	ErrWebhookInactive   = apperror.New(http.StatusConflict, "webhook is disabled")
+	ErrDeliveryInFlight  = apperror.New(http.StatusConflict, "delivery is still pending")
+	ErrInvalidStatus     = apperror.New(http.StatusBadRequest, "invalid delivery status")
+	ErrTooManyWebhooks   = apperror.New(http.StatusConflict, "organization has reached the maximum number of webhooks")
+	ErrDescriptionLength = apperror.New(http.StatusBadRequest, "description must be at most 200 characters")
+)
+
+// Config controls which endpoints webhooks may target.
+type Config struct {
+	// RequireHTTPS rejects plain http URLs. It is set in production.
+	RequireHTTPS bool
+	// AllowPrivateNetworks lets webhooks reach loopback, private and
+	// link-local addresses, for tests with a local receiver.
+	AllowPrivateNetworks bool
+}
+
+// MaxWebhooksPerOrganization bounds how many endpoints one organization can
+// register, so a single booking change fans out to a bounded number of
+// deliveries.
+const MaxWebhooksPerOrganization = 10
+
+// maxDescriptionLength bounds the owner's note on a webhook.
+const maxDescriptionLength = 200
+
+// EventTypes are the event types a webhook can subscribe to.
+var EventTypes = []string{
+	booking.OrgEventBookingCreated,
+	booking.OrgEventBookingUpdated,
+	booking.OrgEventBookingCancelled,
+	booking.OrgEventPaymentStatusChanged,
+}
+
+// IsEventType reports whether t is a subscribable event type.
+func IsEventType(t string) bool {
+	return slices.Contains(EventTypes, t)
+}
+
+// Webhook is an endpoint an organization subscribed to some of its events.
+type Webhook struct {
+	ID             string
+	OrganizationID string
+	URL            string
+	Description    *string
+	// Secret signs every request body with HMAC-SHA256.
+	Secret     string
+	EventTypes []string
+	// IsActive is false while the owner has paused the webhook; its
+	// deliveries then wait until it is enabled again.
+	IsActive  bool
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// Subscribes reports whether the webhook receives events of type t.
+func (w *Webhook) Subscribes(t string) bool {
+	return slices.Contains(w.EventTypes, t)
+}
+
+type DeliveryStatus string
+
+const (
+	DeliveryStatusPending   DeliveryStatus = "pending"
+	DeliveryStatusDelivered DeliveryStatus = "delivered"
+	DeliveryStatusFailed    DeliveryStatus = "failed"
+)
+
+// IsValid reports whether the delivery status is a recognized value.
+func (s DeliveryStatus) IsValid() bool {
+	switch s {
+	case DeliveryStatusPending, DeliveryStatusDelivered, DeliveryStatusFailed:
+		return true
+	}
+	return false
+}
+
+// Retry policy of the dispatcher. The nth failed attempt is retried after
+// RetryBaseDelay * 2^(n-1), capped at RetryMaxDelay; a delivery is given up
+// after MaxAttempts attempts.
+const (
+	MaxAttempts    = 8
+	RetryBaseDelay = 30 * time.Second
+	RetryMaxDelay  = time.Hour
+)
+
+// claimLease is how long a claimed delivery stays hidden from other
+// dispatchers; an attempt that has not finished by then is tried again.
+const claimLease = 5 * time.Minute
+
+// dispatchBatchSize is the number of deliveries claimed at a time.
+const dispatchBatchSize = 100
+
+// retryDelay returns how long to wait after the given failed attempt.
+func retryDelay(attempt int) time.Duration {
+	d := RetryBaseDelay
+	for i := 1; i < attempt && d < RetryMaxDelay; i++ {
+		d *= 2
+	}
+	return min(d, RetryMaxDelay)
+}
+
+// Delivery is one event sent, or to be sent, to one webhook. It is also the
+// delivery log entry: the outcome of its last attempt is kept on it.
+type Delivery struct {
+	ID        string
+	WebhookID string
+	// EventID is the same on every delivery of one event, including manual
+	// redeliveries, so receivers can use it to drop duplicates.
+	EventID   string
+	EventType string
+	// Payload is the JSON request body.
+	Payload []byte
+	Status  DeliveryStatus
+	// Attempts counts the requests made so far.
+	Attempts      int
+	NextAttemptAt time.Time
+	// ResponseStatus and ResponseBody are from the last attempt that got a
+	// response; ResponseBody is cut at maxResponseBody bytes.
+	ResponseStatus *int
+	ResponseBody   *string
+	LastError      *string
+	DeliveredAt    *time.Time
+	// RedeliveryOf is the delivery this one manually repeats.
+	RedeliveryOf *string
+	CreatedAt    time.Time
+}
+
+// DeliveryFilter defines parameters for listing a webhook's deliveries.
+type DeliveryFilter struct {
+	WebhookID string
+	EventType string
+	Status    string
+	Page      int
+	PageSize  int
+}
+
+// Target is a claimed delivery together with the webhook it goes to, as read
+// when the attempt starts.
+type Target struct {
+	Delivery *Delivery
+	URL      string
+	Secret   string
+}
+
+// Outcome is the result of one delivery attempt.
+type Outcome struct {
+	ResponseStatus *int
+	ResponseBody   *string
+	Err            error
+}
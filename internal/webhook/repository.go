@@ -0,0 +1,346 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Repository defines persistence for organization webhooks and their
+// deliveries.
+type Repository interface {
+	Create(ctx context.Context, w *Webhook) error
+	GetByID(ctx context.Context, id string) (*Webhook, error)
+	ListByOrganization(ctx context.Context, orgID string) ([]*Webhook, error)
+	CountByOrganization(ctx context.Context, orgID string) (int, error)
+	Update(ctx context.Context, w *Webhook) error
+	Delete(ctx context.Context, id string) error
+
+	// Enqueue stores a pending delivery of the event for every active webhook
+	// of the organization subscribed to eventType within the caller's
+	// transaction, returning how many there were.
+	Enqueue(ctx context.Context, tx pgx.Tx, orgID, eventID, eventType string, payload []byte) (int64, error)
+	ListDeliveries(ctx context.Context, filter DeliveryFilter) ([]*Delivery, int, error)
+	GetDelivery(ctx context.Context, webhookID, id string) (*Delivery, error)
+	// Redeliver stores a new pending delivery repeating the given one.
+	Redeliver(ctx context.Context, d *Delivery) (*Delivery, error)
+
+	// ClaimDue claims up to limit pending deliveries of active webhooks that
+	// are due, counting the attempt and hiding them from other dispatchers
+	// for lease.
+	ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]*Target, error)
+	MarkDelivered(ctx context.Context, deliveryID string, o Outcome) error
+	// MarkRetry records a failed attempt and reschedules the delivery for at.
+	MarkRetry(ctx context.Context, deliveryID string, o Outcome, at time.Time) error
+	// MarkFailed records a failed attempt and gives up on the delivery.
+	MarkFailed(ctx context.Context, deliveryID string, o Outcome) error
+}
+
+type pgxRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewPgxRepository(pool *pgxpool.Pool) Repository {
+	return &pgxRepository{pool: pool}
+}
+
+const webhookColumns = `id, organization_id, url, description, secret, event_types, is_active, created_at, updated_at`
+
+func scanWebhook(row pgx.Row) (*Webhook, error) {
+	var w Webhook
+	err := row.Scan(&w.ID, &w.OrganizationID, &w.URL, &w.Description, &w.Secret, &w.EventTypes,
+		&w.IsActive, &w.CreatedAt, &w.UpdatedAt)
+	return &w, err
+}
+
+func (r *pgxRepository) Create(ctx context.Context, w *Webhook) error {
+	if err := r.pool.QueryRow(ctx, `
+		INSERT INTO public.organization_webhooks (organization_id, url, description, secret, event_types, is_active)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at, updated_at`,
+		w.OrganizationID, w.URL, w.Description, w.Secret, w.EventTypes, w.IsActive,
+	).Scan(&w.ID, &w.CreatedAt, &w.UpdatedAt); err != nil {
+		return fmt.Errorf("create webhook failed: %w", err)
+	}
+	return nil
+}
+
+func (r *pgxRepository) GetByID(ctx context.Context, id string) (*Webhook, error) {
+	w, err := scanWebhook(r.pool.QueryRow(ctx,
+		`SELECT `+webhookColumns+` FROM public.organization_webhooks WHERE id = $1`, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("get webhook failed: %w", err)
+	}
+	return w, nil
+}
+
+func (r *pgxRepository) ListByOrganization(ctx context.Context, orgID string) ([]*Webhook, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT `+webhookColumns+`
+		FROM public.organization_webhooks
+		WHERE organization_id = $1
+		ORDER BY created_at, id`,
+		orgID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("list webhooks failed: %w", err)
+	}
+	defer rows.Close()
+
+	var result []*Webhook
+	for rows.Next() {
+		w, err := scanWebhook(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan webhook failed: %w", err)
+		}
+		result = append(result, w)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate webhooks failed: %w", err)
+	}
+	return result, nil
+}
+
+func (r *pgxRepository) CountByOrganization(ctx context.Context, orgID string) (int, error) {
+	var n int
+	if err := r.pool.QueryRow(ctx,
+		`SELECT COUNT(*) FROM public.organization_webhooks WHERE organization_id = $1`, orgID,
+	).Scan(&n); err != nil {
+		return 0, fmt.Errorf("count webhooks failed: %w", err)
+	}
+	return n, nil
+}
+
+func (r *pgxRepository) Update(ctx context.Context, w *Webhook) error {
+	err := r.pool.QueryRow(ctx, `
+		UPDATE public.organization_webhooks
+		SET url = $2, description = $3, secret = $4, event_types = $5, is_active = $6, updated_at = now()
+		WHERE id = $1
+		RETURNING updated_at`,
+		w.ID, w.URL, w.Description, w.Secret, w.EventTypes, w.IsActive,
+	).Scan(&w.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrNotFound
+		}
+		return fmt.Errorf("update webhook failed: %w", err)
+	}
+	return nil
+}
+
+func (r *pgxRepository) Delete(ctx context.Context, id string) error {
+	ct, err := r.pool.Exec(ctx, `DELETE FROM public.organization_webhooks WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("delete webhook failed: %w", err)
+	}
+	if ct.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *pgxRepository) Enqueue(ctx context.Context, tx pgx.Tx, orgID, eventID, eventType string, payload []byte) (int64, error) {
+	ct, err := tx.Exec(ctx, `
+		INSERT INTO public.webhook_deliveries (webhook_id, event_id, event_type, payload)
+		SELECT w.id, $2::uuid, $3::text, $4::jsonb
+		FROM public.organization_webhooks w
+		WHERE w.organization_id = $1 AND w.is_active AND $3::text = ANY(w.event_types)`,
+		orgID, eventID, eventType, payload,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("enqueue webhook deliveries failed: %w", err)
+	}
+	return ct.RowsAffected(), nil
+}
+
+const deliveryColumns = `id, webhook_id, event_id, event_type, payload, status, attempts, next_attempt_at,
+	response_status, response_body, last_error, delivered_at, redelivery_of, created_at`
+
+func scanDelivery(row pgx.Row, extra ...any) (*Delivery, error) {
+	var d Delivery
+	dest := []any{&d.ID, &d.WebhookID, &d.EventID, &d.EventType, &d.Payload, &d.Status, &d.Attempts,
+		&d.NextAttemptAt, &d.ResponseStatus, &d.ResponseBody, &d.LastError, &d.DeliveredAt,
+		&d.RedeliveryOf, &d.CreatedAt}
+	err := row.Scan(append(dest, extra...)...)
+	return &d, err
+}
+
+func (r *pgxRepository) ListDeliveries(ctx context.Context, filter DeliveryFilter) ([]*Delivery, int, error) {
+	psql := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
+	query := psql.Select(deliveryColumns, "count(*) OVER() as total_count").
+		From("public.webhook_deliveries").
+		Where(squirrel.Eq{"webhook_id": filter.WebhookID})
+
+	if filter.EventType != "" {
+		query = query.Where(squirrel.Eq{"event_type": filter.EventType})
+	}
+	if filter.Status != "" {
+		query = query.Where(squirrel.Eq{"status": filter.Status})
+	}
+
+	// Pagination
+	if filter.Page < 1 {
+		filter.Page = 1
+	}
+	if filter.PageSize < 1 {
+		filter.PageSize = 20
+	}
+	offset := (filter.Page - 1) * filter.PageSize
+
+	query = query.OrderBy("created_at DESC", "id DESC").
+		Limit(uint64(filter.PageSize)).Offset(uint64(offset))
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, 0, fmt.Errorf("build list webhook deliveries query failed: %w", err)
+	}
+
+	rows, err := r.pool.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("list webhook deliveries failed: %w", err)
+	}
+	defer rows.Close()
+
+	var result []*Delivery
+	var total int
+	for rows.Next() {
+		d, err := scanDelivery(rows, &total)
+		if err != nil {
+			return nil, 0, fmt.Errorf("scan webhook delivery failed: %w", err)
+		}
+		result = append(result, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("iterate webhook deliveries failed: %w", err)
+	}
+	return result, total, nil
+}
+
+func (r *pgxRepository) GetDelivery(ctx context.Context, webhookID, id string) (*Delivery, error) {
+	d, err := scanDelivery(r.pool.QueryRow(ctx,
+		`SELECT `+deliveryColumns+` FROM public.webhook_deliveries WHERE id = $1 AND webhook_id = $2`,
+		id, webhookID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrDeliveryNotFound
+		}
+		return nil, fmt.Errorf("get webhook delivery failed: %w", err)
+	}
+	return d, nil
+}
+
+func (r *pgxRepository) Redeliver(ctx context.Context, d *Delivery) (*Delivery, error) {
+	redelivery, err := scanDelivery(r.pool.QueryRow(ctx, `
+		INSERT INTO public.webhook_deliveries (webhook_id, event_id, event_type, payload, redelivery_of)
+		SELECT webhook_id, event_id, event_type, payload, id
+		FROM public.webhook_deliveries
+		WHERE id = $1
+		RETURNING `+deliveryColumns,
+		d.ID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrDeliveryNotFound
+		}
+		return nil, fmt.Errorf("redeliver webhook delivery failed: %w", err)
+	}
+	return redelivery, nil
+}
+
+func (r *pgxRepository) ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]*Target, error) {
+	rows, err := r.pool.Query(ctx, `
+		WITH due AS (
+			SELECT d.id FROM public.webhook_deliveries d
+			JOIN public.organization_webhooks w ON w.id = d.webhook_id
+			WHERE d.status = 'pending' AND d.next_attempt_at <= now() AND w.is_active
+			ORDER BY d.next_attempt_at
+			LIMIT $1
+			FOR UPDATE OF d SKIP LOCKED
+		), claimed AS (
+			UPDATE public.webhook_deliveries d
+			SET attempts = d.attempts + 1, next_attempt_at = now() + make_interval(secs => $2), updated_at = now()
+			FROM due
+			WHERE d.id = due.id
+			RETURNING d.*
+		)
+		SELECT c.id, c.webhook_id, c.event_id, c.event_type, c.payload, c.status, c.attempts, c.next_attempt_at,
+		       c.response_status, c.response_body, c.last_error, c.delivered_at, c.redelivery_of, c.created_at,
+		       w.url, w.secret
+		FROM claimed c
+		JOIN public.organization_webhooks w ON w.id = c.webhook_id
+		ORDER BY c.created_at`,
+		limit, lease.Seconds(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("claim webhook deliveries failed: %w", err)
+	}
+	defer rows.Close()
+
+	var targets []*Target
+	for rows.Next() {
+		var t Target
+		d, err := scanDelivery(rows, &t.URL, &t.Secret)
+		if err != nil {
+			return nil, fmt.Errorf("scan webhook delivery failed: %w", err)
+		}
+		t.Delivery = d
+		targets = append(targets, &t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate webhook deliveries failed: %w", err)
+	}
+	return targets, nil
+}
+
+// lastError returns the outcome's error message, or nil.
+func lastError(o Outcome) *string {
+	if o.Err == nil {
+		return nil
+	}
+	msg := o.Err.Error()
+	return &msg
+}
+
+func (r *pgxRepository) MarkDelivered(ctx context.Context, deliveryID string, o Outcome) error {
+	if _, err := r.pool.Exec(ctx, `
+		UPDATE public.webhook_deliveries
+		SET status = 'delivered', delivered_at = now(), response_status = $2, response_body = $3,
+		    last_error = NULL, updated_at = now()
+		WHERE id = $1`,
+		deliveryID, o.ResponseStatus, o.ResponseBody,
+	); err != nil {
+		return fmt.Errorf("mark webhook delivery delivered failed: %w", err)
+	}
+	return nil
+}
+
+func (r *pgxRepository) MarkRetry(ctx context.Context, deliveryID string, o Outcome, at time.Time) error {
+	if _, err := r.pool.Exec(ctx, `
+		UPDATE public.webhook_deliveries
+		SET next_attempt_at = $2, response_status = $3, response_body = $4, last_error = $5, updated_at = now()
+		WHERE id = $1`,
+		deliveryID, at, o.ResponseStatus, o.ResponseBody, lastError(o),
+	); err != nil {
+		return fmt.Errorf("reschedule webhook delivery failed: %w", err)
+	}
+	return nil
+}
+
+func (r *pgxRepository) MarkFailed(ctx context.Context, deliveryID string, o Outcome) error {
+	if _, err := r.pool.Exec(ctx, `
+		UPDATE public.webhook_deliveries
+		SET status = 'failed', response_status = $2, response_body = $3, last_error = $4, updated_at = now()
+		WHERE id = $1`,
+		deliveryID, o.ResponseStatus, o.ResponseBody, lastError(o),
+	); err != nil {
+		return fmt.Errorf("give up webhook delivery failed: %w", err)
+	}
+	return nil
+}
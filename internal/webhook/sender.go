@@ -0,0 +1,142 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// Headers of a webhook request.
+const (
+	HeaderEvent     = "X-Webhook-Event"
+	HeaderEventID   = "X-Webhook-Event-ID"
+	HeaderDelivery  = "X-Webhook-Delivery"
+	HeaderSignature = "X-Webhook-Signature"
+)
+
+// requestTimeout bounds one webhook request.
+const requestTimeout = 10 * time.Second
+
+// maxResponseBody is how much of a response body is kept in the delivery log.
+const maxResponseBody = 1 << 10
+
+// errBlockedAddress is the dial error for an address webhooks may not reach.
+var errBlockedAddress = errors.New("webhook address is not publicly routable")
+
+// blockedPrefixes are non-public ranges netip does not classify: shared
+// carrier-grade NAT space and "this network".
+var blockedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("0.0.0.0/8"),
+}
+
+// isPublicAddr reports whether ip is a globally routable unicast address, as
+// opposed to loopback, private, link-local, unspecified or multicast.
+func isPublicAddr(ip netip.Addr) bool {
+	ip = ip.Unmap()
+	if !ip.IsGlobalUnicast() || ip.IsPrivate() {
+		return false
+	}
+	for _, p := range blockedPrefixes {
+		if p.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+// newClient returns the client webhook requests are sent with. Redirects are
+// not followed, so the response logged is always the target's own. Unless
+// allowPrivate is set, connections to non-public addresses are refused when
+// dialing: checking the address actually connected to also covers hosts that
+// resolve elsewhere after their URL was validated.
+func newClient(allowPrivate bool) *http.Client {
+	dialer := &net.Dialer{Timeout: requestTimeout}
+	if !allowPrivate {
+		dialer.Control = func(_, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			ip, err := netip.ParseAddr(host)
+			if err != nil || !isPublicAddr(ip) {
+				return errBlockedAddress
+			}
+			return nil
+		}
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = dialer.DialContext
+	// A proxy would be dialed in place of the target, bypassing the check.
+	transport.Proxy = nil
+	return &http.Client{
+		Timeout:   requestTimeout,
+		Transport: transport,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// Sign returns the signature header value of body: "sha256=" followed by the
+// hex HMAC-SHA256 of the body under secret.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// newSecret returns a random signing secret (256 bits).
+func newSecret() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("generate webhook secret failed: %w", err)
+	}
+	return "whsec_" + hex.EncodeToString(buf), nil
+}
+
+// send POSTs the delivery's payload to the target and reports the outcome. A
+// non-2xx response is an error.
+func send(ctx context.Context, client *http.Client, t *Target) Outcome {
+	d := t.Delivery
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.URL, bytes.NewReader(d.Payload))
+	if err != nil {
+		return Outcome{Err: fmt.Errorf("build webhook request failed: %w", err)}
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "court-booking-webhooks/1")
+	req.Header.Set(HeaderEvent, d.EventType)
+	req.Header.Set(HeaderEventID, d.EventID)
+	req.Header.Set(HeaderDelivery, d.ID)
+	req.Header.Set(HeaderSignature, Sign(t.Secret, d.Payload))
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return Outcome{Err: fmt.Errorf("webhook request failed: %w", err)}
+	}
+	defer resp.Body.Close()
+
+	raw, _ := io.ReadAll(io.LimitReader(resp.Body, maxResponseBody))
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	// The log is a text column: drop what Postgres cannot store.
+	body := strings.ReplaceAll(strings.ToValidUTF8(string(raw), "�"), "\x00", "")
+
+	o := Outcome{ResponseStatus: &resp.StatusCode, ResponseBody: &body}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		o.Err = fmt.Errorf("webhook responded %s", resp.Status)
+	}
+	return o
+}
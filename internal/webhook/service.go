@@ -0,0 +1,367 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/nekogravitycat/court-booking-backend/internal/organization"
+	"github.com/nekogravitycat/court-booking-backend/internal/pkg/event"
+)
+
+type CreateRequest struct {
+	OrganizationID string
+	UserID         string
+	URL            string
+	Description    *string
+	EventTypes     []string
+	// IsActive defaults to true.
+	IsActive *bool
+}
+
+// UpdateRequest changes the fields that are set. RotateSecret replaces the
+// signing secret with a new random one.
+type UpdateRequest struct {
+	URL          *string
+	Description  *string
+	EventTypes   []string
+	IsActive     *bool
+	RotateSecret bool
+}
+
+// Service manages organization webhooks. It is the booking.OrgPublisher the
+// booking service hands its changes to, and dispatches the resulting
+// deliveries in the background. Every management method is for the
+// organization's owner (or a system admin) only.
+type Service interface {
+	// PublishOrgEvent queues a delivery of the event to every active webhook
+	// of the organization subscribed to its type, within the caller's
+	// transaction.
+	PublishOrgEvent(ctx context.Context, tx pgx.Tx, orgID string, e event.Event) error
+	// DispatchDue sends every delivery that is due. Failed deliveries are
+	// rescheduled with backoff, or marked failed after MaxAttempts.
+	DispatchDue(ctx context.Context) error
+
+	Create(ctx context.Context, req CreateRequest) (*Webhook, error)
+	List(ctx context.Context, orgID, userID string) ([]*Webhook, error)
+	Get(ctx context.Context, orgID, id, userID string) (*Webhook, error)
+	Update(ctx context.Context, orgID, id, userID string, req UpdateRequest) (*Webhook, error)
+	Delete(ctx context.Context, orgID, id, userID string) error
+
+	// ListDeliveries returns the webhook's delivery log, newest first.
+	ListDeliveries(ctx context.Context, orgID, userID string, filter DeliveryFilter) ([]*Delivery, int, error)
+	// Redeliver queues the delivery's payload to be sent again as a new
+	// delivery with the same event ID.
+	Redeliver(ctx context.Context, orgID, webhookID, deliveryID, userID string) (*Delivery, error)
+}
+
+type service struct {
+	repo       Repository
+	orgService organization.Service
+	client     *http.Client
+	cfg        Config
+}
+
+func NewService(repo Repository, orgService organization.Service, cfg Config) Service {
+	return &service{
+		repo:       repo,
+		orgService: orgService,
+		client:     newClient(cfg.AllowPrivateNetworks),
+		cfg:        cfg,
+	}
+}
+
+// payload is the JSON body of a webhook request.
+type payload struct {
+	ID             string         `json:"id"`
+	Type           string         `json:"type"`
+	OrganizationID string         `json:"organization_id"`
+	OccurredAt     time.Time      `json:"occurred_at"`
+	Data           map[string]any `json:"data"`
+}
+
+func (s *service) PublishOrgEvent(ctx context.Context, tx pgx.Tx, orgID string, e event.Event) error {
+	if !IsEventType(e.Type) {
+		return fmt.Errorf("publish webhook event: %w: %s", ErrUnknownEventType, e.Type)
+	}
+	p := payload{
+		ID:             uuid.New().String(),
+		Type:           e.Type,
+		OrganizationID: orgID,
+		OccurredAt:     e.OccurredAt.UTC(),
+		Data:           e.Data,
+	}
+	if p.Data == nil {
+		p.Data = map[string]any{}
+	}
+	body, err := json.Marshal(p)
+	if err != nil {
+		return fmt.Errorf("marshal %s webhook payload failed: %w", e.Type, err)
+	}
+	_, err = s.repo.Enqueue(ctx, tx, orgID, p.ID, e.Type, body)
+	return err
+}
+
+func (s *service) DispatchDue(ctx context.Context) error {
+	for {
+		batch, err := s.repo.ClaimDue(ctx, dispatchBatchSize, claimLease)
+		if err != nil {
+			return err
+		}
+		for _, t := range batch {
+			if err := s.deliver(ctx, t); err != nil {
+				return err
+			}
+		}
+		if len(batch) < dispatchBatchSize {
+			return nil
+		}
+	}
+}
+
+// deliver sends one claimed delivery and records the outcome. Only a failure
+// to record the outcome is returned; the claim lease covers that case.
+func (s *service) deliver(ctx context.Context, t *Target) error {
+	d := t.Delivery
+	o := send(ctx, s.client, t)
+	if o.Err == nil {
+		return s.repo.MarkDelivered(ctx, d.ID, o)
+	}
+	if ctx.Err() != nil {
+		// Shutting down: leave the attempt to be retried once the lease lapses.
+		return ctx.Err()
+	}
+
+	if d.Attempts >= MaxAttempts {
+		log.Printf("webhook: giving up delivery %s of %s to webhook %s after %d attempts: %v",
+			d.ID, d.EventType, d.WebhookID, d.Attempts, o.Err)
+		return s.repo.MarkFailed(ctx, d.ID, o)
+	}
+	return s.repo.MarkRetry(ctx, d.ID, o, time.Now().Add(retryDelay(d.Attempts)))
+}
+
+// requireOwner returns ErrPermissionDenied unless the user owns the
+// organization or is a system admin.
+func (s *service) requireOwner(ctx context.Context, orgID, userID string) error {
+	ok, err := s.orgService.IsOwnerOrAbove(ctx, orgID, userID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrPermissionDenied
+	}
+	return nil
+}
+
+// getOwned returns the organization's webhook after checking the user owns
+// the organization.
+func (s *service) getOwned(ctx context.Context, orgID, id, userID string) (*Webhook, error) {
+	if err := s.requireOwner(ctx, orgID, userID); err != nil {
+		return nil, err
+	}
+	w, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if w.OrganizationID != orgID {
+		return nil, ErrNotFound
+	}
+	return w, nil
+}
+
+// validateURL accepts absolute http and https URLs, or only https ones when
+// the config requires it. Hosts that are obviously private are rejected up
+// front; the dialer checks the address each request actually reaches.
+func (s *service) validateURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		return ErrInvalidURL
+	}
+	if s.cfg.RequireHTTPS && u.Scheme != "https" {
+		return ErrHTTPSRequired
+	}
+	if !s.cfg.AllowPrivateNetworks {
+		host := strings.ToLower(u.Hostname())
+		if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+			return ErrPrivateURL
+		}
+		if ip, err := netip.ParseAddr(host); err == nil && !isPublicAddr(ip) {
+			return ErrPrivateURL
+		}
+	}
+	return nil
+}
+
+// normalizeEventTypes checks the types and returns them sorted without
+// duplicates.
+func normalizeEventTypes(types []string) ([]string, error) {
+	if len(types) == 0 {
+		return nil, ErrNoEventTypes
+	}
+	for _, t := range types {
+		if !IsEventType(t) {
+			return nil, ErrUnknownEventType
+		}
+	}
+	types = slices.Clone(types)
+	slices.Sort(types)
+	return slices.Compact(types), nil
+}
+
+func validateDescription(d *string) error {
+	if d != nil && utf8.RuneCountInString(*d) > maxDescriptionLength {
+		return ErrDescriptionLength
+	}
+	return nil
+}
+
+func (s *service) Create(ctx context.Context, req CreateRequest) (*Webhook, error) {
+	if err := s.requireOwner(ctx, req.OrganizationID, req.UserID); err != nil {
+		return nil, err
+	}
+	if err := s.validateURL(req.URL); err != nil {
+		return nil, err
+	}
+	if err := validateDescription(req.Description); err != nil {
+		return nil, err
+	}
+	types, err := normalizeEventTypes(req.EventTypes)
+	if err != nil {
+		return nil, err
+	}
+
+	count, err := s.repo.CountByOrganization(ctx, req.OrganizationID)
+	if err != nil {
+		return nil, err
+	}
+	if count >= MaxWebhooksPerOrganization {
+		return nil, ErrTooManyWebhooks
+	}
+
+	secret, err := newSecret()
+	if err != nil {
+		return nil, err
+	}
+	if req.Description != nil && *req.Description == "" {
+		req.Description = nil
+	}
+	w := &Webhook{
+		OrganizationID: req.OrganizationID,
+		URL:            req.URL,
+		Description:    req.Description,
+		Secret:         secret,
+		EventTypes:     types,
+		IsActive:       req.IsActive == nil || *req.IsActive,
+	}
+	if err := s.repo.Create(ctx, w); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+func (s *service) List(ctx context.Context, orgID, userID string) ([]*Webhook, error) {
+	if err := s.requireOwner(ctx, orgID, userID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListByOrganization(ctx, orgID)
+}
+
+func (s *service) Get(ctx context.Context, orgID, id, userID string) (*Webhook, error) {
+	return s.getOwned(ctx, orgID, id, userID)
+}
+
+func (s *service) Update(ctx context.Context, orgID, id, userID string, req UpdateRequest) (*Webhook, error) {
+	w, err := s.getOwned(ctx, orgID, id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.URL != nil {
+		if err := s.validateURL(*req.URL); err != nil {
+			return nil, err
+		}
+		w.URL = *req.URL
+	}
+	if req.Description != nil {
+		if err := validateDescription(req.Description); err != nil {
+			return nil, err
+		}
+		w.Description = req.Description
+		if *req.Description == "" {
+			w.Description = nil
+		}
+	}
+	if req.EventTypes != nil {
+		types, err := normalizeEventTypes(req.EventTypes)
+		if err != nil {
+			return nil, err
+		}
+		w.EventTypes = types
+	}
+	if req.IsActive != nil {
+		w.IsActive = *req.IsActive
+	}
+	if req.RotateSecret {
+		secret, err := newSecret()
+		if err != nil {
+			return nil, err
+		}
+		w.Secret = secret
+	}
+
+	if err := s.repo.Update(ctx, w); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+func (s *service) Delete(ctx context.Context, orgID, id, userID string) error {
+	if _, err := s.getOwned(ctx, orgID, id, userID); err != nil {
+		return err
+	}
+	return s.repo.Delete(ctx, id)
+}
+
+func (s *service) ListDeliveries(ctx context.Context, orgID, userID string, filter DeliveryFilter) ([]*Delivery, int, error) {
+	if _, err := s.getOwned(ctx, orgID, filter.WebhookID, userID); err != nil {
+		return nil, 0, err
+	}
+	if filter.EventType != "" && !IsEventType(filter.EventType) {
+		return nil, 0, ErrUnknownEventType
+	}
+	if filter.Status != "" && !DeliveryStatus(filter.Status).IsValid() {
+		return nil, 0, ErrInvalidStatus
+	}
+	return s.repo.ListDeliveries(ctx, filter)
+}
+
+func (s *service) Redeliver(ctx context.Context, orgID, webhookID, deliveryID, userID string) (*Delivery, error) {
+	w, err := s.getOwned(ctx, orgID, webhookID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !w.IsActive {
+		return nil, ErrWebhookInactive
+	}
+	d, err := s.repo.GetDelivery(ctx, webhookID, deliveryID)
+	if err != nil {
+		return nil, err
+	}
+	// A pending delivery is still being retried; repeating it would only
+	// send the event twice.
+	if d.Status == DeliveryStatusPending {
+		return nil, ErrDeliveryInFlight
+	}
+	return s.repo.Redeliver(ctx, d)
+}
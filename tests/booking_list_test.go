@@ -0,0 +1,193 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	bookingHttp "github.com/nekogravitycat/court-booking-backend/internal/booking/http"
+	locHttp "github.com/nekogravitycat/court-booking-backend/internal/location/http"
+	orgHttp "github.com/nekogravitycat/court-booking-backend/internal/organization/http"
+	"github.com/nekogravitycat/court-booking-backend/internal/pkg/response"
+	resHttp "github.com/nekogravitycat/court-booking-backend/internal/resource/http"
+)
+
+func TestBookingListFiltersAndCursor(t *testing.T) {
+	clearTables()
+
+	sysAdmin := createTestUser(t, "sysadmin@blist.com", "pass", true)
+	owner := createTestUser(t, "owner@blist.com", "pass", false)
+	alice := createTestUser(t, "alice@blist.com", "pass", false)
+	bob := createTestUser(t, "bob@blist.com", "pass", false)
+
+	sysAdminToken := generateToken(sysAdmin.ID)
+	ownerToken := generateToken(owner.ID)
+	aliceToken := generateToken(alice.ID)
+	bobToken := generateToken(bob.ID)
+
+	// Tomorrow 08:00 UTC, inside the 06:00-23:00 opening hours.
+	base := time.Now().UTC().Truncate(24 * time.Hour).Add(32 * time.Hour)
+
+	var orgID, locationID, resourceID string
+	var bookingIDs []string
+
+	t.Run("Setup", func(t *testing.T) {
+		wOrg := executeRequest("POST", "/v1/organizations", orgHttp.CreateOrganizationRequest{Name: "List Org", OwnerID: owner.ID}, sysAdminToken)
+		require.Equal(t, http.StatusCreated, wOrg.Code)
+		var org orgHttp.OrganizationResponse
+		json.Unmarshal(wOrg.Body.Bytes(), &org)
+		orgID = org.ID
+
+		wLoc := executeRequest("POST", "/v1/locations", locHttp.CreateLocationRequest{
+			OrganizationID:    orgID,
+			Name:              "List Hall",
+			Capacity:          10,
+			OpeningHoursStart: "06:00:00", OpeningHoursEnd: "23:00:00",
+			Opening:      true,
+			Timezone:     "UTC",
+			LocationInfo: "Test Info", Longitude: 120.0, Latitude: 23.0,
+		}, ownerToken)
+		require.Equal(t, http.StatusCreated, wLoc.Code)
+		var loc locHttp.LocationResponse
+		json.Unmarshal(wLoc.Body.Bytes(), &loc)
+		locationID = loc.ID
+
+		wRes := executeRequest("POST", "/v1/resources", resHttp.CreateRequest{
+			Name: "Court 1", LocationID: locationID, ResourceType: "badminton",
+		}, ownerToken)
+		require.Equal(t, http.StatusCreated, wRes.Code)
+		var res resHttp.ResourceResponse
+		json.Unmarshal(wRes.Body.Bytes(), &res)
+		resourceID = res.ID
+
+		// Five consecutive one-hour bookings; alice books even slots, bob odd.
+		for i := 0; i < 5; i++ {
+			token := aliceToken
+			if i%2 == 1 {
+				token = bobToken
+			}
+			w := executeRequest("POST", "/v1/bookings", bookingHttp.CreateBookingRequest{
+				ResourceID: resourceID,
+				StartTime:  base.Add(time.Duration(i) * time.Hour),
+				EndTime:    base.Add(time.Duration(i+1) * time.Hour),
+			}, token)
+			require.Equal(t, http.StatusCreated, w.Code)
+			var b bookingHttp.BookingResponse
+			json.Unmarshal(w.Body.Bytes(), &b)
+			bookingIDs = append(bookingIDs, b.ID)
+		}
+	})
+
+	t.Run("Owner: Location Scope With Search", func(t *testing.T) {
+		w := executeRequest("GET", fmt.Sprintf("/v1/bookings?location_id=%s&q=BOB@", locationID), nil, ownerToken)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var resp response.PageResponse[bookingHttp.BookingResponse]
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		assert.Equal(t, 2, resp.Total)
+		for _, b := range resp.Items {
+			assert.Equal(t, bob.ID, b.User.ID)
+		}
+	})
+
+	t.Run("Owner: Search Wildcards Match Literally", func(t *testing.T) {
+		w := executeRequest("GET", fmt.Sprintf("/v1/bookings?location_id=%s&q=%%25", locationID), nil, ownerToken)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var resp response.PageResponse[bookingHttp.BookingResponse]
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		assert.Equal(t, 0, resp.Total)
+	})
+
+	t.Run("Owner: Payment Status And Resource Type Filters", func(t *testing.T) {
+		w := executeRequest("GET", fmt.Sprintf("/v1/bookings?organization_id=%s&payment_status=pending&resource_type=badminton", orgID), nil, ownerToken)
+		require.Equal(t, http.StatusOK, w.Code)
+		var resp response.PageResponse[bookingHttp.BookingResponse]
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		assert.Equal(t, 5, resp.Total)
+
+		w = executeRequest("GET", fmt.Sprintf("/v1/bookings?organization_id=%s&resource_type=tennis", orgID), nil, ownerToken)
+		require.Equal(t, http.StatusOK, w.Code)
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		assert.Equal(t, 0, resp.Total)
+	})
+
+	t.Run("Created At Range", func(t *testing.T) {
+		future := time.Now().UTC().Add(time.Hour).Format(time.RFC3339)
+		w := executeRequest("GET", fmt.Sprintf("/v1/bookings?organization_id=%s&created_at_from=%s", orgID, future), nil, ownerToken)
+		require.Equal(t, http.StatusOK, w.Code)
+		var resp response.PageResponse[bookingHttp.BookingResponse]
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		assert.Equal(t, 0, resp.Total)
+	})
+
+	t.Run("Regular User: Location Scope Still Only Own", func(t *testing.T) {
+		w := executeRequest("GET", fmt.Sprintf("/v1/bookings?location_id=%s", locationID), nil, aliceToken)
+		require.Equal(t, http.StatusOK, w.Code)
+		var resp response.PageResponse[bookingHttp.BookingResponse]
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		assert.Equal(t, 3, resp.Total)
+	})
+
+	t.Run("Cursor: Walks All Rows Without Gaps Despite Inserts", func(t *testing.T) {
+		var seen []string
+		cursor := ""
+		for page := 0; page < 10; page++ {
+			path := fmt.Sprintf("/v1/bookings?location_id=%s&pagination=cursor&page_size=2&sort_by=start_time&sort_order=asc", locationID)
+			if cursor != "" {
+				path += "&cursor=" + cursor
+			}
+			w := executeRequest("GET", path, nil, ownerToken)
+			require.Equal(t, http.StatusOK, w.Code)
+
+			var resp response.CursorPageResponse[bookingHttp.BookingResponse]
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+			for _, b := range resp.Items {
+				seen = append(seen, b.ID)
+			}
+
+			if page == 0 {
+				// A booking inserted ahead of the cursor position must not shift
+				// later pages (offset pagination would repeat a row here).
+				require.Len(t, resp.Items, 2)
+				wNew := executeRequest("POST", "/v1/bookings", bookingHttp.CreateBookingRequest{
+					ResourceID: resourceID, StartTime: base.Add(-time.Hour), EndTime: base,
+				}, aliceToken)
+				require.Equal(t, http.StatusCreated, wNew.Code)
+			}
+
+			if resp.NextCursor == "" {
+				break
+			}
+			cursor = resp.NextCursor
+		}
+		assert.Equal(t, bookingIDs, seen)
+	})
+
+	t.Run("Cursor: Mismatched Sort Rejected", func(t *testing.T) {
+		w := executeRequest("GET", fmt.Sprintf("/v1/bookings?location_id=%s&pagination=cursor&page_size=2&sort_by=start_time", locationID), nil, ownerToken)
+		require.Equal(t, http.StatusOK, w.Code)
+		var resp response.CursorPageResponse[bookingHttp.BookingResponse]
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		require.NotEmpty(t, resp.NextCursor)
+
+		w = executeRequest("GET", fmt.Sprintf("/v1/bookings?location_id=%s&sort_by=created_at&cursor=%s", locationID, resp.NextCursor), nil, ownerToken)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+
+		w = executeRequest("GET", fmt.Sprintf("/v1/bookings?location_id=%s&sort_by=start_time&sort_order=asc&cursor=%s", locationID, resp.NextCursor), nil, ownerToken)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("Cursor: Invalid Cursor And Unsupported Sort", func(t *testing.T) {
+		w := executeRequest("GET", "/v1/bookings?cursor=not-a-cursor", nil, aliceToken)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+
+		w = executeRequest("GET", "/v1/bookings?pagination=cursor&sort_by=status", nil, aliceToken)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
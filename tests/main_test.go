@@ -20,15 +20,63 @@ import (
 	"github.com/nekogravitycat/court-booking-backend/internal/app"
 	"github.com/nekogravitycat/court-booking-backend/internal/auth"
 	"github.com/nekogravitycat/court-booking-backend/internal/db"
+	"github.com/nekogravitycat/court-booking-backend/internal/notification"
+	"github.com/nekogravitycat/court-booking-backend/internal/pkg/event"
+	"github.com/nekogravitycat/court-booking-backend/internal/pkg/worker"
 	"github.com/nekogravitycat/court-booking-backend/internal/user"
 )
 
 var (
-	testRouter *gin.Engine
-	testPool   *pgxpool.Pool
-	jwtManager *auth.JWTManager
+	testRouter  *gin.Engine
+	testPool    *pgxpool.Pool
+	jwtManager  *auth.JWTManager
+	testWorkers []worker.Periodic
+	testEvents  = &outboxReader{seen: map[string]bool{}}
+	testMail    *smtpSink
+	testWebhook *webhookSink
 )
 
+// testWebhookSecret signs the requests of the webhook notification channel.
+const testWebhookSecret = "test-webhook-secret"
+
+// outboxReader reads the events the services published from the
+// notification outbox. Data comes back decoded from JSON.
+type outboxReader struct {
+	seen map[string]bool
+}
+
+// take returns the events published since the last call, oldest first.
+func (r *outboxReader) take() []event.Event {
+	rows, err := testPool.Query(context.Background(),
+		"SELECT id, user_id, type, data, occurred_at FROM public.notification_outbox ORDER BY created_at, occurred_at")
+	if err != nil {
+		log.Fatalf("read notification outbox failed: %v", err)
+	}
+	defer rows.Close()
+
+	var events []event.Event
+	for rows.Next() {
+		var id string
+		var data []byte
+		var e event.Event
+		if err := rows.Scan(&id, &e.UserID, &e.Type, &data, &e.OccurredAt); err != nil {
+			log.Fatalf("scan notification outbox failed: %v", err)
+		}
+		if r.seen[id] {
+			continue
+		}
+		r.seen[id] = true
+		if err := json.Unmarshal(data, &e.Data); err != nil {
+			log.Fatalf("decode %s event data failed: %v", e.Type, err)
+		}
+		events = append(events, e)
+	}
+	if err := rows.Err(); err != nil {
+		log.Fatalf("read notification outbox failed: %v", err)
+	}
+	return events
+}
+
 func TestMain(m *testing.M) {
 	// Attempt to load .env from parent directory
 	if err := godotenv.Load("../.env"); err != nil {
@@ -54,17 +102,35 @@ func TestMain(m *testing.M) {
 		log.Fatalf("TEST_JWT_SECRET environment variable is not set")
 	}
 
+	// Local sinks for the email and webhook notification channels
+	testMail, err = startSMTPSink()
+	if err != nil {
+		log.Fatalf("Unable to start SMTP sink: %v", err)
+	}
+	testWebhook = startWebhookSink()
+
 	// Initialize App Container using shared logic
 	appContainer := app.NewContainer(app.Config{
 		DBPool:     testPool,
 		JWTSecret:  testSecret,
 		JWTTTL:     30 * time.Minute,
 		BcryptCost: 4, // Lower cost for testing purposes
+		SMTP: notification.SMTPConfig{
+			Host: "127.0.0.1",
+			Port: testMail.port(),
+			From: "Court Booking <no-reply@court-booking.test>",
+		},
+		NotificationWebhookURL:    testWebhook.URL,
+		NotificationWebhookSecret: testWebhookSecret,
+		ReminderOffsets:           []time.Duration{24 * time.Hour, 2 * time.Hour},
+		// Organization webhooks are delivered to the local sink.
+		WebhookAllowPrivateNetworks: true,
 	})
 
 	// Assign global variables for tests to use
 	testRouter = appContainer.Router
 	jwtManager = appContainer.JWTManager
+	testWorkers = appContainer.Workers
 
 	// Setup Gin mode
 	gin.SetMode(gin.TestMode)
@@ -77,6 +143,8 @@ func TestMain(m *testing.M) {
 
 	// Teardown
 	testPool.Close()
+	testMail.close()
+	testWebhook.Close()
 	os.Exit(exitCode)
 }
 
@@ -114,6 +182,17 @@ func clearTables() {
 	}
 }
 
+// runWorker runs one pass of the named background worker synchronously.
+func runWorker(t *testing.T, name string) {
+	for _, w := range testWorkers {
+		if w.Name == name {
+			require.NoError(t, w.Run(context.Background()))
+			return
+		}
+	}
+	t.Fatalf("worker %s not registered", name)
+}
+
 func executeRequest(method, path string, body any, token string) *httptest.ResponseRecorder {
 	var reqBody []byte
 	if body != nil {
@@ -151,7 +230,7 @@ func createTestUser(t *testing.T, email, password string, isAdmin bool) *user.Us
 	}
 
 	repo := user.NewPgxRepository(testPool)
-	err = repo.Create(context.Background(), u)
+	err = repo.Create(context.Background(), u, nil)
 	require.NoError(t, err, "Failed to create test user in DB")
 
 	savedUser, err := repo.GetByEmail(context.Background(), email)
@@ -0,0 +1,195 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nekogravitycat/court-booking-backend/internal/announcement"
+	annHttp "github.com/nekogravitycat/court-booking-backend/internal/announcement/http"
+	"github.com/nekogravitycat/court-booking-backend/internal/app"
+	notificationHttp "github.com/nekogravitycat/court-booking-backend/internal/notification/http"
+	"github.com/nekogravitycat/court-booking-backend/internal/pickup"
+	pickupHttp "github.com/nekogravitycat/court-booking-backend/internal/pickup/http"
+	"github.com/nekogravitycat/court-booking-backend/internal/pkg/response"
+)
+
+func TestNotificationInbox(t *testing.T) {
+	clearTables()
+
+	admin := createTestUser(t, "admin@inbox.com", "pass", true)
+	host := createTestUser(t, "host@inbox.com", "pass", false)
+	grantPickupHost(t, host.ID)
+	player := createTestUser(t, "player@inbox.com", "pass", false)
+	other := createTestUser(t, "other@inbox.com", "pass", false)
+
+	adminToken := generateToken(admin.ID)
+	hostToken := generateToken(host.ID)
+	playerToken := generateToken(player.ID)
+	otherToken := generateToken(other.ID)
+
+	locationID := setupTestLocation(t, hostToken, host.ID)
+	sportID, skillLevelID := getSportSkill(t, "BADMINTON", "B")
+
+	// enrollAndDecide has the player join a new group and the host decide on
+	// the order, then dispatches the resulting notification.
+	enrollAndDecide := func(t *testing.T, title, status string) {
+		w := executeRequest("POST", "/v1/pickup-groups", pickupHttp.CreateGroupBody{
+			Title:        title,
+			StartTime:    time.Now().Add(24 * time.Hour),
+			EndTime:      time.Now().Add(26 * time.Hour),
+			Fee:          100,
+			Capacity:     6,
+			LocationID:   locationID,
+			SportID:      sportID,
+			SkillLevelID: skillLevelID,
+		}, hostToken)
+		require.Equal(t, http.StatusCreated, w.Code)
+		var group pickupHttp.PickupGroupResponse
+		json.Unmarshal(w.Body.Bytes(), &group)
+
+		w = executeRequest("POST", "/v1/pickup-groups/"+group.ID+"/orders", nil, playerToken)
+		require.Equal(t, http.StatusCreated, w.Code)
+		var order pickupHttp.PickupOrderResponse
+		json.Unmarshal(w.Body.Bytes(), &order)
+
+		w = executeRequest("PATCH", "/v1/pickup-orders/"+order.ID, pickupHttp.UpdateOrderBody{Status: &status}, hostToken)
+		require.Equal(t, http.StatusOK, w.Code)
+		runWorker(t, app.NotificationWorker)
+	}
+	announce := func(t *testing.T, title string) {
+		w := executeRequest("POST", "/v1/announcements", annHttp.CreateRequest{Title: title, Content: "Details."}, adminToken)
+		require.Equal(t, http.StatusCreated, w.Code)
+		runWorker(t, app.NotificationWorker)
+	}
+	list := func(t *testing.T, token, query string) response.PageResponse[notificationHttp.NotificationResponse] {
+		w := executeRequest("GET", "/v1/me/notifications"+query, nil, token)
+		require.Equal(t, http.StatusOK, w.Code)
+		var resp response.PageResponse[notificationHttp.NotificationResponse]
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		return resp
+	}
+	unread := func(t *testing.T, token string) notificationHttp.UnreadCountResponse {
+		w := executeRequest("GET", "/v1/me/notifications/unread-count", nil, token)
+		require.Equal(t, http.StatusOK, w.Code)
+		var resp notificationHttp.UnreadCountResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		return resp
+	}
+
+	t.Run("Events Reach The Inbox", func(t *testing.T) {
+		announce(t, "Courts closed on Monday")
+		enrollAndDecide(t, "Declined Game", "rejected")
+
+		resp := list(t, playerToken, "")
+		require.Equal(t, 2, resp.Total)
+		assert.Equal(t, pickup.EventOrderRejected, resp.Items[0].Type, "newest first")
+		assert.Equal(t, "Your pickup enrollment was declined", resp.Items[0].Title)
+		assert.Equal(t, "Declined Game", resp.Items[0].Data["group_title"])
+		assert.Nil(t, resp.Items[0].ReadAt)
+		assert.Equal(t, announcement.EventPublished, resp.Items[1].Type)
+		assert.Equal(t, "Courts closed on Monday", resp.Items[1].Data["title"])
+
+		count := unread(t, playerToken)
+		assert.Equal(t, 2, count.UnreadCount)
+		assert.Equal(t, map[string]int{pickup.EventOrderRejected: 1, announcement.EventPublished: 1}, count.ByType)
+
+		// Announcements reach everyone; the order decision only the player.
+		assert.Equal(t, 1, list(t, otherToken, "").Total)
+
+		filtered := list(t, playerToken, "?type="+announcement.EventPublished)
+		require.Equal(t, 1, filtered.Total)
+		assert.Equal(t, announcement.EventPublished, filtered.Items[0].Type)
+
+		w := executeRequest("GET", "/v1/me/notifications?type=no.such_type", nil, playerToken)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		w = executeRequest("GET", "/v1/me/notifications", nil, "")
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("Mark Read", func(t *testing.T) {
+		id := list(t, playerToken, "").Items[0].ID
+
+		w := executeRequest("POST", "/v1/me/notifications/"+id+"/read", nil, otherToken)
+		assert.Equal(t, http.StatusNotFound, w.Code, "users cannot read each other's notifications")
+
+		w = executeRequest("POST", "/v1/me/notifications/"+id+"/read", nil, playerToken)
+		require.Equal(t, http.StatusOK, w.Code)
+		var n notificationHttp.NotificationResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &n))
+		require.NotNil(t, n.ReadAt)
+
+		// Reading again keeps the first read time.
+		w = executeRequest("POST", "/v1/me/notifications/"+id+"/read", nil, playerToken)
+		require.Equal(t, http.StatusOK, w.Code)
+		var again notificationHttp.NotificationResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &again))
+		assert.True(t, n.ReadAt.Equal(*again.ReadAt))
+
+		assert.Equal(t, 1, unread(t, playerToken).UnreadCount)
+		remaining := list(t, playerToken, "?unread=true")
+		require.Equal(t, 1, remaining.Total)
+		assert.NotEqual(t, id, remaining.Items[0].ID)
+
+		w = executeRequest("POST", "/v1/me/notifications/not-a-uuid/read", nil, playerToken)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("Mark All Read", func(t *testing.T) {
+		w := executeRequest("POST", "/v1/me/notifications/read-all", nil, playerToken)
+		require.Equal(t, http.StatusOK, w.Code)
+		var resp notificationHttp.MarkAllReadResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Equal(t, int64(1), resp.Updated)
+
+		count := unread(t, playerToken)
+		assert.Equal(t, 0, count.UnreadCount)
+		assert.Empty(t, count.ByType)
+		assert.Equal(t, 1, unread(t, otherToken).UnreadCount, "other inboxes are untouched")
+	})
+
+	t.Run("Preferences Control Inbox Items", func(t *testing.T) {
+		w := executeRequest("GET", "/v1/me/notification-preferences", nil, playerToken)
+		require.Equal(t, http.StatusOK, w.Code)
+		var prefs []notificationHttp.PreferenceResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &prefs))
+		require.NotEmpty(t, prefs)
+		for _, p := range prefs {
+			assert.True(t, p.InApp, "%s is enabled by default", p.Type)
+		}
+
+		off := false
+		for _, typ := range []string{pickup.EventOrderConfirmed, announcement.EventPublished} {
+			w = executeRequest("PUT", "/v1/me/notification-preferences/"+typ,
+				notificationHttp.SetPreferenceBody{InApp: &off}, playerToken)
+			require.Equal(t, http.StatusOK, w.Code)
+		}
+
+		w = executeRequest("PUT", "/v1/me/notification-preferences/no.such_type",
+			notificationHttp.SetPreferenceBody{InApp: &off}, playerToken)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		w = executeRequest("PUT", "/v1/me/notification-preferences/"+pickup.EventOrderRejected,
+			map[string]any{}, playerToken)
+		assert.Equal(t, http.StatusBadRequest, w.Code, "in_app is required")
+
+		w = executeRequest("GET", "/v1/me/notification-preferences", nil, playerToken)
+		require.Equal(t, http.StatusOK, w.Code)
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &prefs))
+		for _, p := range prefs {
+			muted := p.Type == pickup.EventOrderConfirmed || p.Type == announcement.EventPublished
+			assert.Equal(t, !muted, p.InApp, p.Type)
+		}
+
+		testMail.take()
+		enrollAndDecide(t, "Accepted Game", "confirmed")
+		announce(t, "New courts open")
+
+		assert.Equal(t, 0, unread(t, playerToken).UnreadCount, "muted types add no inbox items")
+		assert.Equal(t, 2, unread(t, otherToken).ByType[announcement.EventPublished], "other users still get announcements")
+		assert.Len(t, testMail.take(), 1, "preferences only affect the inbox")
+	})
+}
@@ -0,0 +1,182 @@
+package tests
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nekogravitycat/court-booking-backend/internal/app"
+	"github.com/nekogravitycat/court-booking-backend/internal/notification"
+	"github.com/nekogravitycat/court-booking-backend/internal/pickup"
+	pickupHttp "github.com/nekogravitycat/court-booking-backend/internal/pickup/http"
+)
+
+func TestNotificationDispatch(t *testing.T) {
+	clearTables()
+	testMail.take()
+	testWebhook.take()
+	testWebhook.setFailing(false)
+
+	host := createTestUser(t, "host@notify.com", "pass", false)
+	grantPickupHost(t, host.ID)
+	accepted := createTestUser(t, "accepted@notify.com", "pass", false)
+	declined := createTestUser(t, "declined@notify.com", "pass", false)
+
+	hostToken := generateToken(host.ID)
+	locationID := setupTestLocation(t, hostToken, host.ID)
+	sportID, skillLevelID := getSportSkill(t, "BADMINTON", "B")
+
+	w := executeRequest("POST", "/v1/pickup-groups", pickupHttp.CreateGroupBody{
+		Title:        "Notified Group",
+		StartTime:    time.Now().Add(24 * time.Hour),
+		EndTime:      time.Now().Add(26 * time.Hour),
+		Fee:          100,
+		Capacity:     6,
+		LocationID:   locationID,
+		SportID:      sportID,
+		SkillLevelID: skillLevelID,
+	}, hostToken)
+	require.Equal(t, http.StatusCreated, w.Code)
+	var group pickupHttp.PickupGroupResponse
+	json.Unmarshal(w.Body.Bytes(), &group)
+
+	enroll := func(t *testing.T, token string) string {
+		w := executeRequest("POST", "/v1/pickup-groups/"+group.ID+"/orders", nil, token)
+		require.Equal(t, http.StatusCreated, w.Code)
+		var o pickupHttp.PickupOrderResponse
+		json.Unmarshal(w.Body.Bytes(), &o)
+		return o.ID
+	}
+	decide := func(t *testing.T, orderID, status string) {
+		w := executeRequest("PATCH", "/v1/pickup-orders/"+orderID, pickupHttp.UpdateOrderBody{Status: &status}, hostToken)
+		require.Equal(t, http.StatusOK, w.Code)
+	}
+	acceptedOrder := enroll(t, generateToken(accepted.ID))
+	declinedOrder := enroll(t, generateToken(declined.ID))
+
+	// deliveries returns the status of each channel's delivery of the user's
+	// events of the given type.
+	type delivery struct {
+		Status    string
+		Attempts  int
+		LastError *string
+		DueIn     time.Duration
+	}
+	deliveries := func(t *testing.T, userID, eventType string) map[string]delivery {
+		rows, err := testPool.Query(context.Background(), `
+			SELECT d.channel, d.status::text, d.attempts, d.last_error, d.next_attempt_at - now()
+			FROM public.notification_deliveries d
+			JOIN public.notification_outbox o ON o.id = d.outbox_id
+			WHERE o.user_id = $1 AND o.type = $2`, userID, eventType)
+		require.NoError(t, err)
+		defer rows.Close()
+		byChannel := map[string]delivery{}
+		for rows.Next() {
+			var channel string
+			var d delivery
+			require.NoError(t, rows.Scan(&channel, &d.Status, &d.Attempts, &d.LastError, &d.DueIn))
+			byChannel[channel] = d
+		}
+		require.NoError(t, rows.Err())
+		return byChannel
+	}
+
+	t.Run("Decision Is Delivered On Every Channel", func(t *testing.T) {
+		decide(t, acceptedOrder, "confirmed")
+		runWorker(t, app.NotificationWorker)
+
+		byChannel := deliveries(t, accepted.ID, pickup.EventOrderConfirmed)
+		require.Len(t, byChannel, 3)
+		for channel, d := range byChannel {
+			assert.Equal(t, "sent", d.Status, channel)
+			assert.Equal(t, 1, d.Attempts, channel)
+		}
+
+		mail := testMail.take()
+		require.Len(t, mail, 1)
+		assert.Equal(t, []string{accepted.Email}, mail[0].To)
+		assert.Equal(t, "no-reply@court-booking.test", mail[0].From)
+		assert.Contains(t, mail[0].Data, "Subject: Your pickup enrollment is confirmed")
+		assert.Contains(t, mail[0].Data, "Notified Group")
+
+		requests := testWebhook.take()
+		require.Len(t, requests, 1)
+		assert.Equal(t, pickup.EventOrderConfirmed, requests[0].Header.Get("X-Notification-Type"))
+		mac := hmac.New(sha256.New, []byte(testWebhookSecret))
+		mac.Write(requests[0].Body)
+		assert.Equal(t, "sha256="+hex.EncodeToString(mac.Sum(nil)), requests[0].Header.Get("X-Notification-Signature"))
+		var payload struct {
+			ID     string         `json:"id"`
+			UserID string         `json:"user_id"`
+			Data   map[string]any `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(requests[0].Body, &payload))
+		assert.Equal(t, requests[0].Header.Get("X-Notification-ID"), payload.ID)
+		assert.Equal(t, accepted.ID, payload.UserID)
+		assert.Equal(t, acceptedOrder, payload.Data["order_id"])
+
+		var inbox int
+		require.NoError(t, testPool.QueryRow(context.Background(),
+			"SELECT COUNT(*) FROM public.notifications WHERE user_id = $1 AND type = $2",
+			accepted.ID, pickup.EventOrderConfirmed).Scan(&inbox))
+		assert.Equal(t, 1, inbox)
+	})
+
+	t.Run("Failed Delivery Backs Off And Retries", func(t *testing.T) {
+		testWebhook.setFailing(true)
+		decide(t, declinedOrder, "rejected")
+		runWorker(t, app.NotificationWorker)
+
+		byChannel := deliveries(t, declined.ID, pickup.EventOrderRejected)
+		assert.Equal(t, "sent", byChannel[notification.ChannelEmail].Status)
+		assert.Equal(t, "sent", byChannel[notification.ChannelInApp].Status)
+		hook := byChannel[notification.ChannelWebhook]
+		assert.Equal(t, "pending", hook.Status)
+		assert.Equal(t, 1, hook.Attempts)
+		require.NotNil(t, hook.LastError)
+		assert.Contains(t, *hook.LastError, "500")
+		assert.Greater(t, hook.DueIn, notification.RetryBaseDelay-5*time.Second)
+
+		// Not due yet: the next run leaves it alone.
+		runWorker(t, app.NotificationWorker)
+		assert.Equal(t, 1, deliveries(t, declined.ID, pickup.EventOrderRejected)[notification.ChannelWebhook].Attempts)
+
+		testWebhook.setFailing(false)
+		_, err := testPool.Exec(context.Background(),
+			"UPDATE public.notification_deliveries SET next_attempt_at = now() WHERE status = 'pending'")
+		require.NoError(t, err)
+		testWebhook.take()
+		runWorker(t, app.NotificationWorker)
+
+		hook = deliveries(t, declined.ID, pickup.EventOrderRejected)[notification.ChannelWebhook]
+		assert.Equal(t, "sent", hook.Status)
+		assert.Equal(t, 2, hook.Attempts)
+		assert.Len(t, testWebhook.take(), 1)
+		assert.Len(t, testMail.take(), 1, "the email is not sent again")
+	})
+
+	t.Run("Delivery Is Given Up After Max Attempts", func(t *testing.T) {
+		testWebhook.setFailing(true)
+		defer testWebhook.setFailing(false)
+		_, err := testPool.Exec(context.Background(), `
+			UPDATE public.notification_deliveries d
+			SET status = 'pending', attempts = $2, next_attempt_at = now()
+			FROM public.notification_outbox o
+			WHERE o.id = d.outbox_id AND o.user_id = $1 AND d.channel = 'webhook'`,
+			declined.ID, notification.MaxAttempts-1)
+		require.NoError(t, err)
+		runWorker(t, app.NotificationWorker)
+
+		hook := deliveries(t, declined.ID, pickup.EventOrderRejected)[notification.ChannelWebhook]
+		assert.Equal(t, "failed", hook.Status)
+		assert.Equal(t, notification.MaxAttempts, hook.Attempts)
+	})
+}
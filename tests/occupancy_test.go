@@ -0,0 +1,175 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	bookingHttp "github.com/nekogravitycat/court-booking-backend/internal/booking/http"
+	locHttp "github.com/nekogravitycat/court-booking-backend/internal/location/http"
+	orgHttp "github.com/nekogravitycat/court-booking-backend/internal/organization/http"
+	resHttp "github.com/nekogravitycat/court-booking-backend/internal/resource/http"
+)
+
+func TestBookingOccupancyHeatmap(t *testing.T) {
+	clearTables()
+
+	sysAdmin := createTestUser(t, "sysadmin@occ.com", "pass", true)
+	owner := createTestUser(t, "owner@occ.com", "pass", false)
+	booker := createTestUser(t, "booker@occ.com", "pass", false)
+	stranger := createTestUser(t, "stranger@occ.com", "pass", false)
+
+	sysAdminToken := generateToken(sysAdmin.ID)
+	ownerToken := generateToken(owner.ID)
+	bookerToken := generateToken(booker.ID)
+	strangerToken := generateToken(stranger.ID)
+
+	// Tomorrow 08:00 UTC, inside the 06:00-23:00 opening hours.
+	base := time.Now().UTC().Truncate(24 * time.Hour).Add(32 * time.Hour)
+	day := base.Format("2006-01-02")
+	weekday := int(base.Weekday())
+
+	var orgID, locationID, resourceID string
+
+	t.Run("Setup", func(t *testing.T) {
+		wOrg := executeRequest("POST", "/v1/organizations", orgHttp.CreateOrganizationRequest{Name: "Occupancy Org", OwnerID: owner.ID}, sysAdminToken)
+		require.Equal(t, http.StatusCreated, wOrg.Code)
+		var org orgHttp.OrganizationResponse
+		json.Unmarshal(wOrg.Body.Bytes(), &org)
+		orgID = org.ID
+
+		wLoc := executeRequest("POST", "/v1/locations", locHttp.CreateLocationRequest{
+			OrganizationID:    orgID,
+			Name:              "Occupancy Hall",
+			Capacity:          10,
+			OpeningHoursStart: "06:00:00", OpeningHoursEnd: "23:00:00",
+			Opening:      true,
+			Timezone:     "UTC",
+			LocationInfo: "Test Info", Longitude: 120.0, Latitude: 23.0,
+		}, ownerToken)
+		require.Equal(t, http.StatusCreated, wLoc.Code)
+		var loc locHttp.LocationResponse
+		json.Unmarshal(wLoc.Body.Bytes(), &loc)
+		locationID = loc.ID
+
+		wRes := executeRequest("POST", "/v1/resources", resHttp.CreateRequest{
+			Name: "Court 1", LocationID: locationID, ResourceType: "badminton",
+		}, ownerToken)
+		require.Equal(t, http.StatusCreated, wRes.Code)
+		var res resHttp.ResourceResponse
+		json.Unmarshal(wRes.Body.Bytes(), &res)
+		resourceID = res.ID
+
+		// 08:00-10:00 (confirmed), 10:00-11:00 (left pending, must not count).
+		for i, slot := range [][2]time.Time{
+			{base, base.Add(2 * time.Hour)},
+			{base.Add(2 * time.Hour), base.Add(3 * time.Hour)},
+		} {
+			w := executeRequest("POST", "/v1/bookings", bookingHttp.CreateBookingRequest{
+				ResourceID: resourceID, StartTime: slot[0], EndTime: slot[1],
+			}, bookerToken)
+			require.Equal(t, http.StatusCreated, w.Code)
+			var b bookingHttp.BookingResponse
+			json.Unmarshal(w.Body.Bytes(), &b)
+
+			if i == 0 {
+				confirmed := "confirmed"
+				wUpd := executeRequest("PATCH", "/v1/bookings/"+b.ID, bookingHttp.UpdateBookingRequest{Status: &confirmed}, ownerToken)
+				require.Equal(t, http.StatusOK, wUpd.Code)
+			}
+		}
+	})
+
+	t.Run("Owner: Confirmed Bookings Bucketed By Weekday And Hour", func(t *testing.T) {
+		w := executeRequest("GET", fmt.Sprintf("/v1/bookings/occupancy?organization_id=%s&from=%s&to=%s", orgID, day, day), nil, ownerToken)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var resp bookingHttp.OccupancyResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		require.Len(t, resp.Items, 1)
+
+		hm := resp.Items[0]
+		assert.Equal(t, locationID, hm.Location.ID)
+		assert.Equal(t, 1, hm.Cells[weekday][8])
+		assert.Equal(t, 1, hm.Cells[weekday][9])
+		assert.Equal(t, 0, hm.Cells[weekday][10], "pending booking must not be counted")
+		assert.Equal(t, 2, hm.Total)
+	})
+
+	t.Run("Cells Follow The Location's Local Time", func(t *testing.T) {
+		wLoc := executeRequest("POST", "/v1/locations", locHttp.CreateLocationRequest{
+			OrganizationID:    orgID,
+			Name:              "Taipei Hall",
+			Capacity:          10,
+			OpeningHoursStart: "06:00:00", OpeningHoursEnd: "23:00:00",
+			Opening:      true,
+			Timezone:     "Asia/Taipei",
+			LocationInfo: "Test Info", Longitude: 121.5, Latitude: 25.0,
+		}, ownerToken)
+		require.Equal(t, http.StatusCreated, wLoc.Code)
+		var loc locHttp.LocationResponse
+		json.Unmarshal(wLoc.Body.Bytes(), &loc)
+
+		wRes := executeRequest("POST", "/v1/resources", resHttp.CreateRequest{
+			Name: "Court 1", LocationID: loc.ID, ResourceType: "badminton",
+		}, ownerToken)
+		require.Equal(t, http.StatusCreated, wRes.Code)
+		var res resHttp.ResourceResponse
+		json.Unmarshal(wRes.Body.Bytes(), &res)
+
+		// 22:00 UTC is 06:00 the next day in Taipei (UTC+8).
+		start := base.Add(14 * time.Hour)
+		w := executeRequest("POST", "/v1/bookings", bookingHttp.CreateBookingRequest{
+			ResourceID: res.ID, StartTime: start, EndTime: start.Add(time.Hour),
+		}, bookerToken)
+		require.Equal(t, http.StatusCreated, w.Code)
+		var b bookingHttp.BookingResponse
+		json.Unmarshal(w.Body.Bytes(), &b)
+		confirmed := "confirmed"
+		w = executeRequest("PATCH", "/v1/bookings/"+b.ID, bookingHttp.UpdateBookingRequest{Status: &confirmed}, ownerToken)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		localDay := start.In(time.FixedZone("CST", 8*60*60))
+		w = executeRequest("GET", fmt.Sprintf("/v1/bookings/occupancy?location_id=%s&from=%s&to=%s", loc.ID, localDay.Format("2006-01-02"), localDay.Format("2006-01-02")), nil, ownerToken)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var resp bookingHttp.OccupancyResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		require.Len(t, resp.Items, 1)
+
+		hm := resp.Items[0]
+		assert.Equal(t, 1, hm.Cells[localDay.Weekday()][6])
+		assert.Equal(t, 0, hm.Cells[weekday][22], "UTC weekday and hour must not be used")
+		assert.Equal(t, 1, hm.Total)
+	})
+
+	t.Run("Resource Type Filter Keeps Location With Empty Matrix", func(t *testing.T) {
+		w := executeRequest("GET", fmt.Sprintf("/v1/bookings/occupancy?location_id=%s&resource_type=tennis&from=%s&to=%s", locationID, day, day), nil, ownerToken)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var resp bookingHttp.OccupancyResponse
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		require.Len(t, resp.Items, 1)
+		assert.Equal(t, 0, resp.Items[0].Total)
+	})
+
+	t.Run("Stranger: Forbidden", func(t *testing.T) {
+		w := executeRequest("GET", fmt.Sprintf("/v1/bookings/occupancy?organization_id=%s&from=%s&to=%s", orgID, day, day), nil, strangerToken)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("Non-Admin Without Scope: Bad Request", func(t *testing.T) {
+		w := executeRequest("GET", fmt.Sprintf("/v1/bookings/occupancy?from=%s&to=%s", day, day), nil, ownerToken)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("Inverted Range: Bad Request", func(t *testing.T) {
+		w := executeRequest("GET", fmt.Sprintf("/v1/bookings/occupancy?organization_id=%s&from=%s&to=2000-01-01", orgID, day), nil, sysAdminToken)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
@@ -0,0 +1,139 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	pickupHttp "github.com/nekogravitycat/court-booking-backend/internal/pickup/http"
+)
+
+func TestPickupAttendance(t *testing.T) {
+	clearTables()
+
+	host := createTestUser(t, "host@attendance.com", "pass", false)
+	grantPickupHost(t, host.ID)
+	u1 := createTestUser(t, "u1@attendance.com", "pass", false)
+	u2 := createTestUser(t, "u2@attendance.com", "pass", false)
+
+	hostToken := generateToken(host.ID)
+	u1Token := generateToken(u1.ID)
+	u2Token := generateToken(u2.ID)
+
+	locationID := setupTestLocation(t, hostToken, host.ID)
+	sportID, skillLevelID := getSportSkill(t, "BADMINTON", "B")
+
+	createGroup := func(t *testing.T, minReliability int) string {
+		w := executeRequest("POST", "/v1/pickup-groups", pickupHttp.CreateGroupBody{
+			Title:          "Attendance Group",
+			StartTime:      time.Now().Add(24 * time.Hour),
+			EndTime:        time.Now().Add(26 * time.Hour),
+			Capacity:       4,
+			MinReliability: minReliability,
+			LocationID:     locationID,
+			SportID:        sportID,
+			SkillLevelID:   skillLevelID,
+		}, hostToken)
+		require.Equal(t, http.StatusCreated, w.Code)
+		var g pickupHttp.PickupGroupResponse
+		json.Unmarshal(w.Body.Bytes(), &g)
+		assert.Equal(t, minReliability, g.MinReliability)
+		return g.ID
+	}
+	enroll := func(groupID, token string) (int, string) {
+		w := executeRequest("POST", "/v1/pickup-groups/"+groupID+"/orders", nil, token)
+		var o pickupHttp.PickupOrderResponse
+		json.Unmarshal(w.Body.Bytes(), &o)
+		return w.Code, o.ID
+	}
+	mark := func(orderID, attendance, token string) int {
+		w := executeRequest("PATCH", "/v1/pickup-orders/"+orderID+"/attendance",
+			pickupHttp.MarkAttendanceBody{Attendance: attendance}, token)
+		return w.Code
+	}
+
+	groupID := createGroup(t, 0)
+	code, confirmedID := enroll(groupID, u1Token)
+	require.Equal(t, http.StatusCreated, code)
+	code, pendingID := enroll(groupID, u2Token)
+	require.Equal(t, http.StatusCreated, code)
+
+	confirmed := "confirmed"
+	w := executeRequest("PATCH", "/v1/pickup-orders/"+confirmedID, pickupHttp.UpdateOrderBody{Status: &confirmed}, hostToken)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	t.Run("Before Start: 409", func(t *testing.T) {
+		assert.Equal(t, http.StatusConflict, mark(confirmedID, "attended", hostToken))
+	})
+
+	_, err := testPool.Exec(context.Background(),
+		"UPDATE public.pickup_groups SET start_time = $2 WHERE id = $1",
+		groupID, time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+
+	t.Run("Invalid Attendance: 400", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, mark(confirmedID, "late", hostToken))
+	})
+
+	t.Run("Not Host: 403", func(t *testing.T) {
+		assert.Equal(t, http.StatusForbidden, mark(confirmedID, "attended", u2Token))
+	})
+
+	t.Run("Unconfirmed Order: 409", func(t *testing.T) {
+		assert.Equal(t, http.StatusConflict, mark(pendingID, "attended", hostToken))
+	})
+
+	t.Run("Host Marks No-Show", func(t *testing.T) {
+		require.Equal(t, http.StatusOK, mark(confirmedID, "no_show", hostToken))
+
+		w := executeRequest("GET", "/v1/pickup-groups/"+groupID+"/orders", nil, hostToken)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var orders []pickupHttp.GroupOrderResponse
+		json.Unmarshal(w.Body.Bytes(), &orders)
+		byID := map[string]pickupHttp.GroupOrderResponse{}
+		for _, o := range orders {
+			byID[o.ID] = o
+		}
+
+		marked := byID[confirmedID]
+		require.NotNil(t, marked.Attendance)
+		assert.Equal(t, "no_show", *marked.Attendance)
+		assert.Equal(t, 1, marked.Reliability.NoShow)
+		require.NotNil(t, marked.Reliability.Score)
+		assert.Equal(t, 0, *marked.Reliability.Score)
+
+		assert.Nil(t, byID[pendingID].Attendance)
+		assert.Nil(t, byID[pendingID].Reliability.Score)
+	})
+
+	t.Run("Invalid Min Reliability: 400", func(t *testing.T) {
+		w := executeRequest("POST", "/v1/pickup-groups", pickupHttp.CreateGroupBody{
+			Title:          "Too Strict",
+			StartTime:      time.Now().Add(24 * time.Hour),
+			EndTime:        time.Now().Add(26 * time.Hour),
+			Capacity:       4,
+			MinReliability: 101,
+			LocationID:     locationID,
+			SportID:        sportID,
+			SkillLevelID:   skillLevelID,
+		}, hostToken)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("Min Reliability Gates Enrollment", func(t *testing.T) {
+		strictID := createGroup(t, 50)
+
+		code, _ := enroll(strictID, u1Token)
+		assert.Equal(t, http.StatusForbidden, code)
+
+		// No attendance history: not blocked.
+		code, _ = enroll(strictID, u2Token)
+		assert.Equal(t, http.StatusCreated, code)
+	})
+}
@@ -0,0 +1,121 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	pickupHttp "github.com/nekogravitycat/court-booking-backend/internal/pickup/http"
+)
+
+func TestPickupHostBlocks(t *testing.T) {
+	clearTables()
+
+	host := createTestUser(t, "host@block.com", "pass", false)
+	grantPickupHost(t, host.ID)
+	otherHost := createTestUser(t, "otherhost@block.com", "pass", false)
+	grantPickupHost(t, otherHost.ID)
+	troublemaker := createTestUser(t, "trouble@block.com", "pass", false)
+	sysAdmin := createTestUser(t, "admin@block.com", "pass", true)
+
+	hostToken := generateToken(host.ID)
+	otherHostToken := generateToken(otherHost.ID)
+	troublemakerToken := generateToken(troublemaker.ID)
+	sysAdminToken := generateToken(sysAdmin.ID)
+
+	hostLocation := setupTestLocation(t, hostToken, host.ID)
+	otherLocation := setupTestLocation(t, otherHostToken, otherHost.ID)
+	sportID, skillLevelID := getSportSkill(t, "BADMINTON", "B")
+
+	createGroup := func(t *testing.T, token, locationID string) string {
+		w := executeRequest("POST", "/v1/pickup-groups", pickupHttp.CreateGroupBody{
+			Title:        "Block Group",
+			StartTime:    time.Now().Add(24 * time.Hour),
+			EndTime:      time.Now().Add(26 * time.Hour),
+			Fee:          100,
+			Capacity:     4,
+			LocationID:   locationID,
+			SportID:      sportID,
+			SkillLevelID: skillLevelID,
+		}, token)
+		require.Equal(t, http.StatusCreated, w.Code)
+		var g pickupHttp.PickupGroupResponse
+		json.Unmarshal(w.Body.Bytes(), &g)
+		return g.ID
+	}
+	enroll := func(groupID string) int {
+		return executeRequest("POST", "/v1/pickup-groups/"+groupID+"/orders", nil, troublemakerToken).Code
+	}
+
+	firstGroup := createGroup(t, hostToken, hostLocation)
+	secondGroup := createGroup(t, hostToken, hostLocation)
+	otherGroup := createGroup(t, otherHostToken, otherLocation)
+
+	require.Equal(t, http.StatusCreated, enroll(firstGroup))
+
+	blocksPath := "/v1/hosts/" + host.ID + "/pickup-blocks"
+
+	t.Run("Only Host Or Admin Manages Blocklist", func(t *testing.T) {
+		w := executeRequest("PUT", blocksPath+"/"+troublemaker.ID, nil, otherHostToken)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		w = executeRequest("GET", blocksPath, nil, troublemakerToken)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+
+		w = executeRequest("PUT", blocksPath+"/"+host.ID, nil, hostToken)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("Block From Order Review", func(t *testing.T) {
+		w := executeRequest("GET", "/v1/pickup-groups/"+firstGroup+"/orders", nil, hostToken)
+		require.Equal(t, http.StatusOK, w.Code)
+		var orders []pickupHttp.GroupOrderResponse
+		json.Unmarshal(w.Body.Bytes(), &orders)
+		require.Len(t, orders, 1)
+		assert.False(t, orders[0].BlockedByHost)
+
+		reason := "no-show without notice"
+		w = executeRequest("PUT", blocksPath+"/"+orders[0].UserID, pickupHttp.BlockUserBody{Reason: &reason}, hostToken)
+		require.Equal(t, http.StatusOK, w.Code)
+		var block pickupHttp.HostBlockResponse
+		json.Unmarshal(w.Body.Bytes(), &block)
+		assert.Equal(t, troublemaker.ID, block.UserID)
+		require.NotNil(t, block.Reason)
+		assert.Equal(t, reason, *block.Reason)
+
+		w = executeRequest("GET", "/v1/pickup-groups/"+firstGroup+"/orders", nil, hostToken)
+		require.Equal(t, http.StatusOK, w.Code)
+		json.Unmarshal(w.Body.Bytes(), &orders)
+		assert.True(t, orders[0].BlockedByHost, "the existing order stays, flagged")
+	})
+
+	t.Run("Blocked User Cannot Enroll In Any Group Of The Host", func(t *testing.T) {
+		assert.Equal(t, http.StatusForbidden, enroll(secondGroup))
+		w := executeRequest("POST", "/v1/pickup-groups/"+secondGroup+"/orders?join_waitlist=true", nil, troublemakerToken)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+
+		assert.Equal(t, http.StatusCreated, enroll(otherGroup), "other hosts are unaffected")
+	})
+
+	t.Run("System Admin Sees Blocklist", func(t *testing.T) {
+		w := executeRequest("GET", blocksPath, nil, sysAdminToken)
+		require.Equal(t, http.StatusOK, w.Code)
+		var blocks []pickupHttp.HostBlockResponse
+		json.Unmarshal(w.Body.Bytes(), &blocks)
+		require.Len(t, blocks, 1)
+		assert.Equal(t, troublemaker.ID, blocks[0].UserID)
+		assert.NotEmpty(t, blocks[0].Username)
+	})
+
+	t.Run("Unblock", func(t *testing.T) {
+		w := executeRequest("DELETE", blocksPath+"/"+troublemaker.ID, nil, hostToken)
+		require.Equal(t, http.StatusNoContent, w.Code)
+		w = executeRequest("DELETE", blocksPath+"/"+troublemaker.ID, nil, hostToken)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+
+		assert.Equal(t, http.StatusCreated, enroll(secondGroup))
+	})
+}
@@ -0,0 +1,165 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nekogravitycat/court-booking-backend/internal/pickup"
+	pickupHttp "github.com/nekogravitycat/court-booking-backend/internal/pickup/http"
+	paymentHttp "github.com/nekogravitycat/court-booking-backend/internal/pickuppayment/http"
+)
+
+func TestPickupGroupCascade(t *testing.T) {
+	clearTables()
+
+	host := createTestUser(t, "host@cascade.com", "pass", false)
+	grantPickupHost(t, host.ID)
+	withdrawer := createTestUser(t, "withdrawer@cascade.com", "pass", false)
+	stayer := createTestUser(t, "stayer@cascade.com", "pass", false)
+	payer := createTestUser(t, "payer@cascade.com", "pass", false)
+
+	hostToken := generateToken(host.ID)
+	withdrawerToken := generateToken(withdrawer.ID)
+	stayerToken := generateToken(stayer.ID)
+	payerToken := generateToken(payer.ID)
+
+	locationID := setupTestLocation(t, hostToken, host.ID)
+	sportID, skillLevelID := getSportSkill(t, "BADMINTON", "B")
+
+	w := executeRequest("POST", "/v1/pickup-groups", pickupHttp.CreateGroupBody{
+		Title:        "Cascade Group",
+		StartTime:    time.Now().Add(24 * time.Hour),
+		EndTime:      time.Now().Add(26 * time.Hour),
+		Fee:          100,
+		Capacity:     6,
+		LocationID:   locationID,
+		SportID:      sportID,
+		SkillLevelID: skillLevelID,
+	}, hostToken)
+	require.Equal(t, http.StatusCreated, w.Code)
+	var group pickupHttp.PickupGroupResponse
+	json.Unmarshal(w.Body.Bytes(), &group)
+
+	enroll := func(t *testing.T, token string) string {
+		w := executeRequest("POST", "/v1/pickup-groups/"+group.ID+"/orders", nil, token)
+		require.Equal(t, http.StatusCreated, w.Code)
+		var o pickupHttp.PickupOrderResponse
+		json.Unmarshal(w.Body.Bytes(), &o)
+		return o.ID
+	}
+	pay := func(t *testing.T, orderID string) string {
+		w := executeRequest("POST", "/v1/pickup-orders/"+orderID+"/payments",
+			paymentHttp.RecordPaymentBody{Method: "cash"}, hostToken)
+		require.Equal(t, http.StatusCreated, w.Code)
+		var p paymentHttp.PaymentResponse
+		json.Unmarshal(w.Body.Bytes(), &p)
+		return p.ID
+	}
+	orders := func(t *testing.T) map[string]pickupHttp.GroupOrderResponse {
+		w := executeRequest("GET", "/v1/pickup-groups/"+group.ID+"/orders", nil, hostToken)
+		require.Equal(t, http.StatusOK, w.Code)
+		var list []pickupHttp.GroupOrderResponse
+		json.Unmarshal(w.Body.Bytes(), &list)
+		byID := make(map[string]pickupHttp.GroupOrderResponse, len(list))
+		for _, o := range list {
+			byID[o.ID] = o
+		}
+		return byID
+	}
+	updateGroup := func(t *testing.T, body pickupHttp.UpdateGroupBody) {
+		w := executeRequest("PATCH", "/v1/pickup-groups/"+group.ID, body, hostToken)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	}
+
+	withdrawerOrder := enroll(t, withdrawerToken)
+	stayerOrder := enroll(t, stayerToken)
+	payerOrder := enroll(t, payerToken)
+	pay(t, withdrawerOrder)
+	payerPayment := pay(t, payerOrder)
+	testEvents.take()
+
+	t.Run("Minor Change Needs No Reconfirmation", func(t *testing.T) {
+		title, lower := "Renamed", 80
+		updateGroup(t, pickupHttp.UpdateGroupBody{Title: &title, Fee: &lower})
+		assert.Empty(t, testEvents.take())
+		assert.False(t, orders(t)[stayerOrder].ReconfirmRequired)
+	})
+
+	t.Run("Material Change Requires Reconfirmation", func(t *testing.T) {
+		fee := 120
+		updateGroup(t, pickupHttp.UpdateGroupBody{Fee: &fee})
+
+		events := testEvents.take()
+		require.Len(t, events, 3)
+		for _, e := range events {
+			assert.Equal(t, pickup.EventGroupChanged, e.Type)
+			assert.Equal(t, []any{pickup.ChangeFee}, e.Data["changes"])
+		}
+		for _, o := range orders(t) {
+			assert.True(t, o.ReconfirmRequired)
+		}
+	})
+
+	t.Run("Reconfirm", func(t *testing.T) {
+		w := executeRequest("POST", "/v1/pickup-orders/"+stayerOrder+"/reconfirm", nil, payerToken)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+
+		w = executeRequest("POST", "/v1/pickup-orders/"+stayerOrder+"/reconfirm", nil, stayerToken)
+		require.Equal(t, http.StatusOK, w.Code)
+		var o pickupHttp.PickupOrderResponse
+		json.Unmarshal(w.Body.Bytes(), &o)
+		assert.False(t, o.ReconfirmRequired)
+
+		w = executeRequest("POST", "/v1/pickup-orders/"+stayerOrder+"/reconfirm", nil, stayerToken)
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+
+	t.Run("Withdrawal After Change Is Refunded", func(t *testing.T) {
+		cancelled := "cancelled"
+		w := executeRequest("PATCH", "/v1/pickup-orders/"+withdrawerOrder,
+			pickupHttp.UpdateOrderBody{Status: &cancelled}, withdrawerToken)
+		require.Equal(t, http.StatusOK, w.Code)
+		var o pickupHttp.PickupOrderResponse
+		json.Unmarshal(w.Body.Bytes(), &o)
+		assert.False(t, o.ReconfirmRequired)
+		require.NotNil(t, o.RefundStatus)
+		assert.Equal(t, "pending", *o.RefundStatus)
+	})
+
+	t.Run("Cancellation Cascades To Orders", func(t *testing.T) {
+		cancelled := "cancelled"
+		updateGroup(t, pickupHttp.UpdateGroupBody{Status: &cancelled})
+
+		byUser := map[string]map[string]any{}
+		for _, e := range testEvents.take() {
+			assert.Equal(t, pickup.EventGroupCancelled, e.Type)
+			byUser[e.UserID] = e.Data
+		}
+		require.Len(t, byUser, 2, "the withdrawn order is not notified again")
+		assert.Equal(t, false, byUser[stayer.ID]["refund_due"])
+		assert.Equal(t, true, byUser[payer.ID]["refund_due"])
+		assert.Equal(t, "host", byUser[payer.ID]["reason"])
+
+		all := orders(t)
+		for _, o := range all {
+			assert.Equal(t, "cancelled", o.Status)
+		}
+		assert.Nil(t, all[stayerOrder].RefundStatus)
+		require.NotNil(t, all[payerOrder].RefundStatus)
+		assert.Equal(t, "pending", *all[payerOrder].RefundStatus)
+	})
+
+	t.Run("Ledger Refund Settles Order", func(t *testing.T) {
+		w := executeRequest("POST", "/v1/pickup-payments/"+payerPayment+"/refund", nil, hostToken)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		refund := orders(t)[payerOrder].RefundStatus
+		require.NotNil(t, refund)
+		assert.Equal(t, "done", *refund)
+	})
+}
@@ -0,0 +1,172 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	pickupHttp "github.com/nekogravitycat/court-booking-backend/internal/pickup/http"
+	templateHttp "github.com/nekogravitycat/court-booking-backend/internal/pickuptemplate/http"
+)
+
+func TestPickupCoHosts(t *testing.T) {
+	clearTables()
+
+	host := createTestUser(t, "host@cohost.com", "pass", false)
+	grantPickupHost(t, host.ID)
+	coHost := createTestUser(t, "cohost@cohost.com", "pass", false)
+	player := createTestUser(t, "player@cohost.com", "pass", false)
+
+	hostToken := generateToken(host.ID)
+	coHostToken := generateToken(coHost.ID)
+	playerToken := generateToken(player.ID)
+
+	locationID := setupTestLocation(t, hostToken, host.ID)
+	sportID, skillLevelID := getSportSkill(t, "BADMINTON", "B")
+
+	w := executeRequest("POST", "/v1/pickup-groups", pickupHttp.CreateGroupBody{
+		Title:        "Co-Hosted Group",
+		StartTime:    time.Now().Add(24 * time.Hour),
+		EndTime:      time.Now().Add(26 * time.Hour),
+		Fee:          100,
+		Capacity:     6,
+		LocationID:   locationID,
+		SportID:      sportID,
+		SkillLevelID: skillLevelID,
+	}, hostToken)
+	require.Equal(t, http.StatusCreated, w.Code)
+	var group pickupHttp.PickupGroupResponse
+	json.Unmarshal(w.Body.Bytes(), &group)
+	coHostPath := "/v1/pickup-groups/" + group.ID + "/cohosts/"
+
+	w = executeRequest("POST", "/v1/pickup-groups/"+group.ID+"/orders", nil, playerToken)
+	require.Equal(t, http.StatusCreated, w.Code)
+	var order pickupHttp.PickupOrderResponse
+	json.Unmarshal(w.Body.Bytes(), &order)
+
+	t.Run("Only Host Manages Co-Hosts", func(t *testing.T) {
+		w := executeRequest("PUT", coHostPath+coHost.ID, nil, playerToken)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+
+		w = executeRequest("PUT", coHostPath+host.ID, nil, hostToken)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+
+		w = executeRequest("DELETE", coHostPath+coHost.ID, nil, hostToken)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("Add Co-Host", func(t *testing.T) {
+		w := executeRequest("GET", "/v1/pickup-groups/"+group.ID+"/orders", nil, coHostToken)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+
+		w = executeRequest("PUT", coHostPath+coHost.ID, nil, hostToken)
+		require.Equal(t, http.StatusOK, w.Code)
+		var g pickupHttp.PickupGroupResponse
+		json.Unmarshal(w.Body.Bytes(), &g)
+		assert.Equal(t, []string{coHost.ID}, g.CoHostIDs)
+
+		// Adding again is idempotent.
+		w = executeRequest("PUT", coHostPath+coHost.ID, nil, hostToken)
+		require.Equal(t, http.StatusOK, w.Code)
+		json.Unmarshal(w.Body.Bytes(), &g)
+		assert.Len(t, g.CoHostIDs, 1)
+	})
+
+	t.Run("Co-Host Shares Host Duties", func(t *testing.T) {
+		w := executeRequest("GET", "/v1/pickup-groups/"+group.ID+"/orders", nil, coHostToken)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		confirmed := "confirmed"
+		w = executeRequest("PATCH", "/v1/pickup-orders/"+order.ID, pickupHttp.UpdateOrderBody{Status: &confirmed}, coHostToken)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		title := "Renamed By Co-Host"
+		w = executeRequest("PATCH", "/v1/pickup-groups/"+group.ID, pickupHttp.UpdateGroupBody{Title: &title}, coHostToken)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		// Co-hosts cannot appoint further co-hosts.
+		w = executeRequest("PUT", coHostPath+player.ID, nil, coHostToken)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+
+		_, err := testPool.Exec(context.Background(),
+			"UPDATE public.pickup_groups SET start_time = $2 WHERE id = $1",
+			group.ID, time.Now().Add(-time.Hour))
+		require.NoError(t, err)
+		w = executeRequest("PATCH", "/v1/pickup-orders/"+order.ID+"/attendance",
+			pickupHttp.MarkAttendanceBody{Attendance: "attended"}, coHostToken)
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("Remove Co-Host", func(t *testing.T) {
+		w := executeRequest("DELETE", coHostPath+coHost.ID, nil, hostToken)
+		require.Equal(t, http.StatusNoContent, w.Code)
+
+		w = executeRequest("GET", "/v1/pickup-groups/"+group.ID+"/orders", nil, coHostToken)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("Template Co-Hosts Are Copied To New Groups", func(t *testing.T) {
+		w := executeRequest("POST", "/v1/pickup-templates", templateHttp.CreateTemplateBody{
+			Title:           "Weekly Co-Hosted",
+			Fee:             100,
+			Capacity:        8,
+			LocationID:      locationID,
+			SportID:         sportID,
+			SkillLevelID:    skillLevelID,
+			Weekdays:        []int{0, 1, 2, 3, 4, 5, 6},
+			StartLocal:      "23:59",
+			DurationMinutes: 1,
+			Timezone:        "Asia/Taipei",
+			AdvanceDays:     1,
+		}, hostToken)
+		require.Equal(t, http.StatusCreated, w.Code)
+		var tmpl templateHttp.TemplateResponse
+		json.Unmarshal(w.Body.Bytes(), &tmpl)
+		templatePath := "/v1/pickup-templates/" + tmpl.ID + "/cohosts/"
+
+		w = executeRequest("PUT", templatePath+coHost.ID, nil, playerToken)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		w = executeRequest("PUT", templatePath+host.ID, nil, hostToken)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+
+		w = executeRequest("PUT", templatePath+coHost.ID, nil, hostToken)
+		require.Equal(t, http.StatusOK, w.Code)
+		json.Unmarshal(w.Body.Bytes(), &tmpl)
+		assert.Equal(t, []string{coHost.ID}, tmpl.CoHostIDs)
+
+		// Extending the window materialises further occurrences.
+		advance := 3
+		w = executeRequest("PATCH", "/v1/pickup-templates/"+tmpl.ID,
+			templateHttp.UpdateTemplateBody{AdvanceDays: &advance}, hostToken)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		rows, err := testPool.Query(context.Background(), `
+			SELECT pg.id, EXISTS (
+				SELECT 1 FROM public.pickup_group_cohosts ch
+				WHERE ch.pickup_group_id = pg.id AND ch.user_id = $2
+			)
+			FROM public.pickup_groups pg
+			WHERE pg.template_id = $1 ORDER BY pg.occurrence_date`, tmpl.ID, coHost.ID)
+		require.NoError(t, err)
+		defer rows.Close()
+		var withCoHost, withoutCoHost int
+		for rows.Next() {
+			var id string
+			var has bool
+			require.NoError(t, rows.Scan(&id, &has))
+			if has {
+				withCoHost++
+			} else {
+				withoutCoHost++
+			}
+		}
+		require.NoError(t, rows.Err())
+		assert.Equal(t, 2, withCoHost, "only groups materialised after the co-host was added")
+		assert.GreaterOrEqual(t, withoutCoHost, 1)
+	})
+}
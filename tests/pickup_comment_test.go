@@ -0,0 +1,122 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	pickupHttp "github.com/nekogravitycat/court-booking-backend/internal/pickup/http"
+	"github.com/nekogravitycat/court-booking-backend/internal/pkg/response"
+)
+
+func TestPickupGroupComments(t *testing.T) {
+	clearTables()
+
+	host := createTestUser(t, "host@comment.com", "pass", false)
+	grantPickupHost(t, host.ID)
+	member := createTestUser(t, "member@comment.com", "pass", false)
+	outsider := createTestUser(t, "outsider@comment.com", "pass", false)
+
+	hostToken := generateToken(host.ID)
+	memberToken := generateToken(member.ID)
+	outsiderToken := generateToken(outsider.ID)
+
+	locationID := setupTestLocation(t, hostToken, host.ID)
+	sportID, skillLevelID := getSportSkill(t, "BADMINTON", "B")
+
+	w := executeRequest("POST", "/v1/pickup-groups", pickupHttp.CreateGroupBody{
+		Title:        "Comment Group",
+		StartTime:    time.Now().Add(24 * time.Hour),
+		EndTime:      time.Now().Add(26 * time.Hour),
+		Capacity:     4,
+		LocationID:   locationID,
+		SportID:      sportID,
+		SkillLevelID: skillLevelID,
+	}, hostToken)
+	require.Equal(t, http.StatusCreated, w.Code)
+	var group pickupHttp.PickupGroupResponse
+	json.Unmarshal(w.Body.Bytes(), &group)
+	commentsURL := "/v1/pickup-groups/" + group.ID + "/comments"
+
+	w = executeRequest("POST", "/v1/pickup-groups/"+group.ID+"/orders", nil, memberToken)
+	require.Equal(t, http.StatusCreated, w.Code)
+
+	post := func(t *testing.T, body, token string) pickupHttp.CommentResponse {
+		w := executeRequest("POST", commentsURL, pickupHttp.CreateCommentBody{Body: body}, token)
+		require.Equal(t, http.StatusCreated, w.Code)
+		var c pickupHttp.CommentResponse
+		json.Unmarshal(w.Body.Bytes(), &c)
+		return c
+	}
+	list := func(t *testing.T, token string) response.PageResponse[pickupHttp.CommentResponse] {
+		w := executeRequest("GET", commentsURL, nil, token)
+		require.Equal(t, http.StatusOK, w.Code)
+		var resp response.PageResponse[pickupHttp.CommentResponse]
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		return resp
+	}
+
+	var first, second pickupHttp.CommentResponse
+
+	t.Run("Host And Participant Can Post", func(t *testing.T) {
+		first = post(t, "Who brings shuttlecocks?", memberToken)
+		assert.Equal(t, member.ID, first.Author.ID)
+		assert.False(t, first.Author.IsHost)
+
+		second = post(t, "  I will.  ", hostToken)
+		assert.Equal(t, "I will.", second.Body)
+		assert.True(t, second.Author.IsHost)
+
+		resp := list(t, memberToken)
+		require.Equal(t, 2, resp.Total)
+		assert.Equal(t, first.ID, resp.Items[0].ID)
+		assert.Equal(t, second.ID, resp.Items[1].ID)
+	})
+
+	t.Run("Outsider Cannot Read Or Post: 403", func(t *testing.T) {
+		w := executeRequest("GET", commentsURL, nil, outsiderToken)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+
+		w = executeRequest("POST", commentsURL, pickupHttp.CreateCommentBody{Body: "hi"}, outsiderToken)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("Blank Comment: 400", func(t *testing.T) {
+		w := executeRequest("POST", commentsURL, pickupHttp.CreateCommentBody{Body: "   "}, memberToken)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("Host Pins Comment", func(t *testing.T) {
+		pinned := true
+		w := executeRequest("PATCH", commentsURL+"/"+second.ID, pickupHttp.UpdateCommentBody{Pinned: &pinned}, memberToken)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+
+		w = executeRequest("PATCH", commentsURL+"/"+second.ID, pickupHttp.UpdateCommentBody{Pinned: &pinned}, hostToken)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		resp := list(t, memberToken)
+		require.Len(t, resp.Items, 2)
+		assert.Equal(t, second.ID, resp.Items[0].ID)
+		assert.True(t, resp.Items[0].Pinned)
+	})
+
+	t.Run("Delete Moderation", func(t *testing.T) {
+		// A participant cannot delete someone else's comment.
+		w := executeRequest("DELETE", commentsURL+"/"+second.ID, nil, memberToken)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+
+		// The host can delete any comment.
+		w = executeRequest("DELETE", commentsURL+"/"+first.ID, nil, hostToken)
+		assert.Equal(t, http.StatusNoContent, w.Code)
+
+		resp := list(t, hostToken)
+		assert.Equal(t, 1, resp.Total)
+
+		w = executeRequest("DELETE", commentsURL+"/"+first.ID, nil, hostToken)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
@@ -0,0 +1,229 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	bookingHttp "github.com/nekogravitycat/court-booking-backend/internal/booking/http"
+	locHttp "github.com/nekogravitycat/court-booking-backend/internal/location/http"
+	orgHttp "github.com/nekogravitycat/court-booking-backend/internal/organization/http"
+	pickupHttp "github.com/nekogravitycat/court-booking-backend/internal/pickup/http"
+	resHttp "github.com/nekogravitycat/court-booking-backend/internal/resource/http"
+)
+
+func TestPickupCourtBooking(t *testing.T) {
+	clearTables()
+
+	sysAdmin := createTestUser(t, "sysadmin@court.com", "pass", true)
+	host := createTestUser(t, "host@court.com", "pass", false)
+	grantPickupHost(t, host.ID)
+	other := createTestUser(t, "other@court.com", "pass", false)
+
+	sysAdminToken := generateToken(sysAdmin.ID)
+	hostToken := generateToken(host.ID)
+	otherToken := generateToken(other.ID)
+
+	sportID, skillLevelID := getSportSkill(t, "BADMINTON", "B")
+
+	// Tomorrow 08:00 UTC, inside the 06:00-23:00 opening hours.
+	base := time.Now().UTC().Truncate(24 * time.Hour).Add(32 * time.Hour)
+
+	wOrg := executeRequest("POST", "/v1/organizations", orgHttp.CreateOrganizationRequest{Name: "Court Org", OwnerID: host.ID}, sysAdminToken)
+	require.Equal(t, http.StatusCreated, wOrg.Code)
+	var org orgHttp.OrganizationResponse
+	json.Unmarshal(wOrg.Body.Bytes(), &org)
+
+	createLocation := func(name string) string {
+		w := executeRequest("POST", "/v1/locations", locHttp.CreateLocationRequest{
+			OrganizationID:    org.ID,
+			Name:              name,
+			Capacity:          10,
+			OpeningHoursStart: "06:00:00", OpeningHoursEnd: "23:00:00",
+			Opening:      true,
+			Timezone:     "UTC",
+			LocationInfo: "Test Info", Longitude: 120.0, Latitude: 23.0,
+		}, hostToken)
+		require.Equal(t, http.StatusCreated, w.Code)
+		var loc locHttp.LocationResponse
+		json.Unmarshal(w.Body.Bytes(), &loc)
+		return loc.ID
+	}
+	createResource := func(name, locationID string) string {
+		w := executeRequest("POST", "/v1/resources", resHttp.CreateRequest{
+			Name: name, LocationID: locationID, ResourceType: "badminton",
+		}, hostToken)
+		require.Equal(t, http.StatusCreated, w.Code)
+		var res resHttp.ResourceResponse
+		json.Unmarshal(w.Body.Bytes(), &res)
+		return res.ID
+	}
+
+	locationID := createLocation("Court Hall")
+	otherLocationID := createLocation("Other Hall")
+	court1 := createResource("Court 1", locationID)
+	court2 := createResource("Court 2", locationID)
+	farCourt := createResource("Far Court", otherLocationID)
+
+	groupBody := func(start, end time.Time) pickupHttp.CreateGroupBody {
+		return pickupHttp.CreateGroupBody{
+			Title:        "Court Group",
+			StartTime:    start,
+			EndTime:      end,
+			Capacity:     6,
+			LocationID:   locationID,
+			SportID:      sportID,
+			SkillLevelID: skillLevelID,
+		}
+	}
+	getBooking := func(t *testing.T, id string) bookingHttp.BookingResponse {
+		w := executeRequest("GET", "/v1/bookings/"+id, nil, sysAdminToken)
+		require.Equal(t, http.StatusOK, w.Code)
+		var b bookingHttp.BookingResponse
+		json.Unmarshal(w.Body.Bytes(), &b)
+		return b
+	}
+
+	var group pickupHttp.PickupGroupResponse
+
+	t.Run("Create Books The Court", func(t *testing.T) {
+		body := groupBody(base, base.Add(2*time.Hour))
+		body.ResourceID = &court1
+		w := executeRequest("POST", "/v1/pickup-groups", body, hostToken)
+		require.Equal(t, http.StatusCreated, w.Code)
+		json.Unmarshal(w.Body.Bytes(), &group)
+		require.NotNil(t, group.ResourceID)
+		assert.Equal(t, court1, *group.ResourceID)
+		require.NotNil(t, group.BookingID)
+		require.NotNil(t, group.BookingStatus)
+		assert.Equal(t, "pending", *group.BookingStatus)
+
+		b := getBooking(t, *group.BookingID)
+		assert.Equal(t, host.ID, b.User.ID)
+		assert.Equal(t, court1, b.Resource.ID)
+		assert.True(t, b.StartTime.Equal(base))
+		assert.True(t, b.EndTime.Equal(base.Add(2*time.Hour)))
+		require.NotNil(t, b.PickupGroupID)
+		assert.Equal(t, group.ID, *b.PickupGroupID)
+	})
+
+	t.Run("Court Taken: 409", func(t *testing.T) {
+		w := executeRequest("POST", "/v1/bookings", bookingHttp.CreateBookingRequest{
+			ResourceID: court1, StartTime: base.Add(time.Hour), EndTime: base.Add(3 * time.Hour),
+		}, otherToken)
+		assert.Equal(t, http.StatusConflict, w.Code)
+
+		body := groupBody(base.Add(time.Hour), base.Add(3*time.Hour))
+		body.ResourceID = &court1
+		w = executeRequest("POST", "/v1/pickup-groups", body, hostToken)
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+
+	t.Run("Court At Another Location: 400", func(t *testing.T) {
+		body := groupBody(base, base.Add(time.Hour))
+		body.ResourceID = &farCourt
+		w := executeRequest("POST", "/v1/pickup-groups", body, hostToken)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("Booking Is Managed By The Group", func(t *testing.T) {
+		newEnd := base.Add(3 * time.Hour)
+		w := executeRequest("PATCH", "/v1/bookings/"+*group.BookingID, bookingHttp.UpdateBookingRequest{EndTime: &newEnd}, hostToken)
+		assert.Equal(t, http.StatusConflict, w.Code)
+
+		cancelled := "cancelled"
+		w = executeRequest("PATCH", "/v1/bookings/"+*group.BookingID, bookingHttp.UpdateBookingRequest{Status: &cancelled}, hostToken)
+		assert.Equal(t, http.StatusConflict, w.Code)
+
+		w = executeRequest("DELETE", "/v1/bookings/"+*group.BookingID, nil, hostToken)
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+
+	t.Run("Moving The Group Moves The Booking", func(t *testing.T) {
+		start, end := base.Add(3*time.Hour), base.Add(5*time.Hour)
+		w := executeRequest("PATCH", "/v1/pickup-groups/"+group.ID, pickupHttp.UpdateGroupBody{StartTime: &start, EndTime: &end}, hostToken)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		b := getBooking(t, *group.BookingID)
+		assert.True(t, b.StartTime.Equal(start))
+		assert.True(t, b.EndTime.Equal(end))
+
+		// The old slot is free again.
+		w = executeRequest("POST", "/v1/bookings", bookingHttp.CreateBookingRequest{
+			ResourceID: court1, StartTime: base, EndTime: base.Add(time.Hour),
+		}, otherToken)
+		assert.Equal(t, http.StatusCreated, w.Code)
+	})
+
+	t.Run("Switching Court Rebooks", func(t *testing.T) {
+		oldBookingID := *group.BookingID
+		w := executeRequest("PATCH", "/v1/pickup-groups/"+group.ID, pickupHttp.UpdateGroupBody{ResourceID: &court2}, hostToken)
+		require.Equal(t, http.StatusOK, w.Code)
+		json.Unmarshal(w.Body.Bytes(), &group)
+		assert.Equal(t, court2, *group.ResourceID)
+		require.NotNil(t, group.BookingID)
+		assert.NotEqual(t, oldBookingID, *group.BookingID)
+
+		assert.Equal(t, "cancelled", getBooking(t, oldBookingID).Status)
+		assert.Equal(t, court2, getBooking(t, *group.BookingID).Resource.ID)
+	})
+
+	t.Run("Booking Follows Group Status", func(t *testing.T) {
+		cancelled := "cancelled"
+		w := executeRequest("PATCH", "/v1/pickup-groups/"+group.ID, pickupHttp.UpdateGroupBody{Status: &cancelled}, hostToken)
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "cancelled", getBooking(t, *group.BookingID).Status)
+
+		active := "active"
+		w = executeRequest("PATCH", "/v1/pickup-groups/"+group.ID, pickupHttp.UpdateGroupBody{Status: &active}, hostToken)
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "pending", getBooking(t, *group.BookingID).Status)
+	})
+
+	t.Run("Link Existing Booking", func(t *testing.T) {
+		w := executeRequest("POST", "/v1/bookings", bookingHttp.CreateBookingRequest{
+			ResourceID: court1, StartTime: base.Add(6 * time.Hour), EndTime: base.Add(9 * time.Hour),
+		}, hostToken)
+		require.Equal(t, http.StatusCreated, w.Code)
+		var hostBooking bookingHttp.BookingResponse
+		json.Unmarshal(w.Body.Bytes(), &hostBooking)
+
+		// The group must fit inside the booking.
+		body := groupBody(base.Add(5*time.Hour), base.Add(7*time.Hour))
+		body.BookingID = &hostBooking.ID
+		w = executeRequest("POST", "/v1/pickup-groups", body, hostToken)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+
+		body = groupBody(base.Add(7*time.Hour), base.Add(8*time.Hour))
+		body.BookingID = &hostBooking.ID
+		w = executeRequest("POST", "/v1/pickup-groups", body, hostToken)
+		require.Equal(t, http.StatusCreated, w.Code)
+		var linked pickupHttp.PickupGroupResponse
+		json.Unmarshal(w.Body.Bytes(), &linked)
+		require.NotNil(t, linked.BookingID)
+		assert.Equal(t, hostBooking.ID, *linked.BookingID)
+		assert.Equal(t, court1, *linked.ResourceID)
+
+		// A booking backs at most one group.
+		w = executeRequest("POST", "/v1/pickup-groups", body, hostToken)
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+
+	t.Run("Link Someone Else's Booking: 403", func(t *testing.T) {
+		w := executeRequest("POST", "/v1/bookings", bookingHttp.CreateBookingRequest{
+			ResourceID: court2, StartTime: base.Add(10 * time.Hour), EndTime: base.Add(11 * time.Hour),
+		}, otherToken)
+		require.Equal(t, http.StatusCreated, w.Code)
+		var b bookingHttp.BookingResponse
+		json.Unmarshal(w.Body.Bytes(), &b)
+
+		body := groupBody(base.Add(10*time.Hour), base.Add(11*time.Hour))
+		body.BookingID = &b.ID
+		w = executeRequest("POST", "/v1/pickup-groups", body, hostToken)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+}
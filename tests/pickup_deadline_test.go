@@ -0,0 +1,144 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	pickupHttp "github.com/nekogravitycat/court-booking-backend/internal/pickup/http"
+)
+
+func TestPickupDeadlines(t *testing.T) {
+	clearTables()
+
+	host := createTestUser(t, "host@deadline.com", "pass", false)
+	grantPickupHost(t, host.ID)
+	early := createTestUser(t, "early@deadline.com", "pass", false)
+	changed := createTestUser(t, "changed@deadline.com", "pass", false)
+	late := createTestUser(t, "late@deadline.com", "pass", false)
+
+	hostToken := generateToken(host.ID)
+	earlyToken := generateToken(early.ID)
+	changedToken := generateToken(changed.ID)
+	lateToken := generateToken(late.ID)
+
+	locationID := setupTestLocation(t, hostToken, host.ID)
+	sportID, skillLevelID := getSportSkill(t, "BADMINTON", "B")
+
+	start := time.Now().Add(24 * time.Hour).Truncate(time.Second)
+	newBody := func() pickupHttp.CreateGroupBody {
+		return pickupHttp.CreateGroupBody{
+			Title:        "Deadline Group",
+			StartTime:    start,
+			EndTime:      start.Add(2 * time.Hour),
+			Fee:          100,
+			Capacity:     6,
+			LocationID:   locationID,
+			SportID:      sportID,
+			SkillLevelID: skillLevelID,
+		}
+	}
+	updateGroup := func(t *testing.T, groupID string, body pickupHttp.UpdateGroupBody) int {
+		w := executeRequest("PATCH", "/v1/pickup-groups/"+groupID, body, hostToken)
+		return w.Code
+	}
+	cancelOrder := func(t *testing.T, orderID, token string) pickupHttp.PickupOrderResponse {
+		cancelled := "cancelled"
+		w := executeRequest("PATCH", "/v1/pickup-orders/"+orderID, pickupHttp.UpdateOrderBody{Status: &cancelled}, token)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var o pickupHttp.PickupOrderResponse
+		json.Unmarshal(w.Body.Bytes(), &o)
+		return o
+	}
+
+	t.Run("Deadline After Start: 400", func(t *testing.T) {
+		body := newBody()
+		after := start.Add(time.Minute)
+		body.EnrollmentDeadline = &after
+		w := executeRequest("POST", "/v1/pickup-groups", body, hostToken)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+
+		body = newBody()
+		body.CancelDeadline = &after
+		w = executeRequest("POST", "/v1/pickup-groups", body, hostToken)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	body := newBody()
+	enrollBy, cancelBy := start.Add(-2*time.Hour), start.Add(-6*time.Hour)
+	body.EnrollmentDeadline = &enrollBy
+	body.CancelDeadline = &cancelBy
+	w := executeRequest("POST", "/v1/pickup-groups", body, hostToken)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var group pickupHttp.PickupGroupResponse
+	json.Unmarshal(w.Body.Bytes(), &group)
+	require.NotNil(t, group.EnrollmentDeadline)
+	assert.True(t, enrollBy.Equal(*group.EnrollmentDeadline))
+	require.NotNil(t, group.CancelDeadline)
+	assert.True(t, cancelBy.Equal(*group.CancelDeadline))
+
+	enroll := func(t *testing.T, token string) string {
+		w := executeRequest("POST", "/v1/pickup-groups/"+group.ID+"/orders", nil, token)
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+		var o pickupHttp.PickupOrderResponse
+		json.Unmarshal(w.Body.Bytes(), &o)
+		return o.ID
+	}
+	earlyOrder := enroll(t, earlyToken)
+	changedOrder := enroll(t, changedToken)
+
+	t.Run("Moving Start Before A Deadline: 400", func(t *testing.T) {
+		earlier := enrollBy.Add(-time.Hour)
+		end := earlier.Add(2 * time.Hour)
+		assert.Equal(t, http.StatusBadRequest,
+			updateGroup(t, group.ID, pickupHttp.UpdateGroupBody{StartTime: &earlier, EndTime: &end}))
+	})
+
+	t.Run("Enrollment Closed: 409", func(t *testing.T) {
+		past := time.Now().Add(-time.Minute)
+		require.Equal(t, http.StatusOK, updateGroup(t, group.ID, pickupHttp.UpdateGroupBody{
+			EnrollmentDeadline: &past,
+			CancelDeadline:     &past,
+		}))
+
+		w := executeRequest("POST", "/v1/pickup-groups/"+group.ID+"/orders", nil, lateToken)
+		assert.Equal(t, http.StatusConflict, w.Code)
+		w = executeRequest("POST", "/v1/pickup-groups/"+group.ID+"/orders?join_waitlist=true", nil, lateToken)
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+
+	t.Run("Late Cancellation Needs Approval", func(t *testing.T) {
+		o := cancelOrder(t, earlyOrder, earlyToken)
+		assert.Equal(t, "cancel_request", o.Status)
+
+		// Asking again leaves the request pending.
+		o = cancelOrder(t, earlyOrder, earlyToken)
+		assert.Equal(t, "cancel_request", o.Status)
+
+		o = cancelOrder(t, earlyOrder, hostToken)
+		assert.Equal(t, "cancelled", o.Status)
+	})
+
+	t.Run("Material Change Allows Free Withdrawal", func(t *testing.T) {
+		fee := 150
+		require.Equal(t, http.StatusOK, updateGroup(t, group.ID, pickupHttp.UpdateGroupBody{Fee: &fee}))
+
+		o := cancelOrder(t, changedOrder, changedToken)
+		assert.Equal(t, "cancelled", o.Status)
+	})
+
+	t.Run("Clearing Deadlines Reopens The Group", func(t *testing.T) {
+		require.Equal(t, http.StatusOK, updateGroup(t, group.ID, pickupHttp.UpdateGroupBody{
+			ClearEnrollmentDeadline: true,
+			ClearCancelDeadline:     true,
+		}))
+
+		lateOrder := enroll(t, lateToken)
+		o := cancelOrder(t, lateOrder, lateToken)
+		assert.Equal(t, "cancelled", o.Status)
+	})
+}
@@ -0,0 +1,119 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	favoriteHttp "github.com/nekogravitycat/court-booking-backend/internal/favorite/http"
+	"github.com/nekogravitycat/court-booking-backend/internal/pickup"
+	pickupHttp "github.com/nekogravitycat/court-booking-backend/internal/pickup/http"
+)
+
+func TestPickupFavoriteFeed(t *testing.T) {
+	clearTables()
+
+	followed := createTestUser(t, "followed@feed.com", "pass", false)
+	grantPickupHost(t, followed.ID)
+	other := createTestUser(t, "other@feed.com", "pass", false)
+	grantPickupHost(t, other.ID)
+	follower := createTestUser(t, "follower@feed.com", "pass", false)
+
+	followedToken := generateToken(followed.ID)
+	otherToken := generateToken(other.ID)
+	followerToken := generateToken(follower.ID)
+
+	followedLocation := setupTestLocation(t, followedToken, followed.ID)
+	otherLocation := setupTestLocation(t, otherToken, other.ID)
+	sportID, skillLevelID := getSportSkill(t, "BADMINTON", "B")
+
+	w := executeRequest("POST", "/v1/favorites/host", favoriteHttp.FavoriteHostRequest{HostID: followed.ID}, followerToken)
+	require.Equal(t, http.StatusCreated, w.Code)
+	testEvents.take()
+
+	createGroup := func(t *testing.T, token, locationID string, startIn time.Duration, edit func(*pickupHttp.CreateGroupBody)) string {
+		body := pickupHttp.CreateGroupBody{
+			Title:        "Feed Group",
+			StartTime:    time.Now().Add(startIn),
+			EndTime:      time.Now().Add(startIn + 2*time.Hour),
+			Fee:          100,
+			Capacity:     4,
+			LocationID:   locationID,
+			SportID:      sportID,
+			SkillLevelID: skillLevelID,
+		}
+		if edit != nil {
+			edit(&body)
+		}
+		w := executeRequest("POST", "/v1/pickup-groups", body, token)
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+		var g pickupHttp.PickupGroupResponse
+		json.Unmarshal(w.Body.Bytes(), &g)
+		return g.ID
+	}
+
+	laterID := createGroup(t, followedToken, followedLocation, 72*time.Hour, nil)
+	soonerID := createGroup(t, followedToken, followedLocation, 24*time.Hour, nil)
+
+	t.Run("New Public Group Notifies Followers", func(t *testing.T) {
+		events := testEvents.take()
+		require.Len(t, events, 2)
+		for _, e := range events {
+			assert.Equal(t, pickup.EventFavoriteHostGroupCreated, e.Type)
+			assert.Equal(t, follower.ID, e.UserID)
+			assert.Equal(t, followed.ID, e.Data["host_id"])
+		}
+		assert.Equal(t, laterID, events[0].Data["pickup_group_id"])
+	})
+
+	createGroup(t, followedToken, followedLocation, 48*time.Hour, func(b *pickupHttp.CreateGroupBody) {
+		b.Visibility = "private"
+	})
+	createGroup(t, followedToken, followedLocation, 48*time.Hour, func(b *pickupHttp.CreateGroupBody) {
+		closed := time.Now().Add(-time.Minute)
+		b.EnrollmentDeadline = &closed
+	})
+	createGroup(t, otherToken, otherLocation, 24*time.Hour, nil)
+
+	t.Run("Unannounced Groups", func(t *testing.T) {
+		events := testEvents.take()
+		require.Len(t, events, 1, "only the public group with closed enrollment of the followed host")
+	})
+
+	getFeed := func(t *testing.T, token string) []pickupHttp.PickupGroupBrief {
+		w := executeRequest("GET", "/v1/me/pickup-feed", nil, token)
+		require.Equal(t, http.StatusOK, w.Code)
+		var page struct {
+			Items []pickupHttp.PickupGroupBrief `json:"items"`
+			Total int                           `json:"total"`
+		}
+		json.Unmarshal(w.Body.Bytes(), &page)
+		assert.Equal(t, len(page.Items), page.Total)
+		return page.Items
+	}
+
+	t.Run("Feed Lists Bookable Groups Of Favorite Hosts", func(t *testing.T) {
+		w := executeRequest("POST", "/v1/pickup-groups/"+soonerID+"/orders", nil, followerToken)
+		require.Equal(t, http.StatusCreated, w.Code)
+
+		items := getFeed(t, followerToken)
+		require.Len(t, items, 2)
+		assert.Equal(t, soonerID, items[0].ID, "soonest first")
+		assert.Equal(t, "pending", items[0].EnrolledStatus)
+		assert.Equal(t, laterID, items[1].ID)
+		assert.Equal(t, pickup.EnrolledStatusFree, items[1].EnrolledStatus)
+	})
+
+	t.Run("No Favorites, Empty Feed", func(t *testing.T) {
+		assert.Empty(t, getFeed(t, otherToken))
+	})
+
+	t.Run("Unauthenticated: 401", func(t *testing.T) {
+		w := executeRequest("GET", "/v1/me/pickup-feed", nil, "")
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+}
@@ -0,0 +1,127 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	pickupHttp "github.com/nekogravitycat/court-booking-backend/internal/pickup/http"
+)
+
+func TestPickupOrderGuests(t *testing.T) {
+	clearTables()
+
+	host := createTestUser(t, "host@guest.com", "pass", false)
+	grantPickupHost(t, host.ID)
+	party := createTestUser(t, "party@guest.com", "pass", false)
+	solo := createTestUser(t, "solo@guest.com", "pass", false)
+	late := createTestUser(t, "late@guest.com", "pass", false)
+
+	hostToken := generateToken(host.ID)
+	partyToken := generateToken(party.ID)
+	soloToken := generateToken(solo.ID)
+	lateToken := generateToken(late.ID)
+
+	locationID := setupTestLocation(t, hostToken, host.ID)
+	sportID, skillLevelID := getSportSkill(t, "BADMINTON", "B")
+
+	maxGuests := 2
+	w := executeRequest("POST", "/v1/pickup-groups", pickupHttp.CreateGroupBody{
+		Title:             "Guest Group",
+		StartTime:         time.Now().Add(24 * time.Hour),
+		EndTime:           time.Now().Add(26 * time.Hour),
+		Capacity:          4,
+		LocationID:        locationID,
+		SportID:           sportID,
+		SkillLevelID:      skillLevelID,
+		MaxGuestsPerOrder: &maxGuests,
+	}, hostToken)
+	require.Equal(t, http.StatusCreated, w.Code)
+	var group pickupHttp.PickupGroupResponse
+	json.Unmarshal(w.Body.Bytes(), &group)
+	require.NotNil(t, group.MaxGuestsPerOrder)
+	assert.Equal(t, 2, *group.MaxGuestsPerOrder)
+	ordersURL := "/v1/pickup-groups/" + group.ID + "/orders"
+
+	getGroup := func(t *testing.T) pickupHttp.PickupGroupResponse {
+		w := executeRequest("GET", "/v1/pickup-groups/"+group.ID, nil, hostToken)
+		require.Equal(t, http.StatusOK, w.Code)
+		var g pickupHttp.PickupGroupResponse
+		json.Unmarshal(w.Body.Bytes(), &g)
+		return g
+	}
+
+	t.Run("Invalid Guest Requests: 400", func(t *testing.T) {
+		w := executeRequest("POST", ordersURL, pickupHttp.CreateOrderBody{Seats: 4}, partyToken)
+		assert.Equal(t, http.StatusBadRequest, w.Code, "3 guests exceed the cap of 2")
+
+		w = executeRequest("POST", ordersURL, pickupHttp.CreateOrderBody{
+			Seats: 2, GuestNames: []string{"Amy", "Ben"},
+		}, partyToken)
+		assert.Equal(t, http.StatusBadRequest, w.Code, "more names than guests")
+	})
+
+	var partyOrder pickupHttp.PickupOrderResponse
+	t.Run("Enroll With Guests", func(t *testing.T) {
+		w := executeRequest("POST", ordersURL, pickupHttp.CreateOrderBody{
+			Seats: 3, GuestNames: []string{"Amy", "Ben"},
+		}, partyToken)
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+		json.Unmarshal(w.Body.Bytes(), &partyOrder)
+		assert.Equal(t, 3, partyOrder.Seats)
+		assert.Equal(t, []string{"Amy", "Ben"}, partyOrder.GuestNames)
+
+		assert.Equal(t, 3, getGroup(t).CurrentEnrolled)
+	})
+
+	t.Run("Seats Count Toward Capacity", func(t *testing.T) {
+		w := executeRequest("POST", ordersURL+"?join_waitlist=true", pickupHttp.CreateOrderBody{Seats: 2}, lateToken)
+		require.Equal(t, http.StatusCreated, w.Code)
+		var o pickupHttp.PickupOrderResponse
+		json.Unmarshal(w.Body.Bytes(), &o)
+		assert.Equal(t, "waitlisted", o.Status, "2 seats do not fit in the 1 left")
+
+		// A single seat still fits; without a body the order takes one seat.
+		w = executeRequest("POST", ordersURL, nil, soloToken)
+		require.Equal(t, http.StatusCreated, w.Code)
+		json.Unmarshal(w.Body.Bytes(), &o)
+		assert.Equal(t, 1, o.Seats)
+		assert.Empty(t, o.GuestNames)
+
+		g := getGroup(t)
+		assert.Equal(t, 4, g.CurrentEnrolled)
+		assert.Equal(t, 1, g.WaitlistCount)
+	})
+
+	t.Run("Freed Seats Promote Waitlisted Party", func(t *testing.T) {
+		cancelled := "cancelled"
+		w := executeRequest("PATCH", "/v1/pickup-orders/"+partyOrder.ID,
+			pickupHttp.UpdateOrderBody{Status: &cancelled}, partyToken)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		g := getGroup(t)
+		assert.Equal(t, 3, g.CurrentEnrolled)
+		assert.Equal(t, 0, g.WaitlistCount)
+	})
+
+	t.Run("Host Disallows Guests", func(t *testing.T) {
+		noGuests := 0
+		w := executeRequest("PATCH", "/v1/pickup-groups/"+group.ID,
+			pickupHttp.UpdateGroupBody{MaxGuestsPerOrder: &noGuests}, hostToken)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		w = executeRequest("POST", ordersURL, pickupHttp.CreateOrderBody{Seats: 2}, partyToken)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+
+		w = executeRequest("PATCH", "/v1/pickup-groups/"+group.ID,
+			pickupHttp.UpdateGroupBody{ClearMaxGuests: true}, hostToken)
+		require.Equal(t, http.StatusOK, w.Code)
+		var g pickupHttp.PickupGroupResponse
+		json.Unmarshal(w.Body.Bytes(), &g)
+		assert.Nil(t, g.MaxGuestsPerOrder)
+	})
+}
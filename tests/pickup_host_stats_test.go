@@ -0,0 +1,144 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	pickupHttp "github.com/nekogravitycat/court-booking-backend/internal/pickup/http"
+)
+
+func TestPickupHostStats(t *testing.T) {
+	clearTables()
+
+	host := createTestUser(t, "host@stats.com", "pass", false)
+	grantPickupHost(t, host.ID)
+	regular := createTestUser(t, "regular@stats.com", "pass", false)
+	party := createTestUser(t, "party@stats.com", "pass", false)
+
+	hostToken := generateToken(host.ID)
+	regularToken := generateToken(regular.ID)
+	partyToken := generateToken(party.ID)
+
+	locationID := setupTestLocation(t, hostToken, host.ID)
+	sportID, skillLevelID := getSportSkill(t, "BADMINTON", "B")
+
+	createGroup := func(t *testing.T, startIn time.Duration) string {
+		w := executeRequest("POST", "/v1/pickup-groups", pickupHttp.CreateGroupBody{
+			Title:        "Stats Group",
+			StartTime:    time.Now().Add(startIn),
+			EndTime:      time.Now().Add(startIn + 2*time.Hour),
+			Fee:          100,
+			Capacity:     4,
+			LocationID:   locationID,
+			SportID:      sportID,
+			SkillLevelID: skillLevelID,
+		}, hostToken)
+		require.Equal(t, http.StatusCreated, w.Code)
+		var g pickupHttp.PickupGroupResponse
+		json.Unmarshal(w.Body.Bytes(), &g)
+		return g.ID
+	}
+	enrollConfirmed := func(t *testing.T, groupID, token string, body any) string {
+		w := executeRequest("POST", "/v1/pickup-groups/"+groupID+"/orders", body, token)
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+		var o pickupHttp.PickupOrderResponse
+		json.Unmarshal(w.Body.Bytes(), &o)
+
+		confirmed := "confirmed"
+		w = executeRequest("PATCH", "/v1/pickup-orders/"+o.ID, pickupHttp.UpdateOrderBody{Status: &confirmed}, hostToken)
+		require.Equal(t, http.StatusOK, w.Code)
+		return o.ID
+	}
+
+	pastID := createGroup(t, 24*time.Hour)
+	upcomingID := createGroup(t, 48*time.Hour)
+	cancelledID := createGroup(t, 72*time.Hour)
+
+	regularPastOrder := enrollConfirmed(t, pastID, regularToken, nil)
+	partyPastOrder := enrollConfirmed(t, pastID, partyToken, pickupHttp.CreateOrderBody{Seats: 2})
+	enrollConfirmed(t, upcomingID, regularToken, nil)
+	enrollConfirmed(t, cancelledID, partyToken, nil)
+
+	cancelled := "cancelled"
+	w := executeRequest("PATCH", "/v1/pickup-groups/"+cancelledID, pickupHttp.UpdateGroupBody{Status: &cancelled}, hostToken)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	_, err := testPool.Exec(context.Background(),
+		"UPDATE public.pickup_groups SET start_time = $2 WHERE id = $1",
+		pastID, time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+
+	for orderID, attendance := range map[string]string{regularPastOrder: "attended", partyPastOrder: "no_show"} {
+		w := executeRequest("PATCH", "/v1/pickup-orders/"+orderID+"/attendance",
+			pickupHttp.MarkAttendanceBody{Attendance: attendance}, hostToken)
+		require.Equal(t, http.StatusOK, w.Code)
+	}
+
+	getStats := func(t *testing.T, query url.Values, token string) (int, pickupHttp.HostStatsResponse) {
+		w := executeRequest("GET", "/v1/me/pickup-host/stats?"+query.Encode(), nil, token)
+		var resp pickupHttp.HostStatsResponse
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		return w.Code, resp
+	}
+	from := time.Now().AddDate(0, 0, -2).UTC().Format("2006-01-02")
+	to := time.Now().AddDate(0, 0, 5).UTC().Format("2006-01-02")
+
+	t.Run("Overall And Per Sport", func(t *testing.T) {
+		code, stats := getStats(t, url.Values{"from": {from}, "to": {to}, "period": {"day"}}, hostToken)
+		require.Equal(t, http.StatusOK, code)
+		assert.Equal(t, "day", stats.Period)
+		assert.Equal(t, "UTC", stats.Timezone)
+
+		overall := stats.Overall
+		assert.Equal(t, 2, overall.GroupCount, "cancelled groups are excluded")
+		assert.Equal(t, 8, overall.Capacity)
+		assert.Equal(t, 4, overall.Seats)
+		require.NotNil(t, overall.FillRate)
+		assert.InDelta(t, 0.5, *overall.FillRate, 0.001)
+		require.NotNil(t, overall.AverageHeadcount)
+		assert.InDelta(t, 2.0, *overall.AverageHeadcount, 0.001)
+		assert.Equal(t, 1, overall.Attended)
+		assert.Equal(t, 1, overall.NoShow)
+		require.NotNil(t, overall.NoShowRate)
+		assert.InDelta(t, 0.5, *overall.NoShowRate, 0.001)
+		assert.Equal(t, 2, overall.Participants)
+		assert.Equal(t, 1, overall.RepeatParticipants)
+		assert.Equal(t, 400, overall.ExpectedIncome)
+
+		require.Len(t, stats.Sports, 1)
+		assert.Equal(t, sportID, stats.Sports[0].Sport.ID)
+		assert.Equal(t, 400, stats.Sports[0].ExpectedIncome)
+		trendIncome := 0
+		for _, p := range stats.Sports[0].Trend {
+			trendIncome += p.ExpectedIncome
+		}
+		assert.Equal(t, 400, trendIncome)
+	})
+
+	t.Run("Other Users See Only Their Own Groups", func(t *testing.T) {
+		code, stats := getStats(t, url.Values{"from": {from}, "to": {to}}, regularToken)
+		require.Equal(t, http.StatusOK, code)
+		assert.Equal(t, 0, stats.Overall.GroupCount)
+		assert.Nil(t, stats.Overall.FillRate)
+		assert.Empty(t, stats.Sports)
+	})
+
+	t.Run("Invalid Query: 400", func(t *testing.T) {
+		for _, q := range []url.Values{
+			{"from": {from}},
+			{"from": {to}, "to": {from}},
+			{"from": {from}, "to": {to}, "period": {"year"}},
+			{"from": {from}, "to": {to}, "timezone": {"Mars/Olympus"}},
+		} {
+			code, _ := getStats(t, q, hostToken)
+			assert.Equal(t, http.StatusBadRequest, code, q.Encode())
+		}
+	})
+}
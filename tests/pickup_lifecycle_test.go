@@ -0,0 +1,163 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nekogravitycat/court-booking-backend/internal/app"
+	"github.com/nekogravitycat/court-booking-backend/internal/pickup"
+	pickupHttp "github.com/nekogravitycat/court-booking-backend/internal/pickup/http"
+)
+
+func TestPickupLifecycle(t *testing.T) {
+	clearTables()
+
+	host := createTestUser(t, "host@lifecycle.com", "pass", false)
+	grantPickupHost(t, host.ID)
+	u1 := createTestUser(t, "u1@lifecycle.com", "pass", false)
+	u2 := createTestUser(t, "u2@lifecycle.com", "pass", false)
+
+	hostToken := generateToken(host.ID)
+	u1Token := generateToken(u1.ID)
+	u2Token := generateToken(u2.ID)
+
+	locationID := setupTestLocation(t, hostToken, host.ID)
+	sportID, skillLevelID := getSportSkill(t, "BADMINTON", "B")
+
+	createGroup := func(t *testing.T, minParticipants int) string {
+		w := executeRequest("POST", "/v1/pickup-groups", pickupHttp.CreateGroupBody{
+			Title:           "Lifecycle Group",
+			StartTime:       time.Now().Add(24 * time.Hour),
+			EndTime:         time.Now().Add(26 * time.Hour),
+			Capacity:        4,
+			MinParticipants: minParticipants,
+			LocationID:      locationID,
+			SportID:         sportID,
+			SkillLevelID:    skillLevelID,
+		}, hostToken)
+		require.Equal(t, http.StatusCreated, w.Code)
+		var g pickupHttp.PickupGroupResponse
+		json.Unmarshal(w.Body.Bytes(), &g)
+		assert.Equal(t, minParticipants, g.MinParticipants)
+		return g.ID
+	}
+	enroll := func(t *testing.T, groupID, token string) string {
+		w := executeRequest("POST", "/v1/pickup-groups/"+groupID+"/orders", nil, token)
+		require.Equal(t, http.StatusCreated, w.Code)
+		var o pickupHttp.PickupOrderResponse
+		json.Unmarshal(w.Body.Bytes(), &o)
+		return o.ID
+	}
+	shiftGroup := func(t *testing.T, groupID string, start, end time.Time) {
+		_, err := testPool.Exec(context.Background(),
+			"UPDATE public.pickup_groups SET start_time = $2, end_time = $3 WHERE id = $1", groupID, start, end)
+		require.NoError(t, err)
+	}
+	getGroup := func(t *testing.T, groupID string) pickupHttp.PickupGroupResponse {
+		w := executeRequest("GET", "/v1/pickup-groups/"+groupID+"?include_orders=true", nil, hostToken)
+		require.Equal(t, http.StatusOK, w.Code)
+		var g pickupHttp.PickupGroupResponse
+		json.Unmarshal(w.Body.Bytes(), &g)
+		return g
+	}
+	orderStatuses := func(g pickupHttp.PickupGroupResponse) map[string]string {
+		statuses := map[string]string{}
+		for _, o := range *g.Orders {
+			statuses[o.ID] = o.Status
+		}
+		return statuses
+	}
+
+	t.Run("Invalid Min Participants: 400", func(t *testing.T) {
+		w := executeRequest("POST", "/v1/pickup-groups", pickupHttp.CreateGroupBody{
+			Title:           "Too Many",
+			StartTime:       time.Now().Add(24 * time.Hour),
+			EndTime:         time.Now().Add(26 * time.Hour),
+			Capacity:        2,
+			MinParticipants: 3,
+			LocationID:      locationID,
+			SportID:         sportID,
+			SkillLevelID:    skillLevelID,
+		}, hostToken)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("Ended Group Is Completed", func(t *testing.T) {
+		groupID := createGroup(t, 0)
+		confirmedID := enroll(t, groupID, u1Token)
+		pendingID := enroll(t, groupID, u2Token)
+
+		confirmed := "confirmed"
+		w := executeRequest("PATCH", "/v1/pickup-orders/"+confirmedID, pickupHttp.UpdateOrderBody{Status: &confirmed}, hostToken)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		shiftGroup(t, groupID, time.Now().Add(-3*time.Hour), time.Now().Add(-time.Hour))
+		runWorker(t, app.PickupLifecycleWorker)
+
+		g := getGroup(t, groupID)
+		assert.Equal(t, "completed", g.Status)
+		statuses := orderStatuses(g)
+		assert.Equal(t, "completed", statuses[confirmedID])
+		assert.Equal(t, "cancelled", statuses[pendingID])
+	})
+
+	t.Run("Host Completing Group Settles Orders", func(t *testing.T) {
+		groupID := createGroup(t, 0)
+		confirmedID := enroll(t, groupID, u1Token)
+		pendingID := enroll(t, groupID, u2Token)
+
+		confirmed := "confirmed"
+		w := executeRequest("PATCH", "/v1/pickup-orders/"+confirmedID, pickupHttp.UpdateOrderBody{Status: &confirmed}, hostToken)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		completed := "completed"
+		w = executeRequest("PATCH", "/v1/pickup-groups/"+groupID, pickupHttp.UpdateGroupBody{Status: &completed}, hostToken)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		g := getGroup(t, groupID)
+		assert.Equal(t, "completed", g.Status)
+		statuses := orderStatuses(g)
+		assert.Equal(t, "completed", statuses[confirmedID])
+		assert.Equal(t, "cancelled", statuses[pendingID])
+	})
+
+	t.Run("Underfilled Group Is Cancelled At Cutoff", func(t *testing.T) {
+		groupID := createGroup(t, 2)
+		orderID := enroll(t, groupID, u1Token)
+
+		// Not yet at the cutoff: left alone.
+		runWorker(t, app.PickupLifecycleWorker)
+		assert.Equal(t, "active", getGroup(t, groupID).Status)
+
+		shiftGroup(t, groupID, time.Now().Add(-time.Minute), time.Now().Add(time.Hour))
+		testEvents.take()
+		runWorker(t, app.PickupLifecycleWorker)
+
+		g := getGroup(t, groupID)
+		assert.Equal(t, "cancelled", g.Status)
+		assert.Equal(t, "cancelled", orderStatuses(g)[orderID])
+
+		events := testEvents.take()
+		require.Len(t, events, 1)
+		assert.Equal(t, pickup.EventGroupCancelled, events[0].Type)
+		assert.Equal(t, u1.ID, events[0].UserID)
+		assert.Equal(t, "underfilled", events[0].Data["reason"])
+	})
+
+	t.Run("Group Meeting Minimum Keeps Running", func(t *testing.T) {
+		groupID := createGroup(t, 2)
+		enroll(t, groupID, u1Token)
+		enroll(t, groupID, u2Token)
+
+		shiftGroup(t, groupID, time.Now().Add(-time.Minute), time.Now().Add(time.Hour))
+		runWorker(t, app.PickupLifecycleWorker)
+
+		assert.Equal(t, "active", getGroup(t, groupID).Status)
+	})
+}
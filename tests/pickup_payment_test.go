@@ -0,0 +1,184 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	pickupHttp "github.com/nekogravitycat/court-booking-backend/internal/pickup/http"
+	paymentHttp "github.com/nekogravitycat/court-booking-backend/internal/pickuppayment/http"
+	"github.com/nekogravitycat/court-booking-backend/internal/pkg/response"
+)
+
+func TestPickupPayments(t *testing.T) {
+	clearTables()
+
+	admin := createTestUser(t, "admin@payment.com", "pass", true)
+	host := createTestUser(t, "host@payment.com", "pass", false)
+	grantPickupHost(t, host.ID)
+	player := createTestUser(t, "player@payment.com", "pass", false)
+	other := createTestUser(t, "other@payment.com", "pass", false)
+
+	adminToken := generateToken(admin.ID)
+	hostToken := generateToken(host.ID)
+	playerToken := generateToken(player.ID)
+	otherToken := generateToken(other.ID)
+
+	locationID := setupTestLocation(t, hostToken, host.ID)
+	sportID, skillLevelID := getSportSkill(t, "BADMINTON", "B")
+
+	w := executeRequest("POST", "/v1/pickup-groups", pickupHttp.CreateGroupBody{
+		Title:        "Paid Group",
+		StartTime:    time.Now().Add(24 * time.Hour),
+		EndTime:      time.Now().Add(26 * time.Hour),
+		Fee:          150,
+		Capacity:     6,
+		LocationID:   locationID,
+		SportID:      sportID,
+		SkillLevelID: skillLevelID,
+	}, hostToken)
+	require.Equal(t, http.StatusCreated, w.Code)
+	var group pickupHttp.PickupGroupResponse
+	json.Unmarshal(w.Body.Bytes(), &group)
+
+	w = executeRequest("POST", "/v1/pickup-groups/"+group.ID+"/orders", pickupHttp.CreateOrderBody{Seats: 2}, playerToken)
+	require.Equal(t, http.StatusCreated, w.Code)
+	var order pickupHttp.PickupOrderResponse
+	json.Unmarshal(w.Body.Bytes(), &order)
+	paymentsURL := "/v1/pickup-orders/" + order.ID + "/payments"
+
+	record := func(body paymentHttp.RecordPaymentBody, token string) (int, paymentHttp.PaymentResponse) {
+		w := executeRequest("POST", paymentsURL, body, token)
+		var p paymentHttp.PaymentResponse
+		json.Unmarshal(w.Body.Bytes(), &p)
+		return w.Code, p
+	}
+
+	var deposit, balance paymentHttp.PaymentResponse
+	t.Run("Host Records Payments", func(t *testing.T) {
+		code, _ := record(paymentHttp.RecordPaymentBody{Method: "cash"}, playerToken)
+		assert.Equal(t, http.StatusForbidden, code)
+
+		amount := 100
+		code, deposit = record(paymentHttp.RecordPaymentBody{Amount: &amount, Method: "bank_transfer"}, hostToken)
+		require.Equal(t, http.StatusCreated, code)
+		assert.Equal(t, 100, deposit.Amount)
+		assert.Equal(t, host.ID, deposit.HostID)
+		require.NotNil(t, deposit.PayerUserID)
+		assert.Equal(t, player.ID, *deposit.PayerUserID)
+
+		// Without an amount the fee for every seat is charged.
+		code, balance = record(paymentHttp.RecordPaymentBody{Method: "cash"}, hostToken)
+		require.Equal(t, http.StatusCreated, code)
+		assert.Equal(t, 300, balance.Amount)
+
+		w := executeRequest("GET", "/v1/pickup-groups/"+group.ID+"/orders", nil, hostToken)
+		require.Equal(t, http.StatusOK, w.Code)
+		var orders []pickupHttp.PickupOrderResponse
+		json.Unmarshal(w.Body.Bytes(), &orders)
+		require.Len(t, orders, 1)
+		assert.Equal(t, "done", orders[0].PaymentStatus)
+	})
+
+	t.Run("Orders Off The Roster: 409", func(t *testing.T) {
+		w := executeRequest("POST", "/v1/pickup-groups/"+group.ID+"/orders", nil, otherToken)
+		require.Equal(t, http.StatusCreated, w.Code)
+		var rejected pickupHttp.PickupOrderResponse
+		json.Unmarshal(w.Body.Bytes(), &rejected)
+		status := "rejected"
+		w = executeRequest("PATCH", "/v1/pickup-orders/"+rejected.ID, pickupHttp.UpdateOrderBody{Status: &status}, hostToken)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		w = executeRequest("POST", "/v1/pickup-orders/"+rejected.ID+"/payments", paymentHttp.RecordPaymentBody{Method: "cash"}, hostToken)
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+
+	t.Run("Booker Reads Ledger", func(t *testing.T) {
+		w := executeRequest("GET", paymentsURL, nil, playerToken)
+		require.Equal(t, http.StatusOK, w.Code)
+		var list []paymentHttp.PaymentResponse
+		json.Unmarshal(w.Body.Bytes(), &list)
+		assert.Len(t, list, 2)
+
+		w = executeRequest("GET", paymentsURL, nil, otherToken)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("Refund", func(t *testing.T) {
+		w := executeRequest("POST", "/v1/pickup-payments/"+deposit.ID+"/refund", nil, playerToken)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+
+		w = executeRequest("POST", "/v1/pickup-payments/"+deposit.ID+"/refund", nil, hostToken)
+		require.Equal(t, http.StatusOK, w.Code)
+		var p paymentHttp.PaymentResponse
+		json.Unmarshal(w.Body.Bytes(), &p)
+		assert.True(t, p.Refunded)
+
+		w = executeRequest("POST", "/v1/pickup-payments/"+deposit.ID+"/refund", nil, hostToken)
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+
+	t.Run("Group Earnings", func(t *testing.T) {
+		w := executeRequest("GET", "/v1/pickup-groups/"+group.ID+"/earnings", nil, otherToken)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+
+		w = executeRequest("GET", "/v1/pickup-groups/"+group.ID+"/earnings", nil, hostToken)
+		require.Equal(t, http.StatusOK, w.Code)
+		var e paymentHttp.GroupEarningsResponse
+		json.Unmarshal(w.Body.Bytes(), &e)
+		assert.Equal(t, 400, e.Collected)
+		assert.Equal(t, 100, e.Refunded)
+		assert.Equal(t, 300, e.Net)
+		assert.Equal(t, 2, e.OccupiedSeats)
+		assert.Equal(t, 300, e.Expected)
+		assert.Equal(t, 0, e.Outstanding)
+	})
+
+	t.Run("Host Earnings By Period", func(t *testing.T) {
+		today := time.Now().UTC().Format("2006-01-02")
+		w := executeRequest("GET", "/v1/me/pickup-earnings?period=day&from="+today+"&to="+today, nil, hostToken)
+		require.Equal(t, http.StatusOK, w.Code)
+		var e paymentHttp.EarningsResponse
+		json.Unmarshal(w.Body.Bytes(), &e)
+		require.Len(t, e.Periods, 1)
+		assert.Equal(t, today, e.Periods[0].PeriodStart)
+		assert.Equal(t, 300, e.Totals.Net)
+		assert.Equal(t, 2, e.Totals.PaymentCount)
+
+		w = executeRequest("GET", "/v1/me/pickup-earnings?from="+today+"&to="+today+"&timezone=Nowhere/City", nil, hostToken)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("Admin Reconciliation", func(t *testing.T) {
+		w := executeRequest("GET", "/v1/pickup-earnings", nil, hostToken)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+
+		w = executeRequest("GET", "/v1/pickup-earnings", nil, adminToken)
+		require.Equal(t, http.StatusOK, w.Code)
+		var resp response.PageResponse[paymentHttp.HostEarningsResponse]
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		require.Equal(t, 1, resp.Total)
+		assert.Equal(t, host.ID, resp.Items[0].HostID)
+		assert.Equal(t, 300, resp.Items[0].Net)
+		assert.Equal(t, 1, resp.Items[0].GroupCount)
+	})
+
+	t.Run("Full Refund Resets Payment Status", func(t *testing.T) {
+		w := executeRequest("POST", "/v1/pickup-payments/"+balance.ID+"/refund", nil, hostToken)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		w = executeRequest("GET", "/v1/pickup-groups/"+group.ID+"/orders", nil, hostToken)
+		require.Equal(t, http.StatusOK, w.Code)
+		var orders []pickupHttp.PickupOrderResponse
+		json.Unmarshal(w.Body.Bytes(), &orders)
+		for _, o := range orders {
+			if o.ID == order.ID {
+				assert.Equal(t, "pending", o.PaymentStatus)
+			}
+		}
+	})
+}
@@ -0,0 +1,150 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nekogravitycat/court-booking-backend/internal/app"
+	pickupHttp "github.com/nekogravitycat/court-booking-backend/internal/pickup/http"
+	"github.com/nekogravitycat/court-booking-backend/internal/pkg/response"
+	userHttp "github.com/nekogravitycat/court-booking-backend/internal/user/http"
+)
+
+func TestPickupHostReviews(t *testing.T) {
+	clearTables()
+
+	admin := createTestUser(t, "admin@review.com", "pass", true)
+	host := createTestUser(t, "host@review.com", "pass", false)
+	grantPickupHost(t, host.ID)
+	u1 := createTestUser(t, "u1@review.com", "pass", false)
+	u2 := createTestUser(t, "u2@review.com", "pass", false)
+
+	adminToken := generateToken(admin.ID)
+	hostToken := generateToken(host.ID)
+	u1Token := generateToken(u1.ID)
+	u2Token := generateToken(u2.ID)
+
+	locationID := setupTestLocation(t, hostToken, host.ID)
+	sportID, skillLevelID := getSportSkill(t, "BADMINTON", "B")
+
+	createGroup := func(t *testing.T) string {
+		w := executeRequest("POST", "/v1/pickup-groups", pickupHttp.CreateGroupBody{
+			Title:        "Review Group",
+			StartTime:    time.Now().Add(24 * time.Hour),
+			EndTime:      time.Now().Add(26 * time.Hour),
+			Capacity:     4,
+			LocationID:   locationID,
+			SportID:      sportID,
+			SkillLevelID: skillLevelID,
+		}, hostToken)
+		require.Equal(t, http.StatusCreated, w.Code)
+		var g pickupHttp.PickupGroupResponse
+		json.Unmarshal(w.Body.Bytes(), &g)
+		return g.ID
+	}
+	enroll := func(t *testing.T, groupID, token string) string {
+		w := executeRequest("POST", "/v1/pickup-groups/"+groupID+"/orders", nil, token)
+		require.Equal(t, http.StatusCreated, w.Code)
+		var o pickupHttp.PickupOrderResponse
+		json.Unmarshal(w.Body.Bytes(), &o)
+		return o.ID
+	}
+	review := func(orderID string, rating int, token string) int {
+		comment := "great session"
+		w := executeRequest("POST", "/v1/pickup-orders/"+orderID+"/review",
+			pickupHttp.CreateReviewBody{Rating: rating, Comment: &comment}, token)
+		return w.Code
+	}
+
+	groupID := createGroup(t)
+	confirmedID := enroll(t, groupID, u1Token)
+	pendingID := enroll(t, groupID, u2Token)
+
+	confirmed := "confirmed"
+	w := executeRequest("PATCH", "/v1/pickup-orders/"+confirmedID, pickupHttp.UpdateOrderBody{Status: &confirmed}, hostToken)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	t.Run("Group Not Completed: 409", func(t *testing.T) {
+		assert.Equal(t, http.StatusConflict, review(confirmedID, 5, u1Token))
+	})
+
+	// End the group and let the lifecycle worker complete it.
+	_, err := testPool.Exec(context.Background(),
+		"UPDATE public.pickup_groups SET start_time = $2, end_time = $3 WHERE id = $1",
+		groupID, time.Now().Add(-3*time.Hour), time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+	runWorker(t, app.PickupLifecycleWorker)
+
+	t.Run("Invalid Rating: 400", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, review(confirmedID, 6, u1Token))
+	})
+
+	t.Run("Not Order Owner: 403", func(t *testing.T) {
+		assert.Equal(t, http.StatusForbidden, review(confirmedID, 5, u2Token))
+	})
+
+	t.Run("Unconfirmed Order: 409", func(t *testing.T) {
+		assert.Equal(t, http.StatusConflict, review(pendingID, 5, u2Token))
+	})
+
+	t.Run("Participant Reviews Host", func(t *testing.T) {
+		w := executeRequest("POST", "/v1/pickup-orders/"+confirmedID+"/review",
+			pickupHttp.CreateReviewBody{Rating: 4}, u1Token)
+		require.Equal(t, http.StatusCreated, w.Code)
+
+		var r pickupHttp.HostReviewResponse
+		json.Unmarshal(w.Body.Bytes(), &r)
+		assert.Equal(t, host.ID, r.HostID)
+		assert.Equal(t, groupID, r.PickupGroupID)
+		assert.Equal(t, u1.ID, r.Reviewer.ID)
+		assert.Equal(t, 4, r.Rating)
+		assert.Nil(t, r.Comment)
+	})
+
+	t.Run("Second Review Of Same Order: 409", func(t *testing.T) {
+		assert.Equal(t, http.StatusConflict, review(confirmedID, 5, u1Token))
+	})
+
+	t.Run("List Host Reviews (Public)", func(t *testing.T) {
+		w := executeRequest("GET", "/v1/hosts/"+host.ID+"/reviews", nil, "")
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var resp response.PageResponse[pickupHttp.HostReviewResponse]
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		require.Equal(t, 1, resp.Total)
+		assert.Equal(t, confirmedID, resp.Items[0].OrderID)
+	})
+
+	t.Run("Rating Shown On Group List", func(t *testing.T) {
+		createGroup(t)
+
+		w := executeRequest("GET", "/v1/pickup-groups?sort_by=host_rating", nil, "")
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var resp response.PageResponse[pickupHttp.PickupGroupBrief]
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		require.NotEmpty(t, resp.Items)
+		require.NotNil(t, resp.Items[0].HostRatingAverage)
+		assert.Equal(t, 4.0, *resp.Items[0].HostRatingAverage)
+		assert.Equal(t, 1, resp.Items[0].HostRatingCount)
+	})
+
+	t.Run("Rating Shown On Pickup Host List", func(t *testing.T) {
+		w := executeRequest("GET", "/v1/pickup-hosts?sort_by=rating", nil, adminToken)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var resp response.PageResponse[userHttp.PickupHostResponse]
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		require.Equal(t, 1, resp.Total)
+		assert.Equal(t, host.ID, resp.Items[0].ID)
+		require.NotNil(t, resp.Items[0].RatingAverage)
+		assert.Equal(t, 4.0, *resp.Items[0].RatingAverage)
+		assert.Equal(t, 1, resp.Items[0].RatingCount)
+	})
+}
@@ -0,0 +1,118 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	pickupHttp "github.com/nekogravitycat/court-booking-backend/internal/pickup/http"
+)
+
+func TestPickupRosterExport(t *testing.T) {
+	clearTables()
+
+	host := createTestUser(t, "host@roster.com", "pass", false)
+	grantPickupHost(t, host.ID)
+	player := createTestUser(t, "player@roster.com", "pass", false)
+	quitter := createTestUser(t, "quitter@roster.com", "pass", false)
+	sysAdmin := createTestUser(t, "admin@roster.com", "pass", true)
+
+	hostToken := generateToken(host.ID)
+	playerToken := generateToken(player.ID)
+	quitterToken := generateToken(quitter.ID)
+	sysAdminToken := generateToken(sysAdmin.ID)
+
+	locationID := setupTestLocation(t, hostToken, host.ID)
+	sportID, skillLevelID := getSportSkill(t, "BADMINTON", "B")
+
+	w := executeRequest("POST", "/v1/pickup-groups", pickupHttp.CreateGroupBody{
+		Title:        "Roster Group",
+		StartTime:    time.Now().Add(24 * time.Hour),
+		EndTime:      time.Now().Add(26 * time.Hour),
+		Fee:          100,
+		Capacity:     6,
+		LocationID:   locationID,
+		SportID:      sportID,
+		SkillLevelID: skillLevelID,
+	}, hostToken)
+	require.Equal(t, http.StatusCreated, w.Code)
+	var group pickupHttp.PickupGroupResponse
+	json.Unmarshal(w.Body.Bytes(), &group)
+	exportPath := "/v1/pickup-groups/" + group.ID + "/orders/export"
+
+	w = executeRequest("POST", "/v1/pickup-groups/"+group.ID+"/orders", pickupHttp.CreateOrderBody{
+		Seats:      2,
+		GuestNames: []string{"=Guest"},
+	}, playerToken)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+	w = executeRequest("POST", "/v1/pickup-groups/"+group.ID+"/orders", nil, quitterToken)
+	require.Equal(t, http.StatusCreated, w.Code)
+	var quitterOrder pickupHttp.PickupOrderResponse
+	json.Unmarshal(w.Body.Bytes(), &quitterOrder)
+	cancelled := "cancelled"
+	w = executeRequest("PATCH", "/v1/pickup-orders/"+quitterOrder.ID, pickupHttp.UpdateOrderBody{Status: &cancelled}, quitterToken)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	readCSV := func(t *testing.T, body []byte) [][]string {
+		require.True(t, bytes.HasPrefix(body, []byte("\ufeff")), "CSV starts with a byte order mark")
+		records, err := csv.NewReader(bytes.NewReader(body[3:])).ReadAll()
+		require.NoError(t, err)
+		return records
+	}
+
+	t.Run("Only Managers Export", func(t *testing.T) {
+		w := executeRequest("GET", exportPath, nil, playerToken)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		w = executeRequest("GET", exportPath, nil, "")
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("Invalid Query: 400", func(t *testing.T) {
+		w := executeRequest("GET", exportPath+"?format=xlsx", nil, hostToken)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		w = executeRequest("GET", exportPath+"?timezone=Mars/Base", nil, hostToken)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("CSV Lists Seat Holders", func(t *testing.T) {
+		w := executeRequest("GET", exportPath, nil, hostToken)
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.True(t, strings.HasPrefix(w.Header().Get("Content-Type"), "text/csv"))
+		assert.Contains(t, w.Header().Get("Content-Disposition"), "pickup-roster-"+group.ID+".csv")
+
+		records := readCSV(t, w.Body.Bytes())
+		require.Len(t, records, 2, "header and the one seat-holding order")
+		assert.Equal(t, "Name", records[0][1])
+		row := records[1]
+		assert.Equal(t, "2", row[3])
+		assert.Equal(t, "'=Guest", row[4], "formula-like cells are escaped")
+		assert.Equal(t, "pending", row[5])
+		assert.Equal(t, "pending", row[6])
+		assert.Equal(t, "", row[7])
+	})
+
+	t.Run("All Includes Cancelled Orders", func(t *testing.T) {
+		w := executeRequest("GET", exportPath+"?all=true", nil, sysAdminToken)
+		require.Equal(t, http.StatusOK, w.Code)
+		records := readCSV(t, w.Body.Bytes())
+		require.Len(t, records, 3)
+		assert.Equal(t, "cancelled", records[2][5])
+	})
+
+	t.Run("PDF Sign-In Sheet", func(t *testing.T) {
+		w := executeRequest("GET", exportPath+"?format=pdf&timezone=Asia/Taipei", nil, hostToken)
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "application/pdf", w.Header().Get("Content-Type"))
+		assert.Contains(t, w.Header().Get("Content-Disposition"), "pickup-roster-"+group.ID+".pdf")
+		assert.True(t, bytes.HasPrefix(w.Body.Bytes(), []byte("%PDF-")))
+		assert.True(t, bytes.HasSuffix(w.Body.Bytes(), []byte("%%EOF\n")))
+	})
+}
@@ -0,0 +1,116 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	pickupHttp "github.com/nekogravitycat/court-booking-backend/internal/pickup/http"
+	"github.com/nekogravitycat/court-booking-backend/internal/pkg/response"
+)
+
+func TestPickupGroupSearch(t *testing.T) {
+	clearTables()
+
+	host := createTestUser(t, "host@search.com", "pass", false)
+	grantPickupHost(t, host.ID)
+	player := createTestUser(t, "player@search.com", "pass", false)
+
+	hostToken := generateToken(host.ID)
+	playerToken := generateToken(player.ID)
+
+	// setupTestLocation places courts at (25.0, 121.0); move the second one
+	// about 55 km north.
+	nearLocationID := setupTestLocation(t, hostToken, host.ID)
+	farLocationID := setupTestLocation(t, hostToken, host.ID)
+	_, err := testPool.Exec(context.Background(),
+		"UPDATE public.locations SET latitude = 25.5 WHERE id = $1", farLocationID)
+	require.NoError(t, err)
+
+	sportID, skillLevelID := getSportSkill(t, "BADMINTON", "B")
+
+	createGroup := func(t *testing.T, title, locationID string, fee, capacity int, startIn time.Duration) string {
+		w := executeRequest("POST", "/v1/pickup-groups", pickupHttp.CreateGroupBody{
+			Title:        title,
+			StartTime:    time.Now().Add(startIn),
+			EndTime:      time.Now().Add(startIn + 2*time.Hour),
+			Fee:          fee,
+			Capacity:     capacity,
+			LocationID:   locationID,
+			SportID:      sportID,
+			SkillLevelID: skillLevelID,
+		}, hostToken)
+		require.Equal(t, http.StatusCreated, w.Code)
+		var g pickupHttp.PickupGroupResponse
+		json.Unmarshal(w.Body.Bytes(), &g)
+		return g.ID
+	}
+
+	nearID := createGroup(t, "Near", nearLocationID, 150, 4, 24*time.Hour)
+	farID := createGroup(t, "Far", farLocationID, 300, 4, 48*time.Hour)
+	laterID := createGroup(t, "Later", nearLocationID, 100, 2, 10*24*time.Hour)
+
+	w := executeRequest("POST", "/v1/pickup-groups/"+laterID+"/orders", nil, playerToken)
+	require.Equal(t, http.StatusCreated, w.Code)
+
+	search := func(t *testing.T, query url.Values) []pickupHttp.PickupGroupBrief {
+		w := executeRequest("GET", "/v1/pickup-groups?"+query.Encode(), nil, "")
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var resp response.PageResponse[pickupHttp.PickupGroupBrief]
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		return resp.Items
+	}
+	ids := func(items []pickupHttp.PickupGroupBrief) []string {
+		out := make([]string, len(items))
+		for i, g := range items {
+			out[i] = g.ID
+		}
+		return out
+	}
+
+	t.Run("Within Radius", func(t *testing.T) {
+		items := search(t, url.Values{"lat": {"25.0"}, "lng": {"121.0"}, "radius_km": {"5"}})
+		assert.ElementsMatch(t, []string{nearID, laterID}, ids(items))
+		for _, g := range items {
+			require.NotNil(t, g.DistanceKm)
+			assert.InDelta(t, 0, *g.DistanceKm, 0.01)
+		}
+	})
+
+	t.Run("Sort By Distance", func(t *testing.T) {
+		items := search(t, url.Values{"lat": {"25.0"}, "lng": {"121.0"}, "sort_by": {"distance"}})
+		require.Len(t, items, 3)
+		assert.Equal(t, farID, items[2].ID)
+		require.NotNil(t, items[2].DistanceKm)
+		assert.InDelta(t, 55.6, *items[2].DistanceKm, 0.5)
+	})
+
+	t.Run("Time Window", func(t *testing.T) {
+		to := time.Now().Add(3 * 24 * time.Hour).UTC().Format(time.RFC3339)
+		items := search(t, url.Values{"start_to": {to}})
+		assert.ElementsMatch(t, []string{nearID, farID}, ids(items))
+		assert.Nil(t, items[0].DistanceKm)
+	})
+
+	t.Run("Max Fee And Free Seats", func(t *testing.T) {
+		items := search(t, url.Values{"max_fee": {"200"}})
+		assert.ElementsMatch(t, []string{nearID, laterID}, ids(items))
+
+		items = search(t, url.Values{"max_fee": {"200"}, "min_free_seats": {"2"}})
+		assert.Equal(t, []string{nearID}, ids(items))
+	})
+
+	t.Run("Invalid Geo Filter: 400", func(t *testing.T) {
+		for _, q := range []string{"lat=25", "radius_km=5", "sort_by=distance", "lat=91&lng=121"} {
+			w := executeRequest("GET", "/v1/pickup-groups?"+q, nil, "")
+			assert.Equal(t, http.StatusBadRequest, w.Code, fmt.Sprintf("query %q", q))
+		}
+	})
+}
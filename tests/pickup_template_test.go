@@ -0,0 +1,221 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	favoriteHttp "github.com/nekogravitycat/court-booking-backend/internal/favorite/http"
+	"github.com/nekogravitycat/court-booking-backend/internal/pickup"
+	pickupHttp "github.com/nekogravitycat/court-booking-backend/internal/pickup/http"
+	templateHttp "github.com/nekogravitycat/court-booking-backend/internal/pickuptemplate/http"
+)
+
+func TestPickupTemplates(t *testing.T) {
+	clearTables()
+
+	host := createTestUser(t, "host@template.com", "pass", false)
+	grantPickupHost(t, host.ID)
+	other := createTestUser(t, "other@template.com", "pass", false)
+	sysAdmin := createTestUser(t, "admin@template.com", "pass", true)
+	follower := createTestUser(t, "follower@template.com", "pass", false)
+
+	hostToken := generateToken(host.ID)
+	otherToken := generateToken(other.ID)
+	sysAdminToken := generateToken(sysAdmin.ID)
+
+	locationID := setupTestLocation(t, hostToken, host.ID)
+	sportID, skillLevelID := getSportSkill(t, "BADMINTON", "B")
+
+	newBody := func() templateHttp.CreateTemplateBody {
+		return templateHttp.CreateTemplateBody{
+			Title:           "Weekly Session",
+			Fee:             150,
+			Capacity:        8,
+			LocationID:      locationID,
+			SportID:         sportID,
+			SkillLevelID:    skillLevelID,
+			Weekdays:        []int{0, 1, 2, 3, 4, 5, 6},
+			StartLocal:      "23:59",
+			DurationMinutes: 1,
+			Timezone:        "Asia/Taipei",
+			AdvanceDays:     7,
+		}
+	}
+
+	templateGroups := func(t *testing.T, templateID string) []pickupHttp.PickupGroupResponse {
+		rows, err := testPool.Query(context.Background(),
+			"SELECT id FROM public.pickup_groups WHERE template_id = $1 ORDER BY occurrence_date", templateID)
+		require.NoError(t, err)
+		defer rows.Close()
+		var groups []pickupHttp.PickupGroupResponse
+		for rows.Next() {
+			var id string
+			require.NoError(t, rows.Scan(&id))
+			w := executeRequest("GET", "/v1/pickup-groups/"+id, nil, hostToken)
+			require.Equal(t, http.StatusOK, w.Code)
+			var g pickupHttp.PickupGroupResponse
+			json.Unmarshal(w.Body.Bytes(), &g)
+			groups = append(groups, g)
+		}
+		return groups
+	}
+
+	t.Run("Non Host Cannot Create: 403", func(t *testing.T) {
+		w := executeRequest("POST", "/v1/pickup-templates", newBody(), otherToken)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("Invalid Rule: 400", func(t *testing.T) {
+		body := newBody()
+		body.Weekdays = []int{1, 7}
+		w := executeRequest("POST", "/v1/pickup-templates", body, hostToken)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+
+		body = newBody()
+		body.Weekdays = []int{1, 1}
+		w = executeRequest("POST", "/v1/pickup-templates", body, hostToken)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+
+		body = newBody()
+		body.Timezone = "Mars/Olympus"
+		w = executeRequest("POST", "/v1/pickup-templates", body, hostToken)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+
+		body = newBody()
+		body.StartLocal = "7pm"
+		w = executeRequest("POST", "/v1/pickup-templates", body, hostToken)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	var tmpl templateHttp.TemplateResponse
+
+	t.Run("Create Materializes Occurrences", func(t *testing.T) {
+		w := executeRequest("POST", "/v1/favorites/host", favoriteHttp.FavoriteHostRequest{HostID: host.ID}, generateToken(follower.ID))
+		require.Equal(t, http.StatusCreated, w.Code)
+		testEvents.take()
+
+		w = executeRequest("POST", "/v1/pickup-templates", newBody(), hostToken)
+		require.Equal(t, http.StatusCreated, w.Code)
+		json.Unmarshal(w.Body.Bytes(), &tmpl)
+		assert.Equal(t, host.ID, tmpl.HostID)
+		assert.Equal(t, "23:59:00", tmpl.StartLocal)
+		assert.True(t, tmpl.IsActive)
+
+		loc, _ := time.LoadLocation("Asia/Taipei")
+		today := time.Now().In(loc)
+		require.NotNil(t, tmpl.MaterializedThrough)
+		assert.Equal(t, today.AddDate(0, 0, 7).Format("2006-01-02"), *tmpl.MaterializedThrough)
+
+		groups := templateGroups(t, tmpl.ID)
+		// Today's occurrence is only generated if 23:59 local has not passed.
+		assert.GreaterOrEqual(t, len(groups), 7)
+		assert.LessOrEqual(t, len(groups), 8)
+		for _, g := range groups {
+			assert.Equal(t, "Weekly Session", g.Title)
+			assert.Equal(t, 150, g.Fee)
+			assert.Equal(t, 8, g.Capacity)
+			assert.Equal(t, locationID, g.LocationID)
+			assert.Equal(t, sportID, g.Sport.ID)
+			assert.Equal(t, skillLevelID, g.SkillLevel.ID)
+			require.NotNil(t, g.TemplateID)
+			assert.Equal(t, tmpl.ID, *g.TemplateID)
+			require.NotNil(t, g.OccurrenceDate)
+			assert.Equal(t, *g.OccurrenceDate, g.StartTime.In(loc).Format("2006-01-02"))
+			assert.Equal(t, "23:59", g.StartTime.In(loc).Format("15:04"))
+		}
+
+		// Each public occurrence is announced to the host's followers.
+		announced := map[string]bool{}
+		for _, e := range testEvents.take() {
+			if e.Type == pickup.EventFavoriteHostGroupCreated {
+				assert.Equal(t, follower.ID, e.UserID)
+				assert.Equal(t, host.ID, e.Data["host_id"])
+				announced[e.Data["pickup_group_id"].(string)] = true
+			}
+		}
+		assert.Len(t, announced, len(groups))
+		for _, g := range groups {
+			assert.True(t, announced[g.ID], g.ID)
+		}
+	})
+
+	t.Run("Occurrences Diverge Independently", func(t *testing.T) {
+		groups := templateGroups(t, tmpl.ID)
+		require.GreaterOrEqual(t, len(groups), 2)
+		before := len(groups)
+
+		newTitle := "Special Edition"
+		w := executeRequest("PATCH", "/v1/pickup-groups/"+groups[0].ID, pickupHttp.UpdateGroupBody{Title: &newTitle}, hostToken)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		cancelled := "cancelled"
+		w = executeRequest("PATCH", "/v1/pickup-groups/"+groups[1].ID, pickupHttp.UpdateGroupBody{Status: &cancelled}, hostToken)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		// A template edit, including a rule change that resets the watermark,
+		// neither duplicates nor overwrites existing occurrences.
+		title := "Renamed Session"
+		startLocal := "23:58"
+		w = executeRequest("PATCH", "/v1/pickup-templates/"+tmpl.ID, templateHttp.UpdateTemplateBody{
+			Title:      &title,
+			StartLocal: &startLocal,
+		}, hostToken)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		after := templateGroups(t, tmpl.ID)
+		assert.Len(t, after, before)
+		assert.Equal(t, "Special Edition", after[0].Title)
+		assert.Equal(t, "cancelled", after[1].Status)
+		assert.Equal(t, "Weekly Session", after[2].Title)
+	})
+
+	t.Run("Access Control", func(t *testing.T) {
+		w := executeRequest("GET", "/v1/pickup-templates/"+tmpl.ID, nil, otherToken)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+
+		title := "Hijacked"
+		w = executeRequest("PATCH", "/v1/pickup-templates/"+tmpl.ID, templateHttp.UpdateTemplateBody{Title: &title}, otherToken)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+
+		w = executeRequest("GET", "/v1/pickup-templates/"+tmpl.ID, nil, sysAdminToken)
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		// Other users see only their own (empty) list.
+		w = executeRequest("GET", "/v1/pickup-templates", nil, otherToken)
+		require.Equal(t, http.StatusOK, w.Code)
+		var page struct {
+			Items []templateHttp.TemplateResponse `json:"items"`
+			Total int                             `json:"total"`
+		}
+		json.Unmarshal(w.Body.Bytes(), &page)
+		assert.Equal(t, 0, page.Total)
+
+		w = executeRequest("GET", "/v1/pickup-templates", nil, hostToken)
+		require.Equal(t, http.StatusOK, w.Code)
+		json.Unmarshal(w.Body.Bytes(), &page)
+		assert.Equal(t, 1, page.Total)
+	})
+
+	t.Run("Delete Keeps Materialized Groups", func(t *testing.T) {
+		before := templateGroups(t, tmpl.ID)
+
+		w := executeRequest("DELETE", "/v1/pickup-templates/"+tmpl.ID, nil, hostToken)
+		require.Equal(t, http.StatusNoContent, w.Code)
+
+		w = executeRequest("GET", "/v1/pickup-templates/"+tmpl.ID, nil, hostToken)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+
+		w = executeRequest("GET", "/v1/pickup-groups/"+before[0].ID, nil, hostToken)
+		require.Equal(t, http.StatusOK, w.Code)
+		var g pickupHttp.PickupGroupResponse
+		json.Unmarshal(w.Body.Bytes(), &g)
+		assert.Nil(t, g.TemplateID)
+		assert.NotNil(t, g.OccurrenceDate)
+	})
+}
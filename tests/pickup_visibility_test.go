@@ -0,0 +1,150 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	pickupHttp "github.com/nekogravitycat/court-booking-backend/internal/pickup/http"
+	"github.com/nekogravitycat/court-booking-backend/internal/pkg/response"
+)
+
+func TestPickupGroupVisibility(t *testing.T) {
+	clearTables()
+
+	host := createTestUser(t, "host@visibility.com", "pass", false)
+	grantPickupHost(t, host.ID)
+	invitee := createTestUser(t, "invitee@visibility.com", "pass", false)
+	stranger := createTestUser(t, "stranger@visibility.com", "pass", false)
+
+	hostToken := generateToken(host.ID)
+	inviteeToken := generateToken(invitee.ID)
+	strangerToken := generateToken(stranger.ID)
+
+	locationID := setupTestLocation(t, hostToken, host.ID)
+	sportID, skillLevelID := getSportSkill(t, "BADMINTON", "B")
+
+	createGroup := func(t *testing.T, title, visibility string) pickupHttp.PickupGroupResponse {
+		w := executeRequest("POST", "/v1/pickup-groups", pickupHttp.CreateGroupBody{
+			Title:        title,
+			StartTime:    time.Now().Add(24 * time.Hour),
+			EndTime:      time.Now().Add(26 * time.Hour),
+			Capacity:     4,
+			LocationID:   locationID,
+			SportID:      sportID,
+			SkillLevelID: skillLevelID,
+			Visibility:   visibility,
+		}, hostToken)
+		require.Equal(t, http.StatusCreated, w.Code)
+		var g pickupHttp.PickupGroupResponse
+		json.Unmarshal(w.Body.Bytes(), &g)
+		return g
+	}
+	listIDs := func(t *testing.T, url, token string) []string {
+		w := executeRequest("GET", url, nil, token)
+		require.Equal(t, http.StatusOK, w.Code)
+		var resp response.PageResponse[pickupHttp.PickupGroupBrief]
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		ids := make([]string, len(resp.Items))
+		for i, g := range resp.Items {
+			ids[i] = g.ID
+		}
+		return ids
+	}
+
+	public := createGroup(t, "Public Group", "")
+	unlisted := createGroup(t, "Unlisted Group", "unlisted")
+	private := createGroup(t, "Private Group", "private")
+
+	t.Run("Invite Code Issued For Non-Public Groups", func(t *testing.T) {
+		assert.Equal(t, "public", public.Visibility)
+		assert.Nil(t, public.InviteCode)
+		require.NotNil(t, unlisted.InviteCode)
+		require.NotNil(t, private.InviteCode)
+		assert.NotEqual(t, *unlisted.InviteCode, *private.InviteCode)
+	})
+
+	t.Run("Only Public Groups Listed", func(t *testing.T) {
+		ids := listIDs(t, "/v1/pickup-groups", strangerToken)
+		assert.Equal(t, []string{public.ID}, ids)
+
+		ids = listIDs(t, "/v1/hosts/"+host.ID+"/pickup-groups", strangerToken)
+		assert.Equal(t, []string{public.ID}, ids)
+
+		// The host sees all of their own groups.
+		ids = listIDs(t, "/v1/hosts/"+host.ID+"/pickup-groups", hostToken)
+		assert.Len(t, ids, 3)
+	})
+
+	t.Run("Get Group Enforces Private Access", func(t *testing.T) {
+		w := executeRequest("GET", "/v1/pickup-groups/"+unlisted.ID, nil, strangerToken)
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		w = executeRequest("GET", "/v1/pickup-groups/"+private.ID, nil, strangerToken)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+
+		w = executeRequest("GET", "/v1/pickup-groups/"+private.ID+"?invite_code="+*private.InviteCode, nil, strangerToken)
+		require.Equal(t, http.StatusOK, w.Code)
+		var g pickupHttp.PickupGroupResponse
+		json.Unmarshal(w.Body.Bytes(), &g)
+		assert.Nil(t, g.InviteCode, "invite code is only shown to the host")
+	})
+
+	t.Run("Resolve Invite Code", func(t *testing.T) {
+		w := executeRequest("GET", "/v1/pickup-invites/"+*private.InviteCode, nil, inviteeToken)
+		require.Equal(t, http.StatusOK, w.Code)
+		var g pickupHttp.PickupGroupResponse
+		json.Unmarshal(w.Body.Bytes(), &g)
+		assert.Equal(t, private.ID, g.ID)
+
+		w = executeRequest("GET", "/v1/pickup-invites/NOSUCHCODE", nil, inviteeToken)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("Enrolling In Private Group Requires Code", func(t *testing.T) {
+		w := executeRequest("POST", "/v1/pickup-groups/"+private.ID+"/orders", nil, strangerToken)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+
+		w = executeRequest("POST", "/v1/pickup-groups/"+private.ID+"/orders?invite_code="+*private.InviteCode, nil, inviteeToken)
+		assert.Equal(t, http.StatusCreated, w.Code)
+
+		// Enrolled participants keep access without the code.
+		w = executeRequest("GET", "/v1/pickup-groups/"+private.ID, nil, inviteeToken)
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		w = executeRequest("POST", "/v1/pickup-groups/"+unlisted.ID+"/orders", nil, strangerToken)
+		assert.Equal(t, http.StatusCreated, w.Code)
+	})
+
+	t.Run("Rotate Invite Code", func(t *testing.T) {
+		w := executeRequest("POST", "/v1/pickup-groups/"+private.ID+"/invite-code", nil, inviteeToken)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+
+		w = executeRequest("POST", "/v1/pickup-groups/"+public.ID+"/invite-code", nil, hostToken)
+		assert.Equal(t, http.StatusConflict, w.Code)
+
+		w = executeRequest("POST", "/v1/pickup-groups/"+private.ID+"/invite-code", nil, hostToken)
+		require.Equal(t, http.StatusOK, w.Code)
+		var g pickupHttp.PickupGroupResponse
+		json.Unmarshal(w.Body.Bytes(), &g)
+		require.NotNil(t, g.InviteCode)
+		assert.NotEqual(t, *private.InviteCode, *g.InviteCode)
+
+		w = executeRequest("GET", "/v1/pickup-invites/"+*private.InviteCode, nil, strangerToken)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("Switch To Public Clears Code", func(t *testing.T) {
+		visibility := "public"
+		w := executeRequest("PATCH", "/v1/pickup-groups/"+unlisted.ID, pickupHttp.UpdateGroupBody{Visibility: &visibility}, hostToken)
+		require.Equal(t, http.StatusOK, w.Code)
+		var g pickupHttp.PickupGroupResponse
+		json.Unmarshal(w.Body.Bytes(), &g)
+		assert.Equal(t, "public", g.Visibility)
+		assert.Nil(t, g.InviteCode)
+	})
+}
@@ -0,0 +1,179 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	pickupHttp "github.com/nekogravitycat/court-booking-backend/internal/pickup/http"
+)
+
+func TestPickupWaitlist(t *testing.T) {
+	clearTables()
+
+	host := createTestUser(t, "host@waitlist.com", "pass", false)
+	grantPickupHost(t, host.ID)
+	sysAdmin := createTestUser(t, "admin@waitlist.com", "pass", true)
+	u1 := createTestUser(t, "u1@waitlist.com", "pass", false)
+	u2 := createTestUser(t, "u2@waitlist.com", "pass", false)
+	u3 := createTestUser(t, "u3@waitlist.com", "pass", false)
+	u4 := createTestUser(t, "u4@waitlist.com", "pass", false)
+
+	hostToken := generateToken(host.ID)
+	sysAdminToken := generateToken(sysAdmin.ID)
+	u1Token := generateToken(u1.ID)
+	u2Token := generateToken(u2.ID)
+	u3Token := generateToken(u3.ID)
+	u4Token := generateToken(u4.ID)
+
+	locationID := setupTestLocation(t, hostToken, host.ID)
+	sportID, skillLevelID := getSportSkill(t, "BADMINTON", "B")
+
+	w := executeRequest("POST", "/v1/pickup-groups", pickupHttp.CreateGroupBody{
+		Title:        "Waitlist Group",
+		StartTime:    time.Now().Add(24 * time.Hour),
+		EndTime:      time.Now().Add(26 * time.Hour),
+		Capacity:     1,
+		LocationID:   locationID,
+		SportID:      sportID,
+		SkillLevelID: skillLevelID,
+	}, hostToken)
+	require.Equal(t, http.StatusCreated, w.Code)
+	var group pickupHttp.PickupGroupResponse
+	json.Unmarshal(w.Body.Bytes(), &group)
+	groupID := group.ID
+	ordersPath := "/v1/pickup-groups/" + groupID + "/orders"
+
+	enroll := func(t *testing.T, token string, waitlist bool) (int, pickupHttp.PickupOrderResponse) {
+		path := ordersPath
+		if waitlist {
+			path += "?join_waitlist=true"
+		}
+		w := executeRequest("POST", path, nil, token)
+		var o pickupHttp.PickupOrderResponse
+		json.Unmarshal(w.Body.Bytes(), &o)
+		return w.Code, o
+	}
+
+	orderStatus := func(t *testing.T, orderID string) pickupHttp.PickupOrderResponse {
+		w := executeRequest("GET", ordersPath, nil, hostToken)
+		require.Equal(t, http.StatusOK, w.Code)
+		var orders []pickupHttp.PickupOrderResponse
+		json.Unmarshal(w.Body.Bytes(), &orders)
+		for _, o := range orders {
+			if o.ID == orderID {
+				return o
+			}
+		}
+		t.Fatalf("order %s not found", orderID)
+		return pickupHttp.PickupOrderResponse{}
+	}
+
+	var o1, o2, o3, o4 pickupHttp.PickupOrderResponse
+
+	t.Run("Full Group Without Flag: 409", func(t *testing.T) {
+		code, o := enroll(t, u1Token, false)
+		require.Equal(t, http.StatusCreated, code)
+		assert.Equal(t, "pending", o.Status)
+		assert.Nil(t, o.WaitlistPosition)
+		o1 = o
+
+		code, _ = enroll(t, u2Token, false)
+		assert.Equal(t, http.StatusConflict, code)
+	})
+
+	t.Run("Full Group With Flag: Queued In Order", func(t *testing.T) {
+		var code int
+		code, o2 = enroll(t, u2Token, true)
+		require.Equal(t, http.StatusCreated, code)
+		assert.Equal(t, "waitlisted", o2.Status)
+		require.NotNil(t, o2.WaitlistPosition)
+		assert.Equal(t, 1, *o2.WaitlistPosition)
+
+		code, o3 = enroll(t, u3Token, true)
+		require.Equal(t, http.StatusCreated, code)
+		require.NotNil(t, o3.WaitlistPosition)
+		assert.Equal(t, 2, *o3.WaitlistPosition)
+
+		code, o4 = enroll(t, u4Token, true)
+		require.Equal(t, http.StatusCreated, code)
+		assert.Equal(t, 3, *o4.WaitlistPosition)
+
+		// Waitlisted orders do not occupy seats.
+		w := executeRequest("GET", "/v1/pickup-groups/"+groupID, nil, hostToken)
+		var g pickupHttp.PickupGroupResponse
+		json.Unmarshal(w.Body.Bytes(), &g)
+		assert.Equal(t, 1, g.CurrentEnrolled)
+		assert.Equal(t, 3, g.WaitlistCount)
+	})
+
+	t.Run("Already Waitlisted: 409", func(t *testing.T) {
+		code, _ := enroll(t, u2Token, true)
+		assert.Equal(t, http.StatusConflict, code)
+	})
+
+	t.Run("Cancellation Promotes Head Of Queue", func(t *testing.T) {
+		cancelled := "cancelled"
+		w := executeRequest("PATCH", "/v1/pickup-orders/"+o1.ID, pickupHttp.UpdateOrderBody{Status: &cancelled}, u1Token)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		assert.Equal(t, "pending", orderStatus(t, o2.ID).Status)
+		assert.Nil(t, orderStatus(t, o2.ID).WaitlistPosition)
+		got := orderStatus(t, o3.ID)
+		assert.Equal(t, "waitlisted", got.Status)
+		require.NotNil(t, got.WaitlistPosition)
+		assert.Equal(t, 1, *got.WaitlistPosition)
+	})
+
+	t.Run("Rejection Promotes Next", func(t *testing.T) {
+		rejected := "rejected"
+		w := executeRequest("PATCH", "/v1/pickup-orders/"+o2.ID, pickupHttp.UpdateOrderBody{Status: &rejected}, hostToken)
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "pending", orderStatus(t, o3.ID).Status)
+	})
+
+	t.Run("Admin Delete Promotes Next", func(t *testing.T) {
+		w := executeRequest("DELETE", "/v1/pickup-orders/"+o3.ID, nil, sysAdminToken)
+		require.Equal(t, http.StatusNoContent, w.Code)
+		assert.Equal(t, "pending", orderStatus(t, o4.ID).Status)
+	})
+
+	t.Run("Capacity Increase Promotes Queue", func(t *testing.T) {
+		// u1 re-enrolls onto the (full) waitlist, then the host adds a seat.
+		code, o := enroll(t, u1Token, true)
+		require.Equal(t, http.StatusCreated, code)
+		require.Equal(t, "waitlisted", o.Status)
+
+		capacity := 2
+		w := executeRequest("PATCH", "/v1/pickup-groups/"+groupID, pickupHttp.UpdateGroupBody{Capacity: &capacity}, hostToken)
+		require.Equal(t, http.StatusOK, w.Code)
+		var g pickupHttp.PickupGroupResponse
+		json.Unmarshal(w.Body.Bytes(), &g)
+		assert.Equal(t, 2, g.CurrentEnrolled)
+		assert.Equal(t, 0, g.WaitlistCount)
+		assert.Equal(t, "pending", orderStatus(t, o.ID).Status)
+	})
+
+	t.Run("Waitlisted User May Leave Queue But Not Request Cancel", func(t *testing.T) {
+		// u2 was rejected earlier and stays blocked, even from the waitlist.
+		code, _ := enroll(t, u2Token, true)
+		assert.Equal(t, http.StatusConflict, code)
+
+		code, o := enroll(t, u3Token, true)
+		require.Equal(t, http.StatusCreated, code)
+		require.Equal(t, "waitlisted", o.Status)
+
+		cancelRequest := "cancel_request"
+		w := executeRequest("PATCH", "/v1/pickup-orders/"+o.ID, pickupHttp.UpdateOrderBody{Status: &cancelRequest}, u3Token)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+
+		cancelled := "cancelled"
+		w = executeRequest("PATCH", "/v1/pickup-orders/"+o.ID, pickupHttp.UpdateOrderBody{Status: &cancelled}, u3Token)
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "cancelled", orderStatus(t, o.ID).Status)
+	})
+}
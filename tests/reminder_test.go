@@ -0,0 +1,219 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nekogravitycat/court-booking-backend/internal/app"
+	"github.com/nekogravitycat/court-booking-backend/internal/booking"
+	"github.com/nekogravitycat/court-booking-backend/internal/pickup"
+	pickupHttp "github.com/nekogravitycat/court-booking-backend/internal/pickup/http"
+	"github.com/nekogravitycat/court-booking-backend/internal/pkg/event"
+	resHttp "github.com/nekogravitycat/court-booking-backend/internal/resource/http"
+)
+
+func TestReminders(t *testing.T) {
+	clearTables()
+	ctx := context.Background()
+
+	host := createTestUser(t, "host@remind.com", "pass", false)
+	grantPickupHost(t, host.ID)
+	player := createTestUser(t, "player@remind.com", "pass", false)
+	hostToken := generateToken(host.ID)
+	playerToken := generateToken(player.ID)
+
+	locationID := setupTestLocation(t, hostToken, host.ID)
+	w := executeRequest("POST", "/v1/resources", resHttp.CreateRequest{
+		Name: "Court 1", LocationID: locationID, ResourceType: "badminton",
+	}, hostToken)
+	require.Equal(t, http.StatusCreated, w.Code)
+	var court resHttp.ResourceResponse
+	json.Unmarshal(w.Body.Bytes(), &court)
+
+	// insertBooking adds a booking of the player directly, so its start and
+	// creation time can be placed freely relative to now.
+	insertBooking := func(t *testing.T, status string, startIn, createdAgo time.Duration) string {
+		var id string
+		require.NoError(t, testPool.QueryRow(ctx, `
+			INSERT INTO public.bookings (resource_id, user_id, start_time, end_time, status, created_at)
+			VALUES ($1, $2, now() + make_interval(secs => $3), now() + make_interval(secs => $3 + 3600), $4, now() - make_interval(secs => $5))
+			RETURNING id`,
+			court.ID, player.ID, startIn.Seconds(), status, createdAgo.Seconds(),
+		).Scan(&id))
+		return id
+	}
+	const longAgo = 48 * time.Hour
+	tomorrow := insertBooking(t, "confirmed", 23*time.Hour, longAgo)
+	soon := insertBooking(t, "confirmed", 90*time.Minute, longAgo)
+	insertBooking(t, "cancelled", 23*time.Hour, longAgo)
+	insertBooking(t, "pending", 21*time.Hour, longAgo)
+	insertBooking(t, "confirmed", 30*time.Hour, longAgo)
+	insertBooking(t, "confirmed", 19*time.Hour, time.Minute) // booked after its 24h mark
+
+	// A confirmed pickup order for a game tomorrow.
+	sportID, skillLevelID := getSportSkill(t, "BADMINTON", "B")
+	start := time.Now().Add(23 * time.Hour).Truncate(time.Second)
+	w = executeRequest("POST", "/v1/pickup-groups", pickupHttp.CreateGroupBody{
+		Title:        "Reminded Game",
+		StartTime:    start,
+		EndTime:      start.Add(2 * time.Hour),
+		Fee:          100,
+		Capacity:     6,
+		LocationID:   locationID,
+		SportID:      sportID,
+		SkillLevelID: skillLevelID,
+	}, hostToken)
+	require.Equal(t, http.StatusCreated, w.Code)
+	var group pickupHttp.PickupGroupResponse
+	json.Unmarshal(w.Body.Bytes(), &group)
+
+	w = executeRequest("POST", "/v1/pickup-groups/"+group.ID+"/orders", nil, playerToken)
+	require.Equal(t, http.StatusCreated, w.Code)
+	var order pickupHttp.PickupOrderResponse
+	json.Unmarshal(w.Body.Bytes(), &order)
+	confirmed := "confirmed"
+	w = executeRequest("PATCH", "/v1/pickup-orders/"+order.ID, pickupHttp.UpdateOrderBody{Status: &confirmed}, hostToken)
+	require.Equal(t, http.StatusOK, w.Code)
+	_, err := testPool.Exec(ctx, "UPDATE public.pickup_orders SET created_at = now() - interval '2 days' WHERE id = $1", order.ID)
+	require.NoError(t, err)
+
+	// reminders runs the scheduler once and returns the reminders it sent.
+	reminders := func(t *testing.T) []event.Event {
+		testEvents.take()
+		runWorker(t, app.ReminderWorker)
+		var sent []event.Event
+		for _, e := range testEvents.take() {
+			if e.Type == booking.EventBookingReminder || e.Type == pickup.EventOrderReminder {
+				sent = append(sent, e)
+			}
+		}
+		return sent
+	}
+	byID := func(events []event.Event) map[string]event.Event {
+		m := map[string]event.Event{}
+		for _, e := range events {
+			if id, ok := e.Data["booking_id"].(string); ok {
+				m[id] = e
+			} else {
+				m[e.Data["order_id"].(string)] = e
+			}
+		}
+		return m
+	}
+
+	t.Run("Due Confirmed Items Are Reminded Once", func(t *testing.T) {
+		sent := byID(reminders(t))
+		require.Len(t, sent, 3)
+
+		require.Contains(t, sent, tomorrow)
+		assert.Equal(t, player.ID, sent[tomorrow].UserID)
+		assert.EqualValues(t, 24*60, sent[tomorrow].Data["remind_before_minutes"])
+		assert.Equal(t, "Court 1", sent[tomorrow].Data["resource_name"])
+
+		require.Contains(t, sent, soon)
+		assert.EqualValues(t, 2*60, sent[soon].Data["remind_before_minutes"], "only the closest offset is sent")
+
+		require.Contains(t, sent, order.ID)
+		assert.Equal(t, pickup.EventOrderReminder, sent[order.ID].Type)
+		assert.Equal(t, "Reminded Game", sent[order.ID].Data["group_title"])
+		assert.EqualValues(t, 24*60, sent[order.ID].Data["remind_before_minutes"])
+
+		assert.Empty(t, reminders(t), "reminders are not repeated")
+	})
+
+	t.Run("Concurrent Schedulers Send Each Reminder Once", func(t *testing.T) {
+		_, err := testPool.Exec(ctx, "TRUNCATE public.booking_reminders, public.pickup_order_reminders")
+		require.NoError(t, err)
+		testEvents.take()
+
+		var run func(context.Context) error
+		for _, w := range testWorkers {
+			if w.Name == app.ReminderWorker {
+				run = w.Run
+			}
+		}
+		require.NotNil(t, run)
+		var wg sync.WaitGroup
+		errs := make([]error, 4)
+		for i := range errs {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				errs[i] = run(ctx)
+			}()
+		}
+		wg.Wait()
+		for _, err := range errs {
+			require.NoError(t, err)
+		}
+
+		count := 0
+		for _, e := range testEvents.take() {
+			if e.Type == booking.EventBookingReminder || e.Type == pickup.EventOrderReminder {
+				count++
+			}
+		}
+		assert.Equal(t, 3, count)
+	})
+
+	t.Run("Rescheduled And Cancelled Items", func(t *testing.T) {
+		// Moving the game requires the player to reconfirm; until then the
+		// order is not reminded.
+		newStart := start.Add(-3 * time.Hour)
+		newEnd := newStart.Add(2 * time.Hour)
+		w := executeRequest("PATCH", "/v1/pickup-groups/"+group.ID, pickupHttp.UpdateGroupBody{
+			StartTime: &newStart, EndTime: &newEnd,
+		}, hostToken)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		// The booking moves to another slot, which is reminded afresh, and the
+		// one starting soon is cancelled after its reminder.
+		_, err := testPool.Exec(ctx, "UPDATE public.bookings SET start_time = now() + interval '17 hours', end_time = now() + interval '18 hours' WHERE id = $1", tomorrow)
+		require.NoError(t, err)
+		_, err = testPool.Exec(ctx, "UPDATE public.bookings SET status = 'cancelled' WHERE id = $1", soon)
+		require.NoError(t, err)
+
+		sent := byID(reminders(t))
+		require.Len(t, sent, 1)
+		require.Contains(t, sent, tomorrow)
+		assert.EqualValues(t, 24*60, sent[tomorrow].Data["remind_before_minutes"])
+
+		w = executeRequest("POST", "/v1/pickup-orders/"+order.ID+"/reconfirm", nil, playerToken)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		sent = byID(reminders(t))
+		require.Len(t, sent, 1)
+		require.Contains(t, sent, order.ID)
+		remindedStart, err := time.Parse(time.RFC3339Nano, sent[order.ID].Data["start_time"].(string))
+		require.NoError(t, err)
+		assert.True(t, newStart.Equal(remindedStart), "reminded for the new time")
+	})
+
+	t.Run("Reminders Are Delivered As Notifications", func(t *testing.T) {
+		testMail.take()
+		runWorker(t, app.NotificationWorker)
+
+		var booked, played int
+		for _, m := range testMail.take() {
+			if m.To[0] != player.Email {
+				continue
+			}
+			if strings.Contains(m.Data, "Subject: Reminder: your booking starts soon") {
+				booked++
+			}
+			if strings.Contains(m.Data, "Subject: Reminder: your pickup game starts soon") {
+				played++
+			}
+		}
+		assert.Positive(t, booked)
+		assert.Positive(t, played)
+	})
+}
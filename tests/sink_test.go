@@ -0,0 +1,167 @@
+package tests
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+)
+
+// sinkMail is a message received by smtpSink.
+type sinkMail struct {
+	From string
+	To   []string
+	Data string
+}
+
+// smtpSink is a minimal local SMTP server that accepts every message and
+// keeps it, standing in for the mail relay of the email notification channel.
+type smtpSink struct {
+	ln   net.Listener
+	mu   sync.Mutex
+	mail []sinkMail
+}
+
+func startSMTPSink() (*smtpSink, error) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+	s := &smtpSink{ln: ln}
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go s.handle(conn)
+		}
+	}()
+	return s, nil
+}
+
+func (s *smtpSink) port() int {
+	return s.ln.Addr().(*net.TCPAddr).Port
+}
+
+func (s *smtpSink) close() {
+	s.ln.Close()
+}
+
+// take returns the messages received so far and forgets them.
+func (s *smtpSink) take() []sinkMail {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	mail := s.mail
+	s.mail = nil
+	return mail
+}
+
+func (s *smtpSink) handle(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	reply := func(line string) { fmt.Fprintf(conn, "%s\r\n", line) }
+	// address extracts the mailbox from "MAIL FROM:<a@b>" style arguments.
+	address := func(arg string) string {
+		arg = strings.TrimSpace(arg)
+		if i := strings.Index(arg, ">"); i >= 0 {
+			arg = arg[:i]
+		}
+		return strings.TrimPrefix(arg, "<")
+	}
+
+	reply("220 sink ESMTP")
+	var m sinkMail
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		cmd := strings.ToUpper(line)
+		switch {
+		case strings.HasPrefix(cmd, "EHLO"), strings.HasPrefix(cmd, "HELO"):
+			reply("250 sink")
+		case strings.HasPrefix(cmd, "MAIL FROM:"):
+			m = sinkMail{From: address(line[len("MAIL FROM:"):])}
+			reply("250 OK")
+		case strings.HasPrefix(cmd, "RCPT TO:"):
+			m.To = append(m.To, address(line[len("RCPT TO:"):]))
+			reply("250 OK")
+		case cmd == "DATA":
+			reply("354 End data with <CR><LF>.<CR><LF>")
+			var data strings.Builder
+			for {
+				l, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if l == ".\r\n" {
+					break
+				}
+				data.WriteString(strings.TrimPrefix(l, "."))
+			}
+			m.Data = data.String()
+			s.mu.Lock()
+			s.mail = append(s.mail, m)
+			s.mu.Unlock()
+			reply("250 OK")
+		case cmd == "RSET", cmd == "NOOP":
+			reply("250 OK")
+		case cmd == "QUIT":
+			reply("221 Bye")
+			return
+		default:
+			reply("502 Command not implemented")
+		}
+	}
+}
+
+// sinkRequest is a request received by webhookSink.
+type sinkRequest struct {
+	Header http.Header
+	Body   []byte
+}
+
+// webhookSink records webhook requests. While failing is set it answers 500.
+type webhookSink struct {
+	*httptest.Server
+	mu       sync.Mutex
+	failing  bool
+	requests []sinkRequest
+}
+
+func startWebhookSink() *webhookSink {
+	s := &webhookSink{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.requests = append(s.requests, sinkRequest{Header: r.Header.Clone(), Body: body})
+		if s.failing {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	return s
+}
+
+func (s *webhookSink) setFailing(failing bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failing = failing
+}
+
+// take returns the requests received so far and forgets them.
+func (s *webhookSink) take() []sinkRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	requests := s.requests
+	s.requests = nil
+	return requests
+}
@@ -0,0 +1,159 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	pickupHttp "github.com/nekogravitycat/court-booking-backend/internal/pickup/http"
+	skillProfileHttp "github.com/nekogravitycat/court-booking-backend/internal/skillprofile/http"
+)
+
+func TestSkillProfiles(t *testing.T) {
+	clearTables()
+
+	host := createTestUser(t, "host@skill.com", "pass", false)
+	grantPickupHost(t, host.ID)
+	skilled := createTestUser(t, "skilled@skill.com", "pass", false)
+	novice := createTestUser(t, "novice@skill.com", "pass", false)
+	undeclared := createTestUser(t, "undeclared@skill.com", "pass", false)
+
+	hostToken := generateToken(host.ID)
+	skilledToken := generateToken(skilled.ID)
+	noviceToken := generateToken(novice.ID)
+	undeclaredToken := generateToken(undeclared.ID)
+
+	locationID := setupTestLocation(t, hostToken, host.ID)
+	sportID, levelA := getSportSkill(t, "BADMINTON", "A")
+	_, levelB := getSportSkill(t, "BADMINTON", "B")
+	_, levelC := getSportSkill(t, "BADMINTON", "C")
+	_, levelD := getSportSkill(t, "BADMINTON", "D")
+	_, basketballA := getSportSkill(t, "BASKETBALL", "A")
+
+	declare := func(t *testing.T, levelID, token string) (int, skillProfileHttp.SkillProfileResponse) {
+		w := executeRequest("PUT", "/v1/me/skill-profiles/"+sportID,
+			skillProfileHttp.DeclareSkillBody{SkillLevelID: levelID}, token)
+		var p skillProfileHttp.SkillProfileResponse
+		json.Unmarshal(w.Body.Bytes(), &p)
+		return w.Code, p
+	}
+
+	t.Run("Declare Skill Level", func(t *testing.T) {
+		code, p := declare(t, levelB, skilledToken)
+		require.Equal(t, http.StatusOK, code)
+		assert.Equal(t, sportID, p.Sport.ID)
+		assert.Equal(t, "B", p.SkillLevel.Name)
+		assert.False(t, p.Verified)
+
+		code, _ = declare(t, levelD, noviceToken)
+		require.Equal(t, http.StatusOK, code)
+
+		w := executeRequest("GET", "/v1/users/"+skilled.ID+"/skill-profiles", nil, hostToken)
+		require.Equal(t, http.StatusOK, w.Code)
+		var list []skillProfileHttp.SkillProfileResponse
+		json.Unmarshal(w.Body.Bytes(), &list)
+		require.Len(t, list, 1)
+		assert.Equal(t, levelB, list[0].SkillLevel.ID)
+	})
+
+	t.Run("Level Of Another Sport: 400", func(t *testing.T) {
+		code, _ := declare(t, basketballA, skilledToken)
+		assert.Equal(t, http.StatusBadRequest, code)
+	})
+
+	createGroup := func(minID, maxID *string) (int, string) {
+		w := executeRequest("POST", "/v1/pickup-groups", pickupHttp.CreateGroupBody{
+			Title:           "Skill Range Group",
+			StartTime:       time.Now().Add(24 * time.Hour),
+			EndTime:         time.Now().Add(26 * time.Hour),
+			Capacity:        4,
+			LocationID:      locationID,
+			SportID:         sportID,
+			SkillLevelID:    levelB,
+			MinSkillLevelID: minID,
+			MaxSkillLevelID: maxID,
+		}, hostToken)
+		var g pickupHttp.PickupGroupResponse
+		json.Unmarshal(w.Body.Bytes(), &g)
+		return w.Code, g.ID
+	}
+
+	t.Run("Inverted Range: 400", func(t *testing.T) {
+		code, _ := createGroup(&levelC, &levelA)
+		assert.Equal(t, http.StatusBadRequest, code)
+
+		code, _ = createGroup(&basketballA, nil)
+		assert.Equal(t, http.StatusBadRequest, code)
+	})
+
+	code, groupID := createGroup(&levelA, &levelC)
+	require.Equal(t, http.StatusCreated, code)
+	enroll := func(token string) (int, string) {
+		w := executeRequest("POST", "/v1/pickup-groups/"+groupID+"/orders", nil, token)
+		var o pickupHttp.PickupOrderResponse
+		json.Unmarshal(w.Body.Bytes(), &o)
+		return w.Code, o.ID
+	}
+
+	var orderID string
+	t.Run("Range Enforced On Enrollment", func(t *testing.T) {
+		code, _ := enroll(undeclaredToken)
+		assert.Equal(t, http.StatusForbidden, code)
+
+		code, _ = enroll(noviceToken)
+		assert.Equal(t, http.StatusForbidden, code)
+
+		code, orderID = enroll(skilledToken)
+		assert.Equal(t, http.StatusCreated, code)
+	})
+
+	t.Run("Host Verifies After Game", func(t *testing.T) {
+		verify := func(token string) (int, skillProfileHttp.SkillProfileResponse) {
+			w := executeRequest("POST", "/v1/pickup-orders/"+orderID+"/skill-verification",
+				pickupHttp.VerifySkillBody{SkillLevelID: levelA}, token)
+			var p skillProfileHttp.SkillProfileResponse
+			json.Unmarshal(w.Body.Bytes(), &p)
+			return w.Code, p
+		}
+
+		confirmed := "confirmed"
+		w := executeRequest("PATCH", "/v1/pickup-orders/"+orderID, pickupHttp.UpdateOrderBody{Status: &confirmed}, hostToken)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		code, _ := verify(hostToken)
+		assert.Equal(t, http.StatusConflict, code, "group has not started")
+
+		_, err := testPool.Exec(context.Background(),
+			"UPDATE public.pickup_groups SET start_time = $2 WHERE id = $1",
+			groupID, time.Now().Add(-time.Hour))
+		require.NoError(t, err)
+
+		code, _ = verify(noviceToken)
+		assert.Equal(t, http.StatusForbidden, code)
+
+		code, p := verify(hostToken)
+		require.Equal(t, http.StatusOK, code)
+		assert.Equal(t, levelA, p.SkillLevel.ID)
+		assert.True(t, p.Verified)
+		require.NotNil(t, p.VerifiedBy)
+		assert.Equal(t, host.ID, *p.VerifiedBy)
+
+		// Re-declaring drops the verification.
+		code, p = declare(t, levelB, skilledToken)
+		require.Equal(t, http.StatusOK, code)
+		assert.False(t, p.Verified)
+	})
+
+	t.Run("Delete Profile", func(t *testing.T) {
+		w := executeRequest("DELETE", "/v1/me/skill-profiles/"+sportID, nil, noviceToken)
+		assert.Equal(t, http.StatusNoContent, w.Code)
+
+		w = executeRequest("DELETE", "/v1/me/skill-profiles/"+sportID, nil, noviceToken)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
@@ -0,0 +1,396 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nekogravitycat/court-booking-backend/internal/app"
+	"github.com/nekogravitycat/court-booking-backend/internal/booking"
+	bookingHttp "github.com/nekogravitycat/court-booking-backend/internal/booking/http"
+	locHttp "github.com/nekogravitycat/court-booking-backend/internal/location/http"
+	orgHttp "github.com/nekogravitycat/court-booking-backend/internal/organization/http"
+	pickupHttp "github.com/nekogravitycat/court-booking-backend/internal/pickup/http"
+	"github.com/nekogravitycat/court-booking-backend/internal/pkg/response"
+	resHttp "github.com/nekogravitycat/court-booking-backend/internal/resource/http"
+	"github.com/nekogravitycat/court-booking-backend/internal/webhook"
+	webhookHttp "github.com/nekogravitycat/court-booking-backend/internal/webhook/http"
+)
+
+func TestOrganizationWebhooks(t *testing.T) {
+	clearTables()
+	ctx := context.Background()
+
+	sysAdmin := createTestUser(t, "sysadmin@hooks.com", "pass", true)
+	owner := createTestUser(t, "owner@hooks.com", "pass", false)
+	manager := createTestUser(t, "manager@hooks.com", "pass", false)
+	booker := createTestUser(t, "booker@hooks.com", "pass", false)
+	sysAdminToken := generateToken(sysAdmin.ID)
+	ownerToken := generateToken(owner.ID)
+	managerToken := generateToken(manager.ID)
+	bookerToken := generateToken(booker.ID)
+
+	// Endpoints of the organization's own systems.
+	doors := startWebhookSink()
+	defer doors.Close()
+	pos := startWebhookSink()
+	defer pos.Close()
+
+	w := executeRequest("POST", "/v1/organizations", orgHttp.CreateOrganizationRequest{Name: "Hooked Center", OwnerID: owner.ID}, sysAdminToken)
+	require.Equal(t, http.StatusCreated, w.Code)
+	var org orgHttp.OrganizationResponse
+	json.Unmarshal(w.Body.Bytes(), &org)
+	executeRequest("POST", "/v1/organizations/"+org.ID+"/members", orgHttp.AddOrganizationMemberRequest{Email: manager.Email}, sysAdminToken)
+	w = executeRequest("POST", "/v1/organizations/"+org.ID+"/managers", orgHttp.AddOrganizationManagerRequest{UserID: manager.ID}, sysAdminToken)
+	require.Equal(t, http.StatusCreated, w.Code)
+
+	w = executeRequest("POST", "/v1/organizations", orgHttp.CreateOrganizationRequest{Name: "Other Center", OwnerID: sysAdmin.ID}, sysAdminToken)
+	require.Equal(t, http.StatusCreated, w.Code)
+	var otherOrg orgHttp.OrganizationResponse
+	json.Unmarshal(w.Body.Bytes(), &otherOrg)
+
+	w = executeRequest("POST", "/v1/locations", locHttp.CreateLocationRequest{
+		OrganizationID:    org.ID,
+		Name:              "Hooked Courts",
+		Capacity:          10,
+		OpeningHoursStart: "06:00:00", OpeningHoursEnd: "23:00:00",
+		Opening:      true,
+		Timezone:     "UTC",
+		LocationInfo: "Street 1", Longitude: 121.0, Latitude: 25.0,
+	}, ownerToken)
+	require.Equal(t, http.StatusCreated, w.Code)
+	var loc locHttp.LocationResponse
+	json.Unmarshal(w.Body.Bytes(), &loc)
+	w = executeRequest("POST", "/v1/resources", resHttp.CreateRequest{Name: "Court 1", LocationID: loc.ID, ResourceType: "badminton"}, ownerToken)
+	require.Equal(t, http.StatusCreated, w.Code)
+	var court resHttp.ResourceResponse
+	json.Unmarshal(w.Body.Bytes(), &court)
+
+	// Bookings take consecutive one-hour slots from tomorrow 08:00 UTC.
+	base := time.Now().UTC().Truncate(24 * time.Hour).Add(32 * time.Hour)
+	slot := 0
+	book := func(t *testing.T) string {
+		start := base.Add(time.Duration(slot) * time.Hour)
+		slot++
+		w := executeRequest("POST", "/v1/bookings", bookingHttp.CreateBookingRequest{
+			ResourceID: court.ID, StartTime: start, EndTime: start.Add(time.Hour),
+		}, bookerToken)
+		require.Equal(t, http.StatusCreated, w.Code)
+		var b bookingHttp.BookingResponse
+		json.Unmarshal(w.Body.Bytes(), &b)
+		return b.ID
+	}
+	updateBooking := func(t *testing.T, id string, body bookingHttp.UpdateBookingRequest, token string) {
+		w := executeRequest("PATCH", "/v1/bookings/"+id, body, token)
+		require.Equal(t, http.StatusOK, w.Code)
+	}
+
+	hooksPath := "/v1/organizations/" + org.ID + "/webhooks"
+	createHook := func(t *testing.T, body webhookHttp.CreateWebhookBody) webhookHttp.WebhookResponse {
+		w := executeRequest("POST", hooksPath, body, ownerToken)
+		require.Equal(t, http.StatusCreated, w.Code)
+		var resp webhookHttp.WebhookResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		return resp
+	}
+	deliveries := func(t *testing.T, hookID, query string) response.PageResponse[webhookHttp.DeliveryResponse] {
+		w := executeRequest("GET", hooksPath+"/"+hookID+"/deliveries"+query, nil, ownerToken)
+		require.Equal(t, http.StatusOK, w.Code)
+		var resp response.PageResponse[webhookHttp.DeliveryResponse]
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		return resp
+	}
+
+	// received is the body of a webhook request.
+	type received struct {
+		ID             string         `json:"id"`
+		Type           string         `json:"type"`
+		OrganizationID string         `json:"organization_id"`
+		Data           map[string]any `json:"data"`
+	}
+	// verify checks a request's headers and signature and decodes its body.
+	verify := func(t *testing.T, r sinkRequest, secret string) received {
+		assert.Equal(t, webhook.Sign(secret, r.Body), r.Header.Get(webhook.HeaderSignature), "signed with the webhook secret")
+		var p received
+		require.NoError(t, json.Unmarshal(r.Body, &p))
+		assert.Equal(t, p.Type, r.Header.Get(webhook.HeaderEvent))
+		assert.Equal(t, p.ID, r.Header.Get(webhook.HeaderEventID))
+		assert.NotEmpty(t, r.Header.Get(webhook.HeaderDelivery))
+		return p
+	}
+
+	var doorHook, posHook webhookHttp.WebhookResponse
+
+	t.Run("Manage Webhooks", func(t *testing.T) {
+		w := executeRequest("POST", hooksPath, webhookHttp.CreateWebhookBody{
+			URL: doors.URL, EventTypes: []string{booking.OrgEventBookingCreated},
+		}, managerToken)
+		assert.Equal(t, http.StatusForbidden, w.Code, "managers cannot register webhooks")
+
+		for name, body := range map[string]webhookHttp.CreateWebhookBody{
+			"relative url":   {URL: "/hook", EventTypes: []string{booking.OrgEventBookingCreated}},
+			"ftp url":        {URL: "ftp://example.com/hook", EventTypes: []string{booking.OrgEventBookingCreated}},
+			"no event types": {URL: doors.URL, EventTypes: []string{}},
+			"unknown event":  {URL: doors.URL, EventTypes: []string{"booking.exploded"}},
+		} {
+			w := executeRequest("POST", hooksPath, body, ownerToken)
+			assert.Equal(t, http.StatusBadRequest, w.Code, name)
+		}
+
+		doorHook = createHook(t, webhookHttp.CreateWebhookBody{
+			URL: doors.URL,
+			EventTypes: []string{
+				booking.OrgEventBookingCreated, booking.OrgEventBookingUpdated,
+				booking.OrgEventBookingCancelled, booking.OrgEventBookingCreated,
+			},
+		})
+		assert.True(t, strings.HasPrefix(doorHook.Secret, "whsec_"))
+		assert.True(t, doorHook.IsActive)
+		assert.Equal(t, []string{booking.OrgEventBookingCancelled, booking.OrgEventBookingCreated, booking.OrgEventBookingUpdated},
+			doorHook.EventTypes, "event types are sorted and deduplicated")
+
+		description := "Till"
+		posHook = createHook(t, webhookHttp.CreateWebhookBody{
+			URL: pos.URL, Description: &description, EventTypes: []string{booking.OrgEventPaymentStatusChanged},
+		})
+		assert.NotEqual(t, doorHook.Secret, posHook.Secret)
+
+		w = executeRequest("GET", hooksPath, nil, ownerToken)
+		require.Equal(t, http.StatusOK, w.Code)
+		var list []webhookHttp.WebhookResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &list))
+		require.Len(t, list, 2)
+		for _, h := range list {
+			assert.Empty(t, h.Secret, "secrets are not listed")
+		}
+
+		w = executeRequest("GET", hooksPath+"/"+posHook.ID, nil, sysAdminToken)
+		require.Equal(t, http.StatusOK, w.Code)
+		var got webhookHttp.WebhookResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &got))
+		assert.Equal(t, posHook.Secret, got.Secret)
+		assert.Equal(t, "Till", *got.Description)
+
+		w = executeRequest("GET", "/v1/organizations/"+otherOrg.ID+"/webhooks/"+posHook.ID, nil, sysAdminToken)
+		assert.Equal(t, http.StatusNotFound, w.Code, "webhooks are scoped to their organization")
+		w = executeRequest("GET", hooksPath, nil, managerToken)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		w = executeRequest("GET", hooksPath, nil, "")
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("Booking Changes Are Delivered", func(t *testing.T) {
+		doors.take()
+		pos.take()
+
+		id := book(t)
+		confirmed, done, cancelled := "confirmed", "done", "cancelled"
+		updateBooking(t, id, bookingHttp.UpdateBookingRequest{Status: &confirmed, PaymentStatus: &done}, managerToken)
+		updateBooking(t, id, bookingHttp.UpdateBookingRequest{Status: &cancelled}, bookerToken)
+		runWorker(t, app.WebhookWorker)
+
+		sent := doors.take()
+		require.Len(t, sent, 3)
+		created := verify(t, sent[0], doorHook.Secret)
+		assert.Equal(t, booking.OrgEventBookingCreated, created.Type)
+		assert.Equal(t, org.ID, created.OrganizationID)
+		assert.Equal(t, id, created.Data["booking_id"])
+		assert.Equal(t, booker.ID, created.Data["user_id"])
+		assert.Equal(t, "Court 1", created.Data["resource_name"])
+		assert.Equal(t, "pending", created.Data["status"])
+
+		updated := verify(t, sent[1], doorHook.Secret)
+		assert.Equal(t, booking.OrgEventBookingUpdated, updated.Type)
+		assert.Equal(t, "confirmed", updated.Data["status"])
+		assert.Equal(t, map[string]any{"status": "pending"}, updated.Data["previous"])
+
+		cancel := verify(t, sent[2], doorHook.Secret)
+		assert.Equal(t, booking.OrgEventBookingCancelled, cancel.Type)
+		assert.Equal(t, "cancelled", cancel.Data["status"])
+
+		paid := pos.take()
+		require.Len(t, paid, 1, "only subscribed events are sent")
+		payment := verify(t, paid[0], posHook.Secret)
+		assert.Equal(t, booking.OrgEventPaymentStatusChanged, payment.Type)
+		assert.Equal(t, "done", payment.Data["payment_status"])
+		assert.Equal(t, map[string]any{"payment_status": "pending"}, payment.Data["previous"])
+
+		log := deliveries(t, doorHook.ID, "")
+		require.Equal(t, 3, log.Total)
+		assert.Equal(t, booking.OrgEventBookingCancelled, log.Items[0].EventType, "newest first")
+		for _, d := range log.Items {
+			assert.Equal(t, "delivered", d.Status)
+			assert.Equal(t, 1, d.Attempts)
+			assert.Equal(t, http.StatusNoContent, *d.ResponseStatus)
+			assert.NotNil(t, d.DeliveredAt)
+			assert.Nil(t, d.NextAttemptAt)
+		}
+		assert.Equal(t, 1, deliveries(t, doorHook.ID, "?event_type="+booking.OrgEventBookingCreated).Total)
+
+		w := executeRequest("GET", hooksPath+"/"+doorHook.ID+"/deliveries?status=lost", nil, ownerToken)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("Pickup Group Courts Are Delivered", func(t *testing.T) {
+		grantPickupHost(t, owner.ID)
+		sportID, skillLevelID := getSportSkill(t, "BADMINTON", "B")
+		start := base.Add(time.Duration(slot) * time.Hour)
+		slot += 3
+		doors.take()
+
+		w := executeRequest("POST", "/v1/pickup-groups", pickupHttp.CreateGroupBody{
+			Title: "Hooked Game", StartTime: start, EndTime: start.Add(time.Hour), Capacity: 6,
+			LocationID: loc.ID, SportID: sportID, SkillLevelID: skillLevelID, ResourceID: &court.ID,
+		}, ownerToken)
+		require.Equal(t, http.StatusCreated, w.Code)
+		var group pickupHttp.PickupGroupResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &group))
+		require.NotNil(t, group.BookingID)
+
+		end := start.Add(2 * time.Hour)
+		w = executeRequest("PATCH", "/v1/pickup-groups/"+group.ID, pickupHttp.UpdateGroupBody{EndTime: &end}, ownerToken)
+		require.Equal(t, http.StatusOK, w.Code)
+		w = executeRequest("DELETE", "/v1/pickup-groups/"+group.ID, nil, sysAdminToken)
+		require.Equal(t, http.StatusNoContent, w.Code)
+		runWorker(t, app.WebhookWorker)
+
+		sent := doors.take()
+		require.Len(t, sent, 3, "the group's court booking is reported like any other")
+		created := verify(t, sent[0], doorHook.Secret)
+		assert.Equal(t, booking.OrgEventBookingCreated, created.Type)
+		assert.Equal(t, *group.BookingID, created.Data["booking_id"])
+		assert.Equal(t, group.ID, created.Data["pickup_group_id"])
+
+		updated := verify(t, sent[1], doorHook.Secret)
+		assert.Equal(t, booking.OrgEventBookingUpdated, updated.Type)
+		assert.Contains(t, updated.Data["previous"], "end_time")
+
+		cancel := verify(t, sent[2], doorHook.Secret)
+		assert.Equal(t, booking.OrgEventBookingCancelled, cancel.Type)
+		assert.Equal(t, map[string]any{"status": "pending"}, cancel.Data["previous"])
+	})
+
+	var failedID string
+
+	t.Run("Failed Deliveries Are Retried And Logged", func(t *testing.T) {
+		doors.setFailing(true)
+		defer doors.setFailing(false)
+
+		book(t)
+		runWorker(t, app.WebhookWorker)
+		require.Len(t, doors.take(), 1)
+
+		pending := deliveries(t, doorHook.ID, "?status=pending")
+		require.Equal(t, 1, pending.Total)
+		d := pending.Items[0]
+		assert.Equal(t, 1, d.Attempts)
+		assert.Equal(t, http.StatusInternalServerError, *d.ResponseStatus)
+		require.NotNil(t, d.LastError)
+		assert.Contains(t, *d.LastError, "500")
+		require.NotNil(t, d.NextAttemptAt)
+		assert.True(t, d.NextAttemptAt.After(time.Now()), "retried later")
+
+		// Not due yet.
+		runWorker(t, app.WebhookWorker)
+		assert.Empty(t, doors.take())
+
+		// Make the next attempt due and the last one allowed.
+		_, err := testPool.Exec(ctx, "UPDATE public.webhook_deliveries SET next_attempt_at = now(), attempts = $2 WHERE id = $1",
+			d.ID, webhook.MaxAttempts-1)
+		require.NoError(t, err)
+		runWorker(t, app.WebhookWorker)
+		require.Len(t, doors.take(), 1)
+
+		failed := deliveries(t, doorHook.ID, "?status=failed")
+		require.Equal(t, 1, failed.Total)
+		assert.Equal(t, webhook.MaxAttempts, failed.Items[0].Attempts)
+		failedID = failed.Items[0].ID
+	})
+
+	t.Run("Manual Redelivery", func(t *testing.T) {
+		redeliverPath := hooksPath + "/" + doorHook.ID + "/deliveries/" + failedID + "/redeliver"
+
+		w := executeRequest("POST", redeliverPath, nil, managerToken)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		w = executeRequest("POST", hooksPath+"/"+posHook.ID+"/deliveries/"+failedID+"/redeliver", nil, ownerToken)
+		assert.Equal(t, http.StatusNotFound, w.Code, "the delivery belongs to another webhook")
+
+		w = executeRequest("POST", redeliverPath, nil, ownerToken)
+		require.Equal(t, http.StatusAccepted, w.Code)
+		var redelivery webhookHttp.DeliveryResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &redelivery))
+		assert.Equal(t, "pending", redelivery.Status)
+		assert.Equal(t, failedID, *redelivery.RedeliveryOf)
+
+		w = executeRequest("POST", hooksPath+"/"+doorHook.ID+"/deliveries/"+redelivery.ID+"/redeliver", nil, ownerToken)
+		assert.Equal(t, http.StatusConflict, w.Code, "pending deliveries cannot be redelivered")
+
+		runWorker(t, app.WebhookWorker)
+		sent := doors.take()
+		require.Len(t, sent, 1)
+		p := verify(t, sent[0], doorHook.Secret)
+		assert.Equal(t, redelivery.EventID, p.ID, "same event id")
+		assert.Equal(t, redelivery.ID, sent[0].Header.Get(webhook.HeaderDelivery))
+
+		log := deliveries(t, doorHook.ID, "")
+		assert.Equal(t, 8, log.Total, "the failed delivery stays in the log")
+		assert.Equal(t, "delivered", log.Items[0].Status)
+	})
+
+	t.Run("Update, Disable And Delete", func(t *testing.T) {
+		w := executeRequest("PATCH", hooksPath+"/"+doorHook.ID, webhookHttp.UpdateWebhookBody{RotateSecret: true}, ownerToken)
+		require.Equal(t, http.StatusOK, w.Code)
+		var rotated webhookHttp.WebhookResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &rotated))
+		assert.NotEqual(t, doorHook.Secret, rotated.Secret)
+
+		w = executeRequest("PATCH", hooksPath+"/"+doorHook.ID, webhookHttp.UpdateWebhookBody{EventTypes: []string{"nope"}}, ownerToken)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+
+		// Deliveries are signed with the secret current when they are sent.
+		book(t)
+		runWorker(t, app.WebhookWorker)
+		sent := doors.take()
+		require.Len(t, sent, 1)
+		verify(t, sent[0], rotated.Secret)
+
+		off := false
+		w = executeRequest("PATCH", hooksPath+"/"+doorHook.ID, webhookHttp.UpdateWebhookBody{IsActive: &off}, ownerToken)
+		require.Equal(t, http.StatusOK, w.Code)
+		book(t)
+		runWorker(t, app.WebhookWorker)
+		assert.Empty(t, doors.take(), "disabled webhooks receive nothing")
+		w = executeRequest("POST", hooksPath+"/"+doorHook.ID+"/deliveries/"+failedID+"/redeliver", nil, ownerToken)
+		assert.Equal(t, http.StatusConflict, w.Code)
+
+		w = executeRequest("DELETE", hooksPath+"/"+doorHook.ID, nil, managerToken)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		w = executeRequest("DELETE", hooksPath+"/"+doorHook.ID, nil, ownerToken)
+		require.Equal(t, http.StatusNoContent, w.Code)
+		w = executeRequest("GET", hooksPath+"/"+doorHook.ID, nil, ownerToken)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+
+		var n int
+		require.NoError(t, testPool.QueryRow(ctx, "SELECT COUNT(*) FROM public.webhook_deliveries WHERE webhook_id = $1", doorHook.ID).Scan(&n))
+		assert.Zero(t, n, "the delivery log goes with the webhook")
+	})
+
+	t.Run("Webhook Limit", func(t *testing.T) {
+		for i := 1; i < webhook.MaxWebhooksPerOrganization; i++ {
+			createHook(t, webhookHttp.CreateWebhookBody{
+				URL: fmt.Sprintf("%s/%d", pos.URL, i), EventTypes: []string{booking.OrgEventBookingCreated},
+			})
+		}
+		w := executeRequest("POST", hooksPath, webhookHttp.CreateWebhookBody{
+			URL: pos.URL, EventTypes: []string{booking.OrgEventBookingCreated},
+		}, ownerToken)
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+}